@@ -0,0 +1,292 @@
+// Package aggregation aggregates the envelopes received from Loggregator
+// over tumbling windows. Counters are summed, gauges are reduced to their
+// last, average and maximum values and timers are reduced to percentiles.
+// Aggregates are keyed by source ID, name and tags. A CumulativeConverter
+// turns counter deltas into cumulative totals and TopTalkers maintains the
+// source IDs with the largest volume of envelopes.
+package aggregation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// Kind is the kind of metric an Aggregate was computed from.
+type Kind int
+
+// The kinds of metrics that are aggregated.
+const (
+	Counter Kind = iota
+	Gauge
+	Timer
+)
+
+// Aggregate is the result of aggregating a single metric over a window.
+type Aggregate struct {
+	Kind     Kind
+	SourceID string
+	Name     string
+	Tags     map[string]string
+	Start    time.Time
+	End      time.Time
+
+	// Count is the number of values that were aggregated.
+	Count int
+
+	// Sum is the sum of the deltas of a counter.
+	Sum uint64
+
+	// Last, Avg and Max are the last, average and maximum values of a
+	// gauge metric. Unit is the unit of the gauge metric.
+	Last float64
+	Avg  float64
+	Max  float64
+	Unit string
+
+	// Percentiles maps the configured percentiles to the durations of a
+	// timer.
+	Percentiles map[float64]time.Duration
+}
+
+// Envelope converts the aggregate into an envelope. Counters become counter
+// envelopes. Gauges and timers become gauge envelopes with one metric per
+// aggregated value, e.g. "cpu.max" or "request.p99". The envelope is tagged
+// with the length of the window.
+func (a *Aggregate) Envelope() *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		Timestamp: a.End.UnixNano(),
+		SourceId:  a.SourceID,
+		Tags:      make(map[string]string),
+	}
+	for k, v := range a.Tags {
+		e.Tags[k] = v
+	}
+	e.Tags["aggregation_window"] = a.End.Sub(a.Start).String()
+
+	switch a.Kind {
+	case Counter:
+		e.Message = &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{
+				Name:  a.Name,
+				Delta: a.Sum,
+			},
+		}
+	case Gauge:
+		e.Message = &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: map[string]*loggregator_v2.GaugeValue{
+					a.Name + ".last": {Value: a.Last, Unit: a.Unit},
+					a.Name + ".avg":  {Value: a.Avg, Unit: a.Unit},
+					a.Name + ".max":  {Value: a.Max, Unit: a.Unit},
+				},
+			},
+		}
+	case Timer:
+		metrics := make(map[string]*loggregator_v2.GaugeValue)
+		for p, d := range a.Percentiles {
+			name := fmt.Sprintf("%s.p%s", a.Name, strings.Replace(fmt.Sprint(p), ".", "_", -1))
+			metrics[name] = &loggregator_v2.GaugeValue{Value: float64(d), Unit: "ns"}
+		}
+		e.Message = &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{Metrics: metrics},
+		}
+	}
+
+	return e
+}
+
+// AggregatorOption configures an Aggregator.
+type AggregatorOption func(*Aggregator)
+
+// WithPercentiles configures the percentiles computed for timers. It
+// defaults to 50, 95 and 99.
+func WithPercentiles(percentiles ...float64) AggregatorOption {
+	return func(a *Aggregator) {
+		a.percentiles = percentiles
+	}
+}
+
+// Aggregator aggregates envelopes over tumbling windows. It is safe for
+// concurrent use. It should be created with the New constructor.
+type Aggregator struct {
+	window      time.Duration
+	percentiles []float64
+
+	mu    sync.Mutex
+	start time.Time
+	aggs  map[string]*aggregate
+	now   func() time.Time
+}
+
+type aggregate struct {
+	Aggregate
+
+	sum       float64
+	durations []time.Duration
+}
+
+// New creates an Aggregator with the given window length.
+func New(window time.Duration, opts ...AggregatorOption) *Aggregator {
+	a := &Aggregator{
+		window:      window,
+		percentiles: []float64{50, 95, 99},
+		aggs:        make(map[string]*aggregate),
+		now:         time.Now,
+	}
+
+	for _, o := range opts {
+		o(a)
+	}
+
+	a.start = a.now()
+
+	return a
+}
+
+// Add adds the envelope to the current window. Envelopes other than
+// counters, gauges and timers are ignored.
+func (a *Aggregator) Add(e *loggregator_v2.Envelope) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch m := e.GetMessage().(type) {
+	case *loggregator_v2.Envelope_Counter:
+		agg := a.aggregate(Counter, e, m.Counter.GetName())
+		agg.Count++
+		agg.Sum += m.Counter.GetDelta()
+	case *loggregator_v2.Envelope_Gauge:
+		for name, v := range m.Gauge.GetMetrics() {
+			agg := a.aggregate(Gauge, e, name)
+			value := v.GetValue()
+			if agg.Count == 0 || value > agg.Max {
+				agg.Max = value
+			}
+			agg.Count++
+			agg.sum += value
+			agg.Last = value
+			agg.Unit = v.GetUnit()
+		}
+	case *loggregator_v2.Envelope_Timer:
+		agg := a.aggregate(Timer, e, m.Timer.GetName())
+		agg.Count++
+		agg.durations = append(agg.durations, time.Duration(m.Timer.GetStop()-m.Timer.GetStart()))
+	}
+}
+
+func (a *Aggregator) aggregate(kind Kind, e *loggregator_v2.Envelope, name string) *aggregate {
+	k := key(kind, e.GetSourceId(), name, e.GetTags())
+
+	agg, ok := a.aggs[k]
+	if !ok {
+		agg = &aggregate{
+			Aggregate: Aggregate{
+				Kind:     kind,
+				SourceID: e.GetSourceId(),
+				Name:     name,
+				Tags:     e.GetTags(),
+			},
+		}
+		a.aggs[k] = agg
+	}
+
+	return agg
+}
+
+// Flush ends the current window and returns its aggregates. A new window is
+// started.
+func (a *Aggregator) Flush() []*Aggregate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	end := a.now()
+
+	result := make([]*Aggregate, 0, len(a.aggs))
+	for _, agg := range a.aggs {
+		agg.Start = a.start
+		agg.End = end
+
+		switch agg.Kind {
+		case Gauge:
+			agg.Avg = agg.sum / float64(agg.Count)
+		case Timer:
+			agg.Percentiles = percentiles(agg.durations, a.percentiles)
+		}
+
+		result = append(result, &agg.Aggregate)
+	}
+
+	a.start = end
+	a.aggs = make(map[string]*aggregate)
+
+	return result
+}
+
+// Run adds every envelope read from in and passes the aggregates of each
+// window to emit. It blocks until in is closed or the context is done, at
+// which point the current window is flushed.
+func (a *Aggregator) Run(ctx context.Context, in <-chan *loggregator_v2.Envelope, emit func([]*Aggregate)) {
+	t := time.NewTicker(a.window)
+	defer t.Stop()
+
+	defer func() {
+		emit(a.Flush())
+	}()
+
+	for {
+		select {
+		case e, ok := <-in:
+			if !ok {
+				return
+			}
+			a.Add(e)
+		case <-t.C:
+			emit(a.Flush())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// key identifies an aggregate by its kind, source ID, name and tags.
+func key(kind Kind, sourceID, name string, tags map[string]string) string {
+	names := make([]string, 0, len(tags))
+	for k := range tags {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d|%s|%s", kind, sourceID, name)
+	for _, k := range names {
+		fmt.Fprintf(&b, "|%s=%s", k, tags[k])
+	}
+
+	return b.String()
+}
+
+// percentiles computes the given percentiles of the durations using the
+// nearest-rank method.
+func percentiles(durations []time.Duration, ps []float64) map[float64]time.Duration {
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+
+	result := make(map[float64]time.Duration, len(ps))
+	for _, p := range ps {
+		rank := int(math.Ceil(p / 100 * float64(len(durations))))
+		if rank < 1 {
+			rank = 1
+		}
+		result[p] = durations[rank-1]
+	}
+
+	return result
+}
@@ -0,0 +1,146 @@
+package aggregation_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/aggregation"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregator", func() {
+	var a *aggregation.Aggregator
+
+	BeforeEach(func() {
+		a = aggregation.New(time.Minute)
+	})
+
+	It("sums counters by source ID, name and tags", func() {
+		a.Add(counter("source-a", "requests", 2, map[string]string{"az": "z1"}))
+		a.Add(counter("source-a", "requests", 3, map[string]string{"az": "z1"}))
+		a.Add(counter("source-a", "requests", 5, map[string]string{"az": "z2"}))
+		a.Add(counter("source-b", "requests", 7, map[string]string{"az": "z1"}))
+
+		aggs := a.Flush()
+		Expect(aggs).To(HaveLen(3))
+
+		sums := map[string]uint64{}
+		for _, agg := range aggs {
+			Expect(agg.Kind).To(Equal(aggregation.Counter))
+			sums[agg.SourceID+"/"+agg.Tags["az"]] = agg.Sum
+		}
+		Expect(sums).To(Equal(map[string]uint64{
+			"source-a/z1": 5,
+			"source-a/z2": 5,
+			"source-b/z1": 7,
+		}))
+	})
+
+	It("reduces gauges to their last, average and maximum values", func() {
+		a.Add(gauge("source-a", "cpu", 3))
+		a.Add(gauge("source-a", "cpu", 9))
+		a.Add(gauge("source-a", "cpu", 6))
+
+		aggs := a.Flush()
+		Expect(aggs).To(HaveLen(1))
+		Expect(aggs[0].Kind).To(Equal(aggregation.Gauge))
+		Expect(aggs[0].Last).To(Equal(6.0))
+		Expect(aggs[0].Avg).To(Equal(6.0))
+		Expect(aggs[0].Max).To(Equal(9.0))
+		Expect(aggs[0].Unit).To(Equal("percent"))
+
+		e := aggs[0].Envelope()
+		Expect(e.GetGauge().GetMetrics()).To(HaveKey("cpu.max"))
+		Expect(e.GetGauge().GetMetrics()["cpu.max"].GetValue()).To(Equal(9.0))
+	})
+
+	It("computes percentiles of timers", func() {
+		a = aggregation.New(time.Minute, aggregation.WithPercentiles(50, 100))
+		for i := 1; i <= 10; i++ {
+			a.Add(timer("source-a", "request", time.Duration(i)*time.Millisecond))
+		}
+
+		aggs := a.Flush()
+		Expect(aggs).To(HaveLen(1))
+		Expect(aggs[0].Percentiles).To(Equal(map[float64]time.Duration{
+			50:  5 * time.Millisecond,
+			100: 10 * time.Millisecond,
+		}))
+
+		e := aggs[0].Envelope()
+		Expect(e.GetGauge().GetMetrics()).To(HaveKey("request.p50"))
+	})
+
+	It("starts a new window after flushing", func() {
+		a.Add(counter("source-a", "requests", 1, nil))
+		first := a.Flush()
+		a.Add(counter("source-a", "requests", 1, nil))
+		second := a.Flush()
+
+		Expect(second).To(HaveLen(1))
+		Expect(second[0].Sum).To(Equal(uint64(1)))
+		Expect(second[0].Start).To(Equal(first[0].End))
+	})
+
+	It("converts counter aggregates into counter envelopes", func() {
+		a.Add(counter("source-a", "requests", 4, map[string]string{"az": "z1"}))
+
+		e := a.Flush()[0].Envelope()
+		Expect(e.SourceId).To(Equal("source-a"))
+		Expect(e.GetCounter().GetName()).To(Equal("requests"))
+		Expect(e.GetCounter().GetDelta()).To(Equal(uint64(4)))
+		Expect(e.Tags).To(HaveKeyWithValue("az", "z1"))
+		Expect(e.Tags).To(HaveKey("aggregation_window"))
+	})
+
+	It("emits the aggregates of each window", func() {
+		a = aggregation.New(10 * time.Millisecond)
+		in := make(chan *loggregator_v2.Envelope)
+		emitted := make(chan []*aggregation.Aggregate, 100)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go a.Run(ctx, in, func(aggs []*aggregation.Aggregate) {
+			emitted <- aggs
+		})
+
+		in <- counter("source-a", "requests", 1, nil)
+
+		Eventually(emitted).Should(Receive(HaveLen(1)))
+	})
+})
+
+func counter(sourceID, name string, delta uint64, tags map[string]string) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId: sourceID,
+		Tags:     tags,
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: name, Delta: delta},
+		},
+	}
+}
+
+func gauge(sourceID, name string, value float64) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId: sourceID,
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: map[string]*loggregator_v2.GaugeValue{
+					name: {Value: value, Unit: "percent"},
+				},
+			},
+		},
+	}
+}
+
+func timer(sourceID, name string, d time.Duration) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId: sourceID,
+		Message: &loggregator_v2.Envelope_Timer{
+			Timer: &loggregator_v2.Timer{Name: name, Start: 0, Stop: int64(d)},
+		},
+	}
+}
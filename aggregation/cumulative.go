@@ -0,0 +1,184 @@
+package aggregation
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// defaultIgnoredTags are the tags that the ingress client and its options set
+// per envelope and that therefore do not identify a counter.
+var defaultIgnoredTags = []string{"delivery_id", "sequence", "emitted_at", "loggregator_deadline", "loggregator_priority"}
+
+// CumulativeOption configures a CumulativeConverter.
+type CumulativeOption func(*CumulativeConverter)
+
+// WithIgnoredTags configures the tags that are not used to identify a
+// counter. It defaults to the tags that the ingress client sets per
+// envelope, i.e. delivery_id, sequence, emitted_at and deadline.
+func WithIgnoredTags(names ...string) CumulativeOption {
+	return func(c *CumulativeConverter) {
+		c.ignored = make(map[string]bool, len(names))
+		for _, n := range names {
+			c.ignored[n] = true
+		}
+	}
+}
+
+// WithResetOnRestart configures the converter to start the total of a
+// counter again at the emitter's total when a restart is detected, like
+// the counters of Prometheus do. By default totals keep increasing across
+// restarts.
+func WithResetOnRestart() CumulativeOption {
+	return func(c *CumulativeConverter) {
+		c.resetOnRestart = true
+	}
+}
+
+// WithStaleAfter configures the converter to forget the total of a counter
+// that has not been seen for the given duration. Totals are kept forever by
+// default.
+func WithStaleAfter(d time.Duration) CumulativeOption {
+	return func(c *CumulativeConverter) {
+		c.staleAfter = d
+	}
+}
+
+// CumulativeConverter turns counter envelopes into envelopes that carry the
+// cumulative total of each counter. Counters are identified by their source
+// ID, name and tags.
+//
+// Counters that only carry a delta are totaled by adding up the deltas.
+// Counters that also carry the emitter's total, e.g. those emitted via a
+// CounterTotaler, are totaled by adding up the increase of the emitter's
+// total, which stays accurate when envelopes are lost. If the emitter's
+// total decreases, the emitter is assumed to have restarted and its total
+// is counted as the increase since the restart.
+//
+// It is safe for concurrent use. It should be created with the
+// NewCumulativeConverter constructor.
+type CumulativeConverter struct {
+	ignored        map[string]bool
+	resetOnRestart bool
+	staleAfter     time.Duration
+
+	mu        sync.Mutex
+	counters  map[string]*cumulative
+	restarts  uint64
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type cumulative struct {
+	total       uint64
+	sourceTotal uint64
+	lastSeen    time.Time
+}
+
+// NewCumulativeConverter creates an empty CumulativeConverter.
+func NewCumulativeConverter(opts ...CumulativeOption) *CumulativeConverter {
+	c := &CumulativeConverter{
+		counters: make(map[string]*cumulative),
+		now:      time.Now,
+	}
+	WithIgnoredTags(defaultIgnoredTags...)(c)
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	c.lastSweep = c.now()
+
+	return c
+}
+
+// Convert adds the counter envelope to the total of its counter and returns
+// a copy of the envelope whose total is the cumulative total. The delta is
+// kept. Envelopes other than counters are returned unchanged.
+func (c *CumulativeConverter) Convert(e *loggregator_v2.Envelope) *loggregator_v2.Envelope {
+	counter := e.GetCounter()
+	if counter == nil {
+		return e
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+
+	k := key(Counter, e.GetSourceId(), counter.GetName(), c.identifyingTags(e.GetTags()))
+	cum, ok := c.counters[k]
+	if !ok {
+		cum = &cumulative{}
+		c.counters[k] = cum
+	}
+	cum.lastSeen = now
+
+	switch t := counter.GetTotal(); {
+	case t == 0:
+		cum.total += counter.GetDelta()
+	case !ok:
+		cum.total = t
+		cum.sourceTotal = t
+	case t < cum.sourceTotal:
+		c.restarts++
+		if c.resetOnRestart {
+			cum.total = 0
+		}
+		cum.total += t
+		cum.sourceTotal = t
+	default:
+		cum.total += t - cum.sourceTotal
+		cum.sourceTotal = t
+	}
+
+	out := envelope.DeepCopy(e)
+	out.GetCounter().Total = cum.total
+
+	return out
+}
+
+// Restarts returns the number of emitter restarts that were detected.
+func (c *CumulativeConverter) Restarts() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.restarts
+}
+
+// identifyingTags returns the tags without the ignored tags.
+func (c *CumulativeConverter) identifyingTags(tags map[string]string) map[string]string {
+	for name := range tags {
+		if !c.ignored[name] {
+			continue
+		}
+
+		ids := make(map[string]string, len(tags))
+		for k, v := range tags {
+			if !c.ignored[k] {
+				ids[k] = v
+			}
+		}
+		return ids
+	}
+
+	return tags
+}
+
+// sweep forgets stale counters. It only scans the counters once per stale
+// period.
+func (c *CumulativeConverter) sweep(now time.Time) {
+	if c.staleAfter <= 0 || now.Sub(c.lastSweep) < c.staleAfter {
+		return
+	}
+	c.lastSweep = now
+
+	for k, cum := range c.counters {
+		if now.Sub(cum.lastSeen) > c.staleAfter {
+			delete(c.counters, k)
+		}
+	}
+}
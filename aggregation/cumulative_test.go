@@ -0,0 +1,89 @@
+package aggregation_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/aggregation"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CumulativeConverter", func() {
+	var c *aggregation.CumulativeConverter
+
+	BeforeEach(func() {
+		c = aggregation.NewCumulativeConverter()
+	})
+
+	total := func(e *loggregator_v2.Envelope) uint64 {
+		return c.Convert(e).GetCounter().GetTotal()
+	}
+
+	withTotal := func(e *loggregator_v2.Envelope, t uint64) *loggregator_v2.Envelope {
+		e.GetCounter().Total = t
+		return e
+	}
+
+	It("totals deltas by source ID, name and tags", func() {
+		Expect(total(counter("source-a", "requests", 2, map[string]string{"az": "z1"}))).To(Equal(uint64(2)))
+		Expect(total(counter("source-a", "requests", 3, map[string]string{"az": "z1"}))).To(Equal(uint64(5)))
+		Expect(total(counter("source-a", "requests", 5, map[string]string{"az": "z2"}))).To(Equal(uint64(5)))
+		Expect(total(counter("source-b", "requests", 7, map[string]string{"az": "z1"}))).To(Equal(uint64(7)))
+		Expect(total(counter("source-a", "errors", 1, map[string]string{"az": "z1"}))).To(Equal(uint64(1)))
+	})
+
+	It("returns a copy with the delta", func() {
+		e := counter("source-a", "requests", 2, nil)
+
+		out := c.Convert(e)
+
+		Expect(out.GetCounter().GetDelta()).To(Equal(uint64(2)))
+		Expect(out.GetCounter().GetTotal()).To(Equal(uint64(2)))
+		Expect(e.GetCounter().GetTotal()).To(BeZero())
+	})
+
+	It("ignores the per envelope tags of the client", func() {
+		total(counter("source-a", "requests", 2, map[string]string{"az": "z1", "sequence": "1"}))
+
+		Expect(total(counter("source-a", "requests", 3, map[string]string{"az": "z1", "sequence": "2"}))).To(Equal(uint64(5)))
+	})
+
+	It("totals the increase of the emitter's total", func() {
+		Expect(total(withTotal(counter("source-a", "requests", 1, nil), 10))).To(Equal(uint64(10)))
+		Expect(total(withTotal(counter("source-a", "requests", 1, nil), 15))).To(Equal(uint64(15)))
+		Expect(c.Restarts()).To(BeZero())
+	})
+
+	It("keeps increasing when the emitter restarts", func() {
+		total(withTotal(counter("source-a", "requests", 1, nil), 10))
+
+		Expect(total(withTotal(counter("source-a", "requests", 1, nil), 3))).To(Equal(uint64(13)))
+		Expect(total(withTotal(counter("source-a", "requests", 1, nil), 4))).To(Equal(uint64(14)))
+		Expect(c.Restarts()).To(Equal(uint64(1)))
+	})
+
+	It("resets the total when the emitter restarts if configured", func() {
+		c = aggregation.NewCumulativeConverter(aggregation.WithResetOnRestart())
+		total(withTotal(counter("source-a", "requests", 1, nil), 10))
+
+		Expect(total(withTotal(counter("source-a", "requests", 1, nil), 3))).To(Equal(uint64(3)))
+		Expect(c.Restarts()).To(Equal(uint64(1)))
+	})
+
+	It("forgets stale counters", func() {
+		c = aggregation.NewCumulativeConverter(aggregation.WithStaleAfter(10 * time.Millisecond))
+		total(counter("source-a", "requests", 2, nil))
+
+		time.Sleep(30 * time.Millisecond)
+
+		Expect(total(counter("source-a", "requests", 3, nil))).To(Equal(uint64(3)))
+	})
+
+	It("returns other envelopes unchanged", func() {
+		e := gauge("source-a", "cpu", 1)
+
+		Expect(c.Convert(e)).To(BeIdenticalTo(e))
+	})
+})
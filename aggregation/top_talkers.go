@@ -0,0 +1,222 @@
+package aggregation
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// Talker is a source ID and the volume of its envelopes within the window
+// of a TopTalkers.
+type Talker struct {
+	SourceID string
+	Volume   uint64
+}
+
+// TopTalkersOption configures a TopTalkers.
+type TopTalkersOption func(*TopTalkers)
+
+// WithResolution configures the granularity at which the window slides. The
+// window is divided into buckets of the given length, rounded down to a
+// whole number of buckets. It defaults to a tenth of the window.
+func WithResolution(d time.Duration) TopTalkersOption {
+	return func(t *TopTalkers) {
+		t.resolution = d
+	}
+}
+
+// WithVolume configures how much an envelope contributes to the volume of
+// its source ID, e.g. its size in bytes. By default every envelope counts
+// as 1.
+func WithVolume(f func(*loggregator_v2.Envelope) uint64) TopTalkersOption {
+	return func(t *TopTalkers) {
+		t.volume = f
+	}
+}
+
+// WithOnUpdate configures a function that is called with the top talkers
+// whenever the window slides.
+func WithOnUpdate(f func(top []Talker)) TopTalkersOption {
+	return func(t *TopTalkers) {
+		t.onUpdate = f
+	}
+}
+
+// WithOnEnter configures a function that is called when a source ID enters
+// the top talkers as the window slides.
+func WithOnEnter(f func(Talker)) TopTalkersOption {
+	return func(t *TopTalkers) {
+		t.onEnter = f
+	}
+}
+
+// WithOnLeave configures a function that is called when a source ID leaves
+// the top talkers as the window slides.
+func WithOnLeave(f func(Talker)) TopTalkersOption {
+	return func(t *TopTalkers) {
+		t.onLeave = f
+	}
+}
+
+// TopTalkers maintains the source IDs with the largest volume of envelopes
+// within a sliding window, e.g. to detect noisy neighbors. The window
+// slides by one bucket on every call to Rotate, which Run does on every
+// tick of the resolution. Callbacks are called from the go routine that
+// calls Rotate and must not call Rotate themselves.
+//
+// It is safe for concurrent use. It should be created with the
+// NewTopTalkers constructor.
+type TopTalkers struct {
+	n          int
+	window     time.Duration
+	resolution time.Duration
+	volume     func(*loggregator_v2.Envelope) uint64
+
+	onUpdate func([]Talker)
+	onEnter  func(Talker)
+	onLeave  func(Talker)
+
+	mu      sync.Mutex
+	buckets []map[string]uint64
+	current int
+	totals  map[string]uint64
+	top     map[string]bool
+}
+
+// NewTopTalkers creates a TopTalkers that maintains the n source IDs with
+// the largest volume within the given window.
+func NewTopTalkers(n int, window time.Duration, opts ...TopTalkersOption) *TopTalkers {
+	t := &TopTalkers{
+		n:          n,
+		window:     window,
+		resolution: window / 10,
+		volume:     func(*loggregator_v2.Envelope) uint64 { return 1 },
+		totals:     make(map[string]uint64),
+		top:        make(map[string]bool),
+	}
+
+	for _, o := range opts {
+		o(t)
+	}
+
+	buckets := 1
+	if t.resolution > 0 && t.window/t.resolution > 1 {
+		buckets = int(t.window / t.resolution)
+	}
+	t.buckets = make([]map[string]uint64, buckets)
+	for i := range t.buckets {
+		t.buckets[i] = make(map[string]uint64)
+	}
+
+	return t
+}
+
+// Add adds the volume of the envelope to its source ID.
+func (t *TopTalkers) Add(e *loggregator_v2.Envelope) {
+	v := t.volume(e)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buckets[t.current][e.GetSourceId()] += v
+	t.totals[e.GetSourceId()] += v
+}
+
+// Top returns the top talkers ordered by descending volume. Source IDs of
+// the same volume are ordered by source ID.
+func (t *TopTalkers) Top() []Talker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.topTalkers()
+}
+
+// Rotate slides the window by one bucket, dropping the volume of the oldest
+// bucket, and calls the callbacks.
+func (t *TopTalkers) Rotate() {
+	t.mu.Lock()
+	t.current = (t.current + 1) % len(t.buckets)
+	for id, v := range t.buckets[t.current] {
+		t.totals[id] -= v
+		if t.totals[id] == 0 {
+			delete(t.totals, id)
+		}
+	}
+	t.buckets[t.current] = make(map[string]uint64)
+
+	top := t.topTalkers()
+
+	var entered, left []Talker
+	inTop := make(map[string]bool, len(top))
+	for _, talker := range top {
+		inTop[talker.SourceID] = true
+		if !t.top[talker.SourceID] {
+			entered = append(entered, talker)
+		}
+	}
+	for id := range t.top {
+		if !inTop[id] {
+			left = append(left, Talker{SourceID: id, Volume: t.totals[id]})
+		}
+	}
+	t.top = inTop
+	t.mu.Unlock()
+
+	if t.onLeave != nil {
+		for _, talker := range left {
+			t.onLeave(talker)
+		}
+	}
+	if t.onEnter != nil {
+		for _, talker := range entered {
+			t.onEnter(talker)
+		}
+	}
+	if t.onUpdate != nil {
+		t.onUpdate(top)
+	}
+}
+
+// Run adds every envelope read from in and rotates the window on every tick
+// of the resolution. It blocks until in is closed or the context is done.
+func (t *TopTalkers) Run(ctx context.Context, in <-chan *loggregator_v2.Envelope) {
+	tick := t.window / time.Duration(len(t.buckets))
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-in:
+			if !ok {
+				return
+			}
+			t.Add(e)
+		case <-ticker.C:
+			t.Rotate()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (t *TopTalkers) topTalkers() []Talker {
+	talkers := make([]Talker, 0, len(t.totals))
+	for id, v := range t.totals {
+		talkers = append(talkers, Talker{SourceID: id, Volume: v})
+	}
+	sort.Slice(talkers, func(i, j int) bool {
+		if talkers[i].Volume != talkers[j].Volume {
+			return talkers[i].Volume > talkers[j].Volume
+		}
+		return talkers[i].SourceID < talkers[j].SourceID
+	})
+
+	if len(talkers) > t.n {
+		talkers = talkers[:t.n]
+	}
+
+	return talkers
+}
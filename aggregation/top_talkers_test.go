@@ -0,0 +1,121 @@
+package aggregation_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/aggregation"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TopTalkers", func() {
+	addN := func(t *aggregation.TopTalkers, sourceID string, n int) {
+		for i := 0; i < n; i++ {
+			t.Add(counter(sourceID, "requests", 1, nil))
+		}
+	}
+
+	It("maintains the top source IDs by volume", func() {
+		t := aggregation.NewTopTalkers(2, time.Minute)
+		addN(t, "source-a", 3)
+		addN(t, "source-b", 5)
+		addN(t, "source-c", 1)
+		addN(t, "source-d", 3)
+
+		Expect(t.Top()).To(Equal([]aggregation.Talker{
+			{SourceID: "source-b", Volume: 5},
+			{SourceID: "source-a", Volume: 3},
+		}))
+	})
+
+	It("drops the volume that slid out of the window", func() {
+		t := aggregation.NewTopTalkers(2, time.Minute, aggregation.WithResolution(30*time.Second))
+		addN(t, "source-a", 5)
+		t.Rotate()
+		addN(t, "source-b", 2)
+
+		Expect(t.Top()).To(Equal([]aggregation.Talker{
+			{SourceID: "source-a", Volume: 5},
+			{SourceID: "source-b", Volume: 2},
+		}))
+
+		t.Rotate()
+
+		Expect(t.Top()).To(Equal([]aggregation.Talker{
+			{SourceID: "source-b", Volume: 2},
+		}))
+	})
+
+	It("measures the configured volume", func() {
+		t := aggregation.NewTopTalkers(1, time.Minute, aggregation.WithVolume(func(e *loggregator_v2.Envelope) uint64 {
+			return e.GetCounter().GetDelta()
+		}))
+		t.Add(counter("source-a", "requests", 10, nil))
+		addN(t, "source-b", 3)
+
+		Expect(t.Top()).To(Equal([]aggregation.Talker{
+			{SourceID: "source-a", Volume: 10},
+		}))
+	})
+
+	It("calls the callbacks when the window slides", func() {
+		var (
+			updates       [][]aggregation.Talker
+			entered, left []string
+		)
+		t := aggregation.NewTopTalkers(1, time.Minute,
+			aggregation.WithResolution(30*time.Second),
+			aggregation.WithOnUpdate(func(top []aggregation.Talker) {
+				updates = append(updates, top)
+			}),
+			aggregation.WithOnEnter(func(talker aggregation.Talker) {
+				entered = append(entered, talker.SourceID)
+			}),
+			aggregation.WithOnLeave(func(talker aggregation.Talker) {
+				left = append(left, talker.SourceID)
+			}),
+		)
+
+		addN(t, "source-a", 5)
+		t.Rotate()
+		Expect(entered).To(Equal([]string{"source-a"}))
+
+		addN(t, "source-b", 2)
+		t.Rotate()
+		Expect(entered).To(Equal([]string{"source-a", "source-b"}))
+		Expect(left).To(Equal([]string{"source-a"}))
+
+		Expect(updates).To(Equal([][]aggregation.Talker{
+			{{SourceID: "source-a", Volume: 5}},
+			{{SourceID: "source-b", Volume: 2}},
+		}))
+	})
+
+	It("adds envelopes and slides the window until the context is done", func() {
+		updates := make(chan []aggregation.Talker, 100)
+		t := aggregation.NewTopTalkers(1, 100*time.Millisecond,
+			aggregation.WithOnUpdate(func(top []aggregation.Talker) {
+				updates <- top
+			}),
+		)
+		in := make(chan *loggregator_v2.Envelope, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			t.Run(ctx, in)
+		}()
+
+		in <- counter("source-a", "requests", 1, nil)
+
+		Eventually(updates).Should(Receive(Equal([]aggregation.Talker{
+			{SourceID: "source-a", Volume: 1},
+		})))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -0,0 +1,138 @@
+package loggregator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// The tags that chain audit envelopes.
+const (
+	auditSeqTag      = "audit_seq"
+	auditPrevHashTag = "prev_hash"
+	auditHashTag     = "hash"
+)
+
+// EnvelopeEmitter is the interface of the client that an AuditLogger emits
+// envelopes with.
+type EnvelopeEmitter interface {
+	EmitEnvelope(*loggregator_v2.Envelope) error
+}
+
+// AuditLogger emits audit events as log envelopes that form a hash chain.
+// Every envelope carries a sequence number (audit_seq), the hash of the
+// previous envelope (prev_hash) and its own hash (hash), which covers the
+// sequence number, the previous hash, the timestamp, the source ID and the
+// payload. Downstream storage can detect missing, reordered or modified
+// events with VerifyAuditChain. The chain only lives in memory and starts
+// again at sequence number 1 when the process restarts. It is safe for
+// concurrent use.
+type AuditLogger struct {
+	emitter  EnvelopeEmitter
+	sourceID string
+
+	mu       sync.Mutex
+	seq      uint64
+	prevHash string
+}
+
+// NewAuditLogger returns an AuditLogger that emits envelopes with the given
+// source ID via the given emitter.
+func NewAuditLogger(e EnvelopeEmitter, sourceID string) *AuditLogger {
+	return &AuditLogger{
+		emitter:  e,
+		sourceID: sourceID,
+	}
+}
+
+// Log emits an audit event. The options must not change the source ID. If
+// the envelope cannot be emitted, the error is returned and the event is
+// not part of the chain, so that it may be logged again.
+func (a *AuditLogger) Log(message string, opts ...EmitLogOption) error {
+	e := newLogEnvelope(time.Now(), nil, []byte(message), opts)
+	e.SourceId = a.sourceID
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seq := a.seq + 1
+	e.Tags[auditSeqTag] = strconv.FormatUint(seq, 10)
+	e.Tags[auditPrevHashTag] = a.prevHash
+	hash := auditHash(e, seq, a.prevHash)
+	e.Tags[auditHashTag] = hash
+
+	if err := a.emitter.EmitEnvelope(e); err != nil {
+		return err
+	}
+
+	a.seq = seq
+	a.prevHash = hash
+
+	return nil
+}
+
+// VerifyAuditChain checks that the given envelopes, ordered by their
+// sequence numbers, form an unbroken chain created by an AuditLogger. The
+// chain may start at any sequence number. It returns an error describing
+// the first gap, reordering or modification it finds.
+func VerifyAuditChain(envelopes []*loggregator_v2.Envelope) error {
+	var (
+		prevSeq  uint64
+		prevHash string
+	)
+
+	for i, e := range envelopes {
+		seq, err := strconv.ParseUint(e.GetTags()[auditSeqTag], 10, 64)
+		if err != nil {
+			return fmt.Errorf("audit envelope %d has an invalid %s tag: %s", i, auditSeqTag, err)
+		}
+
+		hash := e.GetTags()[auditHashTag]
+		if hash != auditHash(e, seq, e.GetTags()[auditPrevHashTag]) {
+			return fmt.Errorf("audit envelope %d has been modified", seq)
+		}
+
+		if i > 0 {
+			if seq != prevSeq+1 {
+				return fmt.Errorf("audit envelope %d follows %d", seq, prevSeq)
+			}
+			if e.GetTags()[auditPrevHashTag] != prevHash {
+				return fmt.Errorf("audit envelope %d does not follow the previous envelope", seq)
+			}
+		}
+
+		prevSeq, prevHash = seq, hash
+	}
+
+	return nil
+}
+
+// auditHash returns the hex encoded SHA-256 hash of the chained fields of
+// the envelope. Variable length fields are prefixed with their length so
+// that they cannot be shifted into each other.
+func auditHash(e *loggregator_v2.Envelope, seq uint64, prevHash string) string {
+	h := sha256.New()
+	var n [8]byte
+
+	writeField := func(b []byte) {
+		binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+		h.Write(n[:])
+		h.Write(b)
+	}
+
+	binary.BigEndian.PutUint64(n[:], seq)
+	h.Write(n[:])
+	writeField([]byte(prevHash))
+	binary.BigEndian.PutUint64(n[:], uint64(e.GetTimestamp()))
+	h.Write(n[:])
+	writeField([]byte(e.GetSourceId()))
+	writeField(e.GetLog().GetPayload())
+
+	return hex.EncodeToString(h.Sum(nil))
+}
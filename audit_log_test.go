@@ -0,0 +1,90 @@
+package loggregator_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditLogger", func() {
+	var (
+		emitter *spyEnvelopeEmitter
+		logger  *loggregator.AuditLogger
+	)
+
+	BeforeEach(func() {
+		emitter = &spyEnvelopeEmitter{}
+		logger = loggregator.NewAuditLogger(emitter, "some-source")
+	})
+
+	It("chains audit events", func() {
+		Expect(logger.Log("user created")).To(Succeed())
+		Expect(logger.Log("user deleted", loggregator.WithEnvelopeTag("user", "some-user"))).To(Succeed())
+
+		Expect(emitter.envelopes).To(HaveLen(2))
+		first, second := emitter.envelopes[0], emitter.envelopes[1]
+		Expect(first.SourceId).To(Equal("some-source"))
+		Expect(first.Tags).To(HaveKeyWithValue("audit_seq", "1"))
+		Expect(first.Tags).To(HaveKeyWithValue("prev_hash", ""))
+		Expect(first.Tags["hash"]).To(HaveLen(64))
+		Expect(second.Tags).To(HaveKeyWithValue("audit_seq", "2"))
+		Expect(second.Tags).To(HaveKeyWithValue("prev_hash", first.Tags["hash"]))
+		Expect(second.Tags).To(HaveKeyWithValue("user", "some-user"))
+
+		Expect(loggregator.VerifyAuditChain(emitter.envelopes)).To(Succeed())
+	})
+
+	It("does not advance the chain if an event cannot be emitted", func() {
+		emitter.err = errors.New("some-error")
+		Expect(logger.Log("user created")).To(MatchError("some-error"))
+
+		emitter.err = nil
+		Expect(logger.Log("user created")).To(Succeed())
+
+		Expect(emitter.envelopes[1].Tags).To(HaveKeyWithValue("audit_seq", "1"))
+	})
+
+	Describe("VerifyAuditChain", func() {
+		BeforeEach(func() {
+			for i := 0; i < 3; i++ {
+				Expect(logger.Log("event")).To(Succeed())
+			}
+		})
+
+		It("accepts a chain that starts in the middle", func() {
+			Expect(loggregator.VerifyAuditChain(emitter.envelopes[1:])).To(Succeed())
+		})
+
+		It("detects missing events", func() {
+			chain := []*loggregator_v2.Envelope{emitter.envelopes[0], emitter.envelopes[2]}
+
+			Expect(loggregator.VerifyAuditChain(chain)).To(MatchError("audit envelope 3 follows 1"))
+		})
+
+		It("detects reordered events", func() {
+			chain := []*loggregator_v2.Envelope{emitter.envelopes[1], emitter.envelopes[0]}
+
+			Expect(loggregator.VerifyAuditChain(chain)).To(HaveOccurred())
+		})
+
+		It("detects modified events", func() {
+			emitter.envelopes[1].GetLog().Payload = []byte("other-event")
+
+			Expect(loggregator.VerifyAuditChain(emitter.envelopes)).To(MatchError("audit envelope 2 has been modified"))
+		})
+	})
+})
+
+type spyEnvelopeEmitter struct {
+	envelopes []*loggregator_v2.Envelope
+	err       error
+}
+
+func (s *spyEnvelopeEmitter) EmitEnvelope(e *loggregator_v2.Envelope) error {
+	s.envelopes = append(s.envelopes, e)
+	return s.err
+}
@@ -0,0 +1,55 @@
+package loggregator
+
+import (
+	"encoding/base64"
+	"unicode/utf8"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// BinaryPayloadMode determines how the client handles log payloads that are
+// not valid UTF-8, e.g. arbitrary bytes written to a container's stdout.
+type BinaryPayloadMode int
+
+const (
+	// PassThroughBinaryPayloads sends payloads as they are. This is the
+	// default. Consumers that expect text may have to handle invalid
+	// UTF-8.
+	PassThroughBinaryPayloads BinaryPayloadMode = iota
+
+	// Base64BinaryPayloads encodes payloads that are not valid UTF-8 with
+	// base64 and sets the content_encoding tag to base64. Consumers may
+	// decode the payload with envelope.LogPayload.
+	Base64BinaryPayloads
+)
+
+// WithBinaryPayloads configures how logs with payloads that are not valid
+// UTF-8 are sent. Valid UTF-8 payloads are always sent as they are.
+func WithBinaryPayloads(mode BinaryPayloadMode) IngressOption {
+	return func(c *IngressClient) {
+		c.binaryPayloads = mode
+	}
+}
+
+// encodePayload encodes the payload of the log envelope according to the
+// client's binary payload mode.
+func (c *IngressClient) encodePayload(e *loggregator_v2.Envelope) {
+	if c.binaryPayloads != Base64BinaryPayloads {
+		return
+	}
+
+	log := e.GetLog()
+	if log == nil || utf8.Valid(log.Payload) {
+		return
+	}
+
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(log.Payload)))
+	base64.StdEncoding.Encode(encoded, log.Payload)
+	log.Payload = encoded
+
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
+	}
+	e.Tags[envelope.ContentEncodingTag] = envelope.Base64Encoding
+}
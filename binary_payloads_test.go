@@ -0,0 +1,58 @@
+package loggregator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/envelope"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Binary payloads", func() {
+	var server *testIngressServer
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("passes binary payloads through by default", func() {
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false)
+
+		client.EmitLogBytes([]byte("\xff\xfe"))
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes[0].GetLog().Payload).To(Equal([]byte("\xff\xfe")))
+		Expect(envelopes[0].Tags).NotTo(HaveKey("content_encoding"))
+	})
+
+	It("encodes binary payloads with base64", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithBinaryPayloads(loggregator.Base64BinaryPayloads),
+		)
+
+		client.EmitLogBytes([]byte("\xff\xfe"))
+		client.EmitLog("text")
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("content_encoding", "base64"))
+		Expect(envelope.LogPayload(envelopes[0])).To(Equal([]byte("\xff\xfe")))
+		Expect(envelopes[1].GetLog().Payload).To(Equal([]byte("text")))
+		Expect(envelopes[1].Tags).NotTo(HaveKey("content_encoding"))
+	})
+})
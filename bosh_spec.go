@@ -0,0 +1,108 @@
+package loggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+)
+
+// DefaultBOSHSpecPath is the path of the spec that the BOSH agent writes on
+// every instance.
+const DefaultBOSHSpecPath = "/var/vcap/bosh/spec.json"
+
+// BOSHSpec describes the BOSH instance the process runs on.
+type BOSHSpec struct {
+	Deployment string
+	Name       string
+	Index      string
+	ID         string
+	AZ         string
+	IP         string
+}
+
+// boshSpecFile holds the fields of a BOSH spec file that are read.
+type boshSpecFile struct {
+	Deployment string `json:"deployment"`
+	Name       string `json:"name"`
+	Job        struct {
+		Name string `json:"name"`
+	} `json:"job"`
+	Index    *int   `json:"index"`
+	ID       string `json:"id"`
+	AZ       string `json:"az"`
+	Networks map[string]struct {
+		IP      string   `json:"ip"`
+		Default []string `json:"default"`
+	} `json:"networks"`
+}
+
+// LoadBOSHSpec reads the instance from the BOSH spec file at path, usually
+// DefaultBOSHSpecPath. The name is the instance group name and the IP is
+// the IP of the network that provides the default gateway, or of the first
+// network by name if none does.
+func LoadBOSHSpec(path string) (BOSHSpec, error) {
+	var spec BOSHSpec
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return spec, err
+	}
+
+	var f boshSpecFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return spec, fmt.Errorf("invalid BOSH spec %s: %s", path, err)
+	}
+
+	spec = BOSHSpec{
+		Deployment: f.Deployment,
+		Name:       f.Name,
+		ID:         f.ID,
+		AZ:         f.AZ,
+	}
+	if spec.Name == "" {
+		spec.Name = f.Job.Name
+	}
+	if f.Index != nil {
+		spec.Index = strconv.Itoa(*f.Index)
+	}
+
+	names := make([]string, 0, len(f.Networks))
+	for name := range f.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if spec.IP == "" {
+			spec.IP = f.Networks[name].IP
+		}
+		for _, d := range f.Networks[name].Default {
+			if d == "gateway" {
+				spec.IP = f.Networks[name].IP
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// ApplyBOSHSpec sets the job properties of the config that are not set yet
+// from the BOSH spec.
+func (c *MetronConfig) ApplyBOSHSpec(spec BOSHSpec) {
+	fields := []struct {
+		field *string
+		value string
+	}{
+		{&c.JobDeployment, spec.Deployment},
+		{&c.JobName, spec.Name},
+		{&c.JobIndex, spec.Index},
+		{&c.JobIP, spec.IP},
+	}
+
+	for _, f := range fields {
+		if *f.field == "" {
+			*f.field = f.value
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package loggregator_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BOSH spec", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeSpec := func(contents string) string {
+		path := filepath.Join(dir, "spec.json")
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+		return path
+	}
+
+	It("loads the instance from the spec", func() {
+		path := writeSpec(`{
+			"deployment": "cf",
+			"name": "router",
+			"job": {"name": "gorouter", "templates": [{"name": "gorouter"}]},
+			"index": 0,
+			"id": "some-id",
+			"az": "z1",
+			"networks": {
+				"a-private": {"ip": "10.0.1.5", "default": []},
+				"default": {"ip": "10.0.0.5", "default": ["dns", "gateway"]}
+			}
+		}`)
+
+		spec, err := loggregator.LoadBOSHSpec(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec).To(Equal(loggregator.BOSHSpec{
+			Deployment: "cf",
+			Name:       "router",
+			Index:      "0",
+			ID:         "some-id",
+			AZ:         "z1",
+			IP:         "10.0.0.5",
+		}))
+	})
+
+	It("falls back to the job name and the first network", func() {
+		path := writeSpec(`{
+			"job": {"name": "gorouter"},
+			"networks": {
+				"b": {"ip": "10.0.2.5"},
+				"a": {"ip": "10.0.1.5"}
+			}
+		}`)
+
+		spec, err := loggregator.LoadBOSHSpec(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Name).To(Equal("gorouter"))
+		Expect(spec.Index).To(BeEmpty())
+		Expect(spec.IP).To(Equal("10.0.1.5"))
+	})
+
+	It("returns an error for an invalid spec", func() {
+		_, err := loggregator.LoadBOSHSpec(writeSpec("{"))
+		Expect(err).To(MatchError(ContainSubstring("invalid BOSH spec")))
+	})
+
+	It("returns an error for a missing spec", func() {
+		_, err := loggregator.LoadBOSHSpec(filepath.Join(dir, "missing.json"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("sets the job properties of a config that are not set", func() {
+		config := loggregator.MetronConfig{JobName: "configured-job"}
+
+		config.ApplyBOSHSpec(loggregator.BOSHSpec{
+			Deployment: "cf",
+			Name:       "router",
+			Index:      "1",
+			IP:         "10.0.0.5",
+		})
+
+		Expect(config.JobDeployment).To(Equal("cf"))
+		Expect(config.JobName).To(Equal("configured-job"))
+		Expect(config.JobIndex).To(Equal("1"))
+		Expect(config.JobIP).To(Equal("10.0.0.5"))
+	})
+})
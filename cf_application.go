@@ -0,0 +1,80 @@
+package loggregator
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// CFApplication describes the Cloud Foundry application instance the
+// process runs as.
+type CFApplication struct {
+	ApplicationID    string `json:"application_id"`
+	ApplicationName  string `json:"application_name"`
+	SpaceID          string `json:"space_id"`
+	SpaceName        string `json:"space_name"`
+	OrganizationID   string `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	InstanceIndex    string `json:"-"`
+	InstanceGUID     string `json:"-"`
+}
+
+// DetectCFApplication reads the application instance from the
+// VCAP_APPLICATION, CF_INSTANCE_INDEX and CF_INSTANCE_GUID environment
+// variables that Cloud Foundry sets in every application container. It
+// returns false if the process does not run on Cloud Foundry or
+// VCAP_APPLICATION can not be parsed.
+func DetectCFApplication() (CFApplication, bool) {
+	var app CFApplication
+
+	vcap := os.Getenv("VCAP_APPLICATION")
+	if vcap == "" {
+		return app, false
+	}
+
+	if err := json.Unmarshal([]byte(vcap), &app); err != nil {
+		return app, false
+	}
+
+	app.InstanceIndex = os.Getenv("CF_INSTANCE_INDEX")
+	app.InstanceGUID = os.Getenv("CF_INSTANCE_GUID")
+
+	return app, app.ApplicationID != ""
+}
+
+// WithCFApplication configures the client to emit as the given application
+// instance. Envelopes without a source ID or instance ID get the application
+// ID and instance index, and every envelope is tagged with the names and IDs
+// of the application, its space and organization and with the instance
+// GUID. Empty values are not tagged.
+func WithCFApplication(app CFApplication) IngressOption {
+	tags := map[string]string{
+		"app_id":            app.ApplicationID,
+		"app_name":          app.ApplicationName,
+		"space_id":          app.SpaceID,
+		"space_name":        app.SpaceName,
+		"organization_id":   app.OrganizationID,
+		"organization_name": app.OrganizationName,
+		"instance_guid":     app.InstanceGUID,
+	}
+
+	return func(c *IngressClient) {
+		WithDefaultSourceInfo(app.ApplicationID, app.InstanceIndex)(c)
+
+		for k, v := range tags {
+			if v != "" {
+				WithTag(k, v)(c)
+			}
+		}
+	}
+}
+
+// WithDetectedCFApplication configures the client with WithCFApplication if
+// DetectCFApplication finds an application instance. Otherwise it does not
+// change the client.
+func WithDetectedCFApplication() IngressOption {
+	return func(c *IngressClient) {
+		if app, ok := DetectCFApplication(); ok {
+			WithCFApplication(app)(c)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package loggregator_test
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DetectCFApplication", func() {
+	var env map[string]string
+
+	BeforeEach(func() {
+		env = make(map[string]string)
+		for _, k := range []string{"VCAP_APPLICATION", "CF_INSTANCE_INDEX", "CF_INSTANCE_GUID"} {
+			env[k] = os.Getenv(k)
+			os.Unsetenv(k)
+		}
+	})
+
+	AfterEach(func() {
+		for k, v := range env {
+			os.Setenv(k, v)
+		}
+	})
+
+	It("reads the application instance from the environment", func() {
+		os.Setenv("VCAP_APPLICATION", `{
+			"application_id": "some-app-id",
+			"application_name": "some-app",
+			"space_id": "some-space-id",
+			"space_name": "some-space",
+			"organization_id": "some-org-id",
+			"organization_name": "some-org",
+			"cf_api": "https://api.example.com"
+		}`)
+		os.Setenv("CF_INSTANCE_INDEX", "2")
+		os.Setenv("CF_INSTANCE_GUID", "some-instance-guid")
+
+		app, ok := loggregator.DetectCFApplication()
+
+		Expect(ok).To(BeTrue())
+		Expect(app).To(Equal(loggregator.CFApplication{
+			ApplicationID:    "some-app-id",
+			ApplicationName:  "some-app",
+			SpaceID:          "some-space-id",
+			SpaceName:        "some-space",
+			OrganizationID:   "some-org-id",
+			OrganizationName: "some-org",
+			InstanceIndex:    "2",
+			InstanceGUID:     "some-instance-guid",
+		}))
+	})
+
+	It("returns false when not running on Cloud Foundry", func() {
+		_, ok := loggregator.DetectCFApplication()
+
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns false when VCAP_APPLICATION is invalid", func() {
+		os.Setenv("VCAP_APPLICATION", "{")
+
+		_, ok := loggregator.DetectCFApplication()
+
+		Expect(ok).To(BeFalse())
+	})
+})
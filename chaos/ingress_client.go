@@ -0,0 +1,252 @@
+// Package chaos provides a loggregator_v2.IngressClient that injects
+// faults, e.g. latency, errors, partial batch failures and disconnects, into
+// the calls of another client. It is meant for testing how a program behaves
+// when its telemetry pipeline misbehaves. It is installed with
+// loggregator.WithTransportDecorator.
+package chaos
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrInjected is returned by calls that fail because of an injected fault.
+// Its code is Unavailable, like the errors of a failing connection.
+var ErrInjected = status.Error(codes.Unavailable, "chaos: injected fault")
+
+// Option configures an IngressClient.
+type Option func(*IngressClient)
+
+// WithLatency delays calls by the given duration with the given
+// probability.
+func WithLatency(probability float64, d time.Duration) Option {
+	return func(c *IngressClient) {
+		c.latencyProbability = probability
+		c.latency = d
+	}
+}
+
+// WithErrors fails calls with ErrInjected with the given probability. The
+// envelopes of a failed call are not sent.
+func WithErrors(probability float64) Option {
+	return func(c *IngressClient) {
+		c.errorProbability = probability
+	}
+}
+
+// WithPartialBatchFailures sends only a part of a batch and fails the call
+// with ErrInjected with the given probability.
+func WithPartialBatchFailures(probability float64) Option {
+	return func(c *IngressClient) {
+		c.partialProbability = probability
+	}
+}
+
+// WithDisconnects closes BatchSender streams once they are older than the
+// given interval. The next Send on the stream fails with ErrInjected.
+func WithDisconnects(interval time.Duration) Option {
+	return func(c *IngressClient) {
+		c.disconnectInterval = interval
+	}
+}
+
+// WithSeed seeds the random numbers that decide which faults are injected,
+// making a run repeatable.
+func WithSeed(seed int64) Option {
+	return func(c *IngressClient) {
+		c.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// Stats counts the injected faults.
+type Stats struct {
+	Delays          uint64
+	Errors          uint64
+	PartialFailures uint64
+	Disconnects     uint64
+}
+
+// IngressClient decorates a loggregator_v2.IngressClient with faults. It
+// should be created with the NewIngressClient constructor.
+type IngressClient struct {
+	// The counters are accessed atomically and therefore the first fields
+	// to keep them 64-bit aligned on 32-bit platforms.
+	delays          uint64
+	errors          uint64
+	partialFailures uint64
+	disconnects     uint64
+
+	inner loggregator_v2.IngressClient
+
+	latencyProbability float64
+	latency            time.Duration
+	errorProbability   float64
+	partialProbability float64
+	disconnectInterval time.Duration
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewIngressClient returns an IngressClient that injects faults into the
+// calls of inner. Without options it injects no faults.
+func NewIngressClient(inner loggregator_v2.IngressClient, opts ...Option) *IngressClient {
+	c := &IngressClient{
+		inner: inner,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	return c
+}
+
+// Decorator returns a function for loggregator.WithTransportDecorator that
+// wraps the client's transport with the options.
+func Decorator(opts ...Option) func(loggregator_v2.IngressClient) loggregator_v2.IngressClient {
+	return func(inner loggregator_v2.IngressClient) loggregator_v2.IngressClient {
+		return NewIngressClient(inner, opts...)
+	}
+}
+
+// Stats returns the number of faults injected so far.
+func (c *IngressClient) Stats() Stats {
+	return Stats{
+		Delays:          atomic.LoadUint64(&c.delays),
+		Errors:          atomic.LoadUint64(&c.errors),
+		PartialFailures: atomic.LoadUint64(&c.partialFailures),
+		Disconnects:     atomic.LoadUint64(&c.disconnects),
+	}
+}
+
+// Sender implements loggregator_v2.IngressClient.
+func (c *IngressClient) Sender(ctx context.Context, opts ...grpc.CallOption) (loggregator_v2.Ingress_SenderClient, error) {
+	if err := c.fault(); err != nil {
+		return nil, err
+	}
+
+	s, err := c.inner.Sender(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &senderClient{Ingress_SenderClient: s, c: c}, nil
+}
+
+// BatchSender implements loggregator_v2.IngressClient.
+func (c *IngressClient) BatchSender(ctx context.Context, opts ...grpc.CallOption) (loggregator_v2.Ingress_BatchSenderClient, error) {
+	if err := c.fault(); err != nil {
+		return nil, err
+	}
+
+	s, err := c.inner.BatchSender(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batchSenderClient{Ingress_BatchSenderClient: s, c: c, opened: time.Now()}, nil
+}
+
+// Send implements loggregator_v2.IngressClient.
+func (c *IngressClient) Send(ctx context.Context, b *loggregator_v2.EnvelopeBatch, opts ...grpc.CallOption) (*loggregator_v2.SendResponse, error) {
+	if err := c.fault(); err != nil {
+		return nil, err
+	}
+
+	if part, ok := c.partial(b); ok {
+		c.inner.Send(ctx, part, opts...)
+		return nil, ErrInjected
+	}
+
+	return c.inner.Send(ctx, b, opts...)
+}
+
+// fault delays the call and decides whether it fails.
+func (c *IngressClient) fault() error {
+	if c.chance(c.latencyProbability) {
+		atomic.AddUint64(&c.delays, 1)
+		time.Sleep(c.latency)
+	}
+
+	if c.chance(c.errorProbability) {
+		atomic.AddUint64(&c.errors, 1)
+		return ErrInjected
+	}
+
+	return nil
+}
+
+// partial decides whether only a part of the batch is sent and returns that
+// part.
+func (c *IngressClient) partial(b *loggregator_v2.EnvelopeBatch) (*loggregator_v2.EnvelopeBatch, bool) {
+	n := len(b.GetBatch())
+	if n < 2 || !c.chance(c.partialProbability) {
+		return nil, false
+	}
+	atomic.AddUint64(&c.partialFailures, 1)
+
+	c.mu.Lock()
+	sent := 1 + c.rand.Intn(n-1)
+	c.mu.Unlock()
+
+	return &loggregator_v2.EnvelopeBatch{Batch: b.GetBatch()[:sent]}, true
+}
+
+func (c *IngressClient) chance(probability float64) bool {
+	if probability <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rand.Float64() < probability
+}
+
+type senderClient struct {
+	loggregator_v2.Ingress_SenderClient
+	c *IngressClient
+}
+
+func (s *senderClient) Send(e *loggregator_v2.Envelope) error {
+	if err := s.c.fault(); err != nil {
+		return err
+	}
+
+	return s.Ingress_SenderClient.Send(e)
+}
+
+type batchSenderClient struct {
+	loggregator_v2.Ingress_BatchSenderClient
+	c      *IngressClient
+	opened time.Time
+}
+
+func (s *batchSenderClient) Send(b *loggregator_v2.EnvelopeBatch) error {
+	if s.c.disconnectInterval > 0 && time.Since(s.opened) >= s.c.disconnectInterval {
+		atomic.AddUint64(&s.c.disconnects, 1)
+		s.Ingress_BatchSenderClient.CloseSend()
+		return ErrInjected
+	}
+
+	if err := s.c.fault(); err != nil {
+		return err
+	}
+
+	if part, ok := s.c.partial(b); ok {
+		s.Ingress_BatchSenderClient.Send(part)
+		return ErrInjected
+	}
+
+	return s.Ingress_BatchSenderClient.Send(b)
+}
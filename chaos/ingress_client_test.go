@@ -0,0 +1,117 @@
+package chaos_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/chaos"
+	"code.cloudfoundry.org/go-loggregator/fakes"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"golang.org/x/net/context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IngressClient", func() {
+	var (
+		inner  *fakes.FakeIngressClient
+		stream *fakes.FakeBatchSenderClient
+		batch  *loggregator_v2.EnvelopeBatch
+	)
+
+	BeforeEach(func() {
+		inner = &fakes.FakeIngressClient{}
+		stream = &fakes.FakeBatchSenderClient{}
+		inner.BatchSenderReturns(stream, nil)
+		inner.SendReturns(&loggregator_v2.SendResponse{}, nil)
+
+		batch = &loggregator_v2.EnvelopeBatch{
+			Batch: []*loggregator_v2.Envelope{
+				{SourceId: "a"}, {SourceId: "b"}, {SourceId: "c"},
+			},
+		}
+	})
+
+	It("passes calls through without options", func() {
+		c := chaos.NewIngressClient(inner)
+
+		s, err := c.BatchSender(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Send(batch)).To(Succeed())
+		Expect(stream.SendArgsForCall(0)).To(Equal(batch))
+
+		_, err = c.Send(context.Background(), batch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inner.SendCallCount()).To(Equal(1))
+
+		Expect(c.Stats()).To(Equal(chaos.Stats{}))
+	})
+
+	It("injects errors", func() {
+		c := chaos.NewIngressClient(inner, chaos.WithErrors(1))
+
+		_, err := c.BatchSender(context.Background())
+		Expect(err).To(Equal(chaos.ErrInjected))
+		Expect(inner.BatchSenderCallCount()).To(BeZero())
+
+		_, err = c.Send(context.Background(), batch)
+		Expect(err).To(Equal(chaos.ErrInjected))
+		Expect(inner.SendCallCount()).To(BeZero())
+
+		Expect(c.Stats().Errors).To(Equal(uint64(2)))
+	})
+
+	It("injects latency", func() {
+		c := chaos.NewIngressClient(inner, chaos.WithLatency(1, 20*time.Millisecond))
+
+		start := time.Now()
+		_, err := c.Send(context.Background(), batch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+		Expect(c.Stats().Delays).To(Equal(uint64(1)))
+	})
+
+	It("sends only a part of a batch", func() {
+		c := chaos.NewIngressClient(inner, chaos.WithPartialBatchFailures(1), chaos.WithSeed(1))
+
+		s, err := c.BatchSender(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Send(batch)).To(Equal(chaos.ErrInjected))
+
+		sent := stream.SendArgsForCall(0).GetBatch()
+		Expect(len(sent)).To(BeNumerically(">=", 1))
+		Expect(len(sent)).To(BeNumerically("<", 3))
+		Expect(sent[0].GetSourceId()).To(Equal("a"))
+
+		_, err = c.Send(context.Background(), batch)
+		Expect(err).To(Equal(chaos.ErrInjected))
+		_, sentBatch, _ := inner.SendArgsForCall(0)
+		Expect(len(sentBatch.GetBatch())).To(BeNumerically("<", 3))
+
+		Expect(c.Stats().PartialFailures).To(Equal(uint64(2)))
+	})
+
+	It("disconnects streams periodically", func() {
+		c := chaos.NewIngressClient(inner, chaos.WithDisconnects(10*time.Millisecond))
+
+		s, err := c.BatchSender(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Send(batch)).To(Succeed())
+
+		time.Sleep(20 * time.Millisecond)
+		Expect(s.Send(batch)).To(Equal(chaos.ErrInjected))
+		Expect(stream.CloseSendCallCount()).To(Equal(1))
+		Expect(c.Stats().Disconnects).To(Equal(uint64(1)))
+
+		s, err = c.BatchSender(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Send(batch)).To(Succeed())
+	})
+
+	It("decorates the transport of a client", func() {
+		decorate := chaos.Decorator(chaos.WithErrors(1))
+
+		_, err := decorate(inner).BatchSender(context.Background())
+		Expect(err).To(Equal(chaos.ErrInjected))
+	})
+})
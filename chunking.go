@@ -0,0 +1,260 @@
+package loggregator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+)
+
+// The tags that identify the chunks of a log.
+const (
+	chunkIDTag    = "chunk_id"
+	chunkIndexTag = "chunk_index"
+	chunkTotalTag = "chunk_total"
+)
+
+// chunkOverhead is the space reserved in every chunk for the chunk tags and
+// the tags and source info the client adds after chunking.
+const chunkOverhead = 256
+
+// WithLogChunking configures the client to split logs that exceed the
+// maximum envelope size (see WithMaxEnvelopeSize) into several log
+// envelopes instead of dropping them. Every chunk carries the chunk_id,
+// chunk_index and chunk_total tags. Consumers may put the log back
+// together with a LogReassembler.
+func WithLogChunking() IngressOption {
+	return func(c *IngressClient) {
+		c.logChunking = true
+	}
+}
+
+// chunk splits the log envelope into chunks that fit the maximum envelope
+// size. Multi-byte runes are never split across chunks. Envelopes that fit
+// are returned as is.
+func (c *IngressClient) chunk(e *loggregator_v2.Envelope) []*loggregator_v2.Envelope {
+	size := proto.Size(e)
+	if !c.logChunking || size <= c.maxEnvelopeSize {
+		return []*loggregator_v2.Envelope{e}
+	}
+
+	log := e.GetLog()
+	payload := log.GetPayload()
+	chunkSize := c.maxEnvelopeSize - (size - len(payload)) - len(c.sourceID) - len(c.instanceID) - chunkOverhead
+	if chunkSize <= 0 {
+		return []*loggregator_v2.Envelope{e}
+	}
+
+	id, err := newChunkID()
+	if err != nil {
+		c.logger.Printf("Error while chunking log: %s", err)
+		return []*loggregator_v2.Envelope{e}
+	}
+
+	ends := chunkEnds(payload, chunkSize)
+	total := len(ends)
+	chunks := make([]*loggregator_v2.Envelope, 0, total)
+	start := 0
+	for i, end := range ends {
+		tags := make(map[string]string, len(e.Tags)+3)
+		for k, v := range e.Tags {
+			tags[k] = v
+		}
+		tags[chunkIDTag] = id
+		tags[chunkIndexTag] = strconv.Itoa(i)
+		tags[chunkTotalTag] = strconv.Itoa(total)
+
+		chunks = append(chunks, &loggregator_v2.Envelope{
+			Timestamp:      e.Timestamp,
+			SourceId:       e.SourceId,
+			InstanceId:     e.InstanceId,
+			DeprecatedTags: e.DeprecatedTags,
+			Tags:           tags,
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{
+					Payload: payload[start:end],
+					Type:    log.GetType(),
+				},
+			},
+		})
+		start = end
+	}
+
+	return chunks
+}
+
+// chunkEnds returns the end of every chunk of the payload. Chunks hold up to
+// chunkSize bytes and end on a rune boundary so that text stays valid UTF-8
+// in every chunk. Payloads that are not UTF-8 are cut at chunkSize once no
+// rune start is found within the length of a rune.
+func chunkEnds(payload []byte, chunkSize int) []int {
+	ends := make([]int, 0, (len(payload)+chunkSize-1)/chunkSize)
+	for start := 0; start < len(payload); {
+		end := start + chunkSize
+		if end >= len(payload) {
+			ends = append(ends, len(payload))
+			break
+		}
+
+		cut := end
+		for cut > start && end-cut < utf8.UTFMax-1 && !utf8.RuneStart(payload[cut]) {
+			cut--
+		}
+		if cut > start && utf8.RuneStart(payload[cut]) {
+			end = cut
+		}
+
+		ends = append(ends, end)
+		start = end
+	}
+
+	return ends
+}
+
+func newChunkID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// LogReassembler puts logs that were split by a client created with
+// WithLogChunking back together. It is safe for concurrent use.
+type LogReassembler struct {
+	maxPending int
+
+	mu      sync.Mutex
+	pending map[chunkKey]*chunkSet
+	order   []chunkKey
+}
+
+type chunkKey struct {
+	sourceID string
+	id       string
+}
+
+type chunkSet struct {
+	first    *loggregator_v2.Envelope
+	chunks   [][]byte
+	received int
+}
+
+// NewLogReassembler returns a LogReassembler that holds the chunks of up to
+// maxPending incomplete logs. Once the limit is reached, the chunks of the
+// oldest incomplete log are discarded. A maxPending below 1 is treated as 1.
+func NewLogReassembler(maxPending int) *LogReassembler {
+	if maxPending < 1 {
+		maxPending = 1
+	}
+
+	return &LogReassembler{
+		maxPending: maxPending,
+		pending:    make(map[chunkKey]*chunkSet),
+	}
+}
+
+// Add adds an envelope. If the envelope is not a chunk, it is returned as
+// is. If it completes a log, the log is returned with the payloads of all
+// chunks and without the chunk tags. Otherwise, Add returns false.
+func (r *LogReassembler) Add(e *loggregator_v2.Envelope) (*loggregator_v2.Envelope, bool) {
+	id, ok := e.GetTags()[chunkIDTag]
+	if !ok || e.GetLog() == nil {
+		return e, true
+	}
+
+	index, err := strconv.Atoi(e.GetTags()[chunkIndexTag])
+	if err != nil {
+		return e, true
+	}
+	total, err := strconv.Atoi(e.GetTags()[chunkTotalTag])
+	if err != nil || index < 0 || index >= total {
+		return e, true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := chunkKey{sourceID: e.GetSourceId(), id: id}
+	set, ok := r.pending[k]
+	if !ok {
+		set = &chunkSet{chunks: make([][]byte, total)}
+		r.pending[k] = set
+		r.order = append(r.order, k)
+		r.evict()
+	}
+
+	if index >= len(set.chunks) || set.chunks[index] != nil {
+		return nil, false
+	}
+	if index == 0 {
+		set.first = e
+	}
+	set.chunks[index] = e.GetLog().GetPayload()
+	set.received++
+
+	if set.received < len(set.chunks) {
+		return nil, false
+	}
+
+	r.remove(k)
+
+	return set.log(), true
+}
+
+// evict discards the oldest incomplete logs that exceed the limit.
+func (r *LogReassembler) evict() {
+	for len(r.order) > r.maxPending {
+		delete(r.pending, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+func (r *LogReassembler) remove(k chunkKey) {
+	delete(r.pending, k)
+	for i, o := range r.order {
+		if o == k {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// log returns the complete log based on the first chunk.
+func (s *chunkSet) log() *loggregator_v2.Envelope {
+	var size int
+	for _, c := range s.chunks {
+		size += len(c)
+	}
+	payload := make([]byte, 0, size)
+	for _, c := range s.chunks {
+		payload = append(payload, c...)
+	}
+
+	tags := make(map[string]string, len(s.first.Tags))
+	for k, v := range s.first.Tags {
+		tags[k] = v
+	}
+	delete(tags, chunkIDTag)
+	delete(tags, chunkIndexTag)
+	delete(tags, chunkTotalTag)
+
+	return &loggregator_v2.Envelope{
+		Timestamp:      s.first.Timestamp,
+		SourceId:       s.first.SourceId,
+		InstanceId:     s.first.InstanceId,
+		DeprecatedTags: s.first.DeprecatedTags,
+		Tags:           tags,
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{
+				Payload: payload,
+				Type:    s.first.GetLog().GetType(),
+			},
+		},
+	}
+}
@@ -0,0 +1,175 @@
+package loggregator_test
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Log chunking", func() {
+	It("splits large logs into chunks that can be reassembled", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithMaxEnvelopeSize(1024),
+			loggregator.WithLogChunking(),
+		)
+
+		payload := strings.Repeat("stack trace line\n", 200)
+		client.EmitLog(payload, loggregator.WithSourceInfo("some-source", "type", "0"), loggregator.WithStdout())
+
+		var recv loggregator_v2.Ingress_BatchSenderServer
+		Eventually(server.receivers, 10).Should(Receive(&recv))
+		var envelopes []*loggregator_v2.Envelope
+		for len(envelopes) == 0 || len(envelopes) < chunkTotal(envelopes[0]) {
+			batch, err := recv.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			envelopes = append(envelopes, batch.Batch...)
+		}
+		Expect(len(envelopes)).To(BeNumerically(">", 1))
+
+		r := loggregator.NewLogReassembler(10)
+		var (
+			log      *loggregator_v2.Envelope
+			complete bool
+		)
+		for i := len(envelopes) - 1; i >= 0; i-- {
+			Expect(proto.Size(envelopes[i])).To(BeNumerically("<=", 1024))
+			log, complete = r.Add(envelopes[i])
+		}
+
+		Expect(complete).To(BeTrue())
+		Expect(string(log.GetLog().Payload)).To(Equal(payload))
+		Expect(log.GetLog().Type).To(Equal(loggregator_v2.Log_OUT))
+		Expect(log.SourceId).To(Equal("some-source"))
+		Expect(log.Tags).To(HaveKeyWithValue("string", "client-string-tag"))
+		Expect(log.Tags).NotTo(HaveKey("chunk_id"))
+	})
+
+	It("does not split multi-byte runes across chunks", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithMaxEnvelopeSize(1024),
+			loggregator.WithLogChunking(),
+		)
+
+		// The three-byte runes start at offsets of 1 modulo 3, so at least
+		// one of the first two chunk boundaries falls within a rune.
+		payload := "a" + strings.Repeat("€", 600)
+		client.EmitLog(payload, loggregator.WithSourceInfo("some-source", "type", "0"))
+
+		var recv loggregator_v2.Ingress_BatchSenderServer
+		Eventually(server.receivers, 10).Should(Receive(&recv))
+		var envelopes []*loggregator_v2.Envelope
+		for len(envelopes) == 0 || len(envelopes) < chunkTotal(envelopes[0]) {
+			batch, err := recv.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			envelopes = append(envelopes, batch.Batch...)
+		}
+		Expect(len(envelopes)).To(BeNumerically(">", 2))
+
+		var joined []byte
+		for _, e := range envelopes {
+			Expect(proto.Size(e)).To(BeNumerically("<=", 1024))
+			Expect(utf8.Valid(e.GetLog().GetPayload())).To(BeTrue())
+			joined = append(joined, e.GetLog().GetPayload()...)
+		}
+		Expect(string(joined)).To(Equal(payload))
+	})
+
+	Describe("LogReassembler", func() {
+		chunk := func(id string, index, total int, payload string) *loggregator_v2.Envelope {
+			return &loggregator_v2.Envelope{
+				SourceId: "some-source",
+				Tags: map[string]string{
+					"chunk_id":    id,
+					"chunk_index": strconv.Itoa(index),
+					"chunk_total": strconv.Itoa(total),
+				},
+				Message: &loggregator_v2.Envelope_Log{
+					Log: &loggregator_v2.Log{Payload: []byte(payload)},
+				},
+			}
+		}
+
+		It("returns envelopes that are not chunks as is", func() {
+			e := &loggregator_v2.Envelope{SourceId: "some-source"}
+
+			log, ok := loggregator.NewLogReassembler(10).Add(e)
+			Expect(ok).To(BeTrue())
+			Expect(log).To(BeIdenticalTo(e))
+		})
+
+		It("ignores duplicate chunks", func() {
+			r := loggregator.NewLogReassembler(10)
+
+			_, ok := r.Add(chunk("a", 0, 2, "hello "))
+			Expect(ok).To(BeFalse())
+			_, ok = r.Add(chunk("a", 0, 2, "hello "))
+			Expect(ok).To(BeFalse())
+
+			log, ok := r.Add(chunk("a", 1, 2, "world"))
+			Expect(ok).To(BeTrue())
+			Expect(string(log.GetLog().Payload)).To(Equal("hello world"))
+		})
+
+		It("discards the oldest incomplete log", func() {
+			r := loggregator.NewLogReassembler(1)
+
+			r.Add(chunk("a", 0, 2, "a"))
+			r.Add(chunk("b", 0, 2, "b"))
+
+			_, ok := r.Add(chunk("a", 1, 2, "a"))
+			Expect(ok).To(BeFalse())
+		})
+
+		It("holds at least one incomplete log", func() {
+			for _, maxPending := range []int{0, -1} {
+				r := loggregator.NewLogReassembler(maxPending)
+
+				_, ok := r.Add(chunk("a", 0, 2, "hello "))
+				Expect(ok).To(BeFalse())
+
+				log, ok := r.Add(chunk("a", 1, 2, "world"))
+				Expect(ok).To(BeTrue())
+				Expect(string(log.GetLog().Payload)).To(Equal("hello world"))
+			}
+		})
+	})
+})
+
+func chunkTotal(e *loggregator_v2.Envelope) int {
+	total, err := strconv.Atoi(e.Tags["chunk_total"])
+	Expect(err).NotTo(HaveOccurred())
+
+	return total
+}
@@ -0,0 +1,111 @@
+package loggregator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// WithCircuitBreaker configures the client to stop accepting envelopes after
+// failureThreshold consecutive failures to send a batch. While the circuit
+// is open, emitted envelopes are dropped immediately and ErrCircuitOpen is
+// logged. Once the cool down has elapsed, envelopes are accepted again and
+// the circuit closes as soon as a batch is sent successfully. An event
+// envelope is emitted whenever the circuit opens or closes.
+func WithCircuitBreaker(failureThreshold uint, coolDown time.Duration) IngressOption {
+	return func(c *IngressClient) {
+		c.breaker = newCircuitBreaker(failureThreshold, coolDown)
+	}
+}
+
+// WithCircuitBreakerSilentDrop configures the circuit breaker to drop
+// envelopes without logging ErrCircuitOpen while the circuit is open.
+func WithCircuitBreakerSilentDrop() IngressOption {
+	return func(c *IngressClient) {
+		c.breakerSilent = true
+	}
+}
+
+// circuitBreaker tracks consecutive send failures. It is safe for concurrent
+// use.
+type circuitBreaker struct {
+	threshold uint
+	coolDown  time.Duration
+
+	mu        sync.Mutex
+	failures  uint
+	open      bool
+	openUntil time.Time
+
+	onStateChange func(open bool)
+}
+
+func newCircuitBreaker(threshold uint, coolDown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:     threshold,
+		coolDown:      coolDown,
+		onStateChange: func(bool) {},
+	}
+}
+
+// allow reports whether envelopes should be accepted. After the cool down
+// has elapsed envelopes are accepted again to probe whether the agent has
+// recovered.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !b.open || !time.Now().Before(b.openUntil)
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	wasOpen := b.open
+	b.failures = 0
+	b.open = false
+	b.mu.Unlock()
+
+	if wasOpen {
+		b.onStateChange(false)
+	}
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	b.failures++
+	opened := !b.open && b.failures >= b.threshold
+	if opened || b.open {
+		b.open = true
+		b.openUntil = time.Now().Add(b.coolDown)
+	}
+	b.mu.Unlock()
+
+	if opened {
+		b.onStateChange(true)
+	}
+}
+
+func (c *IngressClient) emitCircuitBreakerEvent(open bool) {
+	state := "closed"
+	body := "Sending envelopes succeeded, envelopes are accepted again."
+	if open {
+		state = "opened"
+		body = fmt.Sprintf(
+			"Sending envelopes failed %d consecutive times, envelopes are dropped for %s.",
+			c.breaker.threshold,
+			c.breaker.coolDown,
+		)
+	}
+	c.logger.Printf("Circuit breaker %s", state)
+
+	_, interval := c.batchConfig()
+	ctx, cancel := context.WithTimeout(c.ctx, interval+ackTimeout)
+	defer cancel()
+
+	err := c.EmitEvent(ctx, fmt.Sprintf("Loggregator client circuit breaker %s", state), body)
+	if err != nil {
+		c.logger.Printf("Error while emitting circuit breaker event: %s", err)
+	}
+}
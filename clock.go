@@ -0,0 +1,252 @@
+package loggregator
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Clock provides the timestamps of envelopes built by a client.
+type Clock interface {
+	Now() time.Time
+}
+
+// WithClock configures the client to take the timestamps of the envelopes
+// it builds from the given clock instead of the host clock. Envelopes passed
+// to EmitEnvelope keep their timestamps.
+func WithClock(clock Clock) IngressOption {
+	return func(c *IngressClient) {
+		c.clock = clock
+	}
+}
+
+// now returns the time of the client's clock.
+func (c *IngressClient) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+
+	return c.clock.Now()
+}
+
+// TimeSource returns the reference time, e.g. the time of an NTP server.
+type TimeSource func() (time.Time, error)
+
+// MonotonicClock is a Clock that does not jump when the host clock is
+// stepped. Its time is the host time at its creation advanced by the
+// monotonic clock of the process, corrected by an offset that is
+// periodically measured against a TimeSource. Changes of the offset are
+// slewed in at a bounded rate, so the time never jumps and never goes
+// backwards. It is safe for concurrent use.
+type MonotonicClock struct {
+	base    time.Time
+	wall    time.Time
+	maxSlew float64
+
+	mu     sync.RWMutex
+	synced bool
+	offset time.Duration
+
+	// from is the offset in effect when the clock started slewing toward
+	// offset, at since after base.
+	from  time.Duration
+	since time.Duration
+
+	done chan struct{}
+	once sync.Once
+}
+
+// defaultMaxSlew is the default rate in parts per million at which a
+// MonotonicClock slews toward a new offset, the limit of ntpd.
+const defaultMaxSlew = 500
+
+// maxSlewLimit is the highest slew rate in parts per million. Keeping the
+// rate below one second per second keeps the time from going backwards.
+const maxSlewLimit = 500000
+
+// MonotonicClockOption is the option type passed into NewMonotonicClock.
+type MonotonicClockOption func(*MonotonicClock)
+
+// WithMaxSlew configures the rate in parts per million at which the clock
+// slews toward a newly measured offset. It defaults to 500ppm, i.e. the
+// clock corrects by at most half a millisecond per second. Rates above
+// 500000ppm are capped.
+func WithMaxSlew(ppm float64) MonotonicClockOption {
+	return func(c *MonotonicClock) {
+		if ppm > maxSlewLimit {
+			ppm = maxSlewLimit
+		}
+		c.maxSlew = ppm / 1e6
+	}
+}
+
+// NewMonotonicClock returns a MonotonicClock that measures its offset
+// against the given source immediately and then every interval until Stop
+// is called. The first offset applies right away, later offsets are slewed
+// in. If the source is nil, no offset is applied. Errors of the source are
+// ignored and the previous offset is kept.
+func NewMonotonicClock(source TimeSource, interval time.Duration, opts ...MonotonicClockOption) *MonotonicClock {
+	now := time.Now()
+	c := &MonotonicClock{
+		base:    now,
+		wall:    now.Round(0),
+		maxSlew: defaultMaxSlew / 1e6,
+		done:    make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	if source != nil {
+		c.sync(source)
+		go c.run(source, interval)
+	}
+
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *MonotonicClock) Now() time.Time {
+	elapsed := time.Since(c.base)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.wall.Add(elapsed + c.applied(elapsed))
+}
+
+// Offset returns the last measured difference between the source and the
+// uncorrected time of the clock. The clock may still be slewing toward it.
+func (c *MonotonicClock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.offset
+}
+
+// Stop stops measuring the offset. The clock keeps slewing toward the last
+// offset.
+func (c *MonotonicClock) Stop() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
+
+// raw returns the time of the clock without the offset.
+func (c *MonotonicClock) raw() time.Time {
+	return c.wall.Add(time.Since(c.base))
+}
+
+// applied returns the offset in effect the given time after base. It moves
+// from the previous offset toward the measured one at the maximum slew
+// rate. c.mu must be held.
+func (c *MonotonicClock) applied(elapsed time.Duration) time.Duration {
+	step := time.Duration(float64(elapsed-c.since) * c.maxSlew)
+	diff := c.offset - c.from
+	switch {
+	case diff > step:
+		return c.from + step
+	case diff < -step:
+		return c.from - step
+	default:
+		return c.offset
+	}
+}
+
+func (c *MonotonicClock) run(source TimeSource, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			c.sync(source)
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// sync measures the offset assuming the source time was taken half way
+// through the call.
+func (c *MonotonicClock) sync(source TimeSource) {
+	before := c.raw()
+	ref, err := source()
+	if err != nil {
+		return
+	}
+	after := c.raw()
+
+	offset := ref.Sub(before.Add(after.Sub(before) / 2))
+	elapsed := time.Since(c.base)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.synced {
+		c.from = c.applied(elapsed)
+	} else {
+		c.from = offset
+		c.synced = true
+	}
+	c.since = elapsed
+	c.offset = offset
+}
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and
+// the Unix epoch.
+const ntpEpochOffset = 2208988800
+
+// SNTPTimeSource returns a TimeSource that queries the given (S)NTP server,
+// e.g. "pool.ntp.org:123". The returned time is corrected for the round trip
+// to the server.
+func SNTPTimeSource(addr string, timeout time.Duration) TimeSource {
+	return func() (time.Time, error) {
+		conn, err := net.DialTimeout("udp", addr, timeout)
+		if err != nil {
+			return time.Time{}, err
+		}
+		defer conn.Close()
+
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			return time.Time{}, err
+		}
+
+		// Leap indicator 0, version 4, mode 3 (client).
+		req := make([]byte, 48)
+		req[0] = 0x23
+
+		start := time.Now()
+		if _, err := conn.Write(req); err != nil {
+			return time.Time{}, err
+		}
+
+		resp := make([]byte, 48)
+		n, err := conn.Read(resp)
+		if err != nil {
+			return time.Time{}, err
+		}
+		elapsed := time.Since(start)
+
+		if n < 48 || resp[0]&0x7 != 4 {
+			return time.Time{}, errors.New("loggregator: invalid SNTP response")
+		}
+
+		received := ntpTime(resp[32:40])
+		transmitted := ntpTime(resp[40:48])
+		delay := elapsed - transmitted.Sub(received)
+
+		return transmitted.Add(delay / 2), nil
+	}
+}
+
+// ntpTime decodes a 64 bit NTP timestamp.
+func ntpTime(b []byte) time.Time {
+	secs := int64(binary.BigEndian.Uint32(b[:4])) - ntpEpochOffset
+	frac := int64(binary.BigEndian.Uint32(b[4:8]))
+
+	return time.Unix(secs, (frac*1e9)>>32)
+}
@@ -0,0 +1,150 @@
+package loggregator_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clock", func() {
+	It("takes the timestamps of envelopes from the clock", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false, loggregator.WithClock(fixedClock(now)))
+
+		client.EmitLog("message")
+		client.EmitCounter("some-counter")
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Expect(envelopes[0].Timestamp).To(Equal(now.UnixNano()))
+		Expect(envelopes[1].Timestamp).To(Equal(now.UnixNano()))
+	})
+
+	Describe("MonotonicClock", func() {
+		It("follows the host clock without a source", func() {
+			clock := loggregator.NewMonotonicClock(nil, time.Hour)
+			defer clock.Stop()
+
+			Expect(clock.Now()).To(BeTemporally("~", time.Now(), 10*time.Millisecond))
+			Expect(clock.Offset()).To(BeZero())
+		})
+
+		It("corrects its time by the offset to the source", func() {
+			clock := loggregator.NewMonotonicClock(func() (time.Time, error) {
+				return time.Now().Add(time.Hour), nil
+			}, time.Hour)
+			defer clock.Stop()
+
+			Expect(clock.Offset()).To(BeNumerically("~", time.Hour, 10*time.Millisecond))
+			Expect(clock.Now()).To(BeTemporally("~", time.Now().Add(time.Hour), 10*time.Millisecond))
+		})
+
+		It("keeps the previous offset when the source fails", func() {
+			var calls int64
+			clock := loggregator.NewMonotonicClock(func() (time.Time, error) {
+				if atomic.AddInt64(&calls, 1) > 1 {
+					return time.Time{}, errors.New("some-error")
+				}
+				return time.Now().Add(time.Minute), nil
+			}, 10*time.Millisecond)
+			defer clock.Stop()
+
+			Eventually(func() int64 {
+				return atomic.LoadInt64(&calls)
+			}).Should(BeNumerically(">=", 3))
+			Expect(clock.Offset()).To(BeNumerically("~", time.Minute, 10*time.Millisecond))
+		})
+
+		It("keeps increasing when the offset to the source changes", func() {
+			var calls int64
+			clock := loggregator.NewMonotonicClock(func() (time.Time, error) {
+				if atomic.AddInt64(&calls, 1)%2 == 1 {
+					return time.Now().Add(time.Hour), nil
+				}
+				return time.Now().Add(-time.Hour), nil
+			}, time.Millisecond)
+			defer clock.Stop()
+
+			prev := clock.Now()
+			for atomic.LoadInt64(&calls) < 20 {
+				now := clock.Now()
+				Expect(now).NotTo(BeTemporally("<", prev))
+				prev = now
+			}
+			Expect(prev).To(BeTemporally("~", time.Now().Add(time.Hour), 10*time.Millisecond))
+		})
+
+		It("slews toward a new offset", func() {
+			var calls int64
+			clock := loggregator.NewMonotonicClock(func() (time.Time, error) {
+				if atomic.AddInt64(&calls, 1) > 1 {
+					return time.Now(), nil
+				}
+				return time.Now().Add(time.Hour), nil
+			}, 10*time.Millisecond, loggregator.WithMaxSlew(500000))
+			defer clock.Stop()
+
+			Eventually(func() time.Duration {
+				return clock.Offset()
+			}).Should(BeNumerically("~", 0, 10*time.Millisecond))
+			Expect(clock.Now()).To(BeTemporally(">", time.Now().Add(59*time.Minute)))
+
+			start := clock.Now()
+			hostStart := time.Now()
+			time.Sleep(100 * time.Millisecond)
+			Expect(clock.Now().Sub(start)).To(BeNumerically("~", time.Since(hostStart)/2, 10*time.Millisecond))
+		})
+	})
+
+	It("queries the time of an SNTP server", func() {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		serverTime := time.Now().Add(-time.Hour)
+		go func() {
+			req := make([]byte, 48)
+			_, addr, err := conn.ReadFrom(req)
+			if err != nil {
+				return
+			}
+
+			resp := make([]byte, 48)
+			resp[0] = 0x24
+			putNTPTime(resp[32:40], serverTime)
+			putNTPTime(resp[40:48], serverTime)
+			conn.WriteTo(resp, addr)
+		}()
+
+		source := loggregator.SNTPTimeSource(conn.LocalAddr().String(), time.Second)
+		t, err := source()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t).To(BeTemporally("~", serverTime, 10*time.Millisecond))
+	})
+})
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func putNTPTime(b []byte, t time.Time) {
+	binary.BigEndian.PutUint32(b[:4], uint32(t.Unix()+2208988800))
+	binary.BigEndian.PutUint32(b[4:], uint32((int64(t.Nanosecond())<<32)/1e9))
+}
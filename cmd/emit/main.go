@@ -0,0 +1,188 @@
+// emit sends synthetic envelopes to a Loggregator agent via the v2 ingress
+// API. It can be used to validate the connectivity to an agent or to load
+// test a pipeline.
+//
+// Usage:
+//
+//	emit -type log -message "hello" -count 100 -rate 10 -concurrency 2
+//	tail -f app.log | emit -type log -stdin
+//
+// With -stdin, every line read from stdin is emitted: as the payload of a
+// log or as the delta, value or duration of a counter, gauge or timer.
+// The TLS certificates default to the CA_CERT_PATH, CERT_PATH and KEY_PATH
+// environment variables.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:3458", "address of the Loggregator agent")
+	caPath := flag.String("ca", os.Getenv("CA_CERT_PATH"), "path to the CA certificate")
+	certPath := flag.String("cert", os.Getenv("CERT_PATH"), "path to the client certificate")
+	keyPath := flag.String("key", os.Getenv("KEY_PATH"), "path to the client key")
+	envelopeType := flag.String("type", "log", "envelope type: log, counter, gauge or timer")
+	sourceID := flag.String("source-id", "emit", "source ID of the envelopes")
+	instanceID := flag.String("instance-id", "", "instance ID of the envelopes")
+	name := flag.String("name", "emit", "name of the counter, gauge or timer")
+	message := flag.String("message", "synthetic log from emit", "payload of logs")
+	value := flag.String("value", "1", "delta of counters, value of gauges or duration of timers (e.g. 150ms)")
+	unit := flag.String("unit", "", "unit of gauges")
+	stdin := flag.Bool("stdin", false, "emit one envelope per line read from stdin")
+	count := flag.Int("count", 1, "number of envelopes to emit, ignored with -stdin")
+	rate := flag.Float64("rate", 0, "envelopes per second, unlimited if 0")
+	concurrency := flag.Int("concurrency", 1, "number of concurrent emitters")
+	flag.Parse()
+
+	tlsConfig, err := loggregator.NewIngressTLSConfig(*caPath, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal("Could not create TLS config: ", err)
+	}
+
+	client, err := loggregator.NewIngressClient(
+		tlsConfig,
+		loggregator.WithAddr(*addr),
+		loggregator.WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+	if err != nil {
+		log.Fatal("Could not create client: ", err)
+	}
+
+	e := &emitter{
+		client:       client,
+		envelopeType: *envelopeType,
+		sourceID:     *sourceID,
+		instanceID:   *instanceID,
+		name:         *name,
+		unit:         *unit,
+	}
+	if err := e.emit(*message, *value, true); err != nil {
+		log.Fatal(err)
+	}
+
+	values := make(chan string)
+	go func() {
+		defer close(values)
+
+		if !*stdin {
+			v := *value
+			if *envelopeType == "log" {
+				v = *message
+			}
+			for i := 0; i < *count; i++ {
+				values <- v
+			}
+			return
+		}
+
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			values <- scanner.Text()
+		}
+		if err := scanner.Err(); err != nil {
+			log.Printf("Could not read stdin: %s", err)
+		}
+	}()
+
+	var throttle <-chan time.Time
+	if *rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+		defer t.Stop()
+		throttle = t.C
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for v := range values {
+				if throttle != nil {
+					<-throttle
+				}
+
+				if err := e.emit(v, v, false); err != nil {
+					log.Print(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := client.CloseSend(); err != nil {
+		log.Fatal("Could not flush envelopes: ", err)
+	}
+}
+
+type emitter struct {
+	client       *loggregator.IngressClient
+	envelopeType string
+	sourceID     string
+	instanceID   string
+	name         string
+	unit         string
+}
+
+// emit emits a single envelope. The message is used for logs and the value
+// for every other type. When dryRun is set, the arguments are validated but
+// nothing is emitted.
+func (e *emitter) emit(message, value string, dryRun bool) error {
+	switch e.envelopeType {
+	case "log":
+		if !dryRun {
+			e.client.EmitLog(message, loggregator.WithSourceInfo(e.sourceID, "emit", e.instanceID))
+		}
+	case "counter":
+		delta, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid counter delta %q: %s", value, err)
+		}
+		if !dryRun {
+			e.client.EmitCounter(
+				e.name,
+				loggregator.WithDelta(delta),
+				loggregator.WithCounterSourceInfo(e.sourceID, e.instanceID),
+			)
+		}
+	case "gauge":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid gauge value %q: %s", value, err)
+		}
+		if !dryRun {
+			e.client.EmitGauge(
+				loggregator.WithGaugeValue(e.name, v, e.unit),
+				loggregator.WithGaugeSourceInfo(e.sourceID, e.instanceID),
+			)
+		}
+	case "timer":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid timer duration %q: %s", value, err)
+		}
+		if !dryRun {
+			stop := time.Now()
+			e.client.EmitTimer(
+				e.name,
+				stop.Add(-d),
+				stop,
+				loggregator.WithTimerSourceInfo(e.sourceID, e.instanceID),
+			)
+		}
+	default:
+		return fmt.Errorf("unknown envelope type %q", e.envelopeType)
+	}
+
+	return nil
+}
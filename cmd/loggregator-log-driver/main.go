@@ -0,0 +1,72 @@
+//go:build linux
+// +build linux
+
+// loggregator-log-driver is a docker logging driver plugin that forwards the
+// stdout and stderr of containers to a Loggregator agent as v2 logs. It
+// serves the plugin API on a unix socket in docker's plugin directory.
+//
+// Usage:
+//
+//	loggregator-log-driver -addr <host:port> [-socket /run/docker/plugins/loggregator.sock]
+//	docker run --log-driver loggregator --log-opt source-id=web nginx
+//
+// The TLS certificates default to the CA_CERT_PATH, CERT_PATH and KEY_PATH
+// environment variables. See the logdriver package for the log options.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/logdriver"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:3458", "address of the Loggregator agent")
+	caPath := flag.String("ca", os.Getenv("CA_CERT_PATH"), "path to the CA certificate")
+	certPath := flag.String("cert", os.Getenv("CERT_PATH"), "path to the client certificate")
+	keyPath := flag.String("key", os.Getenv("KEY_PATH"), "path to the client key")
+	socket := flag.String("socket", "/run/docker/plugins/loggregator.sock", "path of the plugin socket")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+
+	tlsConfig, err := loggregator.NewIngressTLSConfig(*caPath, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal("Could not create TLS config: ", err)
+	}
+
+	client, err := loggregator.NewIngressClient(
+		tlsConfig,
+		loggregator.WithAddr(*addr),
+		loggregator.WithLogger(logger),
+	)
+	if err != nil {
+		log.Fatal("Could not create client: ", err)
+	}
+
+	os.Remove(*socket)
+	lis, err := net.Listen("unix", *socket)
+	if err != nil {
+		log.Fatal("Could not listen: ", err)
+	}
+
+	serveErrs := make(chan error, 1)
+	go func() {
+		serveErrs <- http.Serve(lis, logdriver.New(client, logdriver.WithLogger(logger)))
+	}()
+
+	select {
+	case err := <-serveErrs:
+		log.Fatal(err)
+	case err := <-loggregator.FlushOnSignal(client):
+		lis.Close()
+		if err != nil {
+			log.Fatal("Could not flush logs: ", err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// loggregator-tail streams the envelopes of a source ID from the Loggregator
+// egress API (e.g. the Reverse Log Proxy) and writes them to stdout.
+//
+// Usage:
+//
+//	loggregator-tail -addr <host:port> -source-id <source-id> [-types log,counter] [-output json|pretty|multiline] [-include APP,RTR]
+//
+// The TLS certificates default to the CA_CERT_PATH, CERT_PATH and KEY_PATH
+// environment variables.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/format"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+func main() {
+	addr := flag.String("addr", os.Getenv("LOGS_API_ADDR"), "address of the Loggregator egress API")
+	caPath := flag.String("ca", os.Getenv("CA_CERT_PATH"), "path to the CA certificate")
+	certPath := flag.String("cert", os.Getenv("CERT_PATH"), "path to the client certificate")
+	keyPath := flag.String("key", os.Getenv("KEY_PATH"), "path to the client key")
+	sourceID := flag.String("source-id", "", "source ID to tail, all sources if empty")
+	shardID := flag.String("shard-id", "loggregator-tail", "shard ID of the subscription")
+	types := flag.String("types", "log,counter,gauge,timer,event", "comma separated envelope types to tail")
+	output := flag.String("output", "pretty", "output format: json, pretty or multiline")
+	color := flag.String("color", "auto", "color pretty output: auto, always or never")
+	utc := flag.Bool("utc", false, "render timestamps in UTC instead of the local time zone")
+	include := flag.String("include", "", "comma separated source type prefixes to include, e.g. APP,RTR")
+	exclude := flag.String("exclude", "", "comma separated source type prefixes to exclude")
+	flag.Parse()
+
+	formatter, err := buildFormatter(*color, *utc, *include, *exclude)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	selectors, err := buildSelectors(*sourceID, strings.Split(*types, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	write, err := writer(*output, formatter)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tlsConfig, err := loggregator.NewEgressTLSConfig(*caPath, *certPath, *keyPath)
+	if err != nil {
+		log.Fatal("Could not create TLS config: ", err)
+	}
+
+	streamConnector := loggregator.NewEnvelopeStreamConnector(
+		*addr,
+		tlsConfig,
+		loggregator.WithEnvelopeStreamLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+
+	rx := streamConnector.Stream(context.Background(), &loggregator_v2.EgressBatchRequest{
+		ShardId:   *shardID,
+		Selectors: selectors,
+	})
+
+	for {
+		for _, e := range rx() {
+			write(e)
+		}
+	}
+}
+
+func buildSelectors(sourceID string, types []string) ([]*loggregator_v2.Selector, error) {
+	var selectors []*loggregator_v2.Selector
+	for _, t := range types {
+		s := &loggregator_v2.Selector{SourceId: sourceID}
+
+		switch strings.TrimSpace(t) {
+		case "log":
+			s.Message = &loggregator_v2.Selector_Log{Log: &loggregator_v2.LogSelector{}}
+		case "counter":
+			s.Message = &loggregator_v2.Selector_Counter{Counter: &loggregator_v2.CounterSelector{}}
+		case "gauge":
+			s.Message = &loggregator_v2.Selector_Gauge{Gauge: &loggregator_v2.GaugeSelector{}}
+		case "timer":
+			s.Message = &loggregator_v2.Selector_Timer{Timer: &loggregator_v2.TimerSelector{}}
+		case "event":
+			s.Message = &loggregator_v2.Selector_Event{Event: &loggregator_v2.EventSelector{}}
+		default:
+			return nil, fmt.Errorf("unknown envelope type %q", t)
+		}
+
+		selectors = append(selectors, s)
+	}
+
+	return selectors, nil
+}
+
+func buildFormatter(color string, utc bool, include, exclude string) (*format.Formatter, error) {
+	var opts []format.FormatterOption
+
+	switch color {
+	case "auto":
+		opts = append(opts, format.WithColor(format.ColorEnabled(os.Stdout)))
+	case "always":
+		opts = append(opts, format.WithColor(true))
+	case "never":
+	default:
+		return nil, fmt.Errorf("unknown color mode %q", color)
+	}
+
+	if utc {
+		opts = append(opts, format.WithLocation(time.UTC))
+	}
+	if include != "" {
+		opts = append(opts, format.WithIncludeSourceTypes(strings.Split(include, ",")...))
+	}
+	if exclude != "" {
+		opts = append(opts, format.WithExcludeSourceTypes(strings.Split(exclude, ",")...))
+	}
+
+	return format.NewFormatter(opts...), nil
+}
+
+func writer(output string, f *format.Formatter) (func(*loggregator_v2.Envelope), error) {
+	write, err := formatWriter(output, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(e *loggregator_v2.Envelope) {
+		if f.Include(e) {
+			write(e)
+		}
+	}, nil
+}
+
+func formatWriter(output string, f *format.Formatter) (func(*loggregator_v2.Envelope), error) {
+	switch output {
+	case "json":
+		m := jsonpb.Marshaler{}
+		return func(e *loggregator_v2.Envelope) {
+			if err := m.Marshal(os.Stdout, e); err != nil {
+				log.Printf("Could not marshal envelope: %s", err)
+				return
+			}
+			fmt.Println()
+		}, nil
+	case "pretty":
+		return func(e *loggregator_v2.Envelope) {
+			fmt.Println(f.Line(e))
+		}, nil
+	case "multiline":
+		return func(e *loggregator_v2.Envelope) {
+			fmt.Println(f.Multiline(e))
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", output)
+	}
+}
@@ -0,0 +1,196 @@
+package loggregator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// maxTagLength is the maximum length of a tag name or value accepted by
+// Loggregator.
+const maxTagLength = 256
+
+// MetronConfig holds the configuration of a client that emits to the local
+// Loggregator agent. Its JSON field names match the properties rendered by
+// BOSH job templates. A config file may be loaded with LoadConfig and the
+// job properties may be read from the instance's BOSH spec with
+// ApplyBOSHSpec.
+type MetronConfig struct {
+	// Addr is the address of the agent. It may be a host name or an IP
+	// address, optionally followed by a port, e.g. "agent.example.com:3458"
+	// or "[::1]:3458". IPv6 addresses with a port have to be enclosed in
+	// brackets. It defaults to localhost.
+	Addr string `json:"loggregator_addr" yaml:"loggregator_addr"`
+
+	// APIPort is the port of the agent if Addr does not include one.
+	//
+	// Deprecated: include the port in Addr instead.
+	APIPort int `json:"loggregator_api_port" yaml:"loggregator_api_port"`
+
+	CACertPath string `json:"loggregator_ca_path" yaml:"loggregator_ca_path"`
+	CertPath   string `json:"loggregator_cert_path" yaml:"loggregator_cert_path"`
+	KeyPath    string `json:"loggregator_key_path" yaml:"loggregator_key_path"`
+
+	JobDeployment string `json:"job_deployment" yaml:"job_deployment"`
+	JobName       string `json:"job_name" yaml:"job_name"`
+	JobIndex      string `json:"job_index" yaml:"job_index"`
+	JobIP         string `json:"job_ip" yaml:"job_ip"`
+	JobOrigin     string `json:"job_origin" yaml:"job_origin"`
+
+	Tags map[string]string `json:"tags" yaml:"tags"`
+
+	// RetireV1 disables the v1 path of a DualClient. See WithV1Retired.
+	RetireV1 bool `json:"loggregator_retire_v1" yaml:"loggregator_retire_v1"`
+}
+
+// ValidationError is returned by MetronConfig.Validate. It holds every
+// problem found in the config.
+type ValidationError struct {
+	Errors []error
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return "invalid metron config: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the config for problems that would otherwise only surface
+// as TLS or dial errors. If any are found, a *ValidationError holding all of
+// them is returned.
+func (c MetronConfig) Validate() error {
+	var errs []error
+
+	if _, err := c.Address(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.CACertPath == "" {
+		errs = append(errs, errors.New("loggregator_ca_path is required"))
+	}
+	if c.CertPath == "" {
+		errs = append(errs, errors.New("loggregator_cert_path is required"))
+	}
+	if c.KeyPath == "" {
+		errs = append(errs, errors.New("loggregator_key_path is required"))
+	}
+
+	for name, value := range c.tags() {
+		if len(name) > maxTagLength {
+			errs = append(errs, fmt.Errorf("tag name %q exceeds %d characters", name, maxTagLength))
+		}
+		if len(value) > maxTagLength {
+			errs = append(errs, fmt.Errorf("value of tag %q exceeds %d characters", name, maxTagLength))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// Address returns the address of the agent in host:port form. The host
+// defaults to localhost and the port to APIPort if Addr does not include
+// them.
+func (c MetronConfig) Address() (string, error) {
+	host, port := "localhost", ""
+	if c.Addr != "" {
+		var err error
+		host, port, err = net.SplitHostPort(c.Addr)
+		if err != nil {
+			host, port = strings.TrimSuffix(strings.TrimPrefix(c.Addr, "["), "]"), ""
+		}
+	}
+
+	if port == "" {
+		if c.APIPort < 1 || c.APIPort > 65535 {
+			return "", fmt.Errorf("loggregator_api_port %d is not between 1 and 65535", c.APIPort)
+		}
+		port = strconv.Itoa(c.APIPort)
+	}
+
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("port of loggregator_addr %q is not between 1 and 65535", c.Addr)
+	}
+
+	if host == "" {
+		return "", fmt.Errorf("loggregator_addr %q has no host", c.Addr)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// tags returns the tags derived from the job properties merged with the
+// configured tags.
+func (c MetronConfig) tags() map[string]string {
+	tags := map[string]string{}
+	jobTags := map[string]string{
+		"deployment": c.JobDeployment,
+		"job":        c.JobName,
+		"index":      c.JobIndex,
+		"ip":         c.JobIP,
+		"origin":     c.JobOrigin,
+	}
+	for k, v := range jobTags {
+		if v != "" {
+			tags[k] = v
+		}
+	}
+
+	for k, v := range c.Tags {
+		tags[k] = v
+	}
+
+	return tags
+}
+
+// hasJob reports whether any job property is configured.
+func (c MetronConfig) hasJob() bool {
+	return c.JobDeployment != "" ||
+		c.JobName != "" ||
+		c.JobIndex != "" ||
+		c.JobIP != "" ||
+		c.JobOrigin != ""
+}
+
+// NewIngressClientFromConfig validates the config and creates an
+// IngressClient that emits to the configured agent address.
+// The envelopes are tagged with the configured job properties and tags. If
+// no job property is configured and the process runs on Kubernetes, the
+// envelopes are tagged with the pod instead (see WithDetectedKubernetesPod).
+// Any given options are applied after the config.
+func NewIngressClientFromConfig(config MetronConfig, opts ...IngressOption) (*IngressClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := NewIngressTLSConfig(config.CACertPath, config.CertPath, config.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := config.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	configOpts := []IngressOption{
+		WithAddr(addr),
+	}
+	if !config.hasJob() {
+		configOpts = append(configOpts, WithDetectedKubernetesPod())
+	}
+	for name, value := range config.tags() {
+		configOpts = append(configOpts, WithTag(name, value))
+	}
+
+	return NewIngressClient(tlsConfig, append(configOpts, opts...)...)
+}
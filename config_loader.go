@@ -0,0 +1,37 @@
+package loggregator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// LoadConfig reads a MetronConfig from the file at path. Files ending in
+// .json are parsed as JSON, any other file is parsed as YAML. References to
+// environment variables such as ${JOB_IP} are replaced with their values
+// before the file is parsed. The loaded config is validated.
+func LoadConfig(path string) (MetronConfig, error) {
+	var config MetronConfig
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	expanded := []byte(os.ExpandEnv(string(data)))
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(expanded, &config)
+	} else {
+		err = yaml.Unmarshal(expanded, &config)
+	}
+	if err != nil {
+		return config, err
+	}
+
+	return config, config.Validate()
+}
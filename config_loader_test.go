@@ -0,0 +1,94 @@
+package loggregator_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadConfig", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		os.Setenv("LOGGREGATOR_TEST_JOB_IP", "10.0.0.1")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("LOGGREGATOR_TEST_JOB_IP")
+		os.RemoveAll(dir)
+	})
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+		return path
+	}
+
+	It("loads a YAML config", func() {
+		path := writeFile("metron.yml", `
+loggregator_api_port: 3458
+loggregator_ca_path: /ca.crt
+loggregator_cert_path: /client.crt
+loggregator_key_path: /client.key
+job_name: some-job
+job_ip: ${LOGGREGATOR_TEST_JOB_IP}
+tags:
+  az: z1
+`)
+
+		config, err := loggregator.LoadConfig(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(loggregator.MetronConfig{
+			APIPort:    3458,
+			CACertPath: "/ca.crt",
+			CertPath:   "/client.crt",
+			KeyPath:    "/client.key",
+			JobName:    "some-job",
+			JobIP:      "10.0.0.1",
+			Tags:       map[string]string{"az": "z1"},
+		}))
+	})
+
+	It("loads a JSON config", func() {
+		path := writeFile("metron.json", `{
+			"loggregator_api_port": 3458,
+			"loggregator_ca_path": "/ca.crt",
+			"loggregator_cert_path": "/client.crt",
+			"loggregator_key_path": "/client.key",
+			"job_ip": "$LOGGREGATOR_TEST_JOB_IP"
+		}`)
+
+		config, err := loggregator.LoadConfig(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.APIPort).To(Equal(3458))
+		Expect(config.JobIP).To(Equal("10.0.0.1"))
+	})
+
+	It("validates the loaded config", func() {
+		path := writeFile("metron.yml", "loggregator_api_port: 3458\n")
+
+		_, err := loggregator.LoadConfig(path)
+		Expect(err).To(BeAssignableToTypeOf(&loggregator.ValidationError{}))
+	})
+
+	It("returns an error for malformed files", func() {
+		path := writeFile("metron.json", "{")
+
+		_, err := loggregator.LoadConfig(path)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when the file does not exist", func() {
+		_, err := loggregator.LoadConfig(filepath.Join(dir, "missing.yml"))
+		Expect(err).To(HaveOccurred())
+	})
+})
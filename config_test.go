@@ -0,0 +1,152 @@
+package loggregator_test
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MetronConfig", func() {
+	var config loggregator.MetronConfig
+
+	BeforeEach(func() {
+		config = loggregator.MetronConfig{
+			APIPort:    3458,
+			CACertPath: fixture("CA.crt"),
+			CertPath:   fixture("client.crt"),
+			KeyPath:    fixture("client.key"),
+			JobName:    "some-job",
+		}
+	})
+
+	It("accepts a valid config", func() {
+		Expect(config.Validate()).To(Succeed())
+	})
+
+	It("reports every problem at once", func() {
+		config.APIPort = 70000
+		config.CertPath = ""
+		config.KeyPath = ""
+
+		err := config.Validate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*loggregator.ValidationError).Errors).To(HaveLen(3))
+		Expect(err.Error()).To(ContainSubstring("loggregator_api_port 70000"))
+		Expect(err.Error()).To(ContainSubstring("loggregator_cert_path is required"))
+		Expect(err.Error()).To(ContainSubstring("loggregator_key_path is required"))
+	})
+
+	It("rejects a missing port", func() {
+		config.APIPort = 0
+
+		Expect(config.Validate()).To(MatchError(ContainSubstring("loggregator_api_port")))
+	})
+
+	Describe("Address", func() {
+		It("defaults to localhost and the API port", func() {
+			Expect(config.Address()).To(Equal("localhost:3458"))
+		})
+
+		It("uses a host name with a port", func() {
+			config.Addr = "agent.example.com:1234"
+
+			Expect(config.Address()).To(Equal("agent.example.com:1234"))
+		})
+
+		It("uses the API port for a host name without a port", func() {
+			config.Addr = "agent.example.com"
+
+			Expect(config.Address()).To(Equal("agent.example.com:3458"))
+		})
+
+		It("accepts IPv6 addresses with and without brackets", func() {
+			config.Addr = "::1"
+			Expect(config.Address()).To(Equal("[::1]:3458"))
+
+			config.Addr = "[::1]"
+			Expect(config.Address()).To(Equal("[::1]:3458"))
+
+			config.Addr = "[::1]:1234"
+			Expect(config.Address()).To(Equal("[::1]:1234"))
+		})
+
+		It("does not require the API port if the address has a port", func() {
+			config.Addr = "10.0.0.1:1234"
+			config.APIPort = 0
+
+			Expect(config.Validate()).To(Succeed())
+		})
+
+		It("rejects an invalid port in the address", func() {
+			config.Addr = "10.0.0.1:70000"
+
+			Expect(config.Validate()).To(MatchError(ContainSubstring("loggregator_addr")))
+		})
+	})
+
+	It("rejects tags that are too long", func() {
+		config.JobName = strings.Repeat("a", 257)
+		config.Tags = map[string]string{
+			strings.Repeat("b", 257): "value",
+		}
+
+		err := config.Validate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*loggregator.ValidationError).Errors).To(HaveLen(2))
+	})
+
+	It("validates the config when creating a client", func() {
+		config.CACertPath = ""
+
+		_, err := loggregator.NewIngressClientFromConfig(config)
+		Expect(err).To(MatchError(ContainSubstring("loggregator_ca_path is required")))
+	})
+
+	It("creates a client that tags envelopes with the job properties", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		_, port, err := net.SplitHostPort(server.addr)
+		Expect(err).NotTo(HaveOccurred())
+		config.APIPort, err = strconv.Atoi(port)
+		Expect(err).NotTo(HaveOccurred())
+
+		client, err := loggregator.NewIngressClientFromConfig(
+			config,
+			loggregator.WithBatchFlushInterval(10*time.Millisecond),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		client.EmitLog("message")
+
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env.Tags).To(HaveKeyWithValue("job", "some-job"))
+	})
+
+	It("exposes the tags derived from the config", func() {
+		config.JobDeployment = "some-deployment"
+		config.JobIndex = "some-index"
+
+		client, err := loggregator.NewIngressClientFromConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(client.Tags()).To(Equal(map[string]string{
+			"deployment": "some-deployment",
+			"job":        "some-job",
+			"index":      "some-index",
+		}))
+	})
+})
@@ -0,0 +1,292 @@
+package containeremitter
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unlimited is the smallest memory limit that cgroup v1 reports for a
+// cgroup without a limit. The exact value depends on the page size.
+const unlimited = 1 << 62
+
+type stats struct {
+	cpu         time.Duration
+	memoryBytes uint64
+	memoryQuota uint64
+
+	// hasIO is false if the I/O controller is not enabled for the cgroup.
+	hasIO    bool
+	ioReads  uint64
+	ioWrites uint64
+}
+
+// cgroupReader reads the stats of a cgroup of either version.
+type cgroupReader interface {
+	read() (stats, error)
+
+	// procs returns the path of the file that lists the processes of the
+	// cgroup.
+	procs() string
+}
+
+// newCgroupReader returns a reader for the cgroup at path below root. The
+// unified hierarchy (cgroup v2) is used if root has a cgroup.controllers
+// file, otherwise the controllers are expected in separate hierarchies
+// (cgroup v1).
+func newCgroupReader(root, path string) cgroupReader {
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return v2Reader{dir: filepath.Join(root, path)}
+	}
+
+	return v1Reader{root: root, path: path}
+}
+
+type v1Reader struct {
+	root string
+	path string
+}
+
+func (r v1Reader) read() (stats, error) {
+	var s stats
+
+	usage, err := readUint(filepath.Join(r.root, "cpuacct", r.path, "cpuacct.usage"))
+	if err != nil {
+		return s, err
+	}
+	s.cpu = time.Duration(usage)
+
+	memDir := filepath.Join(r.root, "memory", r.path)
+	s.memoryBytes, err = workingSet(
+		filepath.Join(memDir, "memory.usage_in_bytes"),
+		filepath.Join(memDir, "memory.stat"),
+		"total_inactive_file",
+	)
+	if err != nil {
+		return s, err
+	}
+
+	s.memoryQuota, err = readUint(filepath.Join(memDir, "memory.limit_in_bytes"))
+	if err != nil {
+		return s, err
+	}
+	if s.memoryQuota >= unlimited {
+		s.memoryQuota = 0
+	}
+
+	s.ioReads, s.ioWrites, s.hasIO = readV1IO(filepath.Join(r.root, "blkio", r.path, "blkio.throttle.io_serviced"))
+
+	return s, nil
+}
+
+func (r v1Reader) procs() string {
+	return filepath.Join(r.root, "memory", r.path, "cgroup.procs")
+}
+
+// readV1IO sums the operations of all devices in lines of the form
+// "<major>:<minor> Read|Write <ops>".
+func readV1IO(path string) (reads, writes uint64, ok bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+
+		n, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch fields[1] {
+		case "Read":
+			reads += n
+		case "Write":
+			writes += n
+		}
+	}
+
+	return reads, writes, true
+}
+
+type v2Reader struct {
+	dir string
+}
+
+func (r v2Reader) read() (stats, error) {
+	var s stats
+
+	cpu, err := readKeyedUint(filepath.Join(r.dir, "cpu.stat"), "usage_usec")
+	if err != nil {
+		return s, err
+	}
+	s.cpu = time.Duration(cpu) * time.Microsecond
+
+	s.memoryBytes, err = workingSet(
+		filepath.Join(r.dir, "memory.current"),
+		filepath.Join(r.dir, "memory.stat"),
+		"inactive_file",
+	)
+	if err != nil {
+		return s, err
+	}
+
+	max, err := ioutil.ReadFile(filepath.Join(r.dir, "memory.max"))
+	if err != nil {
+		return s, err
+	}
+	if v := strings.TrimSpace(string(max)); v != "max" {
+		s.memoryQuota, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return s, err
+		}
+	}
+
+	s.ioReads, s.ioWrites, s.hasIO = readV2IO(filepath.Join(r.dir, "io.stat"))
+
+	return s, nil
+}
+
+func (r v2Reader) procs() string {
+	return filepath.Join(r.dir, "cgroup.procs")
+}
+
+// readV2IO sums the operations of all devices in lines of the form
+// "<major>:<minor> rbytes=<n> wbytes=<n> rios=<n> wios=<n> ...".
+func readV2IO(path string) (reads, writes uint64, ok bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		for _, field := range strings.Fields(line) {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			n, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				continue
+			}
+
+			switch kv[0] {
+			case "rios":
+				reads += n
+			case "wios":
+				writes += n
+			}
+		}
+	}
+
+	return reads, writes, true
+}
+
+// workingSet returns the memory usage without the inactive page cache,
+// which the kernel reclaims before it kills a container. This is the value
+// the memory quota is effectively enforced against.
+func workingSet(usagePath, statPath, inactiveKey string) (uint64, error) {
+	usage, err := readUint(usagePath)
+	if err != nil {
+		return 0, err
+	}
+
+	inactive, err := readKeyedUint(statPath, inactiveKey)
+	if err != nil {
+		return 0, err
+	}
+
+	if inactive > usage {
+		return 0, nil
+	}
+
+	return usage - inactive, nil
+}
+
+func readUint(path string) (uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// readKeyedUint reads the value of key from a file of "key value" lines.
+func readKeyedUint(path, key string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			return strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, errors.New(key + " not found in " + filepath.Base(path))
+}
+
+// readNetwork sums the received and transmitted bytes of all interfaces
+// but loopback in the network namespace of the first process of the
+// cgroup, read from /proc/<pid>/net/dev.
+func readNetwork(procRoot, procsPath string) (rx, tx uint64, err error) {
+	procs, err := ioutil.ReadFile(procsPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pids := strings.Fields(string(procs))
+	if len(pids) == 0 {
+		return 0, 0, errors.New("cgroup has no processes")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(procRoot, pids[0], "net", "dev"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		i := strings.IndexByte(line, ':')
+		if i < 0 || strings.TrimSpace(line[:i]) == "lo" {
+			continue
+		}
+
+		fields := strings.Fields(line[i+1:])
+		if len(fields) < 9 {
+			continue
+		}
+
+		r, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		t, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		rx += r
+		tx += t
+	}
+
+	return rx, tx, nil
+}
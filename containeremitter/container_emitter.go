@@ -0,0 +1,192 @@
+// Package containeremitter provides an emitter that reads the CPU, memory
+// and disk usage of a container from its cgroup and emits them as the gauge
+// that the v2 API uses in place of v1 ContainerMetrics. Both cgroup v1 and
+// the unified hierarchy of cgroup v2 are supported. It is therefore only
+// useful on Linux.
+//
+// In addition to the ContainerMetrics, the gauge carries the disk
+// operations per second of the container and the bytes its network
+// interfaces received and transmitted:
+//
+//	disk_read_iops    reads per second, from the I/O controller
+//	disk_write_iops   writes per second, from the I/O controller
+//	network_rx_bytes  total bytes received, from /proc/<pid>/net/dev
+//	network_tx_bytes  total bytes transmitted, from /proc/<pid>/net/dev
+//
+// Each is omitted if its source is unavailable.
+package containeremitter
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/units"
+)
+
+// Sender is the interface of the client that can be used to emit gauge
+// metrics.
+type Sender interface {
+	EmitGauge(opts ...loggregator.EmitGaugeOption)
+}
+
+// DiskUsageFunc returns the bytes of disk used by a container and its disk
+// quota. cgroups do not account for disk usage, so it has to be provided
+// by the container runtime.
+type DiskUsageFunc func() (used, quota uint64, err error)
+
+// Emitter will emit a container metrics gauge via the sender on the given
+// interval. The default interval is 15 seconds.
+type Emitter struct {
+	interval   time.Duration
+	sender     Sender
+	cgroupRoot string
+	cgroupPath string
+	procRoot   string
+	sourceID   string
+	instanceID string
+	diskUsage  DiskUsageFunc
+	tags       map[string]string
+
+	reader     cgroupReader
+	last       stats
+	lastSample time.Time
+}
+
+// ContainerEmitterOption is the option that provides configuration for an
+// Emitter.
+type ContainerEmitterOption func(e *Emitter)
+
+// WithInterval returns a ContainerEmitterOption to configure the interval at
+// which the container emitter emits gauges.
+func WithInterval(d time.Duration) ContainerEmitterOption {
+	return func(e *Emitter) {
+		e.interval = d
+	}
+}
+
+// WithSourceInfo returns a ContainerEmitterOption to configure the source ID
+// and instance ID of the gauges, e.g. the app GUID and the instance index.
+func WithSourceInfo(sourceID, instanceID string) ContainerEmitterOption {
+	return func(e *Emitter) {
+		e.sourceID = sourceID
+		e.instanceID = instanceID
+	}
+}
+
+// WithDiskUsage returns a ContainerEmitterOption to configure how the disk
+// usage and quota of the container are read. Without it, no disk metrics
+// are emitted.
+func WithDiskUsage(f DiskUsageFunc) ContainerEmitterOption {
+	return func(e *Emitter) {
+		e.diskUsage = f
+	}
+}
+
+// WithTags returns a ContainerEmitterOption that adds the given tags to
+// every gauge emitted.
+func WithTags(tags map[string]string) ContainerEmitterOption {
+	return func(e *Emitter) {
+		for k, v := range tags {
+			e.tags[k] = v
+		}
+	}
+}
+
+// WithCgroupRoot returns a ContainerEmitterOption to configure where the
+// cgroup filesystem is mounted. It defaults to /sys/fs/cgroup.
+func WithCgroupRoot(path string) ContainerEmitterOption {
+	return func(e *Emitter) {
+		e.cgroupRoot = path
+	}
+}
+
+// WithProcRoot returns a ContainerEmitterOption to configure where the proc
+// filesystem is mounted. It defaults to /proc.
+func WithProcRoot(path string) ContainerEmitterOption {
+	return func(e *Emitter) {
+		e.procRoot = path
+	}
+}
+
+// New returns an Emitter for the container whose cgroup is at cgroupPath
+// relative to the cgroup root, e.g. "/garden/<container-handle>".
+func New(sender Sender, cgroupPath string, opts ...ContainerEmitterOption) *Emitter {
+	e := &Emitter{
+		sender:     sender,
+		interval:   15 * time.Second,
+		cgroupRoot: "/sys/fs/cgroup",
+		cgroupPath: cgroupPath,
+		procRoot:   "/proc",
+		tags:       make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	e.reader = newCgroupReader(e.cgroupRoot, e.cgroupPath)
+
+	return e
+}
+
+// Run starts the ticker with the configured interval and emits a gauge on
+// that interval. This method will block but the user may run in a go routine.
+// If the cgroup can not be read, nothing is emitted for that interval. CPU
+// usage and IOPS are emitted from the second interval on, as they are
+// computed from the difference of two samples.
+func (e *Emitter) Run() {
+	for range time.Tick(e.interval) {
+		e.emit(time.Now())
+	}
+}
+
+func (e *Emitter) emit(now time.Time) {
+	s, err := e.reader.read()
+	if err != nil {
+		return
+	}
+
+	opts := []loggregator.EmitGaugeOption{
+		loggregator.WithGaugeSourceInfo(e.sourceID, e.instanceID),
+		loggregator.WithGaugeValue("memory", float64(s.memoryBytes), units.Bytes),
+		loggregator.WithGaugeValue("memory_quota", float64(s.memoryQuota), units.Bytes),
+		loggregator.WithEnvelopeTags(e.tags),
+	}
+
+	if !e.lastSample.IsZero() && now.After(e.lastSample) {
+		elapsed := now.Sub(e.lastSample).Seconds()
+
+		if s.cpu >= e.last.cpu {
+			cpu := 100 * (s.cpu - e.last.cpu).Seconds() / elapsed
+			opts = append(opts, loggregator.WithGaugeValue("cpu", cpu, units.Percentage))
+		}
+
+		if s.hasIO && e.last.hasIO && s.ioReads >= e.last.ioReads && s.ioWrites >= e.last.ioWrites {
+			opts = append(opts,
+				loggregator.WithGaugeValue("disk_read_iops", float64(s.ioReads-e.last.ioReads)/elapsed, units.IOPS),
+				loggregator.WithGaugeValue("disk_write_iops", float64(s.ioWrites-e.last.ioWrites)/elapsed, units.IOPS),
+			)
+		}
+	}
+	e.last = s
+	e.lastSample = now
+
+	if rx, tx, err := readNetwork(e.procRoot, e.reader.procs()); err == nil {
+		opts = append(opts,
+			loggregator.WithGaugeValue("network_rx_bytes", float64(rx), units.Bytes),
+			loggregator.WithGaugeValue("network_tx_bytes", float64(tx), units.Bytes),
+		)
+	}
+
+	if e.diskUsage != nil {
+		used, quota, err := e.diskUsage()
+		if err == nil {
+			opts = append(opts,
+				loggregator.WithGaugeValue("disk", float64(used), units.Bytes),
+				loggregator.WithGaugeValue("disk_quota", float64(quota), units.Bytes),
+			)
+		}
+	}
+
+	e.sender.EmitGauge(opts...)
+}
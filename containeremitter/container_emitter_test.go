@@ -0,0 +1,236 @@
+package containeremitter_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/containeremitter"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContainerEmitter", func() {
+	var (
+		cgroupRoot string
+		spy        *spyV2Client
+	)
+
+	BeforeEach(func() {
+		var err error
+		cgroupRoot, err = ioutil.TempDir("", "cgroup")
+		Expect(err).ToNot(HaveOccurred())
+
+		spy = newSpyV2Client()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cgroupRoot)
+	})
+
+	run := func(opts ...containeremitter.ContainerEmitterOption) {
+		opts = append([]containeremitter.ContainerEmitterOption{
+			containeremitter.WithInterval(10 * time.Millisecond),
+			containeremitter.WithCgroupRoot(cgroupRoot),
+			containeremitter.WithProcRoot(filepath.Join(cgroupRoot, "proc")),
+			containeremitter.WithSourceInfo("some-app", "3"),
+		}, opts...)
+
+		go containeremitter.New(spy, "/garden/some-container", opts...).Run()
+	}
+
+	Context("with cgroup v1", func() {
+		BeforeEach(func() {
+			cpuDir := filepath.Join(cgroupRoot, "cpuacct", "garden", "some-container")
+			memDir := filepath.Join(cgroupRoot, "memory", "garden", "some-container")
+			Expect(os.MkdirAll(cpuDir, 0755)).To(Succeed())
+			Expect(os.MkdirAll(memDir, 0755)).To(Succeed())
+
+			writeFile(filepath.Join(cpuDir, "cpuacct.usage"), "5000000000\n")
+			writeFile(filepath.Join(memDir, "memory.usage_in_bytes"), "3072\n")
+			writeFile(filepath.Join(memDir, "memory.stat"), "cache 2048\ntotal_inactive_file 1024\n")
+			writeFile(filepath.Join(memDir, "memory.limit_in_bytes"), "9223372036854771712\n")
+		})
+
+		It("emits the memory usage without inactive page cache", func() {
+			run()
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.SourceId).To(Equal("some-app"))
+			Expect(env.InstanceId).To(Equal("3"))
+
+			metrics := env.GetGauge().GetMetrics()
+			Expect(metrics["memory"]).To(Equal(&loggregator_v2.GaugeValue{Value: 2048, Unit: "bytes"}))
+			Expect(metrics["memory_quota"]).To(Equal(&loggregator_v2.GaugeValue{Value: 0, Unit: "bytes"}))
+		})
+
+		It("emits the CPU usage from the second interval on", func() {
+			run()
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.GetGauge().GetMetrics()).ToNot(HaveKey("cpu"))
+
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.GetGauge().GetMetrics()).To(HaveKey("cpu"))
+			Expect(env.GetGauge().GetMetrics()["cpu"].Unit).To(Equal("percentage"))
+		})
+
+		It("emits the IOPS from the second interval on", func() {
+			blkioDir := filepath.Join(cgroupRoot, "blkio", "garden", "some-container")
+			Expect(os.MkdirAll(blkioDir, 0755)).To(Succeed())
+			writeFile(filepath.Join(blkioDir, "blkio.throttle.io_serviced"), "8:0 Read 10\n8:0 Write 5\n8:0 Total 15\nTotal 15\n")
+			run()
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.GetGauge().GetMetrics()).ToNot(HaveKey("disk_read_iops"))
+
+			writeFile(filepath.Join(blkioDir, "blkio.throttle.io_serviced"), "8:0 Read 1010\n8:0 Write 5\n8:0 Total 1015\nTotal 1015\n")
+
+			Eventually(func() float64 {
+				Eventually(spy.envelopes).Should(Receive(&env))
+				return env.GetGauge().GetMetrics()["disk_read_iops"].GetValue()
+			}).Should(BeNumerically(">", 0))
+			Expect(env.GetGauge().GetMetrics()["disk_read_iops"].Unit).To(Equal("iops"))
+			Expect(env.GetGauge().GetMetrics()["disk_write_iops"]).To(Equal(&loggregator_v2.GaugeValue{Value: 0, Unit: "iops"}))
+		})
+
+		It("omits the IOPS without the I/O controller", func() {
+			run()
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.GetGauge().GetMetrics()).ToNot(HaveKey("disk_read_iops"))
+			Expect(env.GetGauge().GetMetrics()).ToNot(HaveKey("network_rx_bytes"))
+		})
+	})
+
+	Context("with cgroup v2", func() {
+		BeforeEach(func() {
+			dir := filepath.Join(cgroupRoot, "garden", "some-container")
+			Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+
+			writeFile(filepath.Join(cgroupRoot, "cgroup.controllers"), "cpu memory io\n")
+			writeFile(filepath.Join(dir, "cpu.stat"), "usage_usec 5000000\nuser_usec 4000000\n")
+			writeFile(filepath.Join(dir, "memory.current"), "4096\n")
+			writeFile(filepath.Join(dir, "memory.stat"), "anon 1024\ninactive_file 512\n")
+			writeFile(filepath.Join(dir, "memory.max"), "8192\n")
+			writeFile(filepath.Join(dir, "io.stat"), "8:0 rbytes=4096 wbytes=0 rios=1 wios=0 dbytes=0 dios=0\n")
+			writeFile(filepath.Join(dir, "cgroup.procs"), "1234\n1235\n")
+
+			netDir := filepath.Join(cgroupRoot, "proc", "1234", "net")
+			Expect(os.MkdirAll(netDir, 0755)).To(Succeed())
+			writeFile(filepath.Join(netDir, "dev"), `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:     500       5    0    0    0     0          0         0      500       5    0    0    0     0       0          0
+  eth0:    1000      10    0    0    0     0          0         0     2000      20    0    0    0     0       0          0
+  eth1:     100       1    0    0    0     0          0         0      200       2    0    0    0     0       0          0
+`)
+		})
+
+		It("emits the memory usage and quota", func() {
+			run(containeremitter.WithTags(map[string]string{"origin": "rep"}))
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.GetTags()).To(HaveKeyWithValue("origin", "rep"))
+
+			metrics := env.GetGauge().GetMetrics()
+			Expect(metrics["memory"]).To(Equal(&loggregator_v2.GaugeValue{Value: 3584, Unit: "bytes"}))
+			Expect(metrics["memory_quota"]).To(Equal(&loggregator_v2.GaugeValue{Value: 8192, Unit: "bytes"}))
+		})
+
+		It("emits the network bytes of all interfaces but loopback", func() {
+			run()
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+
+			metrics := env.GetGauge().GetMetrics()
+			Expect(metrics["network_rx_bytes"]).To(Equal(&loggregator_v2.GaugeValue{Value: 1100, Unit: "bytes"}))
+			Expect(metrics["network_tx_bytes"]).To(Equal(&loggregator_v2.GaugeValue{Value: 2200, Unit: "bytes"}))
+		})
+
+		It("emits the IOPS from the second interval on", func() {
+			run()
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.GetGauge().GetMetrics()).ToNot(HaveKey("disk_write_iops"))
+
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.GetGauge().GetMetrics()["disk_read_iops"]).To(Equal(&loggregator_v2.GaugeValue{Value: 0, Unit: "iops"}))
+			Expect(env.GetGauge().GetMetrics()["disk_write_iops"]).To(Equal(&loggregator_v2.GaugeValue{Value: 0, Unit: "iops"}))
+		})
+
+		It("emits the disk usage if configured", func() {
+			run(containeremitter.WithDiskUsage(func() (uint64, uint64, error) {
+				return 100, 1000, nil
+			}))
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+
+			metrics := env.GetGauge().GetMetrics()
+			Expect(metrics["disk"]).To(Equal(&loggregator_v2.GaugeValue{Value: 100, Unit: "bytes"}))
+			Expect(metrics["disk_quota"]).To(Equal(&loggregator_v2.GaugeValue{Value: 1000, Unit: "bytes"}))
+		})
+
+		It("omits the disk usage if it can not be read", func() {
+			run(containeremitter.WithDiskUsage(func() (uint64, uint64, error) {
+				return 0, 0, errors.New("some-error")
+			}))
+
+			var env *loggregator_v2.Envelope
+			Eventually(spy.envelopes).Should(Receive(&env))
+			Expect(env.GetGauge().GetMetrics()).ToNot(HaveKey("disk"))
+			Expect(env.GetGauge().GetMetrics()).To(HaveKey("memory"))
+		})
+	})
+
+	It("does not emit when the cgroup is unavailable", func() {
+		run()
+
+		Consistently(spy.envelopes).Should(BeEmpty())
+	})
+})
+
+func writeFile(path, contents string) {
+	err := ioutil.WriteFile(path, []byte(contents), 0644)
+	Expect(err).ToNot(HaveOccurred())
+}
+
+type spyV2Client struct {
+	envelopes chan *loggregator_v2.Envelope
+}
+
+func newSpyV2Client() *spyV2Client {
+	return &spyV2Client{
+		envelopes: make(chan *loggregator_v2.Envelope, 100),
+	}
+}
+
+func (s *spyV2Client) EmitGauge(opts ...loggregator.EmitGaugeOption) {
+	env := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: make(map[string]*loggregator_v2.GaugeValue),
+			},
+		},
+		Tags: make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(env)
+	}
+
+	s.envelopes <- env
+}
@@ -0,0 +1,13 @@
+package containeremitter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestContaineremitter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Container Emitter Suite")
+}
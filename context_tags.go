@@ -0,0 +1,94 @@
+package loggregator
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+)
+
+type contextTagsKey struct{}
+
+// WithTagsContext returns a copy of ctx that carries the given tags in
+// addition to any tags already carried by ctx. Tags given here take
+// precedence over tags of the same name in ctx.
+func WithTagsContext(ctx context.Context, tags map[string]string) context.Context {
+	parent := TagsFromContext(ctx)
+
+	merged := make(map[string]string, len(parent)+len(tags))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, contextTagsKey{}, merged)
+}
+
+// TagsFromContext returns the tags carried by ctx. The returned map must not
+// be modified.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(contextTagsKey{}).(map[string]string)
+	return tags
+}
+
+// WithContextTags adds the tags carried by ctx to the envelope. It can be
+// passed to any of the emit methods.
+func WithContextTags(ctx context.Context) func(proto.Message) {
+	return WithEnvelopeTags(TagsFromContext(ctx))
+}
+
+// TraceExtractor returns the IDs of the trace and span carried by ctx. It
+// returns false if ctx does not carry a span. The oteltrace package provides
+// a TraceExtractor for OpenTelemetry spans.
+type TraceExtractor func(ctx context.Context) (traceID, spanID string, ok bool)
+
+// WithTraceExtractor configures the client to tag logs and timers that are
+// emitted with a context with the trace_id and span_id of the span carried
+// by the context.
+func WithTraceExtractor(e TraceExtractor) IngressOption {
+	return func(c *IngressClient) {
+		c.traceExtractor = e
+	}
+}
+
+// EmitLogFromContext sends a message to loggregator with the tags carried by
+// ctx and, if configured, the IDs of its trace. Tags set by the options take
+// precedence over the tags of ctx.
+func (c *IngressClient) EmitLogFromContext(ctx context.Context, message string, opts ...EmitLogOption) {
+	logOpts := make([]EmitLogOption, 0, len(opts)+1)
+	logOpts = append(logOpts, c.contextTags(ctx))
+	logOpts = append(logOpts, opts...)
+
+	c.EmitLog(message, logOpts...)
+}
+
+// EmitTimerFromContext sends a timer envelope with the tags carried by ctx
+// and, if configured, the IDs of its trace. Tags set by the options take
+// precedence over the tags of ctx.
+func (c *IngressClient) EmitTimerFromContext(ctx context.Context, name string, start, stop time.Time, opts ...EmitTimerOption) {
+	timerOpts := make([]EmitTimerOption, 0, len(opts)+1)
+	timerOpts = append(timerOpts, c.contextTags(ctx))
+	timerOpts = append(timerOpts, opts...)
+
+	c.EmitTimer(name, start, stop, timerOpts...)
+}
+
+func (c *IngressClient) contextTags(ctx context.Context) func(proto.Message) {
+	tags := TagsFromContext(ctx)
+	if c.traceExtractor == nil {
+		return WithEnvelopeTags(tags)
+	}
+
+	traceID, spanID, ok := c.traceExtractor(ctx)
+	if !ok {
+		return WithEnvelopeTags(tags)
+	}
+
+	return func(m proto.Message) {
+		WithEnvelopeTags(tags)(m)
+		WithEnvelopeTag("trace_id", traceID)(m)
+		WithEnvelopeTag("span_id", spanID)(m)
+	}
+}
@@ -0,0 +1,41 @@
+package loggregator_test
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("context tags", func() {
+	It("merges tags with the tags of the parent context", func() {
+		ctx := loggregator.WithTagsContext(context.Background(), map[string]string{
+			"request_id": "some-request",
+			"user":       "some-user",
+		})
+		ctx = loggregator.WithTagsContext(ctx, map[string]string{"user": "other-user"})
+
+		Expect(loggregator.TagsFromContext(ctx)).To(Equal(map[string]string{
+			"request_id": "some-request",
+			"user":       "other-user",
+		}))
+	})
+
+	It("returns no tags for a context without tags", func() {
+		Expect(loggregator.TagsFromContext(context.Background())).To(BeEmpty())
+	})
+
+	It("adds the tags of the context to an envelope", func() {
+		ctx := loggregator.WithTagsContext(context.Background(), map[string]string{
+			"request_id": "some-request",
+		})
+		e := &loggregator_v2.Envelope{Tags: map[string]string{}}
+
+		loggregator.WithContextTags(ctx)(e)
+
+		Expect(e.Tags).To(Equal(map[string]string{"request_id": "some-request"}))
+	})
+})
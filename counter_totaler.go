@@ -0,0 +1,54 @@
+package loggregator
+
+import "sync"
+
+// CounterEmitter is the interface of the client that a CounterTotaler emits
+// counters with.
+type CounterEmitter interface {
+	EmitCounter(name string, opts ...EmitCounterOption)
+}
+
+// CounterTotaler keeps the running total of every counter name and emits
+// counters that carry both the delta and the total. Downstream consumers can
+// then compute rates from totals, which stay accurate when envelopes are
+// lost. Totals only live in memory and start again at zero when the process
+// restarts. It is safe for concurrent use.
+type CounterTotaler struct {
+	emitter CounterEmitter
+
+	mu     sync.Mutex
+	totals map[string]uint64
+}
+
+// NewCounterTotaler returns a CounterTotaler that emits via the given
+// emitter.
+func NewCounterTotaler(e CounterEmitter) *CounterTotaler {
+	return &CounterTotaler{
+		emitter: e,
+		totals:  make(map[string]uint64),
+	}
+}
+
+// Add adds the delta to the total of the named counter and emits a counter
+// with the delta and the new total. Any WithDelta or WithTotal option in
+// opts is overridden. Counters of the same name are emitted in the order of
+// their totals.
+func (t *CounterTotaler) Add(name string, delta uint64, opts ...EmitCounterOption) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.totals[name] += delta
+
+	counterOpts := make([]EmitCounterOption, 0, len(opts)+2)
+	counterOpts = append(counterOpts, opts...)
+	counterOpts = append(counterOpts, WithDelta(delta), WithTotal(t.totals[name]))
+	t.emitter.EmitCounter(name, counterOpts...)
+}
+
+// Total returns the current total of the named counter.
+func (t *CounterTotaler) Total(name string) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.totals[name]
+}
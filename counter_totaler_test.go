@@ -0,0 +1,71 @@
+package loggregator_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CounterTotaler", func() {
+	var (
+		emitter *spyCounterEmitter
+		totaler *loggregator.CounterTotaler
+	)
+
+	BeforeEach(func() {
+		emitter = &spyCounterEmitter{}
+		totaler = loggregator.NewCounterTotaler(emitter)
+	})
+
+	It("emits counters with the delta and the running total", func() {
+		totaler.Add("some-counter", 2)
+		totaler.Add("some-counter", 3)
+		totaler.Add("other-counter", 1)
+
+		Expect(emitter.counters).To(HaveLen(3))
+		Expect(emitter.counters[0].Delta).To(Equal(uint64(2)))
+		Expect(emitter.counters[0].Total).To(Equal(uint64(2)))
+		Expect(emitter.counters[1].Delta).To(Equal(uint64(3)))
+		Expect(emitter.counters[1].Total).To(Equal(uint64(5)))
+		Expect(emitter.counters[2].Name).To(Equal("other-counter"))
+		Expect(emitter.counters[2].Total).To(Equal(uint64(1)))
+
+		Expect(totaler.Total("some-counter")).To(Equal(uint64(5)))
+		Expect(totaler.Total("unknown")).To(BeZero())
+	})
+
+	It("overrides the delta and total given as options", func() {
+		totaler.Add(
+			"some-counter",
+			2,
+			loggregator.WithDelta(10),
+			loggregator.WithTotal(100),
+			loggregator.WithCounterSourceInfo("source-id", "0"),
+		)
+
+		Expect(emitter.envelopes[0].SourceId).To(Equal("source-id"))
+		Expect(emitter.counters[0].Delta).To(Equal(uint64(2)))
+		Expect(emitter.counters[0].Total).To(Equal(uint64(2)))
+	})
+})
+
+type spyCounterEmitter struct {
+	envelopes []*loggregator_v2.Envelope
+	counters  []*loggregator_v2.Counter
+}
+
+func (s *spyCounterEmitter) EmitCounter(name string, opts ...loggregator.EmitCounterOption) {
+	e := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: name},
+		},
+	}
+	for _, o := range opts {
+		o(e)
+	}
+
+	s.envelopes = append(s.envelopes, e)
+	s.counters = append(s.counters, e.GetCounter())
+}
@@ -0,0 +1,64 @@
+package loggregator
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// CrashCounterName is the name of the counter ReportPanic increments.
+const CrashCounterName = "crashes"
+
+// ReportPanic reports a panic to Loggregator, so that crashes are visible
+// even when the process' output is not captured. It must be deferred
+// directly:
+//
+//	defer client.ReportPanic(loggregator.WithSourceInfo("worker", "APP", "0"))
+//
+// If the go routine panics, it emits an error log with the panic value and
+// the stack trace and increments the crashes counter of the log's source.
+// It then closes the client, waiting up to the drain timeout for the
+// envelopes to be sent, and panics again with the same value. Without a
+// panic it does nothing.
+func (c *IngressClient) ReportPanic(opts ...EmitLogOption) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	c.reportPanic(r, debug.Stack(), opts)
+	panic(r)
+}
+
+// WrapPanics returns a function that runs f and reports its panics like
+// ReportPanic, e.g. for go routines:
+//
+//	go client.WrapPanics(worker, loggregator.WithSourceInfo("worker", "APP", "0"))()
+func (c *IngressClient) WrapPanics(f func(), opts ...EmitLogOption) func() {
+	return func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			c.reportPanic(r, debug.Stack(), opts)
+			panic(r)
+		}()
+
+		f()
+	}
+}
+
+func (c *IngressClient) reportPanic(r interface{}, stack []byte, opts []EmitLogOption) {
+	e := newLogEnvelope(c.now(), c.defaultTags(), []byte(fmt.Sprintf("panic: %v\n\n%s", r, stack)), opts)
+	e.GetLog().Type = loggregator_v2.Log_ERR
+	c.enqueueLog(e)
+
+	c.EmitCounter(CrashCounterName, WithCounterSourceInfo(e.GetSourceId(), e.GetInstanceId()))
+
+	if err := c.closeWithTimeout(c.drainTimeout); err != nil && err != ErrClosed {
+		c.logger.Printf("Error while flushing crash report: %s", err)
+	}
+}
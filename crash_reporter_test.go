@@ -0,0 +1,88 @@
+package loggregator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Crash reporting", func() {
+	var (
+		server *testIngressServer
+		client *loggregator.IngressClient
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+
+		client, _, _ = buildIngressClient(server.addr, time.Hour, false)
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("reports a panic, flushes and panics again", func() {
+		recovered := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				recovered <- recover()
+			}()
+			defer client.ReportPanic(loggregator.WithSourceInfo("worker", "APP", "0"))
+
+			panic("boom")
+		}()
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Eventually(recovered).Should(Receive(Equal("boom")))
+
+		log := envelopes[0]
+		Expect(log.GetSourceId()).To(Equal("worker"))
+		Expect(log.GetLog().GetType()).To(Equal(loggregator_v2.Log_ERR))
+		Expect(string(log.GetLog().GetPayload())).To(HavePrefix("panic: boom\n\n"))
+		Expect(string(log.GetLog().GetPayload())).To(ContainSubstring("crash_reporter_test.go"))
+
+		counter := envelopes[1]
+		Expect(counter.GetSourceId()).To(Equal("worker"))
+		Expect(counter.GetCounter().GetName()).To(Equal(loggregator.CrashCounterName))
+		Expect(counter.GetCounter().GetDelta()).To(Equal(uint64(1)))
+
+		Expect(client.EmitEnvelope(&loggregator_v2.Envelope{})).To(MatchError(loggregator.ErrClosed))
+	})
+
+	It("reports the panics of wrapped functions", func() {
+		recovered := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				recovered <- recover()
+			}()
+
+			client.WrapPanics(func() {
+				panic("boom")
+			})()
+		}()
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Eventually(recovered).Should(Receive(Equal("boom")))
+		Expect(string(envelopes[0].GetLog().GetPayload())).To(HavePrefix("panic: boom"))
+	})
+
+	It("does nothing without a panic", func() {
+		func() {
+			defer client.ReportPanic()
+		}()
+
+		Expect(client.EmitEnvelope(&loggregator_v2.Envelope{SourceId: "after"})).To(Succeed())
+	})
+})
@@ -0,0 +1,74 @@
+package loggregator
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// DeadLetterHandler receives envelopes that were permanently rejected
+// together with the reason. It may be called from several go routines at
+// once.
+type DeadLetterHandler func(e *loggregator_v2.Envelope, err error)
+
+// WithDeadLetterHandler configures the client to hand every envelope that
+// is dropped to h instead of only logging the error. This covers envelopes
+// that are rejected when emitted (e.g. ErrEnvelopeTooLarge or ErrQueueFull)
+// as well as envelopes of batches that could not be sent.
+func WithDeadLetterHandler(h DeadLetterHandler) IngressOption {
+	return func(c *IngressClient) {
+		c.deadLetter = h
+	}
+}
+
+// NewDeadLetterWriter returns a DeadLetterHandler that writes every envelope
+// and its error as a single line of JSON to w.
+func NewDeadLetterWriter(w io.Writer) DeadLetterHandler {
+	var (
+		mu sync.Mutex
+		m  jsonpb.Marshaler
+	)
+
+	return func(e *loggregator_v2.Envelope, err error) {
+		env, merr := m.MarshalToString(e)
+		if merr != nil {
+			return
+		}
+
+		line, merr := json.Marshal(struct {
+			Error    string          `json:"error"`
+			Envelope json.RawMessage `json:"envelope"`
+		}{
+			Error:    err.Error(),
+			Envelope: json.RawMessage(env),
+		})
+		if merr != nil {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Write(append(line, '\n'))
+	}
+}
+
+// DeadLetterCounter counts dead letters. Its Handle method may be passed to
+// WithDeadLetterHandler.
+type DeadLetterCounter struct {
+	count uint64
+}
+
+// Handle counts the envelope.
+func (c *DeadLetterCounter) Handle(e *loggregator_v2.Envelope, err error) {
+	atomic.AddUint64(&c.count, 1)
+}
+
+// Count returns the number of dead letters handled so far.
+func (c *DeadLetterCounter) Count() uint64 {
+	return atomic.LoadUint64(&c.count)
+}
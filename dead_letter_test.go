@@ -0,0 +1,45 @@
+package loggregator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeadLetterHandler", func() {
+	It("writes dead letters as JSON lines", func() {
+		buf := &bytes.Buffer{}
+		h := loggregator.NewDeadLetterWriter(buf)
+
+		h(&loggregator_v2.Envelope{SourceId: "some-source"}, errors.New("some-error"))
+		h(&loggregator_v2.Envelope{SourceId: "other-source"}, errors.New("other-error"))
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(2))
+
+		var line struct {
+			Error    string `json:"error"`
+			Envelope struct {
+				SourceID string `json:"sourceId"`
+			} `json:"envelope"`
+		}
+		Expect(json.Unmarshal(lines[0], &line)).To(Succeed())
+		Expect(line.Error).To(Equal("some-error"))
+		Expect(line.Envelope.SourceID).To(Equal("some-source"))
+	})
+
+	It("counts dead letters", func() {
+		c := &loggregator.DeadLetterCounter{}
+
+		c.Handle(&loggregator_v2.Envelope{}, errors.New("some-error"))
+		c.Handle(&loggregator_v2.Envelope{}, errors.New("some-error"))
+
+		Expect(c.Count()).To(Equal(uint64(2)))
+	})
+})
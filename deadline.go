@@ -0,0 +1,166 @@
+package loggregator
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+)
+
+// deadlineTag is the tag that holds the time in nanoseconds since the Unix
+// epoch after which an envelope is no longer useful to its consumers.
+const deadlineTag = "loggregator_deadline"
+
+// WithDeadline tags the envelope with the time after which it is no longer
+// useful. The client drops the envelope if its batch is sent after the
+// deadline and consumers may count late envelopes with a DeadlineTracker.
+func WithDeadline(t time.Time) func(proto.Message) {
+	return WithEnvelopeTag(deadlineTag, strconv.FormatInt(t.UnixNano(), 10))
+}
+
+// WithEnvelopeDeadline configures the client to tag every envelope that has
+// no deadline with one that is the given duration after its timestamp. See
+// WithDeadline.
+func WithEnvelopeDeadline(d time.Duration) IngressOption {
+	return func(c *IngressClient) {
+		c.deadline = d
+	}
+}
+
+// tagDeadline adds the client's default deadline to the envelope. The
+// deadline of envelopes without a timestamp is relative to the client's
+// clock.
+func (c *IngressClient) tagDeadline(e *loggregator_v2.Envelope) {
+	if c.deadline <= 0 {
+		return
+	}
+	if _, ok := e.GetTags()[deadlineTag]; ok {
+		return
+	}
+
+	ts := e.GetTimestamp()
+	if ts == 0 {
+		ts = c.now().UnixNano()
+	}
+
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
+	}
+	e.Tags[deadlineTag] = strconv.FormatInt(ts+int64(c.deadline), 10)
+}
+
+// deadline returns the deadline of the envelope.
+func deadline(e *loggregator_v2.Envelope) (time.Time, bool) {
+	ns, err := strconv.ParseInt(e.GetTags()[deadlineTag], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, ns), true
+}
+
+// DeadlineTracker counts the envelopes with a deadline that consumers
+// receive after their deadline passed. Envelopes without a deadline are
+// ignored. It is safe for concurrent use.
+type DeadlineTracker struct {
+	clock Clock
+
+	mu    sync.Mutex
+	stats DeadlineStats
+}
+
+// DeadlineTrackerOption is the option type passed into NewDeadlineTracker.
+type DeadlineTrackerOption func(*DeadlineTracker)
+
+// WithTrackerClock configures the tracker to compare deadlines with the
+// time of the given clock instead of the host clock. Passing the clock of
+// the emitting client, see WithClock, makes both agree on when a deadline
+// passed.
+func WithTrackerClock(clock Clock) DeadlineTrackerOption {
+	return func(t *DeadlineTracker) {
+		t.clock = clock
+	}
+}
+
+// NewDeadlineTracker returns an empty DeadlineTracker.
+func NewDeadlineTracker(opts ...DeadlineTrackerOption) *DeadlineTracker {
+	t := &DeadlineTracker{}
+	for _, o := range opts {
+		o(t)
+	}
+
+	return t
+}
+
+func (t *DeadlineTracker) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+
+	return t.clock.Now()
+}
+
+// Observe records whether the envelope is received after its deadline and
+// reports whether it is.
+func (t *DeadlineTracker) Observe(e *loggregator_v2.Envelope) bool {
+	d, ok := deadline(e)
+	if !ok {
+		return false
+	}
+
+	overrun := t.now().Sub(d)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stats.Received++
+	if overrun <= 0 {
+		return false
+	}
+
+	t.stats.Expired++
+	if t.stats.ExpiredByPriority == nil {
+		t.stats.ExpiredByPriority = make(map[Priority]uint64)
+	}
+	t.stats.ExpiredByPriority[PriorityOf(e)]++
+	if overrun > t.stats.MaxOverrun {
+		t.stats.MaxOverrun = overrun
+	}
+
+	return true
+}
+
+// Stats returns the envelopes recorded so far.
+func (t *DeadlineTracker) Stats() DeadlineStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.stats
+	if t.stats.ExpiredByPriority != nil {
+		stats.ExpiredByPriority = make(map[Priority]uint64, len(t.stats.ExpiredByPriority))
+		for p, n := range t.stats.ExpiredByPriority {
+			stats.ExpiredByPriority[p] = n
+		}
+	}
+
+	return stats
+}
+
+// DeadlineStats summarizes the envelopes recorded by a DeadlineTracker.
+type DeadlineStats struct {
+	// Received is the number of envelopes with a deadline.
+	Received uint64
+
+	// Expired is the number of envelopes received after their deadline.
+	Expired uint64
+
+	// ExpiredByPriority breaks down the expired envelopes by their
+	// priority, see WithPriority.
+	ExpiredByPriority map[Priority]uint64
+
+	// MaxOverrun is the longest time an envelope was received after its
+	// deadline.
+	MaxOverrun time.Duration
+}
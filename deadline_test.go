@@ -0,0 +1,136 @@
+package loggregator_test
+
+import (
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deadlines", func() {
+	var server *testIngressServer
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("tags envelopes with their deadline", func() {
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false)
+		d := time.Now().Add(time.Minute)
+
+		client.EmitLog("message", loggregator.WithDeadline(d))
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("loggregator_deadline", strconv.FormatInt(d.UnixNano(), 10)))
+	})
+
+	It("tags envelopes without a deadline with the default deadline", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithEnvelopeDeadline(time.Minute),
+		)
+		ts := time.Now()
+		d := ts.Add(time.Hour)
+
+		Expect(client.EmitEnvelope(&loggregator_v2.Envelope{Timestamp: ts.UnixNano()})).To(Succeed())
+		client.EmitLog("message", loggregator.WithDeadline(d))
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("loggregator_deadline", strconv.FormatInt(ts.Add(time.Minute).UnixNano(), 10)))
+		Expect(envelopes[1].Tags).To(HaveKeyWithValue("loggregator_deadline", strconv.FormatInt(d.UnixNano(), 10)))
+	})
+
+	It("drops envelopes whose deadline passed", func() {
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false)
+
+		client.EmitLog("late", loggregator.WithDeadline(time.Now().Add(-time.Second)))
+		client.EmitLog("on-time", loggregator.WithDeadline(time.Now().Add(time.Minute)))
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes).To(HaveLen(1))
+		Expect(string(envelopes[0].GetLog().GetPayload())).To(Equal("on-time"))
+		Expect(client.Expired()).To(Equal(uint64(1)))
+	})
+})
+
+var _ = Describe("DeadlineTracker", func() {
+	withDeadline := func(d time.Time) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			Tags: map[string]string{"loggregator_deadline": strconv.FormatInt(d.UnixNano(), 10)},
+		}
+	}
+
+	It("counts the envelopes received after their deadline", func() {
+		t := loggregator.NewDeadlineTracker()
+
+		Expect(t.Observe(withDeadline(time.Now().Add(time.Minute)))).To(BeFalse())
+		Expect(t.Observe(withDeadline(time.Now().Add(-time.Second)))).To(BeTrue())
+		Expect(t.Observe(withDeadline(time.Now().Add(-time.Minute)))).To(BeTrue())
+		Expect(t.Observe(&loggregator_v2.Envelope{})).To(BeFalse())
+
+		stats := t.Stats()
+		Expect(stats.Received).To(Equal(uint64(3)))
+		Expect(stats.Expired).To(Equal(uint64(2)))
+		Expect(stats.MaxOverrun).To(BeNumerically(">=", time.Minute))
+		Expect(stats.MaxOverrun).To(BeNumerically("<", 2*time.Minute))
+	})
+
+	It("compares deadlines with the given clock", func() {
+		now := time.Unix(1000, 0)
+		t := loggregator.NewDeadlineTracker(loggregator.WithTrackerClock(fixedClock(now)))
+
+		Expect(t.Observe(withDeadline(now.Add(time.Second)))).To(BeFalse())
+		Expect(t.Observe(withDeadline(now.Add(-time.Second)))).To(BeTrue())
+
+		Expect(t.Stats().MaxOverrun).To(Equal(time.Second))
+	})
+
+	It("breaks down the expired envelopes by priority", func() {
+		t := loggregator.NewDeadlineTracker()
+		late := func(p loggregator.Priority) *loggregator_v2.Envelope {
+			e := withDeadline(time.Now().Add(-time.Second))
+			loggregator.WithPriority(p)(e)
+			return e
+		}
+
+		t.Observe(late(loggregator.PriorityHigh))
+		t.Observe(late(loggregator.PriorityHigh))
+		t.Observe(withDeadline(time.Now().Add(-time.Second)))
+
+		Expect(t.Stats().ExpiredByPriority).To(Equal(map[loggregator.Priority]uint64{
+			loggregator.PriorityHigh:   2,
+			loggregator.PriorityNormal: 1,
+		}))
+	})
+})
+
+var _ = Describe("Priority", func() {
+	It("tags envelopes with their priority", func() {
+		e := &loggregator_v2.Envelope{Tags: map[string]string{}}
+		Expect(loggregator.PriorityOf(e)).To(Equal(loggregator.PriorityNormal))
+		Expect(loggregator.IsHighPriority(e)).To(BeFalse())
+
+		loggregator.WithPriority(loggregator.PriorityHigh)(e)
+
+		Expect(e.Tags).To(HaveKeyWithValue("loggregator_priority", "high"))
+		Expect(loggregator.PriorityOf(e)).To(Equal(loggregator.PriorityHigh))
+		Expect(loggregator.IsHighPriority(e)).To(BeTrue())
+	})
+})
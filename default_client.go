@@ -0,0 +1,52 @@
+package loggregator
+
+import (
+	"crypto/tls"
+	"sync"
+)
+
+var defaultClient struct {
+	mu     sync.RWMutex
+	client *IngressClient
+}
+
+// Configure creates the process-wide IngressClient returned by Default. Once
+// a client was created, later calls, including concurrent ones, return nil
+// without applying their configuration. If creating the client fails, its
+// error is returned and the next call tries again.
+func Configure(tlsConfig *tls.Config, opts ...IngressOption) error {
+	defaultClient.mu.Lock()
+	defer defaultClient.mu.Unlock()
+
+	if defaultClient.client != nil {
+		return nil
+	}
+
+	client, err := NewIngressClient(tlsConfig, opts...)
+	if err != nil {
+		return err
+	}
+	defaultClient.client = client
+
+	return nil
+}
+
+// Default returns the process-wide client created by Configure. It lets
+// libraries emit telemetry without being handed a client. Until Configure
+// has succeeded, the returned Emitter discards everything emitted to it.
+func Default() Emitter {
+	defaultClient.mu.RLock()
+	defer defaultClient.mu.RUnlock()
+
+	if defaultClient.client == nil {
+		return discardEmitter{}
+	}
+
+	return defaultClient.client
+}
+
+type discardEmitter struct{}
+
+func (discardEmitter) EmitLog(string, ...EmitLogOption)         {}
+func (discardEmitter) EmitGauge(...EmitGaugeOption)             {}
+func (discardEmitter) EmitCounter(string, ...EmitCounterOption) {}
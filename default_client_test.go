@@ -0,0 +1,70 @@
+package loggregator_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Default", func() {
+	It("discards envelopes until configured successfully and then emits to the configured client", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		Expect(func() {
+			loggregator.Default().EmitLog("discarded")
+		}).NotTo(Panic())
+
+		tlsConfig, err := loggregator.NewIngressTLSConfig(
+			fixture("CA.crt"),
+			fixture("client.crt"),
+			fixture("client.key"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = loggregator.Configure(
+			tlsConfig,
+			loggregator.WithAddr(server.addr),
+			loggregator.WithProxy("ftp://proxy.example.com"),
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(loggregator.Default()).NotTo(BeAssignableToTypeOf(&loggregator.IngressClient{}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+
+				err := loggregator.Configure(
+					tlsConfig,
+					loggregator.WithAddr(server.addr),
+					loggregator.WithBatchFlushInterval(10*time.Millisecond),
+				)
+				Expect(err).NotTo(HaveOccurred())
+			}()
+		}
+		wg.Wait()
+
+		client := loggregator.Default()
+		Expect(client).To(BeAssignableToTypeOf(&loggregator.IngressClient{}))
+		Expect(loggregator.Default()).To(BeIdenticalTo(client))
+
+		client.EmitLog("message")
+
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env.GetLog().Payload).To(Equal([]byte("message")))
+	})
+})
@@ -0,0 +1,50 @@
+package loggregator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// ackTimeout is the maximum time to wait for the agent to acknowledge a
+// batch when at-least-once delivery is enabled.
+const ackTimeout = 10 * time.Second
+
+// deliveryIDTag is the tag used to identify an envelope across retries.
+const deliveryIDTag = "delivery_id"
+
+// deliveryIDGenerator creates identifiers that are unique across clients by
+// combining a random prefix with a sequence number. It is only used from the
+// sender go routine and is therefore not safe for concurrent use.
+type deliveryIDGenerator struct {
+	prefix string
+	seq    uint64
+}
+
+func newDeliveryIDGenerator() (*deliveryIDGenerator, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return &deliveryIDGenerator{
+		prefix: hex.EncodeToString(b) + "-",
+	}, nil
+}
+
+// tag sets the delivery ID on the envelope unless it already has one.
+func (g *deliveryIDGenerator) tag(e *loggregator_v2.Envelope) {
+	if _, ok := e.Tags[deliveryIDTag]; ok {
+		return
+	}
+
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
+	}
+
+	g.seq++
+	e.Tags[deliveryIDTag] = g.prefix + strconv.FormatUint(g.seq, 10)
+}
@@ -0,0 +1,218 @@
+package loggregator
+
+import (
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// controlMsg is a request to the dispatcher from outside of its go routine.
+// The control channel holds a single message and further messages are
+// dropped while it is full, so messages must be idempotent.
+type controlMsg int
+
+const (
+	// reconfigure applies the batch settings changed with UpdateConfig to
+	// the pending batch and the flush timer.
+	reconfigure controlMsg = iota
+)
+
+// dispatcher is the state of the single sender go routine. It reads the
+// control channel and both queues, builds batches from batched and
+// unbatched envelopes alike and flushes them when they are full, when an
+// unbatched envelope is added, when the flush interval passes or when the
+// queues are closed.
+type dispatcher struct {
+	c     *IngressClient
+	timer *time.Timer
+	batch []*loggregator_v2.Envelope
+
+	// retained is the number of envelopes at the front of the batch that
+	// failed to send and are being retried to preserve ordering.
+	retained int
+}
+
+func (c *IngressClient) startDispatcher() {
+	_, interval := c.batchConfig()
+	d := &dispatcher{
+		c:     c,
+		timer: time.NewTimer(interval),
+	}
+
+	// A nil channel is never ready, so a channel is set to nil once it is
+	// closed.
+	envelopes, priority := c.envelopes, c.priorityEnvelopes
+	for envelopes != nil || priority != nil {
+		// Priority envelopes are always read first so that they are not
+		// stuck behind bulk envelopes.
+		select {
+		case q, ok := <-priority:
+			if !ok {
+				priority = nil
+				continue
+			}
+			d.add(q)
+			continue
+		default:
+		}
+
+		select {
+		case msg := <-c.control:
+			d.handle(msg)
+		case q, ok := <-priority:
+			if !ok {
+				priority = nil
+				continue
+			}
+			d.add(q)
+		case q, ok := <-envelopes:
+			if !ok {
+				envelopes = nil
+				continue
+			}
+			d.add(q)
+		case <-d.timer.C:
+			if len(d.batch) > 0 {
+				d.flush()
+			}
+			_, interval := c.batchConfig()
+			d.timer.Reset(interval)
+		}
+	}
+
+	d.drain()
+}
+
+// add tags the envelope for sending and adds it to the batch.
+func (d *dispatcher) add(q queued) {
+	c := d.c
+	env := q.e
+
+	route := c.route(q)
+	if c.atLeastOnce {
+		c.deliveryIDs.tag(env)
+	}
+	if c.sequencer != nil {
+		c.sequencer.tag(env)
+	}
+	c.tagDeadline(env)
+	if c.emitTimestamps {
+		if env.Tags == nil {
+			env.Tags = make(map[string]string)
+		}
+		env.Tags[emittedAtTag] = strconv.FormatInt(c.now().UnixNano(), 10)
+	}
+	d.batch = append(d.batch, env)
+
+	maxSize, _ := c.batchConfig()
+	if len(d.batch)-d.retained >= int(maxSize) || route == Unbatched {
+		d.flush()
+		d.restartTimer()
+	}
+}
+
+func (d *dispatcher) handle(msg controlMsg) {
+	switch msg {
+	case reconfigure:
+		maxSize, _ := d.c.batchConfig()
+		if len(d.batch) > 0 && len(d.batch)-d.retained >= int(maxSize) {
+			d.flush()
+		}
+		d.restartTimer()
+	}
+}
+
+// flush dispatches the batch and keeps the envelopes that have to be
+// retried.
+func (d *dispatcher) flush() {
+	d.batch = d.c.dispatch(d.batch)
+	d.retained = len(d.batch)
+}
+
+// restartTimer restarts the flush timer with the current flush interval.
+func (d *dispatcher) restartTimer() {
+	if !d.timer.Stop() {
+		<-d.timer.C
+	}
+	_, interval := d.c.batchConfig()
+	d.timer.Reset(interval)
+}
+
+// drain flushes what is left once the queues are closed, closes the stream
+// and reports the result to CloseSend. Exactly one result is reported and
+// closeErrors is buffered, so the dispatcher exits even if the caller of
+// CloseSend has given up waiting, e.g. after a drain timeout.
+func (d *dispatcher) drain() {
+	c := d.c
+	batch := d.batch
+
+	var err error
+	if c.inFlight != nil {
+		if len(batch) > 0 {
+			c.inFlight <- batch
+		}
+		close(c.inFlight)
+
+		// The writer reports the result of its last flush, which only
+		// belongs to this close if it flushed the remaining batch.
+		if werr := <-c.inFlightErrs; len(batch) > 0 {
+			err = werr
+		}
+	} else if len(batch) > 0 {
+		var retry []*loggregator_v2.Envelope
+		retry, err = c.flush(batch)
+		c.handleDeadLetters(retry, err)
+		c.report(retry, Failed, err)
+	}
+
+	c.closeStream()
+	c.closeErrors <- err
+}
+
+// closeStream half-closes the stream and cancels the client's context once
+// the server has read the stream to its end or, should the server stall,
+// once the drain timeout has passed. Cancelling right away would reset the
+// stream and could discard the batches that were written to it but not yet
+// sent. With a drain timeout of 0, the context is cancelled only once the
+// server has read the stream.
+func (c *IngressClient) closeStream() {
+	if c.sender == nil {
+		c.cancel()
+		return
+	}
+
+	go func(s loggregator_v2.Ingress_BatchSenderClient) {
+		if c.drainTimeout > 0 {
+			t := time.AfterFunc(c.drainTimeout, c.cancel)
+			defer t.Stop()
+		}
+
+		s.CloseAndRecv()
+		c.cancel()
+	}(c.sender)
+	c.sender = nil
+}
+
+// dispatch flushes the batch or, when batches are pipelined, hands it off to
+// the writer go routine. It returns the envelopes that have to be retried.
+func (c *IngressClient) dispatch(batch []*loggregator_v2.Envelope) []*loggregator_v2.Envelope {
+	if c.inFlight != nil {
+		c.inFlight <- batch
+		return nil
+	}
+
+	retry, _ := c.flush(batch)
+	return retry
+}
+
+// startWriter writes pipelined batches to the stream. Once the in-flight
+// channel is closed, it reports the result of the last flush.
+func (c *IngressClient) startWriter() {
+	var err error
+	for batch := range c.inFlight {
+		_, err = c.flush(batch)
+	}
+
+	c.inFlightErrs <- err
+}
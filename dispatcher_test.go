@@ -0,0 +1,246 @@
+package loggregator_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dispatcher", func() {
+	var server *testIngressServer
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("releases the stream once the server has read the batch flushed on close", func() {
+		streams := make(chan context.Context, 1)
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithTransportDecorator(func(c loggregator_v2.IngressClient) loggregator_v2.IngressClient {
+				return &slowIngressClient{IngressClient: c, streams: streams}
+			}),
+		)
+		go func() {
+			defer GinkgoRecover()
+			receiveEnvelopes(server.receivers, 1)
+		}()
+
+		client.EmitLog("message")
+		Expect(client.CloseSend()).To(Succeed())
+
+		var ctx context.Context
+		Eventually(streams).Should(Receive(&ctx))
+		Consistently(ctx.Done()).ShouldNot(BeClosed())
+
+		server.stop()
+		Eventually(ctx.Done()).Should(BeClosed())
+	})
+
+	for _, pipelined := range []bool{false, true} {
+		opts := []loggregator.IngressOption{
+			loggregator.WithBatchMaxSize(3),
+			loggregator.WithSendTimeout(10 * time.Millisecond),
+		}
+		name := "handles concurrent emitters racing with CloseSend"
+		if pipelined {
+			opts = append(opts, loggregator.WithMaxInFlightBatches(4))
+			name += " with pipelined batches"
+		}
+
+		It(name, func() {
+			client, _, _ := buildIngressClient(server.addr, time.Millisecond, false, opts...)
+			go discardBatches(server.receivers)
+
+			done := emitConcurrently(client, 8, 200)
+			time.Sleep(5 * time.Millisecond)
+			closed := make(chan error, 1)
+			go func() {
+				closed <- client.CloseSend()
+			}()
+
+			Eventually(done, 5).Should(BeClosed())
+			Eventually(closed, 5).Should(Receive())
+			Expect(client.CloseSend()).To(Equal(loggregator.ErrClosed))
+		})
+	}
+
+	It("does not block emitters behind a slow stream", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Millisecond,
+			false,
+			loggregator.WithBatchMaxSize(1),
+			loggregator.WithSendTimeout(time.Millisecond),
+			loggregator.WithTransportDecorator(func(c loggregator_v2.IngressClient) loggregator_v2.IngressClient {
+				return &slowIngressClient{IngressClient: c, delay: 20 * time.Millisecond}
+			}),
+		)
+		go discardBatches(server.receivers)
+
+		Eventually(emitConcurrently(client, 4, 300), 5).Should(BeClosed())
+
+		closed := make(chan error, 1)
+		go func() {
+			closed <- client.CloseSend()
+		}()
+		Eventually(closed, 5).Should(Receive())
+	})
+
+	It("releases a stalled stream once the drain timeout has passed", func() {
+		streams := make(chan context.Context, 1)
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Millisecond,
+			false,
+			loggregator.WithBatchMaxSize(3),
+			loggregator.WithSendTimeout(time.Millisecond),
+			loggregator.WithDrainTimeout(100*time.Millisecond),
+			loggregator.WithTransportDecorator(func(c loggregator_v2.IngressClient) loggregator_v2.IngressClient {
+				return &stalledIngressClient{IngressClient: c, streams: streams}
+			}),
+		)
+
+		done := emitConcurrently(client, 32, 100)
+		time.Sleep(5 * time.Millisecond)
+		closed := make(chan error, 1)
+		go func() {
+			closed <- client.CloseSend()
+		}()
+
+		Eventually(done, 10).Should(BeClosed())
+		Eventually(closed, 10).Should(Receive())
+
+		var ctx context.Context
+		Eventually(streams).Should(Receive(&ctx))
+		Eventually(ctx.Done(), 5).Should(BeClosed())
+	})
+})
+
+// emitConcurrently emits n logs and counters from each of the given number
+// of go routines and closes the returned channel once all emits returned.
+func emitConcurrently(client *loggregator.IngressClient, routines, n int) chan struct{} {
+	var wg sync.WaitGroup
+	for i := 0; i < routines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				client.EmitLog("message")
+				client.EmitCounter("counter")
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	return done
+}
+
+// discardBatches reads the first stream of the server until it ends.
+func discardBatches(receivers chan loggregator_v2.Ingress_BatchSenderServer) {
+	defer GinkgoRecover()
+
+	var recv loggregator_v2.Ingress_BatchSenderServer
+	Eventually(receivers, 10).Should(Receive(&recv))
+	for {
+		if _, err := recv.Recv(); err != nil {
+			return
+		}
+	}
+}
+
+// slowIngressClient delays every batch written to its streams and reports
+// the context of every stream it opens.
+type slowIngressClient struct {
+	loggregator_v2.IngressClient
+	delay   time.Duration
+	streams chan context.Context
+}
+
+func (c *slowIngressClient) BatchSender(ctx context.Context, opts ...grpc.CallOption) (loggregator_v2.Ingress_BatchSenderClient, error) {
+	if c.streams != nil {
+		select {
+		case c.streams <- ctx:
+		default:
+		}
+	}
+
+	s, err := c.IngressClient.BatchSender(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &slowStream{Ingress_BatchSenderClient: s, delay: c.delay}, nil
+}
+
+type slowStream struct {
+	loggregator_v2.Ingress_BatchSenderClient
+	delay time.Duration
+}
+
+func (s *slowStream) Send(b *loggregator_v2.EnvelopeBatch) error {
+	time.Sleep(s.delay)
+	return s.Ingress_BatchSenderClient.Send(b)
+}
+
+// stalledIngressClient opens streams that accept batches slowly without
+// sending them and never acknowledge that the stream was closed until their
+// context is done.
+type stalledIngressClient struct {
+	loggregator_v2.IngressClient
+	streams chan context.Context
+}
+
+func (c *stalledIngressClient) BatchSender(ctx context.Context, opts ...grpc.CallOption) (loggregator_v2.Ingress_BatchSenderClient, error) {
+	select {
+	case c.streams <- ctx:
+	default:
+	}
+
+	s, err := c.IngressClient.BatchSender(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stalledStream{Ingress_BatchSenderClient: s, ctx: ctx}, nil
+}
+
+type stalledStream struct {
+	loggregator_v2.Ingress_BatchSenderClient
+	ctx context.Context
+}
+
+func (s *stalledStream) Send(*loggregator_v2.EnvelopeBatch) error {
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+func (s *stalledStream) CloseAndRecv() (*loggregator_v2.BatchSenderResponse, error) {
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
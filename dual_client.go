@@ -0,0 +1,94 @@
+package loggregator
+
+import (
+	"io/ioutil"
+	"log"
+)
+
+// Emitter is the interface implemented by both the v1 and the v2 client.
+type Emitter interface {
+	EmitLog(message string, opts ...EmitLogOption)
+	EmitGauge(opts ...EmitGaugeOption)
+	EmitCounter(name string, opts ...EmitCounterOption)
+}
+
+// DualClientOption is the option type passed into NewDualClient.
+type DualClientOption func(*DualClient)
+
+// WithV1Retired stops the DualClient from emitting to the v1 client when
+// retired is true. It is meant to be set from MetronConfig.RetireV1 once
+// every consumer has migrated to the v2 API.
+func WithV1Retired(retired bool) DualClientOption {
+	return func(c *DualClient) {
+		c.v1Retired = retired
+	}
+}
+
+// WithDualClientLogger allows for the configuration of a logger.
+// By default, the logger is disabled.
+func WithDualClientLogger(l Logger) DualClientOption {
+	return func(c *DualClient) {
+		c.logger = l
+	}
+}
+
+// DualClient emits every envelope to both a v1 and a v2 client. It is meant
+// for migration windows in which consumers of both APIs have to be served.
+// A failure in one of the clients does not prevent the envelope from being
+// emitted to the other one.
+type DualClient struct {
+	v1        Emitter
+	v2        Emitter
+	v1Retired bool
+	logger    Logger
+}
+
+// NewDualClient creates a DualClient that emits to the given v1 and v2
+// clients.
+func NewDualClient(v1, v2 Emitter, opts ...DualClientOption) *DualClient {
+	c := &DualClient{
+		v1:     v1,
+		v2:     v2,
+		logger: log.New(ioutil.Discard, "", 0),
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	return c
+}
+
+// EmitLog sends a message to both clients.
+func (c *DualClient) EmitLog(message string, opts ...EmitLogOption) {
+	c.each(func(e Emitter) { e.EmitLog(message, opts...) })
+}
+
+// EmitGauge sends the configured gauge values to both clients.
+func (c *DualClient) EmitGauge(opts ...EmitGaugeOption) {
+	c.each(func(e Emitter) { e.EmitGauge(opts...) })
+}
+
+// EmitCounter sends a counter envelope to both clients.
+func (c *DualClient) EmitCounter(name string, opts ...EmitCounterOption) {
+	c.each(func(e Emitter) { e.EmitCounter(name, opts...) })
+}
+
+func (c *DualClient) each(f func(Emitter)) {
+	if !c.v1Retired {
+		c.isolate("v1", c.v1, f)
+	}
+	c.isolate("v2", c.v2, f)
+}
+
+// isolate calls f with the emitter and recovers from any panic so that the
+// other emitter is still called.
+func (c *DualClient) isolate(version string, e Emitter, f func(Emitter)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.logger.Printf("Error while emitting to %s client: %v", version, r)
+		}
+	}()
+
+	f(e)
+}
@@ -0,0 +1,85 @@
+package loggregator_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DualClient", func() {
+	var (
+		v1 *spyEmitter
+		v2 *spyEmitter
+	)
+
+	BeforeEach(func() {
+		v1 = &spyEmitter{}
+		v2 = &spyEmitter{}
+	})
+
+	It("emits to both clients", func() {
+		c := loggregator.NewDualClient(v1, v2)
+
+		c.EmitLog("message")
+		c.EmitGauge(loggregator.WithGaugeValue("some-gauge", 1, "unit"))
+		c.EmitCounter("some-counter")
+
+		Expect(v1.calls).To(Equal([]string{"log:message", "gauge", "counter:some-counter"}))
+		Expect(v2.calls).To(Equal([]string{"log:message", "gauge", "counter:some-counter"}))
+	})
+
+	It("emits to the v2 client when the v1 client fails", func() {
+		v1.panics = true
+		logger := newSpyLogger()
+		c := loggregator.NewDualClient(v1, v2, loggregator.WithDualClientLogger(logger))
+
+		c.EmitLog("message")
+
+		Expect(v2.calls).To(Equal([]string{"log:message"}))
+		Expect(logger.messages()).To(ConsistOf(ContainSubstring("v1 client")))
+	})
+
+	It("emits to the v1 client when the v2 client fails", func() {
+		v2.panics = true
+		c := loggregator.NewDualClient(v1, v2)
+
+		c.EmitCounter("some-counter")
+
+		Expect(v1.calls).To(Equal([]string{"counter:some-counter"}))
+	})
+
+	It("does not emit to the v1 client once it is retired", func() {
+		c := loggregator.NewDualClient(v1, v2, loggregator.WithV1Retired(true))
+
+		c.EmitLog("message")
+
+		Expect(v1.calls).To(BeEmpty())
+		Expect(v2.calls).To(Equal([]string{"log:message"}))
+	})
+})
+
+type spyEmitter struct {
+	calls  []string
+	panics bool
+}
+
+func (s *spyEmitter) EmitLog(message string, opts ...loggregator.EmitLogOption) {
+	s.record("log:" + message)
+}
+
+func (s *spyEmitter) EmitGauge(opts ...loggregator.EmitGaugeOption) {
+	s.record("gauge")
+}
+
+func (s *spyEmitter) EmitCounter(name string, opts ...loggregator.EmitCounterOption) {
+	s.record("counter:" + name)
+}
+
+func (s *spyEmitter) record(call string) {
+	if s.panics {
+		panic("some-error")
+	}
+
+	s.calls = append(s.calls, call)
+}
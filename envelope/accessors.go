@@ -0,0 +1,79 @@
+package envelope
+
+import (
+	"strconv"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// GetTag returns the value of the envelope's tag with the given name. Tags
+// are looked up in the preferred string tags first and then in the
+// deprecated tags, whose integer and decimal values are formatted as
+// strings. It returns an empty string if the envelope has no such tag.
+func GetTag(e *loggregator_v2.Envelope, name string) string {
+	if v, ok := e.GetTags()[name]; ok {
+		return v
+	}
+
+	v, ok := e.GetDeprecatedTags()[name]
+	if !ok || v == nil {
+		return ""
+	}
+
+	switch d := v.GetData().(type) {
+	case *loggregator_v2.Value_Text:
+		return d.Text
+	case *loggregator_v2.Value_Integer:
+		return strconv.FormatInt(d.Integer, 10)
+	case *loggregator_v2.Value_Decimal:
+		return strconv.FormatFloat(d.Decimal, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// GetGaugeValue returns the value of the envelope's gauge metric with the
+// given name. It returns false if the envelope is not a gauge or has no
+// such metric.
+func GetGaugeValue(e *loggregator_v2.Envelope, name string) (float64, bool) {
+	v, ok := e.GetGauge().GetMetrics()[name]
+	if !ok || v == nil {
+		return 0, false
+	}
+
+	return v.GetValue(), true
+}
+
+// GetGaugeUnit returns the unit of the envelope's gauge metric with the
+// given name. It returns false if the envelope is not a gauge or has no
+// such metric.
+func GetGaugeUnit(e *loggregator_v2.Envelope, name string) (string, bool) {
+	v, ok := e.GetGauge().GetMetrics()[name]
+	if !ok || v == nil {
+		return "", false
+	}
+
+	return v.GetUnit(), true
+}
+
+// CounterDelta returns the delta of the envelope's counter. It returns false
+// if the envelope is not a counter.
+func CounterDelta(e *loggregator_v2.Envelope) (uint64, bool) {
+	c := e.GetCounter()
+	if c == nil {
+		return 0, false
+	}
+
+	return c.GetDelta(), true
+}
+
+// CounterTotal returns the total of the envelope's counter. It returns false
+// if the envelope is not a counter.
+func CounterTotal(e *loggregator_v2.Envelope) (uint64, bool) {
+	c := e.GetCounter()
+	if c == nil {
+		return 0, false
+	}
+
+	return c.GetTotal(), true
+}
@@ -0,0 +1,103 @@
+package envelope_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Accessors", func() {
+	Describe("GetTag", func() {
+		e := &loggregator_v2.Envelope{
+			Tags: map[string]string{
+				"preferred": "preferred-value",
+				"both":      "preferred-value",
+			},
+			DeprecatedTags: map[string]*loggregator_v2.Value{
+				"both":    {Data: &loggregator_v2.Value_Text{Text: "deprecated-value"}},
+				"text":    {Data: &loggregator_v2.Value_Text{Text: "text-value"}},
+				"integer": {Data: &loggregator_v2.Value_Integer{Integer: -12}},
+				"decimal": {Data: &loggregator_v2.Value_Decimal{Decimal: 1.5}},
+				"nil":     nil,
+			},
+		}
+
+		It("returns preferred tags over deprecated tags", func() {
+			Expect(envelope.GetTag(e, "preferred")).To(Equal("preferred-value"))
+			Expect(envelope.GetTag(e, "both")).To(Equal("preferred-value"))
+		})
+
+		It("formats deprecated tags as strings", func() {
+			Expect(envelope.GetTag(e, "text")).To(Equal("text-value"))
+			Expect(envelope.GetTag(e, "integer")).To(Equal("-12"))
+			Expect(envelope.GetTag(e, "decimal")).To(Equal("1.5"))
+		})
+
+		It("returns an empty string for missing tags", func() {
+			Expect(envelope.GetTag(e, "nil")).To(BeEmpty())
+			Expect(envelope.GetTag(e, "missing")).To(BeEmpty())
+			Expect(envelope.GetTag(nil, "missing")).To(BeEmpty())
+		})
+	})
+
+	Describe("GetGaugeValue", func() {
+		e := &loggregator_v2.Envelope{
+			Message: &loggregator_v2.Envelope_Gauge{
+				Gauge: &loggregator_v2.Gauge{
+					Metrics: map[string]*loggregator_v2.GaugeValue{
+						"cpu": {Value: 0.5, Unit: "percentage"},
+					},
+				},
+			},
+		}
+
+		It("returns the value and unit of a metric", func() {
+			v, ok := envelope.GetGaugeValue(e, "cpu")
+			Expect(ok).To(BeTrue())
+			Expect(v).To(Equal(0.5))
+
+			unit, ok := envelope.GetGaugeUnit(e, "cpu")
+			Expect(ok).To(BeTrue())
+			Expect(unit).To(Equal("percentage"))
+		})
+
+		It("returns false for missing metrics and other envelopes", func() {
+			_, ok := envelope.GetGaugeValue(e, "memory")
+			Expect(ok).To(BeFalse())
+
+			_, ok = envelope.GetGaugeValue(counterEnvelope, "cpu")
+			Expect(ok).To(BeFalse())
+
+			_, ok = envelope.GetGaugeUnit(nil, "cpu")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("counters", func() {
+		It("returns the delta and total of a counter", func() {
+			e := &loggregator_v2.Envelope{
+				Message: &loggregator_v2.Envelope_Counter{
+					Counter: &loggregator_v2.Counter{Delta: 2, Total: 10},
+				},
+			}
+
+			delta, ok := envelope.CounterDelta(e)
+			Expect(ok).To(BeTrue())
+			Expect(delta).To(Equal(uint64(2)))
+
+			total, ok := envelope.CounterTotal(e)
+			Expect(ok).To(BeTrue())
+			Expect(total).To(Equal(uint64(10)))
+		})
+
+		It("returns false for other envelopes", func() {
+			_, ok := envelope.CounterDelta(gaugeEnvelope)
+			Expect(ok).To(BeFalse())
+
+			_, ok = envelope.CounterTotal(nil)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
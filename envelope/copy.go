@@ -0,0 +1,41 @@
+package envelope
+
+import (
+	"github.com/golang/protobuf/proto"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// DeepCopy returns a copy of the envelope that shares no memory with it.
+func DeepCopy(e *loggregator_v2.Envelope) *loggregator_v2.Envelope {
+	if e == nil {
+		return nil
+	}
+
+	return proto.Clone(e).(*loggregator_v2.Envelope)
+}
+
+// MergeTags adds the given tags to the envelope's tags. A tag that the
+// envelope already has, as a preferred or as a deprecated tag, is only
+// replaced if overwrite is true. Replacing a deprecated tag removes it from
+// the deprecated tags.
+func MergeTags(dst *loggregator_v2.Envelope, src map[string]string, overwrite bool) {
+	if len(src) == 0 {
+		return
+	}
+
+	if dst.Tags == nil {
+		dst.Tags = make(map[string]string, len(src))
+	}
+
+	for k, v := range src {
+		_, preferred := dst.Tags[k]
+		_, deprecated := dst.DeprecatedTags[k]
+		if (preferred || deprecated) && !overwrite {
+			continue
+		}
+
+		delete(dst.DeprecatedTags, k)
+		dst.Tags[k] = v
+	}
+}
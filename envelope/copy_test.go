@@ -0,0 +1,84 @@
+package envelope_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Copy", func() {
+	Describe("DeepCopy", func() {
+		It("returns a copy that shares no memory", func() {
+			e := &loggregator_v2.Envelope{
+				SourceId: "some-source",
+				Tags:     map[string]string{"a": "b"},
+				Message: &loggregator_v2.Envelope_Log{
+					Log: &loggregator_v2.Log{Payload: []byte("message")},
+				},
+			}
+
+			c := envelope.DeepCopy(e)
+			Expect(proto.Equal(c, e)).To(BeTrue())
+
+			c.Tags["a"] = "changed"
+			c.GetLog().Payload[0] = 'M'
+			Expect(e.Tags["a"]).To(Equal("b"))
+			Expect(e.GetLog().Payload).To(Equal([]byte("message")))
+		})
+
+		It("returns nil for nil", func() {
+			Expect(envelope.DeepCopy(nil)).To(BeNil())
+		})
+	})
+
+	Describe("MergeTags", func() {
+		var e *loggregator_v2.Envelope
+
+		BeforeEach(func() {
+			e = &loggregator_v2.Envelope{
+				Tags: map[string]string{"preferred": "envelope-value"},
+				DeprecatedTags: map[string]*loggregator_v2.Value{
+					"deprecated": {Data: &loggregator_v2.Value_Text{Text: "envelope-value"}},
+				},
+			}
+		})
+
+		It("keeps existing tags", func() {
+			envelope.MergeTags(e, map[string]string{
+				"preferred":  "merged-value",
+				"deprecated": "merged-value",
+				"new":        "merged-value",
+			}, false)
+
+			Expect(e.Tags).To(Equal(map[string]string{
+				"preferred": "envelope-value",
+				"new":       "merged-value",
+			}))
+			Expect(envelope.GetTag(e, "deprecated")).To(Equal("envelope-value"))
+		})
+
+		It("overwrites existing tags", func() {
+			envelope.MergeTags(e, map[string]string{
+				"preferred":  "merged-value",
+				"deprecated": "merged-value",
+			}, true)
+
+			Expect(e.Tags).To(Equal(map[string]string{
+				"preferred":  "merged-value",
+				"deprecated": "merged-value",
+			}))
+			Expect(e.DeprecatedTags).NotTo(HaveKey("deprecated"))
+		})
+
+		It("creates the tags of envelopes without tags", func() {
+			e := &loggregator_v2.Envelope{}
+
+			envelope.MergeTags(e, map[string]string{"a": "b"}, false)
+
+			Expect(e.Tags).To(Equal(map[string]string{"a": "b"}))
+		})
+	})
+})
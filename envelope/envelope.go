@@ -0,0 +1,104 @@
+// Package envelope provides helpers to inspect loggregator v2 envelopes
+// without switching over the type of their message in every consumer.
+package envelope
+
+import "code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+// MessageType identifies the type of message an envelope carries.
+type MessageType int
+
+// The message types of an envelope. Unknown is the type of envelopes without
+// a message.
+const (
+	Unknown MessageType = iota
+	Log
+	Counter
+	Gauge
+	Timer
+	Event
+)
+
+var messageTypeNames = map[MessageType]string{
+	Unknown: "unknown",
+	Log:     "log",
+	Counter: "counter",
+	Gauge:   "gauge",
+	Timer:   "timer",
+	Event:   "event",
+}
+
+// String returns the lower case name of the message type, e.g. "counter".
+func (t MessageType) String() string {
+	if name, ok := messageTypeNames[t]; ok {
+		return name
+	}
+
+	return messageTypeNames[Unknown]
+}
+
+// Type returns the type of the envelope's message.
+func Type(e *loggregator_v2.Envelope) MessageType {
+	switch e.GetMessage().(type) {
+	case *loggregator_v2.Envelope_Log:
+		return Log
+	case *loggregator_v2.Envelope_Counter:
+		return Counter
+	case *loggregator_v2.Envelope_Gauge:
+		return Gauge
+	case *loggregator_v2.Envelope_Timer:
+		return Timer
+	case *loggregator_v2.Envelope_Event:
+		return Event
+	default:
+		return Unknown
+	}
+}
+
+// Visitor holds a function for each type of message. Functions may be nil.
+type Visitor struct {
+	Log     func(*loggregator_v2.Envelope, *loggregator_v2.Log)
+	Counter func(*loggregator_v2.Envelope, *loggregator_v2.Counter)
+	Gauge   func(*loggregator_v2.Envelope, *loggregator_v2.Gauge)
+	Timer   func(*loggregator_v2.Envelope, *loggregator_v2.Timer)
+	Event   func(*loggregator_v2.Envelope, *loggregator_v2.Event)
+
+	// Default is called for envelopes whose message type has no function,
+	// including envelopes without a message.
+	Default func(*loggregator_v2.Envelope)
+}
+
+// Visit calls the function of the visitor that matches the type of the
+// envelope's message.
+func Visit(e *loggregator_v2.Envelope, v Visitor) {
+	switch m := e.GetMessage().(type) {
+	case *loggregator_v2.Envelope_Log:
+		if v.Log != nil {
+			v.Log(e, m.Log)
+			return
+		}
+	case *loggregator_v2.Envelope_Counter:
+		if v.Counter != nil {
+			v.Counter(e, m.Counter)
+			return
+		}
+	case *loggregator_v2.Envelope_Gauge:
+		if v.Gauge != nil {
+			v.Gauge(e, m.Gauge)
+			return
+		}
+	case *loggregator_v2.Envelope_Timer:
+		if v.Timer != nil {
+			v.Timer(e, m.Timer)
+			return
+		}
+	case *loggregator_v2.Envelope_Event:
+		if v.Event != nil {
+			v.Event(e, m.Event)
+			return
+		}
+	}
+
+	if v.Default != nil {
+		v.Default(e)
+	}
+}
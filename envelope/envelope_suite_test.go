@@ -0,0 +1,13 @@
+package envelope_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Envelope Suite")
+}
@@ -0,0 +1,116 @@
+package envelope_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	logEnvelope = &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Log{Log: &loggregator_v2.Log{}},
+	}
+	counterEnvelope = &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Counter{Counter: &loggregator_v2.Counter{}},
+	}
+	gaugeEnvelope = &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Gauge{Gauge: &loggregator_v2.Gauge{}},
+	}
+	timerEnvelope = &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Timer{Timer: &loggregator_v2.Timer{}},
+	}
+	eventEnvelope = &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Event{Event: &loggregator_v2.Event{}},
+	}
+	emptyEnvelope = &loggregator_v2.Envelope{}
+)
+
+var _ = Describe("Envelope", func() {
+	DescribeTable("Type",
+		func(e *loggregator_v2.Envelope, t envelope.MessageType, name string) {
+			Expect(envelope.Type(e)).To(Equal(t))
+			Expect(envelope.Type(e).String()).To(Equal(name))
+		},
+		Entry("log", logEnvelope, envelope.Log, "log"),
+		Entry("counter", counterEnvelope, envelope.Counter, "counter"),
+		Entry("gauge", gaugeEnvelope, envelope.Gauge, "gauge"),
+		Entry("timer", timerEnvelope, envelope.Timer, "timer"),
+		Entry("event", eventEnvelope, envelope.Event, "event"),
+		Entry("empty", emptyEnvelope, envelope.Unknown, "unknown"),
+		Entry("nil", nil, envelope.Unknown, "unknown"),
+	)
+
+	Describe("Visit", func() {
+		var (
+			visited []string
+			visitor envelope.Visitor
+		)
+
+		BeforeEach(func() {
+			visited = nil
+			visitor = envelope.Visitor{
+				Log: func(_ *loggregator_v2.Envelope, _ *loggregator_v2.Log) {
+					visited = append(visited, "log")
+				},
+				Counter: func(_ *loggregator_v2.Envelope, _ *loggregator_v2.Counter) {
+					visited = append(visited, "counter")
+				},
+				Gauge: func(_ *loggregator_v2.Envelope, _ *loggregator_v2.Gauge) {
+					visited = append(visited, "gauge")
+				},
+				Timer: func(_ *loggregator_v2.Envelope, _ *loggregator_v2.Timer) {
+					visited = append(visited, "timer")
+				},
+				Event: func(_ *loggregator_v2.Envelope, _ *loggregator_v2.Event) {
+					visited = append(visited, "event")
+				},
+				Default: func(_ *loggregator_v2.Envelope) {
+					visited = append(visited, "default")
+				},
+			}
+		})
+
+		It("calls the function of the message type", func() {
+			for _, e := range []*loggregator_v2.Envelope{
+				logEnvelope,
+				counterEnvelope,
+				gaugeEnvelope,
+				timerEnvelope,
+				eventEnvelope,
+				emptyEnvelope,
+			} {
+				envelope.Visit(e, visitor)
+			}
+
+			Expect(visited).To(Equal([]string{"log", "counter", "gauge", "timer", "event", "default"}))
+		})
+
+		It("calls the default function for message types without a function", func() {
+			visitor.Counter = nil
+
+			envelope.Visit(counterEnvelope, visitor)
+
+			Expect(visited).To(Equal([]string{"default"}))
+		})
+
+		It("passes the message to the function", func() {
+			var actual *loggregator_v2.Counter
+			envelope.Visit(counterEnvelope, envelope.Visitor{
+				Counter: func(_ *loggregator_v2.Envelope, c *loggregator_v2.Counter) {
+					actual = c
+				},
+			})
+
+			Expect(actual).To(BeIdenticalTo(counterEnvelope.GetCounter()))
+		})
+
+		It("ignores envelopes without a matching function", func() {
+			Expect(func() {
+				envelope.Visit(counterEnvelope, envelope.Visitor{})
+			}).NotTo(Panic())
+		})
+	})
+})
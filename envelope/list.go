@@ -0,0 +1,49 @@
+package envelope
+
+import "strings"
+
+// EncodeList encodes a list of values, e.g. the addresses of the forwarded
+// tag of an HTTP timer, as a single tag value. This is the canonical
+// encoding of list-valued tags: the values are separated by newlines, which
+// is the separator the v1 conversion of Loggregator splits on. Backslashes
+// and newlines within values are escaped as \\ and \n.
+func EncodeList(values []string) string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		v = strings.Replace(v, `\`, `\\`, -1)
+		escaped[i] = strings.Replace(v, "\n", `\n`, -1)
+	}
+
+	return strings.Join(escaped, "\n")
+}
+
+// DecodeList decodes a tag value encoded with EncodeList. An empty string
+// decodes to an empty list.
+func DecodeList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	var (
+		values []string
+		cur    strings.Builder
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\n':
+			values = append(values, cur.String())
+			cur.Reset()
+		case c == '\\' && i+1 < len(s):
+			i++
+			if s[i] == 'n' {
+				cur.WriteByte('\n')
+			} else {
+				cur.WriteByte(s[i])
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+
+	return append(values, cur.String())
+}
@@ -0,0 +1,32 @@
+package envelope_test
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("List encoding", func() {
+	It("separates values with newlines", func() {
+		encoded := envelope.EncodeList([]string{"10.0.0.1", "10.0.0.2"})
+
+		Expect(encoded).To(Equal("10.0.0.1\n10.0.0.2"))
+		Expect(strings.Split(encoded, "\n")).To(Equal([]string{"10.0.0.1", "10.0.0.2"}))
+	})
+
+	It("escapes newlines and backslashes", func() {
+		values := []string{"a\nb", `c\d`, `e\n`, ""}
+
+		encoded := envelope.EncodeList(values)
+		Expect(strings.Count(encoded, "\n")).To(Equal(3))
+		Expect(envelope.DecodeList(encoded)).To(Equal(values))
+	})
+
+	It("decodes an empty string to an empty list", func() {
+		Expect(envelope.DecodeList("")).To(BeEmpty())
+		Expect(envelope.EncodeList(nil)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,38 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// ContentEncodingTag is the tag that names the encoding of a log payload.
+// Payloads without it are the raw bytes that were logged.
+const ContentEncodingTag = "content_encoding"
+
+// Base64Encoding is the value of ContentEncodingTag for payloads that are
+// encoded with standard base64.
+const Base64Encoding = "base64"
+
+// LogPayload returns the decoded payload of the envelope's log. It returns
+// nil if the envelope is not a log and an error if the payload has an
+// unknown encoding or cannot be decoded.
+func LogPayload(e *loggregator_v2.Envelope) ([]byte, error) {
+	payload := e.GetLog().GetPayload()
+
+	switch enc := e.GetTags()[ContentEncodingTag]; enc {
+	case "":
+		return payload, nil
+	case Base64Encoding:
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(payload)))
+		n, err := base64.StdEncoding.Decode(decoded, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		return decoded[:n], nil
+	default:
+		return nil, fmt.Errorf("unknown content encoding %q", enc)
+	}
+}
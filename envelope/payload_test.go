@@ -0,0 +1,42 @@
+package envelope_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogPayload", func() {
+	log := func(payload string, tags map[string]string) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			Tags: tags,
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte(payload)},
+			},
+		}
+	}
+
+	It("returns raw payloads as is", func() {
+		Expect(envelope.LogPayload(log("\xff\xfe", nil))).To(Equal([]byte("\xff\xfe")))
+	})
+
+	It("decodes base64 payloads", func() {
+		e := log("//4=", map[string]string{"content_encoding": "base64"})
+
+		Expect(envelope.LogPayload(e)).To(Equal([]byte("\xff\xfe")))
+	})
+
+	It("returns an error for invalid payloads", func() {
+		_, err := envelope.LogPayload(log("!", map[string]string{"content_encoding": "base64"}))
+		Expect(err).To(HaveOccurred())
+
+		_, err = envelope.LogPayload(log("", map[string]string{"content_encoding": "gzip"}))
+		Expect(err).To(MatchError(`unknown content encoding "gzip"`))
+	})
+
+	It("returns nil for envelopes that are not logs", func() {
+		Expect(envelope.LogPayload(&loggregator_v2.Envelope{})).To(BeNil())
+	})
+})
@@ -0,0 +1,74 @@
+package envelope
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// SourceTypeTag is the tag that holds the source type of an envelope, e.g.
+// the component that emitted a log on behalf of an application.
+const SourceTypeTag = "source_type"
+
+// The conventional source types of Cloud Foundry. A source type may be
+// qualified with a suffix separated by a slash, e.g. "APP/PROC/WEB".
+const (
+	SourceTypeApp     = "APP"
+	SourceTypeRouter  = "RTR"
+	SourceTypeStaging = "STG"
+	SourceTypeCell    = "CELL"
+	SourceTypeSSH     = "SSH"
+	SourceTypeAPI     = "API"
+)
+
+var sourceTypes = []string{
+	SourceTypeApp,
+	SourceTypeRouter,
+	SourceTypeStaging,
+	SourceTypeCell,
+	SourceTypeSSH,
+	SourceTypeAPI,
+}
+
+// SourceType returns the source type of the envelope or an empty string.
+func SourceType(e *loggregator_v2.Envelope) string {
+	return e.GetTags()[SourceTypeTag]
+}
+
+// SourceTypePrefix returns the conventional source type the given source
+// type is qualified from, e.g. "APP" for "APP/PROC/WEB". It returns an
+// empty string if the source type is not conventional.
+func SourceTypePrefix(sourceType string) string {
+	prefix := sourceType
+	if i := strings.IndexByte(sourceType, '/'); i >= 0 {
+		prefix = sourceType[:i]
+	}
+
+	for _, t := range sourceTypes {
+		if prefix == t {
+			return t
+		}
+	}
+
+	return ""
+}
+
+// ValidSourceType reports whether the source type is conventional or a
+// qualification of a conventional source type.
+func ValidSourceType(sourceType string) bool {
+	return SourceTypePrefix(sourceType) != ""
+}
+
+// NormalizeSourceType trims the source type and upper-cases it if it is a
+// conventional source type in another case, e.g. "app/proc/web". Other
+// source types are only trimmed.
+func NormalizeSourceType(sourceType string) string {
+	sourceType = strings.TrimSpace(sourceType)
+
+	upper := strings.ToUpper(sourceType)
+	if ValidSourceType(upper) {
+		return upper
+	}
+
+	return sourceType
+}
@@ -0,0 +1,33 @@
+package envelope_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Source types", func() {
+	It("returns the source type of an envelope", func() {
+		e := &loggregator_v2.Envelope{Tags: map[string]string{"source_type": "APP/PROC/WEB"}}
+
+		Expect(envelope.SourceType(e)).To(Equal("APP/PROC/WEB"))
+		Expect(envelope.SourceType(&loggregator_v2.Envelope{})).To(BeEmpty())
+	})
+
+	DescribeTable("validates and normalizes source types",
+		func(sourceType, prefix, normalized string) {
+			Expect(envelope.SourceTypePrefix(sourceType)).To(Equal(prefix))
+			Expect(envelope.ValidSourceType(sourceType)).To(Equal(prefix != ""))
+			Expect(envelope.NormalizeSourceType(sourceType)).To(Equal(normalized))
+		},
+		Entry("conventional", "RTR", "RTR", "RTR"),
+		Entry("qualified", "APP/PROC/WEB", "APP", "APP/PROC/WEB"),
+		Entry("lower case", "app/proc/web", "", "APP/PROC/WEB"),
+		Entry("padded", " STG ", "", "STG"),
+		Entry("prefix of another word", "APPLE", "", "APPLE"),
+		Entry("custom", "my-component", "", "my-component"),
+	)
+})
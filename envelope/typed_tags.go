@@ -0,0 +1,138 @@
+package envelope
+
+import (
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// TagEditor reads and writes the tags of an envelope regardless of whether
+// they are preferred string tags or deprecated typed tags. It should be
+// created with Tags or DeprecatedTags, which determine the representation
+// written by its setters. The setters return the editor so that they can be
+// chained.
+type TagEditor struct {
+	e          *loggregator_v2.Envelope
+	deprecated bool
+}
+
+// Tags returns a TagEditor that writes preferred string tags, e.g. the
+// integer 10 is written as "10".
+func Tags(e *loggregator_v2.Envelope) *TagEditor {
+	return &TagEditor{e: e}
+}
+
+// DeprecatedTags returns a TagEditor that writes deprecated typed tags,
+// which the v1 conversion of Loggregator expects for some tags, e.g. the
+// tags of HTTP timers.
+func DeprecatedTags(e *loggregator_v2.Envelope) *TagEditor {
+	return &TagEditor{e: e, deprecated: true}
+}
+
+// SetText sets the tag to the text.
+func (t *TagEditor) SetText(name, value string) *TagEditor {
+	return t.set(name, value, &loggregator_v2.Value{
+		Data: &loggregator_v2.Value_Text{Text: value},
+	})
+}
+
+// SetInt sets the tag to the integer.
+func (t *TagEditor) SetInt(name string, value int64) *TagEditor {
+	return t.set(name, strconv.FormatInt(value, 10), &loggregator_v2.Value{
+		Data: &loggregator_v2.Value_Integer{Integer: value},
+	})
+}
+
+// SetDecimal sets the tag to the decimal.
+func (t *TagEditor) SetDecimal(name string, value float64) *TagEditor {
+	return t.set(name, strconv.FormatFloat(value, 'g', -1, 64), &loggregator_v2.Value{
+		Data: &loggregator_v2.Value_Decimal{Decimal: value},
+	})
+}
+
+// set writes the tag in the editor's representation and removes it from
+// the other one so that readers do not see conflicting values.
+func (t *TagEditor) set(name, text string, value *loggregator_v2.Value) *TagEditor {
+	if t.deprecated {
+		if t.e.DeprecatedTags == nil {
+			t.e.DeprecatedTags = make(map[string]*loggregator_v2.Value)
+		}
+		t.e.DeprecatedTags[name] = value
+		delete(t.e.Tags, name)
+
+		return t
+	}
+
+	if t.e.Tags == nil {
+		t.e.Tags = make(map[string]string)
+	}
+	t.e.Tags[name] = text
+	delete(t.e.DeprecatedTags, name)
+
+	return t
+}
+
+// Text returns the tag as text. See GetTag.
+func (t *TagEditor) Text(name string) (string, bool) {
+	if !t.has(name) {
+		return "", false
+	}
+
+	return GetTag(t.e, name), true
+}
+
+// Int returns the tag as an integer. Text values are parsed, ignoring
+// surrounding white space, and decimal values without a fraction are
+// accepted. It returns false if the tag is missing or not an integer.
+func (t *TagEditor) Int(name string) (int64, bool) {
+	if v, ok := t.e.GetDeprecatedTags()[name]; ok && v != nil {
+		switch d := v.GetData().(type) {
+		case *loggregator_v2.Value_Integer:
+			return d.Integer, true
+		case *loggregator_v2.Value_Decimal:
+			if d.Decimal == float64(int64(d.Decimal)) {
+				return int64(d.Decimal), true
+			}
+			return 0, false
+		}
+	}
+
+	s, ok := t.Text(name)
+	if !ok {
+		return 0, false
+	}
+
+	i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return i, true
+}
+
+// Decimal returns the tag as a decimal. Text values are parsed, ignoring
+// surrounding white space. It returns false if the tag is missing or not a
+// number.
+func (t *TagEditor) Decimal(name string) (float64, bool) {
+	s, ok := t.Text(name)
+	if !ok {
+		return 0, false
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return f, true
+}
+
+func (t *TagEditor) has(name string) bool {
+	if _, ok := t.e.GetTags()[name]; ok {
+		return true
+	}
+
+	v, ok := t.e.GetDeprecatedTags()[name]
+	return ok && v != nil
+}
@@ -0,0 +1,75 @@
+package envelope_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TagEditor", func() {
+	It("writes preferred string tags", func() {
+		e := &loggregator_v2.Envelope{}
+
+		envelope.Tags(e).
+			SetInt("instance_index", 10).
+			SetText("app_name", "some-app").
+			SetDecimal("ratio", 0.5)
+
+		Expect(e.Tags).To(Equal(map[string]string{
+			"instance_index": "10",
+			"app_name":       "some-app",
+			"ratio":          "0.5",
+		}))
+		Expect(e.DeprecatedTags).To(BeEmpty())
+	})
+
+	It("writes deprecated typed tags", func() {
+		e := &loggregator_v2.Envelope{Tags: map[string]string{"instance_index": "1"}}
+
+		envelope.DeprecatedTags(e).SetInt("instance_index", 10).SetText("method", "GET")
+
+		Expect(e.Tags).To(BeEmpty())
+		Expect(e.DeprecatedTags["instance_index"].GetInteger()).To(Equal(int64(10)))
+		Expect(e.DeprecatedTags["method"].GetText()).To(Equal("GET"))
+	})
+
+	It("reads integers from every representation", func() {
+		e := &loggregator_v2.Envelope{
+			Tags: map[string]string{
+				"text":    " 10 ",
+				"invalid": "ten",
+			},
+			DeprecatedTags: map[string]*loggregator_v2.Value{
+				"integer":        {Data: &loggregator_v2.Value_Integer{Integer: 11}},
+				"deprecatedText": {Data: &loggregator_v2.Value_Text{Text: "12"}},
+				"decimal":        {Data: &loggregator_v2.Value_Decimal{Decimal: 13}},
+				"fraction":       {Data: &loggregator_v2.Value_Decimal{Decimal: 13.5}},
+			},
+		}
+		tags := envelope.Tags(e)
+
+		for name, expected := range map[string]int64{"text": 10, "integer": 11, "deprecatedText": 12, "decimal": 13} {
+			i, ok := tags.Int(name)
+			Expect(ok).To(BeTrue(), name)
+			Expect(i).To(Equal(expected), name)
+		}
+
+		for _, name := range []string{"invalid", "fraction", "missing"} {
+			_, ok := tags.Int(name)
+			Expect(ok).To(BeFalse(), name)
+		}
+
+		f, ok := tags.Decimal("fraction")
+		Expect(ok).To(BeTrue())
+		Expect(f).To(Equal(13.5))
+
+		s, ok := tags.Text("integer")
+		Expect(ok).To(BeTrue())
+		Expect(s).To(Equal("11"))
+
+		_, ok = tags.Text("missing")
+		Expect(ok).To(BeFalse())
+	})
+})
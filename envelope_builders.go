@@ -0,0 +1,105 @@
+package loggregator
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// The envelope builders are shared by the clients in this package. Each
+// returns a new envelope with the given timestamp, tags and options applied.
+
+func newLogEnvelope(now time.Time, tags map[string]string, payload []byte, opts []EmitLogOption) *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		Timestamp: now.UnixNano(),
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{
+				Payload: payload,
+				Type:    loggregator_v2.Log_ERR,
+			},
+		},
+		Tags: envelopeTags(tags),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+func newGaugeEnvelope(now time.Time, tags map[string]string, opts []EmitGaugeOption) *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		Timestamp: now.UnixNano(),
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: make(map[string]*loggregator_v2.GaugeValue),
+			},
+		},
+		Tags: envelopeTags(tags),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+func newCounterEnvelope(now time.Time, tags map[string]string, name string, opts []EmitCounterOption) *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		Timestamp: now.UnixNano(),
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{
+				Name:  name,
+				Delta: uint64(1),
+			},
+		},
+		Tags: envelopeTags(tags),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+func newTimerEnvelope(now time.Time, tags map[string]string, name string, start, stop time.Time, opts []EmitTimerOption) *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		Timestamp: now.UnixNano(),
+		Message: &loggregator_v2.Envelope_Timer{
+			Timer: &loggregator_v2.Timer{
+				Name:  name,
+				Start: start.UnixNano(),
+				Stop:  stop.UnixNano(),
+			},
+		},
+		Tags: envelopeTags(tags),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+func newEventEnvelope(now time.Time, tags map[string]string, title, body string, opts []EmitEventOption) *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		Timestamp: now.UnixNano(),
+		Message: &loggregator_v2.Envelope_Event{
+			Event: &loggregator_v2.Event{
+				Title: title,
+				Body:  body,
+			},
+		},
+		Tags: envelopeTags(tags),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
@@ -10,7 +10,9 @@ import (
 	gendiodes "code.cloudfoundry.org/go-diodes"
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 // EnvelopeStreamConnector provides a way to connect to loggregator and
@@ -25,7 +27,11 @@ type EnvelopeStreamConnector struct {
 	bufferSize int
 	alerter    func(int)
 
-	log Logger
+	log        Logger
+	errHandler func(error)
+	fallback   bool
+	gaps       *GapDetector
+	latencies  *LatencyTracker
 }
 
 // NewEnvelopeStreamConnector creates a new EnvelopeStreamConnector. Its TLS
@@ -72,6 +78,64 @@ func WithEnvelopeStreamBuffer(size int, alerter func(missed int)) EnvelopeStream
 	}
 }
 
+// WithEnvelopeStreamErrorHandler configures the EnvelopeStream to stop at
+// the first error that is not retryable (see IsRetryable), e.g. when the
+// client is not permitted to read envelopes, and to pass it to the given
+// handler. Once stopped, the EnvelopeStream returns nil without blocking.
+// Retryable errors are never passed to the handler; the stream reconnects
+// instead. Without a handler, the stream reconnects after any error.
+func WithEnvelopeStreamErrorHandler(f func(error)) EnvelopeStreamOption {
+	return func(c *EnvelopeStreamConnector) {
+		c.errHandler = f
+	}
+}
+
+// WithEnvelopeStreamFallback configures the EnvelopeStream to fall back to
+// the unbatched Receiver endpoint when the server does not implement the
+// BatchedReceiver endpoint. The envelopes received from the Receiver
+// endpoint are returned in batches of one.
+func WithEnvelopeStreamFallback() EnvelopeStreamOption {
+	return func(c *EnvelopeStreamConnector) {
+		c.fallback = true
+	}
+}
+
+// WithEnvelopeStreamGapDetector configures the EnvelopeStream to pass every
+// received envelope to the given GapDetector. Envelopes dropped by the
+// buffer of WithEnvelopeStreamBuffer are not reported as gaps.
+func WithEnvelopeStreamGapDetector(d *GapDetector) EnvelopeStreamOption {
+	return func(c *EnvelopeStreamConnector) {
+		c.gaps = d
+	}
+}
+
+// WithEnvelopeStreamLatencyTracker configures the EnvelopeStream to pass
+// every received envelope to the given LatencyTracker.
+func WithEnvelopeStreamLatencyTracker(t *LatencyTracker) EnvelopeStreamOption {
+	return func(c *EnvelopeStreamConnector) {
+		c.latencies = t
+	}
+}
+
+// IsRetryable reports whether an error returned by the Loggregator API is
+// transient, in which case the request may succeed when retried. Errors
+// caused by the request or the client's credentials, e.g. PermissionDenied
+// or InvalidArgument, are not retryable.
+func IsRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument,
+		codes.NotFound,
+		codes.PermissionDenied,
+		codes.Unauthenticated,
+		codes.FailedPrecondition,
+		codes.OutOfRange,
+		codes.Unimplemented:
+		return false
+	default:
+		return true
+	}
+}
+
 // EnvelopeStream returns batches of envelopes. It blocks until its context
 // is done or a batch of envelopes is available.
 type EnvelopeStream func() []*loggregator_v2.Envelope
@@ -81,7 +145,7 @@ type EnvelopeStream func() []*loggregator_v2.Envelope
 // underlying gRPC stream dies, it attempts to reconnect until the context
 // is done.
 func (c *EnvelopeStreamConnector) Stream(ctx context.Context, req *loggregator_v2.EgressBatchRequest) EnvelopeStream {
-	s := newStream(ctx, c.addr, req, c.tlsConf, c.log)
+	s := c.newStream(ctx, req)
 	if c.alerter != nil || c.bufferSize > 0 {
 		d := NewOneToOneEnvelopeBatch(
 			c.bufferSize,
@@ -97,7 +161,11 @@ func (c *EnvelopeStreamConnector) Stream(ctx context.Context, req *loggregator_v
 				default:
 				}
 
-				d.Set(s.recv())
+				batch := s.recv()
+				if s.stopped {
+					return
+				}
+				d.Set(batch)
 			}
 		}()
 		return d.Next
@@ -106,12 +174,31 @@ func (c *EnvelopeStreamConnector) Stream(ctx context.Context, req *loggregator_v
 	return s.recv
 }
 
+func (c *EnvelopeStreamConnector) newStream(ctx context.Context, req *loggregator_v2.EgressBatchRequest) *stream {
+	s := newStream(ctx, c.addr, req, c.tlsConf, c.log, c.errHandler)
+	s.fallback = c.fallback
+	s.gaps = c.gaps
+	s.latencies = c.latencies
+
+	return s
+}
+
 type stream struct {
-	log    Logger
-	ctx    context.Context
-	req    *loggregator_v2.EgressBatchRequest
-	client loggregator_v2.EgressClient
-	rx     loggregator_v2.Egress_BatchedReceiverClient
+	log        Logger
+	errHandler func(error)
+	ctx        context.Context
+	req        *loggregator_v2.EgressBatchRequest
+	client     loggregator_v2.EgressClient
+	rx         func() ([]*loggregator_v2.Envelope, error)
+	stopped    bool
+
+	// fallback enables switching to the unbatched Receiver endpoint, which
+	// is used once unbatched is set.
+	fallback  bool
+	unbatched bool
+
+	gaps      *GapDetector
+	latencies *LatencyTracker
 }
 
 func newStream(
@@ -120,6 +207,7 @@ func newStream(
 	req *loggregator_v2.EgressBatchRequest,
 	c *tls.Config,
 	log Logger,
+	errHandler func(error),
 ) *stream {
 	conn, err := grpc.Dial(
 		addr,
@@ -134,10 +222,11 @@ func newStream(
 	client := loggregator_v2.NewEgressClient(conn)
 
 	return &stream{
-		ctx:    ctx,
-		req:    req,
-		client: client,
-		log:    log,
+		ctx:        ctx,
+		req:        req,
+		client:     client,
+		log:        log,
+		errHandler: errHandler,
 	}
 }
 
@@ -147,13 +236,30 @@ func (s *stream) recv() []*loggregator_v2.Envelope {
 		if !ok {
 			return nil
 		}
-		batch, err := s.rx.Recv()
+		batch, err := s.rx()
 		if err != nil {
 			s.rx = nil
+			if s.fallback && !s.unbatched && status.Code(err) == codes.Unimplemented {
+				s.log.Printf("Falling back to unbatched stream: %s", err)
+				s.unbatched = true
+				continue
+			}
+			if s.terminal(err) {
+				return nil
+			}
 			continue
 		}
 
-		return batch.Batch
+		for _, e := range batch {
+			if s.gaps != nil {
+				s.gaps.Observe(e)
+			}
+			if s.latencies != nil {
+				s.latencies.Observe(e)
+			}
+		}
+
+		return batch
 	}
 }
 
@@ -163,17 +269,25 @@ func (s *stream) connect(ctx context.Context) bool {
 		case <-ctx.Done():
 			return false
 		default:
+			if s.stopped {
+				return false
+			}
+
 			if s.rx != nil {
 				return true
 			}
 
 			var err error
-			s.rx, err = s.client.BatchedReceiver(
-				ctx,
-				s.req,
-			)
+			if s.unbatched {
+				s.rx, err = s.receiver(ctx)
+			} else {
+				s.rx, err = s.batchedReceiver(ctx)
+			}
 
 			if err != nil {
+				if s.terminal(err) {
+					return false
+				}
 				s.log.Printf("Error connecting to Logs Provider: %s", err)
 				time.Sleep(50 * time.Millisecond)
 				continue
@@ -183,3 +297,55 @@ func (s *stream) connect(ctx context.Context) bool {
 		}
 	}
 }
+
+func (s *stream) batchedReceiver(ctx context.Context) (func() ([]*loggregator_v2.Envelope, error), error) {
+	rx, err := s.client.BatchedReceiver(ctx, s.req)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() ([]*loggregator_v2.Envelope, error) {
+		batch, err := rx.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		return batch.Batch, nil
+	}, nil
+}
+
+func (s *stream) receiver(ctx context.Context) (func() ([]*loggregator_v2.Envelope, error), error) {
+	rx, err := s.client.Receiver(ctx, &loggregator_v2.EgressRequest{
+		ShardId:           s.req.GetShardId(),
+		DeterministicName: s.req.GetDeterministicName(),
+		LegacySelector:    s.req.GetLegacySelector(),
+		Selectors:         s.req.GetSelectors(),
+		UsePreferredTags:  s.req.GetUsePreferredTags(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func() ([]*loggregator_v2.Envelope, error) {
+		e, err := rx.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		return []*loggregator_v2.Envelope{e}, nil
+	}, nil
+}
+
+// terminal reports whether the stream has to stop because of the given
+// error. If so, the error is passed to the error handler.
+func (s *stream) terminal(err error) bool {
+	if s.errHandler == nil || IsRetryable(err) || s.ctx.Err() != nil {
+		return false
+	}
+
+	s.log.Printf("Stopping stream after terminal error: %s", err)
+	s.stopped = true
+	s.errHandler(err)
+
+	return true
+}
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
@@ -46,7 +47,7 @@ var _ = Describe("Connector", func() {
 		rx := c.Stream(context.Background(), req)
 
 		Expect(len(rx())).NotTo(BeZero())
-		Expect(producer.actualReq()).To(Equal(req))
+		Expect(proto.Equal(producer.actualReq(), req)).To(BeTrue())
 	})
 
 	It("reconnects if the stream fails", func() {
@@ -90,6 +91,141 @@ var _ = Describe("Connector", func() {
 		Consistently(producer.connectionAttempts).Should(Equal(2))
 	})
 
+	It("stops and reports terminal errors", func() {
+		producer, err := newFakeEventProducer()
+		Expect(err).NotTo(HaveOccurred())
+		producer.failWith(grpc.Errorf(codes.PermissionDenied, "not allowed"))
+		producer.start()
+		defer producer.stop()
+
+		tlsConf, err := NewClientMutualTLSConfig(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+			"metron",
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		errs := make(chan error, 10)
+		c := loggregator.NewEnvelopeStreamConnector(
+			producer.addr,
+			tlsConf,
+			loggregator.WithEnvelopeStreamErrorHandler(func(err error) {
+				errs <- err
+			}),
+		)
+		rx := c.Stream(context.Background(), &loggregator_v2.EgressBatchRequest{})
+
+		Expect(rx()).To(BeNil())
+		Expect(rx()).To(BeNil())
+
+		var terminalErr error
+		Expect(errs).To(Receive(&terminalErr))
+		Expect(loggregator.IsRetryable(terminalErr)).To(BeFalse())
+		Expect(terminalErr.Error()).To(ContainSubstring("not allowed"))
+		Expect(errs).NotTo(Receive())
+		Expect(producer.connectionAttempts()).To(Equal(1))
+	})
+
+	It("retries transient errors", func() {
+		producer, err := newFakeEventProducer()
+		Expect(err).NotTo(HaveOccurred())
+		producer.failWith(grpc.Errorf(codes.Unavailable, "try again"))
+		producer.start()
+		defer producer.stop()
+
+		tlsConf, err := NewClientMutualTLSConfig(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+			"metron",
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		errs := make(chan error, 10)
+		c := loggregator.NewEnvelopeStreamConnector(
+			producer.addr,
+			tlsConf,
+			loggregator.WithEnvelopeStreamErrorHandler(func(err error) {
+				errs <- err
+			}),
+		)
+		go func() {
+			defer GinkgoRecover()
+			rx := c.Stream(context.Background(), &loggregator_v2.EgressBatchRequest{})
+			Expect(rx()).NotTo(BeEmpty())
+		}()
+
+		Eventually(producer.connectionAttempts).Should(BeNumerically(">", 1))
+		producer.failWith(nil)
+		Consistently(errs).ShouldNot(Receive())
+	})
+
+	It("falls back to the unbatched stream", func() {
+		producer, err := newFakeEventProducer()
+		Expect(err).NotTo(HaveOccurred())
+		producer.unbatchedOnly = true
+		producer.start()
+		defer producer.stop()
+
+		tlsConf, err := NewClientMutualTLSConfig(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+			"metron",
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		c := loggregator.NewEnvelopeStreamConnector(
+			producer.addr,
+			tlsConf,
+			loggregator.WithEnvelopeStreamFallback(),
+		)
+		rx := c.Stream(context.Background(), &loggregator_v2.EgressBatchRequest{ShardId: "some-id"})
+
+		batch := rx()
+		Expect(batch).To(HaveLen(1))
+		Expect(batch[0].SourceId).To(Equal("unbatched"))
+		Expect(producer.actualUnbatchedReq().ShardId).To(Equal("some-id"))
+	})
+
+	It("reports gaps in the sequence numbers of received envelopes", func() {
+		producer, err := newFakeEventProducer()
+		Expect(err).NotTo(HaveOccurred())
+		producer.batch = []*loggregator_v2.Envelope{
+			{SourceId: "some-source", Tags: map[string]string{"sequence": "1"}},
+			{SourceId: "some-source", Tags: map[string]string{"sequence": "2"}},
+			{SourceId: "some-source", Tags: map[string]string{"sequence": "5"}},
+		}
+		producer.start()
+		defer producer.stop()
+
+		tlsConf, err := NewClientMutualTLSConfig(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+			"metron",
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		gaps := make(chan loggregator.Gap, 100)
+		c := loggregator.NewEnvelopeStreamConnector(
+			producer.addr,
+			tlsConf,
+			loggregator.WithEnvelopeStreamGapDetector(loggregator.NewGapDetector(func(g loggregator.Gap) {
+				gaps <- g
+			})),
+		)
+		rx := c.Stream(context.Background(), &loggregator_v2.EgressBatchRequest{})
+
+		Expect(rx()).To(HaveLen(3))
+		Expect(gaps).To(Receive(Equal(loggregator.Gap{
+			SourceID: "some-source",
+			From:     3,
+			To:       4,
+		})))
+	})
+
 	It("enables buffering", func() {
 		producer, err := newFakeEventProducer()
 		Expect(err).NotTo(HaveOccurred())
@@ -155,6 +291,16 @@ type fakeEventProducer struct {
 	mu                  sync.Mutex
 	connectionAttempts_ int
 	actualReq_          *loggregator_v2.EgressBatchRequest
+	err                 error
+	unbatchedOnly       bool
+	batch               []*loggregator_v2.Envelope
+	actualUnbatchedReq_ *loggregator_v2.EgressRequest
+}
+
+func (f *fakeEventProducer) failWith(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
 }
 
 func newFakeEventProducer() (*fakeEventProducer, error) {
@@ -164,11 +310,31 @@ func newFakeEventProducer() (*fakeEventProducer, error) {
 }
 
 func (f *fakeEventProducer) Receiver(
-	*loggregator_v2.EgressRequest,
-	loggregator_v2.Egress_ReceiverServer,
+	req *loggregator_v2.EgressRequest,
+	srv loggregator_v2.Egress_ReceiverServer,
 ) error {
+	f.mu.Lock()
+	unbatchedOnly := f.unbatchedOnly
+	f.actualUnbatchedReq_ = req
+	f.mu.Unlock()
 
-	return grpc.Errorf(codes.Unimplemented, "use BatchedReceiver instead")
+	if !unbatchedOnly {
+		return grpc.Errorf(codes.Unimplemented, "use BatchedReceiver instead")
+	}
+
+	for range time.Tick(10 * time.Millisecond) {
+		err := srv.Send(&loggregator_v2.Envelope{SourceId: "unbatched"})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeEventProducer) actualUnbatchedReq() *loggregator_v2.EgressRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.actualUnbatchedReq_
 }
 
 func (f *fakeEventProducer) BatchedReceiver(
@@ -178,9 +344,23 @@ func (f *fakeEventProducer) BatchedReceiver(
 	f.mu.Lock()
 	f.connectionAttempts_++
 	f.actualReq_ = req
+	err := f.err
+	unbatchedOnly := f.unbatchedOnly
 	f.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if unbatchedOnly {
+		return grpc.Errorf(codes.Unimplemented, "use Receiver instead")
+	}
 	var i int
 	for range time.Tick(10 * time.Millisecond) {
+		if f.batch != nil {
+			srv.Send(&loggregator_v2.EnvelopeBatch{Batch: f.batch})
+			continue
+		}
+
 		srv.Send(&loggregator_v2.EnvelopeBatch{
 			Batch: []*loggregator_v2.Envelope{
 				{
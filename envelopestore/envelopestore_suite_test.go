@@ -0,0 +1,13 @@
+package envelopestore_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestEnvelopestore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Envelope Store Suite")
+}
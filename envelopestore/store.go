@@ -0,0 +1,146 @@
+// Package envelopestore provides an in-memory store of envelopes with simple
+// query functions. It is meant for debugging tools and tests that capture
+// envelope streams.
+package envelopestore
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// StoreOption configures a Store.
+type StoreOption func(*Store)
+
+// WithMaxEnvelopes limits the number of envelopes kept by the store. When
+// the limit is exceeded, the oldest envelopes are evicted. It defaults to
+// 10000.
+func WithMaxEnvelopes(n int) StoreOption {
+	return func(s *Store) {
+		s.max = n
+	}
+}
+
+// Store keeps envelopes in memory. It is safe for concurrent use. It should
+// be created with the New constructor.
+type Store struct {
+	max int
+
+	mu        sync.RWMutex
+	envelopes []*loggregator_v2.Envelope
+}
+
+// New creates an empty Store.
+func New(opts ...StoreOption) *Store {
+	s := &Store{
+		max: 10000,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	return s
+}
+
+// Add stores the envelopes.
+func (s *Store) Add(envelopes ...*loggregator_v2.Envelope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.envelopes = append(s.envelopes, envelopes...)
+	if len(s.envelopes) > s.max {
+		s.envelopes = append([]*loggregator_v2.Envelope(nil), s.envelopes[len(s.envelopes)-s.max:]...)
+	}
+}
+
+// Len returns the number of stored envelopes.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.envelopes)
+}
+
+// Select returns the stored envelopes with a timestamp in [start, end) for
+// which match returns true. A nil match selects every envelope.
+func (s *Store) Select(start, end time.Time, match func(*loggregator_v2.Envelope) bool) []*loggregator_v2.Envelope {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*loggregator_v2.Envelope
+	for _, e := range s.envelopes {
+		if e.GetTimestamp() < start.UnixNano() || e.GetTimestamp() >= end.UnixNano() {
+			continue
+		}
+
+		if match != nil && !match(e) {
+			continue
+		}
+
+		result = append(result, e)
+	}
+
+	return result
+}
+
+// Rate returns the per-second rate of the counter with the given source ID
+// and name over [start, end). It is the sum of the counter's deltas divided
+// by the length of the range.
+func (s *Store) Rate(sourceID, name string, start, end time.Time) float64 {
+	seconds := end.Sub(start).Seconds()
+	if seconds <= 0 {
+		return 0
+	}
+
+	counters := s.Select(start, end, func(e *loggregator_v2.Envelope) bool {
+		return e.GetSourceId() == sourceID && e.GetCounter() != nil && e.GetCounter().GetName() == name
+	})
+
+	var sum uint64
+	for _, e := range counters {
+		sum += e.GetCounter().GetDelta()
+	}
+
+	return float64(sum) / seconds
+}
+
+// SourceCount is the number of envelopes of a source.
+type SourceCount struct {
+	SourceID string
+	Count    int
+}
+
+// TopKSourcesByLogVolume returns the k sources that emitted the most logs
+// in [start, end), ordered by descending count. Sources with the same count
+// are ordered by source ID.
+func (s *Store) TopKSourcesByLogVolume(k int, start, end time.Time) []SourceCount {
+	logs := s.Select(start, end, func(e *loggregator_v2.Envelope) bool {
+		return e.GetLog() != nil
+	})
+
+	counts := make(map[string]int)
+	for _, e := range logs {
+		counts[e.GetSourceId()]++
+	}
+
+	result := make([]SourceCount, 0, len(counts))
+	for id, n := range counts {
+		result = append(result, SourceCount{SourceID: id, Count: n})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].SourceID < result[j].SourceID
+	})
+
+	if len(result) > k {
+		result = result[:k]
+	}
+
+	return result
+}
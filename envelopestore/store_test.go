@@ -0,0 +1,94 @@
+package envelopestore_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/envelopestore"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Store", func() {
+	var (
+		s     *envelopestore.Store
+		start time.Time
+	)
+
+	BeforeEach(func() {
+		s = envelopestore.New()
+		start = time.Unix(1000, 0)
+	})
+
+	It("selects envelopes in the time range", func() {
+		s.Add(
+			logAt("a", start.Add(-time.Second)),
+			logAt("b", start),
+			logAt("c", start.Add(9*time.Second)),
+			logAt("d", start.Add(10*time.Second)),
+		)
+
+		envelopes := s.Select(start, start.Add(10*time.Second), nil)
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].SourceId).To(Equal("b"))
+		Expect(envelopes[1].SourceId).To(Equal("c"))
+	})
+
+	It("evicts the oldest envelopes", func() {
+		s = envelopestore.New(envelopestore.WithMaxEnvelopes(2))
+		s.Add(logAt("a", start), logAt("b", start), logAt("c", start))
+
+		Expect(s.Len()).To(Equal(2))
+		Expect(s.Select(start, start.Add(time.Second), nil)[0].SourceId).To(Equal("b"))
+	})
+
+	It("computes the rate of a counter", func() {
+		s.Add(
+			counterAt("a", "requests", 10, start),
+			counterAt("a", "requests", 20, start.Add(5*time.Second)),
+			counterAt("a", "errors", 100, start.Add(5*time.Second)),
+			counterAt("b", "requests", 100, start.Add(5*time.Second)),
+		)
+
+		Expect(s.Rate("a", "requests", start, start.Add(10*time.Second))).To(Equal(3.0))
+	})
+
+	It("returns the top k sources by log volume", func() {
+		s.Add(
+			logAt("a", start),
+			logAt("b", start),
+			logAt("b", start),
+			logAt("c", start),
+			logAt("c", start),
+			logAt("c", start),
+			counterAt("a", "requests", 1, start),
+			counterAt("a", "requests", 1, start),
+		)
+
+		Expect(s.TopKSourcesByLogVolume(2, start, start.Add(time.Second))).To(Equal([]envelopestore.SourceCount{
+			{SourceID: "c", Count: 3},
+			{SourceID: "b", Count: 2},
+		}))
+	})
+})
+
+func logAt(sourceID string, t time.Time) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId:  sourceID,
+		Timestamp: t.UnixNano(),
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{Payload: []byte("message")},
+		},
+	}
+}
+
+func counterAt(sourceID, name string, delta uint64, t time.Time) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId:  sourceID,
+		Timestamp: t.UnixNano(),
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: name, Delta: delta},
+		},
+	}
+}
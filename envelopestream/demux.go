@@ -0,0 +1,156 @@
+package envelopestream
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// Matcher reports whether an envelope should be routed to a consumer.
+type Matcher func(*loggregator_v2.Envelope) bool
+
+// MessageType identifies the type of message an envelope carries.
+type MessageType int
+
+// The message types of an envelope.
+const (
+	Log MessageType = iota
+	Counter
+	Gauge
+	Timer
+	Event
+)
+
+// ByType returns a Matcher that matches envelopes of any of the given types.
+func ByType(types ...MessageType) Matcher {
+	return func(e *loggregator_v2.Envelope) bool {
+		for _, t := range types {
+			if messageType(e) == t {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// BySourceID returns a Matcher that matches envelopes of any of the given
+// source IDs.
+func BySourceID(sourceIDs ...string) Matcher {
+	return func(e *loggregator_v2.Envelope) bool {
+		for _, id := range sourceIDs {
+			if e.GetSourceId() == id {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func messageType(e *loggregator_v2.Envelope) MessageType {
+	switch e.GetMessage().(type) {
+	case *loggregator_v2.Envelope_Log:
+		return Log
+	case *loggregator_v2.Envelope_Counter:
+		return Counter
+	case *loggregator_v2.Envelope_Gauge:
+		return Gauge
+	case *loggregator_v2.Envelope_Timer:
+		return Timer
+	case *loggregator_v2.Envelope_Event:
+		return Event
+	default:
+		return -1
+	}
+}
+
+// DemuxOption configures a Demux.
+type DemuxOption func(*Demux)
+
+// WithDropAlerter configures a function that is invoked with the number of
+// dropped envelopes whenever a consumer's buffer is full.
+func WithDropAlerter(alerter func(missed int)) DemuxOption {
+	return func(d *Demux) {
+		d.alerter = alerter
+	}
+}
+
+type route struct {
+	match Matcher
+	out   chan *loggregator_v2.Envelope
+}
+
+// Demux splits a channel of envelopes into a channel per consumer. Every
+// consumer has a bounded buffer. When a consumer does not keep up and its
+// buffer is full, envelopes for that consumer are dropped so that other
+// consumers are not affected. It should be created with the NewDemux
+// constructor.
+type Demux struct {
+	bufferSize int
+	alerter    func(int)
+	routes     []route
+}
+
+// NewDemux creates a Demux whose consumer channels buffer up to bufferSize
+// envelopes.
+func NewDemux(bufferSize int, opts ...DemuxOption) *Demux {
+	d := &Demux{
+		bufferSize: bufferSize,
+	}
+
+	for _, o := range opts {
+		o(d)
+	}
+
+	return d
+}
+
+// Route returns a channel that receives every envelope that matches. An
+// envelope that matches several routes is sent to each of them. Route must
+// be called before Run.
+func (d *Demux) Route(match Matcher) <-chan *loggregator_v2.Envelope {
+	out := make(chan *loggregator_v2.Envelope, d.bufferSize)
+	d.routes = append(d.routes, route{match: match, out: out})
+
+	return out
+}
+
+// Run reads envelopes from in and routes them to the consumers. It blocks
+// until in is closed or the context is done and then closes every consumer
+// channel.
+func (d *Demux) Run(ctx context.Context, in <-chan *loggregator_v2.Envelope) {
+	defer func() {
+		for _, r := range d.routes {
+			close(r.out)
+		}
+	}()
+
+	for {
+		select {
+		case e, ok := <-in:
+			if !ok {
+				return
+			}
+			d.route(e)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *Demux) route(e *loggregator_v2.Envelope) {
+	for _, r := range d.routes {
+		if !r.match(e) {
+			continue
+		}
+
+		select {
+		case r.out <- e:
+		default:
+			if d.alerter != nil {
+				d.alerter(1)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package envelopestream_test
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/go-loggregator/envelopestream"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Demux", func() {
+	var (
+		in chan *loggregator_v2.Envelope
+	)
+
+	BeforeEach(func() {
+		in = make(chan *loggregator_v2.Envelope, 10)
+	})
+
+	It("routes envelopes by type and source ID", func() {
+		d := envelopestream.NewDemux(10)
+		logs := d.Route(envelopestream.ByType(envelopestream.Log))
+		sourceA := d.Route(envelopestream.BySourceID("a"))
+
+		in <- &loggregator_v2.Envelope{
+			SourceId: "a",
+			Message:  &loggregator_v2.Envelope_Log{Log: &loggregator_v2.Log{}},
+		}
+		in <- &loggregator_v2.Envelope{
+			SourceId: "b",
+			Message:  &loggregator_v2.Envelope_Counter{Counter: &loggregator_v2.Counter{}},
+		}
+		in <- &loggregator_v2.Envelope{
+			SourceId: "a",
+			Message:  &loggregator_v2.Envelope_Gauge{Gauge: &loggregator_v2.Gauge{}},
+		}
+		close(in)
+
+		d.Run(context.Background(), in)
+
+		Expect(sourceIDs(logs)).To(Equal([]string{"a"}))
+		Expect(sourceIDs(sourceA)).To(Equal([]string{"a", "a"}))
+	})
+
+	It("drops envelopes for consumers whose buffer is full", func() {
+		var missed int
+		d := envelopestream.NewDemux(1, envelopestream.WithDropAlerter(func(n int) {
+			missed += n
+		}))
+		slow := d.Route(envelopestream.BySourceID("a"))
+		fast := d.Route(envelopestream.BySourceID("a"))
+
+		for i := 0; i < 3; i++ {
+			in <- &loggregator_v2.Envelope{SourceId: "a"}
+		}
+		close(in)
+
+		done := make(chan struct{})
+		var received []string
+		go func() {
+			defer close(done)
+			received = sourceIDs(fast)
+		}()
+
+		d.Run(context.Background(), in)
+
+		Eventually(done).Should(BeClosed())
+		Expect(sourceIDs(slow)).To(HaveLen(1))
+		Expect(missed).To(Equal(3 - 1 + 3 - len(received)))
+	})
+
+	It("closes consumer channels once the context is done", func() {
+		d := envelopestream.NewDemux(1)
+		out := d.Route(envelopestream.ByType(envelopestream.Event))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		d.Run(ctx, in)
+
+		Expect(out).To(BeClosed())
+	})
+})
+
+func sourceIDs(c <-chan *loggregator_v2.Envelope) []string {
+	var ids []string
+	for e := range c {
+		ids = append(ids, e.SourceId)
+	}
+
+	return ids
+}
@@ -0,0 +1,82 @@
+// Package envelopestream provides utilities to build pipelines on top of the
+// envelopes read from Loggregator. Streams can be merged into a single
+// channel and a channel can be split into per-consumer channels.
+package envelopestream
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// FromStream reads the given EnvelopeStream into a channel. The channel is
+// closed once the context is done.
+func FromStream(ctx context.Context, s loggregator.EnvelopeStream) <-chan *loggregator_v2.Envelope {
+	out := make(chan *loggregator_v2.Envelope)
+
+	go func() {
+		defer close(out)
+
+		for {
+			for _, e := range s() {
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+	}()
+
+	return out
+}
+
+// Merge returns a channel that receives the envelopes of every given
+// channel. The channel is closed once every given channel is closed or the
+// context is done.
+func Merge(ctx context.Context, in ...<-chan *loggregator_v2.Envelope) <-chan *loggregator_v2.Envelope {
+	out := make(chan *loggregator_v2.Envelope)
+
+	var wg sync.WaitGroup
+	wg.Add(len(in))
+	for _, c := range in {
+		go func(c <-chan *loggregator_v2.Envelope) {
+			defer wg.Done()
+
+			for {
+				var e *loggregator_v2.Envelope
+				var ok bool
+
+				select {
+				case e, ok = <-c:
+					if !ok {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(c)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
@@ -0,0 +1,13 @@
+package envelopestream_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestEnvelopestream(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Envelope Stream Suite")
+}
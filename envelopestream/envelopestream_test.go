@@ -0,0 +1,59 @@
+package envelopestream_test
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/go-loggregator/envelopestream"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Merge", func() {
+	It("merges the envelopes of every channel", func() {
+		a := make(chan *loggregator_v2.Envelope, 1)
+		b := make(chan *loggregator_v2.Envelope, 1)
+		a <- &loggregator_v2.Envelope{SourceId: "a"}
+		b <- &loggregator_v2.Envelope{SourceId: "b"}
+		close(a)
+		close(b)
+
+		var ids []string
+		for e := range envelopestream.Merge(context.Background(), a, b) {
+			ids = append(ids, e.SourceId)
+		}
+
+		Expect(ids).To(ConsistOf("a", "b"))
+	})
+
+	It("closes the channel once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := envelopestream.Merge(ctx, make(chan *loggregator_v2.Envelope))
+
+		cancel()
+
+		Eventually(out).Should(BeClosed())
+	})
+})
+
+var _ = Describe("FromStream", func() {
+	It("reads the envelopes of the stream into a channel", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := func() []*loggregator_v2.Envelope {
+			return []*loggregator_v2.Envelope{{SourceId: "a"}, {SourceId: "b"}}
+		}
+		out := envelopestream.FromStream(ctx, s)
+
+		var e *loggregator_v2.Envelope
+		Eventually(out).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("a"))
+		Eventually(out).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("b"))
+
+		cancel()
+		Eventually(out).Should(BeClosed())
+	})
+})
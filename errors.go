@@ -0,0 +1,46 @@
+package loggregator
+
+import "errors"
+
+var (
+	// ErrTimeout is reported when an envelope could not be handed to the
+	// sender within the configured send timeout, e.g. because the sender is
+	// stuck establishing a stream.
+	ErrTimeout = errors.New("loggregator: timed out sending envelope")
+
+	// ErrNotConnected is reported when a stream to the agent could not be
+	// established.
+	ErrNotConnected = errors.New("loggregator: not connected")
+
+	// ErrQueueFull is reported when an envelope is dropped because the
+	// client's buffer is full and the client is configured to not block.
+	ErrQueueFull = errors.New("loggregator: queue full")
+
+	// ErrEnvelopeTooLarge is reported when an envelope exceeds the maximum
+	// envelope size and is therefore dropped.
+	ErrEnvelopeTooLarge = errors.New("loggregator: envelope too large")
+
+	// ErrClosed is reported when an envelope is emitted after CloseSend has
+	// been called, or when CloseSend is called more than once.
+	ErrClosed = errors.New("loggregator: client closed")
+
+	// ErrCircuitOpen is reported when an envelope is dropped because the
+	// client's circuit breaker is open.
+	ErrCircuitOpen = errors.New("loggregator: circuit breaker open")
+
+	// ErrRateLimited is reported when an envelope is dropped by a
+	// RateLimitMiddleware.
+	ErrRateLimited = errors.New("loggregator: rate limited")
+
+	// ErrUnknownUnit is reported when a gauge is dropped by a strict
+	// GaugeUnitMiddleware because one of its units is unknown.
+	ErrUnknownUnit = errors.New("loggregator: unknown gauge unit")
+
+	// ErrEnvelopeExpired is reported when an envelope is dropped because
+	// it exceeded the age configured with WithMaxEnvelopeAge.
+	ErrEnvelopeExpired = errors.New("loggregator: envelope expired")
+
+	// ErrDrainTimeout is returned by FlushOnSignal when the buffered
+	// envelopes could not be sent within the drain timeout.
+	ErrDrainTimeout = errors.New("loggregator: timed out draining envelopes")
+)
@@ -0,0 +1,215 @@
+// Package eventlogemitter provides an emitter that subscribes to Windows
+// Event Log channels, e.g. Application or System, and emits their records as
+// logs with level and provider tags. It is meant for Windows Diego cells and
+// .NET apps. Run is only supported on Windows.
+package eventlogemitter
+
+import (
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// ErrUnsupported is returned by Run on platforms without an event log.
+var ErrUnsupported = errors.New("eventlogemitter: the event log is only supported on windows")
+
+// levels are the names of the standard event levels.
+var levels = map[int]string{
+	0: "information",
+	1: "critical",
+	2: "error",
+	3: "warning",
+	4: "information",
+	5: "verbose",
+}
+
+// errLevel is the lowest level that is emitted as stderr.
+const errLevel = 2
+
+// Sender is the interface of the client that can be used to emit the
+// records.
+type Sender interface {
+	EmitEnvelope(*loggregator_v2.Envelope) error
+}
+
+// Emitter subscribes to event log channels and emits their records via the
+// sender. It should be created with the New constructor.
+type Emitter struct {
+	sender       Sender
+	channels     []string
+	query        string
+	sourceID     string
+	tags         map[string]string
+	pollInterval time.Duration
+}
+
+// EventLogEmitterOption is the option that provides configuration for an
+// Emitter.
+type EventLogEmitterOption func(e *Emitter)
+
+// WithQuery returns an EventLogEmitterOption that filters the records with
+// an XPath query, e.g. "*[System[Level<=3]]". It defaults to every record.
+func WithQuery(query string) EventLogEmitterOption {
+	return func(e *Emitter) {
+		e.query = query
+	}
+}
+
+// WithSourceID returns an EventLogEmitterOption for setting the source ID of
+// the logs. By default, the source ID is the provider of the record.
+func WithSourceID(id string) EventLogEmitterOption {
+	return func(e *Emitter) {
+		e.sourceID = id
+	}
+}
+
+// WithTags returns an EventLogEmitterOption that adds the given tags to
+// every log.
+func WithTags(tags map[string]string) EventLogEmitterOption {
+	return func(e *Emitter) {
+		for k, v := range tags {
+			e.tags[k] = v
+		}
+	}
+}
+
+// WithPollInterval returns an EventLogEmitterOption to configure how long
+// Run waits for new records before it checks whether its context is done.
+// It defaults to 500 milliseconds.
+func WithPollInterval(d time.Duration) EventLogEmitterOption {
+	return func(e *Emitter) {
+		e.pollInterval = d
+	}
+}
+
+// New returns an Emitter that emits the records added to the given channels
+// via the sender.
+func New(sender Sender, channels []string, opts ...EventLogEmitterOption) *Emitter {
+	e := &Emitter{
+		sender:       sender,
+		channels:     channels,
+		query:        "*",
+		tags:         make(map[string]string),
+		pollInterval: 500 * time.Millisecond,
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+// Record is an event log record.
+type Record struct {
+	Channel  string
+	Provider string
+	EventID  int
+	Level    int
+	Time     time.Time
+	Computer string
+	Message  string
+}
+
+// eventXML is the XML representation of an event as rendered by the event
+// log.
+type eventXML struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		EventID     int `xml:"EventID"`
+		Level       int `xml:"Level"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+		Channel  string `xml:"Channel"`
+		Computer string `xml:"Computer"`
+	} `xml:"System"`
+	EventData struct {
+		Data []string `xml:"Data"`
+	} `xml:"EventData"`
+	RenderingInfo struct {
+		Message string `xml:"Message"`
+	} `xml:"RenderingInfo"`
+}
+
+// ParseRecord parses the XML representation of an event. Without a rendered
+// message, the message is made of the event's data.
+func ParseRecord(b []byte) (Record, error) {
+	var x eventXML
+	if err := xml.Unmarshal(b, &x); err != nil {
+		return Record{}, err
+	}
+
+	r := Record{
+		Channel:  x.System.Channel,
+		Provider: x.System.Provider.Name,
+		EventID:  x.System.EventID,
+		Level:    x.System.Level,
+		Computer: x.System.Computer,
+		Message:  strings.TrimSpace(x.RenderingInfo.Message),
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, x.System.TimeCreated.SystemTime); err == nil {
+		r.Time = t
+	}
+
+	if r.Message == "" {
+		r.Message = strings.Join(x.EventData.Data, ", ")
+	}
+
+	return r, nil
+}
+
+// Envelope converts the record to a log envelope.
+func (r Record) Envelope() *loggregator_v2.Envelope {
+	level, ok := levels[r.Level]
+	if !ok {
+		level = strconv.Itoa(r.Level)
+	}
+
+	e := &loggregator_v2.Envelope{
+		Timestamp: r.Time.UnixNano(),
+		SourceId:  r.Provider,
+		Tags: map[string]string{
+			"channel":  r.Channel,
+			"provider": r.Provider,
+			"level":    level,
+			"event_id": strconv.Itoa(r.EventID),
+		},
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{
+				Payload: []byte(r.Message),
+				Type:    loggregator_v2.Log_OUT,
+			},
+		},
+	}
+	if r.Time.IsZero() {
+		e.Timestamp = time.Now().UnixNano()
+	}
+	if r.Computer != "" {
+		e.Tags["computer"] = r.Computer
+	}
+	if r.Level > 0 && r.Level <= errLevel {
+		e.GetLog().Type = loggregator_v2.Log_ERR
+	}
+
+	return e
+}
+
+func (e *Emitter) emit(r Record) {
+	env := r.Envelope()
+	if e.sourceID != "" {
+		env.SourceId = e.sourceID
+	}
+	for k, v := range e.tags {
+		env.Tags[k] = v
+	}
+
+	e.sender.EmitEnvelope(env)
+}
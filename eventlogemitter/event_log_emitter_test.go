@@ -0,0 +1,109 @@
+package eventlogemitter_test
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/eventlogemitter"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const serviceEvent = `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>
+  <System>
+    <Provider Name='Service Control Manager' Guid='{555908d1-a6d7-4695-8e1e-26931d2012f4}'/>
+    <EventID Qualifiers='16384'>7036</EventID>
+    <Level>4</Level>
+    <TimeCreated SystemTime='2024-01-02T03:04:05.123456700Z'/>
+    <Channel>System</Channel>
+    <Computer>cell-1</Computer>
+  </System>
+  <EventData>
+    <Data Name='param1'>Windows Update</Data>
+    <Data Name='param2'>running</Data>
+  </EventData>
+</Event>`
+
+const renderedEvent = `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>
+  <System>
+    <Provider Name='.NET Runtime'/>
+    <EventID>1026</EventID>
+    <Level>2</Level>
+    <TimeCreated SystemTime='2024-01-02T03:04:05Z'/>
+    <Channel>Application</Channel>
+  </System>
+  <RenderingInfo Culture='en-US'>
+    <Message>Application: app.exe
+Exception Info: System.Exception</Message>
+  </RenderingInfo>
+</Event>`
+
+var _ = Describe("Record", func() {
+	It("parses the XML of an event", func() {
+		r, err := eventlogemitter.ParseRecord([]byte(serviceEvent))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r).To(Equal(eventlogemitter.Record{
+			Channel:  "System",
+			Provider: "Service Control Manager",
+			EventID:  7036,
+			Level:    4,
+			Time:     time.Date(2024, 1, 2, 3, 4, 5, 123456700, time.UTC),
+			Computer: "cell-1",
+			Message:  "Windows Update, running",
+		}))
+	})
+
+	It("prefers the rendered message", func() {
+		r, err := eventlogemitter.ParseRecord([]byte(renderedEvent))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Message).To(Equal("Application: app.exe\nException Info: System.Exception"))
+	})
+
+	It("rejects malformed XML", func() {
+		_, err := eventlogemitter.ParseRecord([]byte("<Event>"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("converts records to log envelopes", func() {
+		r, err := eventlogemitter.ParseRecord([]byte(serviceEvent))
+		Expect(err).NotTo(HaveOccurred())
+
+		e := r.Envelope()
+		Expect(e.GetSourceId()).To(Equal("Service Control Manager"))
+		Expect(e.GetTimestamp()).To(Equal(r.Time.UnixNano()))
+		Expect(string(e.GetLog().GetPayload())).To(Equal("Windows Update, running"))
+		Expect(e.GetLog().GetType()).To(Equal(loggregator_v2.Log_OUT))
+		Expect(e.GetTags()).To(Equal(map[string]string{
+			"channel":  "System",
+			"provider": "Service Control Manager",
+			"level":    "information",
+			"event_id": "7036",
+			"computer": "cell-1",
+		}))
+	})
+
+	It("emits errors and critical records as stderr", func() {
+		r, err := eventlogemitter.ParseRecord([]byte(renderedEvent))
+		Expect(err).NotTo(HaveOccurred())
+
+		e := r.Envelope()
+		Expect(e.GetLog().GetType()).To(Equal(loggregator_v2.Log_ERR))
+		Expect(e.GetTags()).To(HaveKeyWithValue("level", "error"))
+	})
+})
+
+var _ = Describe("Emitter", func() {
+	It("is only supported on windows", func() {
+		if runtime.GOOS == "windows" {
+			Skip("the event log is supported")
+		}
+
+		e := eventlogemitter.New(nil, []string{"Application"})
+		Expect(e.Run(context.Background())).To(MatchError(eventlogemitter.ErrUnsupported))
+	})
+})
@@ -0,0 +1,13 @@
+package eventlogemitter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestEventlogemitter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Event Log Emitter Suite")
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package eventlogemitter
+
+import "context"
+
+// Run returns ErrUnsupported.
+func (e *Emitter) Run(ctx context.Context) error {
+	return ErrUnsupported
+}
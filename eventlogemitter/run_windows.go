@@ -0,0 +1,235 @@
+//go:build windows
+// +build windows
+
+package eventlogemitter
+
+import (
+	"context"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	wevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtSubscribe             = wevtapi.NewProc("EvtSubscribe")
+	procEvtNext                  = wevtapi.NewProc("EvtNext")
+	procEvtRender                = wevtapi.NewProc("EvtRender")
+	procEvtClose                 = wevtapi.NewProc("EvtClose")
+	procEvtOpenPublisherMetadata = wevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtFormatMessage         = wevtapi.NewProc("EvtFormatMessage")
+)
+
+const (
+	evtSubscribeToFutureEvents = 1
+	evtRenderEventXML          = 1
+	evtFormatMessageEvent      = 1
+)
+
+type evtHandle uintptr
+
+// Run subscribes to the channels and emits the records added to them until
+// the context is done.
+func (e *Emitter) Run(ctx context.Context) error {
+	signal, err := windows.CreateEvent(nil, 1, 1, nil)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(signal)
+
+	var subs []evtHandle
+	defer func() {
+		for _, s := range subs {
+			evtClose(s)
+		}
+	}()
+	for _, c := range e.channels {
+		s, err := evtSubscribe(signal, c, e.query)
+		if err != nil {
+			return err
+		}
+		subs = append(subs, s)
+	}
+
+	publishers := make(map[string]evtHandle)
+	defer func() {
+		for _, p := range publishers {
+			evtClose(p)
+		}
+	}()
+
+	events := make([]evtHandle, 16)
+	for {
+		if err := windows.ResetEvent(signal); err != nil {
+			return err
+		}
+
+		for _, s := range subs {
+			if err := e.drain(s, events, publishers); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		if _, err := windows.WaitForSingleObject(signal, uint32(e.pollInterval.Milliseconds())); err != nil {
+			return err
+		}
+	}
+}
+
+// drain emits the records that are available on the subscription.
+func (e *Emitter) drain(sub evtHandle, events []evtHandle, publishers map[string]evtHandle) error {
+	for {
+		n, err := evtNext(sub, events)
+		if err == windows.ERROR_NO_MORE_ITEMS {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, ev := range events[:n] {
+			if r, ok := render(ev, publishers); ok {
+				e.emit(r)
+			}
+			evtClose(ev)
+		}
+	}
+}
+
+// render renders the event as a record. If the rendered event has no
+// message, the message is formatted with the provider's message table.
+func render(ev evtHandle, publishers map[string]evtHandle) (Record, bool) {
+	b, err := evtRender(ev)
+	if err != nil {
+		return Record{}, false
+	}
+
+	r, err := ParseRecord(b)
+	if err != nil {
+		return Record{}, false
+	}
+
+	if p, ok := publisher(r.Provider, publishers); ok {
+		if msg, err := evtFormatMessage(p, ev); err == nil && msg != "" {
+			r.Message = msg
+		}
+	}
+
+	return r, true
+}
+
+func publisher(name string, publishers map[string]evtHandle) (evtHandle, bool) {
+	if p, ok := publishers[name]; ok {
+		return p, p != 0
+	}
+
+	p, _ := evtOpenPublisherMetadata(name)
+	publishers[name] = p
+
+	return p, p != 0
+}
+
+func evtSubscribe(signal windows.Handle, channel, query string) (evtHandle, error) {
+	c, err := windows.UTF16PtrFromString(channel)
+	if err != nil {
+		return 0, err
+	}
+	q, err := windows.UTF16PtrFromString(query)
+	if err != nil {
+		return 0, err
+	}
+
+	r, _, err := procEvtSubscribe.Call(
+		0,
+		uintptr(signal),
+		uintptr(unsafe.Pointer(c)),
+		uintptr(unsafe.Pointer(q)),
+		0,
+		0,
+		0,
+		evtSubscribeToFutureEvents,
+	)
+	if r == 0 {
+		return 0, err
+	}
+
+	return evtHandle(r), nil
+}
+
+func evtNext(sub evtHandle, events []evtHandle) (int, error) {
+	var returned uint32
+	r, _, err := procEvtNext.Call(
+		uintptr(sub),
+		uintptr(len(events)),
+		uintptr(unsafe.Pointer(&events[0])),
+		0,
+		0,
+		uintptr(unsafe.Pointer(&returned)),
+	)
+	if r == 0 {
+		return 0, err
+	}
+
+	return int(returned), nil
+}
+
+func evtRender(ev evtHandle) ([]byte, error) {
+	var used, count uint32
+	r, _, err := procEvtRender.Call(0, uintptr(ev), evtRenderEventXML, 0, 0,
+		uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&count)))
+	if r == 0 && err != windows.ERROR_INSUFFICIENT_BUFFER {
+		return nil, err
+	}
+
+	buf := make([]uint16, used/2+1)
+	r, _, err = procEvtRender.Call(0, uintptr(ev), evtRenderEventXML, uintptr(len(buf)*2),
+		uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&count)))
+	if r == 0 {
+		return nil, err
+	}
+
+	return []byte(windows.UTF16ToString(buf)), nil
+}
+
+func evtOpenPublisherMetadata(name string) (evtHandle, error) {
+	n, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+
+	r, _, err := procEvtOpenPublisherMetadata.Call(0, uintptr(unsafe.Pointer(n)), 0, 0, 0)
+	if r == 0 {
+		return 0, err
+	}
+
+	return evtHandle(r), nil
+}
+
+func evtFormatMessage(publisher, ev evtHandle) (string, error) {
+	var used uint32
+	r, _, err := procEvtFormatMessage.Call(uintptr(publisher), uintptr(ev), 0, 0, 0,
+		evtFormatMessageEvent, 0, 0, uintptr(unsafe.Pointer(&used)))
+	if r == 0 && err != windows.ERROR_INSUFFICIENT_BUFFER {
+		return "", err
+	}
+
+	buf := make([]uint16, used+1)
+	r, _, err = procEvtFormatMessage.Call(uintptr(publisher), uintptr(ev), 0, 0, 0,
+		evtFormatMessageEvent, uintptr(len(buf)), uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&used)))
+	if r == 0 {
+		return "", err
+	}
+
+	return windows.UTF16ToString(buf), nil
+}
+
+func evtClose(h evtHandle) {
+	procEvtClose.Call(uintptr(h))
+}
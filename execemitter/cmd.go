@@ -0,0 +1,158 @@
+// Package execemitter runs commands and reports them to Loggregator. The
+// output of a command is emitted as logs, its exit code as a counter and its
+// run time as a gauge. It is meant for components that run other programs,
+// e.g. lifecycle or buildpack components.
+package execemitter
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/units"
+)
+
+// The names of the metrics emitted for every command.
+const (
+	ExitsCounterName  = "command_exits"
+	DurationGaugeName = "command_duration"
+)
+
+// Sender is the interface of the client the command is reported with.
+type Sender interface {
+	EmitLog(message string, opts ...loggregator.EmitLogOption)
+	EmitCounter(name string, opts ...loggregator.EmitCounterOption)
+	EmitGauge(opts ...loggregator.EmitGaugeOption)
+}
+
+// CmdOption configures a Cmd.
+type CmdOption func(*Cmd)
+
+// WithSourceInfo configures the source of the logs and metrics.
+func WithSourceInfo(sourceID, sourceType, instanceID string) CmdOption {
+	return func(c *Cmd) {
+		c.sourceID = sourceID
+		c.sourceType = sourceType
+		c.instanceID = instanceID
+	}
+}
+
+// WithName configures the value of the command tag. It defaults to the base
+// name of the command's path.
+func WithName(name string) CmdOption {
+	return func(c *Cmd) {
+		c.name = name
+	}
+}
+
+// Cmd is an exec.Cmd whose output and result are reported. It should be
+// created with the Command constructor.
+type Cmd struct {
+	*exec.Cmd
+
+	sender     Sender
+	name       string
+	sourceID   string
+	sourceType string
+	instanceID string
+
+	stdout  *loggregator.LogWriter
+	stderr  *loggregator.LogWriter
+	started time.Time
+}
+
+// Command returns a Cmd that reports cmd. The command's Stdout and Stderr,
+// if set, still receive the output.
+func Command(sender Sender, cmd *exec.Cmd, opts ...CmdOption) *Cmd {
+	c := &Cmd{
+		Cmd:    cmd,
+		sender: sender,
+		name:   filepath.Base(cmd.Path),
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	return c
+}
+
+// Start starts the command like exec.Cmd.Start.
+func (c *Cmd) Start() error {
+	c.stdout = loggregator.NewLogWriter(c.sender, c.logOptions(loggregator.WithStdout())...)
+	c.stderr = loggregator.NewLogWriter(c.sender, c.logOptions()...)
+	c.Cmd.Stdout = tee(c.Cmd.Stdout, c.stdout)
+	c.Cmd.Stderr = tee(c.Cmd.Stderr, c.stderr)
+
+	c.started = time.Now()
+	if err := c.Cmd.Start(); err != nil {
+		c.report(err)
+		return err
+	}
+
+	return nil
+}
+
+// Wait waits for the command like exec.Cmd.Wait and reports its exit code
+// and run time.
+func (c *Cmd) Wait() error {
+	err := c.Cmd.Wait()
+	c.report(err)
+
+	return err
+}
+
+// Run starts the command and waits for it to complete.
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	return c.Wait()
+}
+
+func (c *Cmd) report(err error) {
+	c.stdout.Close()
+	c.stderr.Close()
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+
+	c.sender.EmitCounter(ExitsCounterName,
+		loggregator.WithCounterSourceInfo(c.sourceID, c.instanceID),
+		loggregator.WithEnvelopeTag("command", c.name),
+		loggregator.WithEnvelopeTag("exit_code", strconv.Itoa(exitCode)),
+	)
+
+	c.sender.EmitGauge(
+		loggregator.WithGaugeSourceInfo(c.sourceID, c.instanceID),
+		loggregator.WithGaugeValue(DurationGaugeName, float64(time.Since(c.started))/float64(time.Millisecond), units.Millis),
+		loggregator.WithEnvelopeTag("command", c.name),
+	)
+}
+
+func (c *Cmd) logOptions(opts ...loggregator.EmitLogOption) []loggregator.EmitLogOption {
+	return append(opts,
+		loggregator.WithSourceInfo(c.sourceID, c.sourceType, c.instanceID),
+		loggregator.WithEnvelopeTag("command", c.name),
+	)
+}
+
+func tee(w io.Writer, lw *loggregator.LogWriter) io.Writer {
+	if w == nil {
+		return lw
+	}
+
+	return io.MultiWriter(w, lw)
+}
@@ -0,0 +1,152 @@
+package execemitter_test
+
+import (
+	"bytes"
+	"os/exec"
+	"sync"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/execemitter"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/units"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cmd", func() {
+	var spy *spyV2Client
+
+	BeforeEach(func() {
+		spy = &spyV2Client{}
+	})
+
+	It("emits the output as logs", func() {
+		var stdout bytes.Buffer
+		cmd := exec.Command("sh", "-c", "echo out; echo err >&2")
+		cmd.Stdout = &stdout
+
+		c := execemitter.Command(spy, cmd, execemitter.WithSourceInfo("some-source", "STG", "0"))
+		Expect(c.Run()).To(Succeed())
+
+		Expect(stdout.String()).To(Equal("out\n"))
+
+		logs := spy.ofType(envelopeLog)
+		Expect(logs).To(HaveLen(2))
+
+		byType := map[loggregator_v2.Log_Type]*loggregator_v2.Envelope{}
+		for _, e := range logs {
+			byType[e.GetLog().GetType()] = e
+		}
+		Expect(string(byType[loggregator_v2.Log_OUT].GetLog().GetPayload())).To(Equal("out"))
+		Expect(string(byType[loggregator_v2.Log_ERR].GetLog().GetPayload())).To(Equal("err"))
+		Expect(byType[loggregator_v2.Log_OUT].GetSourceId()).To(Equal("some-source"))
+		Expect(byType[loggregator_v2.Log_OUT].GetTags()).To(HaveKeyWithValue("command", "sh"))
+		Expect(byType[loggregator_v2.Log_OUT].GetTags()).To(HaveKeyWithValue("source_type", "STG"))
+	})
+
+	It("emits the exit code and the duration", func() {
+		c := execemitter.Command(spy, exec.Command("sh", "-c", "exit 3"),
+			execemitter.WithName("failing"),
+			execemitter.WithSourceInfo("some-source", "STG", "0"),
+		)
+		err := c.Run()
+		Expect(err).To(HaveOccurred())
+
+		counters := spy.ofType(envelopeCounter)
+		Expect(counters).To(HaveLen(1))
+		Expect(counters[0].GetCounter().GetName()).To(Equal(execemitter.ExitsCounterName))
+		Expect(counters[0].GetCounter().GetDelta()).To(Equal(uint64(1)))
+		Expect(counters[0].GetSourceId()).To(Equal("some-source"))
+		Expect(counters[0].GetTags()).To(HaveKeyWithValue("exit_code", "3"))
+		Expect(counters[0].GetTags()).To(HaveKeyWithValue("command", "failing"))
+
+		gauges := spy.ofType(envelopeGauge)
+		Expect(gauges).To(HaveLen(1))
+		Expect(gauges[0].GetGauge().GetMetrics()).To(HaveKey(execemitter.DurationGaugeName))
+		Expect(gauges[0].GetGauge().GetMetrics()[execemitter.DurationGaugeName].GetUnit()).To(Equal(units.Millis))
+	})
+
+	It("reports commands that fail to start", func() {
+		c := execemitter.Command(spy, exec.Command("/does/not/exist"))
+		Expect(c.Run()).To(HaveOccurred())
+
+		counters := spy.ofType(envelopeCounter)
+		Expect(counters).To(HaveLen(1))
+		Expect(counters[0].GetTags()).To(HaveKeyWithValue("exit_code", "-1"))
+	})
+})
+
+type envelopeType int
+
+const (
+	envelopeLog envelopeType = iota
+	envelopeCounter
+	envelopeGauge
+)
+
+type spyV2Client struct {
+	mu        sync.Mutex
+	envelopes []*loggregator_v2.Envelope
+	types     []envelopeType
+}
+
+func (s *spyV2Client) EmitLog(message string, opts ...loggregator.EmitLogOption) {
+	e := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{Payload: []byte(message), Type: loggregator_v2.Log_ERR},
+		},
+		Tags: make(map[string]string),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	s.add(e, envelopeLog)
+}
+
+func (s *spyV2Client) EmitCounter(name string, opts ...loggregator.EmitCounterOption) {
+	e := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: name, Delta: 1},
+		},
+		Tags: make(map[string]string),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	s.add(e, envelopeCounter)
+}
+
+func (s *spyV2Client) EmitGauge(opts ...loggregator.EmitGaugeOption) {
+	e := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{Metrics: make(map[string]*loggregator_v2.GaugeValue)},
+		},
+		Tags: make(map[string]string),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	s.add(e, envelopeGauge)
+}
+
+func (s *spyV2Client) add(e *loggregator_v2.Envelope, t envelopeType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.envelopes = append(s.envelopes, e)
+	s.types = append(s.types, t)
+}
+
+func (s *spyV2Client) ofType(t envelopeType) []*loggregator_v2.Envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []*loggregator_v2.Envelope
+	for i, e := range s.envelopes {
+		if s.types[i] == t {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,13 @@
+package execemitter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestExecemitter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Exec Emitter Suite")
+}
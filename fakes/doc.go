@@ -0,0 +1,9 @@
+// Package fakes provides fakes of the interfaces of this library for use in
+// the tests of downstream repositories.
+package fakes
+
+//go:generate counterfeiter -o fake_emitter.go -fake-name FakeEmitter code.cloudfoundry.org/go-loggregator.Emitter
+//go:generate counterfeiter -o fake_sink.go -fake-name FakeSink code.cloudfoundry.org/go-loggregator.Sink
+//go:generate counterfeiter -o fake_ingress_client.go -fake-name FakeIngressClient code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2.IngressClient
+//go:generate counterfeiter -o fake_batch_sender_client.go -fake-name FakeBatchSenderClient code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2.Ingress_BatchSenderClient
+//go:generate counterfeiter -o fake_egress_client.go -fake-name FakeEgressClient code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2.EgressClient
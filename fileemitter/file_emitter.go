@@ -0,0 +1,320 @@
+// Package fileemitter provides an emitter that follows log files and emits
+// their lines as logs. Files are found by glob patterns and are followed
+// across rotation and truncation. Lines can be joined into multi-line
+// records, e.g. stack traces. It replaces ad hoc scripts that pipe tail into
+// an agent.
+package fileemitter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+)
+
+// maxRecord is the length after which a record is emitted although it may
+// not be complete.
+const maxRecord = 64 * 1024
+
+// Sender is the interface of the client that can be used to emit the lines
+// as logs.
+type Sender interface {
+	EmitLog(message string, opts ...loggregator.EmitLogOption)
+}
+
+// Emitter follows the files matching its patterns. It should be created
+// with the New constructor.
+type Emitter struct {
+	sender         Sender
+	patterns       []string
+	pollInterval   time.Duration
+	fromStart      bool
+	multilineStart *regexp.Regexp
+	logOpts        []loggregator.EmitLogOption
+
+	files map[string]*tailedFile
+}
+
+// FileEmitterOption is the option that provides configuration for an
+// Emitter.
+type FileEmitterOption func(e *Emitter)
+
+// WithPollInterval returns a FileEmitterOption to configure how often the
+// patterns and files are checked for changes. It defaults to 250
+// milliseconds.
+func WithPollInterval(d time.Duration) FileEmitterOption {
+	return func(e *Emitter) {
+		e.pollInterval = d
+	}
+}
+
+// WithFromStart returns a FileEmitterOption that emits the existing content
+// of the files found when Run starts. By default only the lines added
+// afterwards are emitted. Files created later are always emitted from their
+// start.
+func WithFromStart() FileEmitterOption {
+	return func(e *Emitter) {
+		e.fromStart = true
+	}
+}
+
+// WithMultilineStart returns a FileEmitterOption that joins lines into
+// records. A line matching the pattern starts a new record, every other
+// line is appended to the current one, e.g. the lines of a stack trace. A
+// record is emitted when the next one starts or when no line was added for
+// a poll interval.
+func WithMultilineStart(pattern *regexp.Regexp) FileEmitterOption {
+	return func(e *Emitter) {
+		e.multilineStart = pattern
+	}
+}
+
+// WithLogOptions returns a FileEmitterOption that applies the options to
+// every log, e.g. loggregator.WithSourceInfo or loggregator.WithStdout.
+func WithLogOptions(opts ...loggregator.EmitLogOption) FileEmitterOption {
+	return func(e *Emitter) {
+		e.logOpts = append(e.logOpts, opts...)
+	}
+}
+
+// New returns an Emitter that follows the files matching the glob
+// patterns, see filepath.Match, and emits their lines via the sender.
+func New(sender Sender, patterns []string, opts ...FileEmitterOption) *Emitter {
+	e := &Emitter{
+		sender:       sender,
+		patterns:     patterns,
+		pollInterval: 250 * time.Millisecond,
+		files:        make(map[string]*tailedFile),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+// Run follows the files until the context is done. It returns an error if
+// a pattern is malformed. Before returning, the lines that are left are
+// emitted.
+func (e *Emitter) Run(ctx context.Context) error {
+	for _, p := range e.patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return err
+		}
+	}
+
+	e.poll(!e.fromStart)
+
+	t := time.NewTicker(e.pollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			for path, f := range e.files {
+				e.read(f)
+				e.flush(f)
+				f.close()
+				delete(e.files, path)
+			}
+			return nil
+		case <-t.C:
+			e.poll(false)
+		}
+	}
+}
+
+// tailedFile is the state of a followed file.
+type tailedFile struct {
+	path     string
+	f        *os.File
+	info     os.FileInfo
+	offset   int64
+	partial  []byte
+	record   []string
+	size     int
+	lastData time.Time
+}
+
+func (t *tailedFile) close() {
+	t.f.Close()
+}
+
+// poll finds new and removed files and reads the followed files.
+func (e *Emitter) poll(skipExisting bool) {
+	seen := make(map[string]bool)
+	for _, p := range e.patterns {
+		matches, _ := filepath.Glob(p)
+		for _, path := range matches {
+			seen[path] = true
+
+			t, ok := e.files[path]
+			if !ok {
+				var err error
+				t, err = open(path, skipExisting)
+				if err != nil {
+					continue
+				}
+				e.files[path] = t
+			}
+
+			e.follow(t)
+		}
+	}
+
+	for path, t := range e.files {
+		if seen[path] {
+			continue
+		}
+
+		e.read(t)
+		e.flush(t)
+		t.close()
+		delete(e.files, path)
+	}
+
+	for _, t := range e.files {
+		if len(t.record) > 0 && time.Since(t.lastData) >= e.pollInterval {
+			e.emitRecord(t)
+		}
+	}
+}
+
+func open(path string, seekEnd bool) (*tailedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, os.ErrInvalid
+	}
+
+	t := &tailedFile{path: path, f: f, info: info}
+	if seekEnd {
+		t.offset, err = f.Seek(0, io.SeekEnd)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// follow reads the lines added to the file. If the file was rotated, the
+// rest of the old file is read before the new one is followed. If it was
+// truncated, it is read from the start again.
+func (e *Emitter) follow(t *tailedFile) {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return
+	}
+
+	if !os.SameFile(info, t.info) {
+		e.read(t)
+		e.flush(t)
+		t.close()
+
+		rotated, err := open(t.path, false)
+		if err != nil {
+			return
+		}
+		*t = *rotated
+	} else if info.Size() < t.offset {
+		e.flush(t)
+		if _, err := t.f.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+		t.offset = 0
+	}
+
+	e.read(t)
+}
+
+// read emits the complete lines that can be read from the file.
+func (e *Emitter) read(t *tailedFile) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.f.Read(buf)
+		if n > 0 {
+			t.offset += int64(n)
+			t.lastData = time.Now()
+			e.consume(t, buf[:n])
+		}
+		if err != nil || n == 0 {
+			return
+		}
+	}
+}
+
+func (e *Emitter) consume(t *tailedFile, data []byte) {
+	t.partial = append(t.partial, data...)
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i < 0 {
+			break
+		}
+
+		e.line(t, string(t.partial[:i]))
+		t.partial = t.partial[i+1:]
+	}
+
+	if len(t.partial) >= maxRecord {
+		e.line(t, string(t.partial))
+		t.partial = nil
+	}
+}
+
+func (e *Emitter) line(t *tailedFile, line string) {
+	line = strings.TrimSuffix(line, "\r")
+
+	if e.multilineStart == nil {
+		e.emit(t, line)
+		return
+	}
+
+	if e.multilineStart.MatchString(line) || t.size+len(line) >= maxRecord {
+		e.emitRecord(t)
+	}
+	t.record = append(t.record, line)
+	t.size += len(line) + 1
+}
+
+// flush emits the partial line and the record that are left.
+func (e *Emitter) flush(t *tailedFile) {
+	if len(t.partial) > 0 {
+		e.line(t, string(t.partial))
+		t.partial = nil
+	}
+
+	e.emitRecord(t)
+}
+
+func (e *Emitter) emitRecord(t *tailedFile) {
+	if len(t.record) == 0 {
+		return
+	}
+
+	e.emit(t, strings.Join(t.record, "\n"))
+	t.record = nil
+	t.size = 0
+}
+
+func (e *Emitter) emit(t *tailedFile, message string) {
+	opts := append([]loggregator.EmitLogOption{loggregator.WithEnvelopeTag("file", t.path)}, e.logOpts...)
+	e.sender.EmitLog(message, opts...)
+}
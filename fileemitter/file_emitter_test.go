@@ -0,0 +1,204 @@
+package fileemitter_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/fileemitter"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Emitter", func() {
+	var (
+		dir    string
+		spy    *spySender
+		cancel context.CancelFunc
+		done   chan error
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "fileemitter")
+		Expect(err).NotTo(HaveOccurred())
+
+		spy = &spySender{}
+		cancel = nil
+	})
+
+	AfterEach(func() {
+		if cancel != nil {
+			cancel()
+			Eventually(done).Should(Receive())
+		}
+		os.RemoveAll(dir)
+	})
+
+	run := func(opts ...fileemitter.FileEmitterOption) {
+		opts = append(opts, fileemitter.WithPollInterval(10*time.Millisecond))
+		e := fileemitter.New(spy, []string{filepath.Join(dir, "*.log")}, opts...)
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan error, 1)
+		go func() {
+			done <- e.Run(ctx)
+		}()
+
+		// Let the emitter find the existing files.
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	It("emits the lines appended to existing files", func() {
+		path := filepath.Join(dir, "app.log")
+		write(path, "old\n")
+		run()
+
+		write(path, "first\nsecond\r\n")
+
+		Eventually(spy.messages).Should(Equal([]string{"first", "second"}))
+		Expect(spy.envelopes()[0].GetTags()).To(HaveKeyWithValue("file", path))
+	})
+
+	It("emits existing content when configured", func() {
+		path := filepath.Join(dir, "app.log")
+		write(path, "old\n")
+		run(fileemitter.WithFromStart())
+
+		Eventually(spy.messages).Should(Equal([]string{"old"}))
+	})
+
+	It("follows files created later from their start", func() {
+		run()
+
+		write(filepath.Join(dir, "new.log"), "hello\n")
+		write(filepath.Join(dir, "ignored.txt"), "ignored\n")
+
+		Eventually(spy.messages).Should(Equal([]string{"hello"}))
+		Consistently(spy.messages, 50*time.Millisecond).Should(HaveLen(1))
+	})
+
+	It("follows rotated files", func() {
+		path := filepath.Join(dir, "app.log")
+		write(path, "")
+		run()
+
+		write(path, "before\n")
+		Eventually(spy.messages).Should(Equal([]string{"before"}))
+
+		Expect(os.Rename(path, filepath.Join(dir, "app.log.1"))).To(Succeed())
+		write(filepath.Join(dir, "app.log.1"), "late\n")
+		write(path, "after\n")
+
+		Eventually(spy.messages).Should(Equal([]string{"before", "late", "after"}))
+	})
+
+	It("reads truncated files from the start", func() {
+		path := filepath.Join(dir, "app.log")
+		write(path, "")
+		run()
+
+		write(path, "a long line before truncation\n")
+		Eventually(spy.messages).Should(HaveLen(1))
+
+		Expect(os.Truncate(path, 0)).To(Succeed())
+		time.Sleep(30 * time.Millisecond)
+		write(path, "short\n")
+
+		Eventually(spy.messages).Should(Equal([]string{"a long line before truncation", "short"}))
+	})
+
+	It("joins multi-line records", func() {
+		path := filepath.Join(dir, "app.log")
+		write(path, "")
+		run(fileemitter.WithMultilineStart(regexp.MustCompile(`^\d{4}-`)))
+
+		write(path, "2024-01-01 panic: boom\n\tat main.go:1\n\tat main.go:2\n2024-01-01 recovered\n")
+
+		Eventually(spy.messages).Should(Equal([]string{
+			"2024-01-01 panic: boom\n\tat main.go:1\n\tat main.go:2",
+			"2024-01-01 recovered",
+		}))
+	})
+
+	It("emits the partial line of a removed file", func() {
+		path := filepath.Join(dir, "app.log")
+		write(path, "")
+		run()
+
+		write(path, "no newline")
+		Consistently(spy.messages, 50*time.Millisecond).Should(BeEmpty())
+
+		Expect(os.Remove(path)).To(Succeed())
+		Eventually(spy.messages).Should(Equal([]string{"no newline"}))
+	})
+
+	It("applies the log options", func() {
+		path := filepath.Join(dir, "app.log")
+		write(path, "")
+		run(fileemitter.WithLogOptions(loggregator.WithSourceInfo("some-source", "APP", "0")))
+
+		write(path, "hello\n")
+
+		Eventually(spy.messages).Should(HaveLen(1))
+		Expect(spy.envelopes()[0].GetSourceId()).To(Equal("some-source"))
+	})
+
+	It("rejects malformed patterns", func() {
+		e := fileemitter.New(spy, []string{"["})
+		Expect(e.Run(context.Background())).To(HaveOccurred())
+	})
+})
+
+func write(path, content string) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	Expect(err).NotTo(HaveOccurred())
+	defer f.Close()
+
+	_, err = f.WriteString(content)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+type spySender struct {
+	mu  sync.Mutex
+	env []*loggregator_v2.Envelope
+}
+
+func (s *spySender) EmitLog(message string, opts ...loggregator.EmitLogOption) {
+	e := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{Payload: []byte(message)},
+		},
+		Tags: make(map[string]string),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.env = append(s.env, e)
+}
+
+func (s *spySender) envelopes() []*loggregator_v2.Envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*loggregator_v2.Envelope(nil), s.env...)
+}
+
+func (s *spySender) messages() []string {
+	var m []string
+	for _, e := range s.envelopes() {
+		m = append(m, string(e.GetLog().GetPayload()))
+	}
+	return m
+}
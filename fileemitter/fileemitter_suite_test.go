@@ -0,0 +1,13 @@
+package fileemitter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFileemitter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "File Emitter Suite")
+}
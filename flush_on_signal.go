@@ -0,0 +1,77 @@
+package loggregator
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// WithDrainTimeout configures how long the client waits for the buffered
+// envelopes to be sent when it is closed. It defaults to 5 seconds.
+// FlushOnSignal, ReportPanic and WrapPanics give up on closing the client
+// once the timeout elapses. CloseSend keeps the stream open for at most the
+// timeout after half-closing it, so the server can read the last batches,
+// and then cancels the client's connection.
+//
+// A timeout of 0 makes FlushOnSignal, ReportPanic and WrapPanics give up
+// right away, while CloseSend waits without a bound for the server to read
+// the stream to its end.
+func WithDrainTimeout(d time.Duration) IngressOption {
+	return func(c *IngressClient) {
+		c.drainTimeout = d
+	}
+}
+
+// FlushOnSignal closes the client when one of the signals is received. It
+// defaults to SIGTERM and SIGINT, the signals BOSH and most process
+// supervisors stop jobs with. Once a signal is received, the client stops
+// accepting envelopes and the buffered envelopes are sent until the drain
+// timeout elapses. The returned channel receives the result of closing the
+// client, ErrDrainTimeout if the timeout elapsed. The signal handlers are
+// removed after the first signal, so a second signal has its default effect.
+//
+//	done := loggregator.FlushOnSignal(client)
+//	...
+//	if err := <-done; err != nil {
+//		log.Printf("could not flush envelopes: %s", err)
+//	}
+//	os.Exit(0)
+func FlushOnSignal(c *IngressClient, signals ...os.Signal) <-chan error {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, signals...)
+
+	done := make(chan error, 1)
+	go func() {
+		<-sigs
+		signal.Stop(sigs)
+
+		done <- c.closeWithTimeout(c.drainTimeout)
+	}()
+
+	return done
+}
+
+// closeWithTimeout closes the client and waits for the buffered envelopes
+// to be sent. When the timeout elapses, pending sends are aborted.
+func (c *IngressClient) closeWithTimeout(timeout time.Duration) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.CloseSend()
+	}()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-t.C:
+		c.cancel()
+		return ErrDrainTimeout
+	}
+}
@@ -0,0 +1,79 @@
+//go:build !windows
+// +build !windows
+
+package loggregator_test
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FlushOnSignal", func() {
+	var server *testIngressServer
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("flushes buffered envelopes and closes the client on a signal", func() {
+		client, _, _ := buildIngressClient(server.addr, time.Hour, false)
+		done := loggregator.FlushOnSignal(client, syscall.SIGUSR2)
+
+		client.EmitLog("message")
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR2)).To(Succeed())
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(string(envelopes[0].GetLog().GetPayload())).To(Equal("message"))
+
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(client.EmitEnvelope(&loggregator_v2.Envelope{})).To(MatchError(loggregator.ErrClosed))
+	})
+
+	It("gives up when the drain timeout elapses", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithDrainTimeout(50*time.Millisecond),
+			loggregator.WithTransportDecorator(func(c loggregator_v2.IngressClient) loggregator_v2.IngressClient {
+				return &stuckIngressClient{IngressClient: c}
+			}),
+		)
+		done := loggregator.FlushOnSignal(client, syscall.SIGUSR2)
+
+		client.EmitLog("message")
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR2)).To(Succeed())
+
+		Eventually(done).Should(Receive(Equal(loggregator.ErrDrainTimeout)))
+	})
+})
+
+// stuckIngressClient never establishes a stream until its context is done.
+type stuckIngressClient struct {
+	loggregator_v2.IngressClient
+}
+
+func (c *stuckIngressClient) BatchSender(ctx context.Context, _ ...grpc.CallOption) (loggregator_v2.Ingress_BatchSenderClient, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
@@ -0,0 +1,91 @@
+// Package format renders v2 envelopes as human-readable text, similar to the
+// output of `cf logs`.
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// timeFormat is the timestamp format used by `cf logs`.
+const timeFormat = "2006-01-02T15:04:05.00-0700"
+
+// Line renders the envelope as a single line in the local time zone, e.g.
+//
+//	2018-01-02T15:04:05.00+0000 [APP/PROC/WEB/0] OUT some log message
+func Line(e *loggregator_v2.Envelope) string {
+	return defaultFormatter.Line(e)
+}
+
+// Multiline renders the envelope with one field per line, including its
+// tags.
+func Multiline(e *loggregator_v2.Envelope) string {
+	return defaultFormatter.Multiline(e)
+}
+
+var defaultFormatter = NewFormatter()
+
+// origin follows `cf logs` in preferring the source type over the source ID.
+func origin(e *loggregator_v2.Envelope) string {
+	source := envelope.SourceType(e)
+	if source == "" {
+		source = e.GetSourceId()
+	}
+
+	if e.GetInstanceId() == "" {
+		return source
+	}
+
+	return source + "/" + e.GetInstanceId()
+}
+
+func message(e *loggregator_v2.Envelope) string {
+	switch m := e.GetMessage().(type) {
+	case *loggregator_v2.Envelope_Log:
+		stream := "ERR"
+		if m.Log.GetType() == loggregator_v2.Log_OUT {
+			stream = "OUT"
+		}
+		return fmt.Sprintf("%s %s", stream, m.Log.GetPayload())
+	case *loggregator_v2.Envelope_Counter:
+		return fmt.Sprintf("COUNTER %s:%d", m.Counter.GetName(), m.Counter.GetDelta())
+	case *loggregator_v2.Envelope_Gauge:
+		return "GAUGE " + strings.Join(gaugeValues(m.Gauge.GetMetrics()), " ")
+	case *loggregator_v2.Envelope_Timer:
+		d := time.Duration(m.Timer.GetStop() - m.Timer.GetStart())
+		return fmt.Sprintf("TIMER %s:%s", m.Timer.GetName(), d)
+	case *loggregator_v2.Envelope_Event:
+		return fmt.Sprintf("EVENT %s: %s", m.Event.GetTitle(), m.Event.GetBody())
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func gaugeValues(metrics map[string]*loggregator_v2.GaugeValue) []string {
+	values := make([]string, 0, len(metrics))
+	for name, v := range metrics {
+		value := fmt.Sprintf("%s:%g", name, v.GetValue())
+		if v.GetUnit() != "" {
+			value += " " + v.GetUnit()
+		}
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	return values
+}
+
+func tagList(tags map[string]string) []string {
+	list := make([]string, 0, len(tags))
+	for k, v := range tags {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+
+	return list
+}
@@ -0,0 +1,73 @@
+package format_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/format"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Format", func() {
+	var ts = time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC).Local()
+
+	envelope := func(m interface{}) *loggregator_v2.Envelope {
+		e := &loggregator_v2.Envelope{
+			Timestamp:  ts.UnixNano(),
+			SourceId:   "some-source",
+			InstanceId: "0",
+			Tags:       map[string]string{"source_type": "APP/PROC/WEB"},
+		}
+
+		switch m := m.(type) {
+		case *loggregator_v2.Log:
+			e.Message = &loggregator_v2.Envelope_Log{Log: m}
+		case *loggregator_v2.Counter:
+			e.Message = &loggregator_v2.Envelope_Counter{Counter: m}
+		case *loggregator_v2.Gauge:
+			e.Message = &loggregator_v2.Envelope_Gauge{Gauge: m}
+		case *loggregator_v2.Timer:
+			e.Message = &loggregator_v2.Envelope_Timer{Timer: m}
+		case *loggregator_v2.Event:
+			e.Message = &loggregator_v2.Envelope_Event{Event: m}
+		}
+
+		return e
+	}
+
+	DescribeTable("Line", func(m interface{}, expected string) {
+		Expect(format.Line(envelope(m))).To(Equal(ts.Format("2006-01-02T15:04:05.00-0700") + " [APP/PROC/WEB/0] " + expected))
+	},
+		Entry("stdout log", &loggregator_v2.Log{Payload: []byte("hello"), Type: loggregator_v2.Log_OUT}, "OUT hello"),
+		Entry("stderr log", &loggregator_v2.Log{Payload: []byte("oops"), Type: loggregator_v2.Log_ERR}, "ERR oops"),
+		Entry("counter", &loggregator_v2.Counter{Name: "requests", Delta: 3}, "COUNTER requests:3"),
+		Entry("gauge", &loggregator_v2.Gauge{Metrics: map[string]*loggregator_v2.GaugeValue{
+			"mem": {Value: 1024, Unit: "bytes"},
+			"cpu": {Value: 0.5},
+		}}, "GAUGE cpu:0.5 mem:1024 bytes"),
+		Entry("timer", &loggregator_v2.Timer{Name: "http", Start: 0, Stop: int64(150 * time.Millisecond)}, "TIMER http:150ms"),
+		Entry("event", &loggregator_v2.Event{Title: "deployed", Body: "v2"}, "EVENT deployed: v2"),
+	)
+
+	It("falls back to the source ID without a source type", func() {
+		e := envelope(&loggregator_v2.Counter{Name: "requests", Delta: 1})
+		e.Tags = nil
+
+		Expect(format.Line(e)).To(ContainSubstring("[some-source/0]"))
+	})
+
+	It("renders an envelope on multiple lines", func() {
+		e := envelope(&loggregator_v2.Log{Payload: []byte("hello"), Type: loggregator_v2.Log_OUT})
+
+		Expect(format.Multiline(e)).To(Equal(
+			"Timestamp:   " + ts.Format("2006-01-02T15:04:05.00-0700") + "\n" +
+				"Source ID:   some-source\n" +
+				"Instance ID: 0\n" +
+				"Tags:        source_type=APP/PROC/WEB\n" +
+				"Message:     OUT hello\n",
+		))
+	})
+})
@@ -0,0 +1,186 @@
+package format
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// ANSI escape codes used for coloring.
+const (
+	reset   = "\x1b[0m"
+	red     = "\x1b[31m"
+	green   = "\x1b[32m"
+	yellow  = "\x1b[33m"
+	blue    = "\x1b[34m"
+	magenta = "\x1b[35m"
+	cyan    = "\x1b[36m"
+	dim     = "\x1b[2m"
+)
+
+// sourceTypeColors follows the cf CLI in coloring the origin of a log by the
+// prefix of its source type.
+var sourceTypeColors = map[string]string{
+	envelope.SourceTypeApp:     cyan,
+	envelope.SourceTypeRouter:  magenta,
+	envelope.SourceTypeStaging: yellow,
+	envelope.SourceTypeAPI:     blue,
+	envelope.SourceTypeCell:    green,
+}
+
+// FormatterOption configures a Formatter.
+type FormatterOption func(*Formatter)
+
+// WithColor enables ANSI coloring of the origin by source type, of the
+// timestamp and of stderr logs. ColorEnabled may be used to only enable
+// coloring when writing to a terminal.
+func WithColor(enabled bool) FormatterOption {
+	return func(f *Formatter) {
+		f.color = enabled
+	}
+}
+
+// WithLocation configures the time zone timestamps are rendered in. It
+// defaults to the local time zone.
+func WithLocation(loc *time.Location) FormatterOption {
+	return func(f *Formatter) {
+		f.location = loc
+	}
+}
+
+// WithIncludeSourceTypes only includes envelopes whose source type starts
+// with any of the given prefixes, e.g. "APP" or "RTR".
+func WithIncludeSourceTypes(prefixes ...string) FormatterOption {
+	return func(f *Formatter) {
+		f.include = prefixes
+	}
+}
+
+// WithExcludeSourceTypes excludes envelopes whose source type starts with
+// any of the given prefixes.
+func WithExcludeSourceTypes(prefixes ...string) FormatterOption {
+	return func(f *Formatter) {
+		f.exclude = prefixes
+	}
+}
+
+// Formatter renders envelopes like Line and Multiline but can be configured
+// to color its output, to render timestamps in a given time zone and to
+// filter envelopes by source type. It should be created with the
+// NewFormatter constructor.
+type Formatter struct {
+	color    bool
+	location *time.Location
+	include  []string
+	exclude  []string
+}
+
+// NewFormatter creates a Formatter.
+func NewFormatter(opts ...FormatterOption) *Formatter {
+	f := &Formatter{
+		location: time.Local,
+	}
+
+	for _, o := range opts {
+		o(f)
+	}
+
+	return f
+}
+
+// Include reports whether the envelope passes the source type filters.
+func (f *Formatter) Include(e *loggregator_v2.Envelope) bool {
+	sourceType := envelope.SourceType(e)
+
+	if len(f.include) > 0 && !hasAnyPrefix(sourceType, f.include) {
+		return false
+	}
+
+	return !hasAnyPrefix(sourceType, f.exclude)
+}
+
+// Line renders the envelope as a single line. See the Line function.
+func (f *Formatter) Line(e *loggregator_v2.Envelope) string {
+	return f.timestamp(e) + " " + f.origin(e) + " " + f.message(e)
+}
+
+// Multiline renders the envelope with one field per line. See the Multiline
+// function.
+func (f *Formatter) Multiline(e *loggregator_v2.Envelope) string {
+	lines := []string{
+		"Timestamp:   " + f.timestamp(e),
+		"Source ID:   " + e.GetSourceId(),
+		"Instance ID: " + e.GetInstanceId(),
+	}
+	if tags := tagList(e.GetTags()); len(tags) > 0 {
+		lines = append(lines, "Tags:        "+strings.Join(tags, ", "))
+	}
+	lines = append(lines, "Message:     "+f.message(e))
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (f *Formatter) timestamp(e *loggregator_v2.Envelope) string {
+	ts := time.Unix(0, e.GetTimestamp()).In(f.location).Format(timeFormat)
+	return f.paint(dim, ts)
+}
+
+func (f *Formatter) origin(e *loggregator_v2.Envelope) string {
+	return f.paint(originColor(envelope.SourceType(e)), "["+origin(e)+"]")
+}
+
+func (f *Formatter) message(e *loggregator_v2.Envelope) string {
+	m := message(e)
+	if e.GetLog() != nil && e.GetLog().GetType() == loggregator_v2.Log_ERR {
+		return f.paint(red, m)
+	}
+
+	return m
+}
+
+func (f *Formatter) paint(color, s string) string {
+	if !f.color || color == "" {
+		return s
+	}
+
+	return color + s + reset
+}
+
+func originColor(sourceType string) string {
+	for prefix, color := range sourceTypeColors {
+		if strings.HasPrefix(sourceType, prefix) {
+			return color
+		}
+	}
+
+	return ""
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ColorEnabled reports whether output written to the file should be colored.
+// This is the case if the file is a terminal, TERM is not "dumb" and NO_COLOR
+// is not set.
+func ColorEnabled(file *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,63 @@
+package format_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/format"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Formatter", func() {
+	logEnvelope := func(sourceType string, t loggregator_v2.Log_Type) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			Timestamp:  time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC).UnixNano(),
+			SourceId:   "some-source",
+			InstanceId: "0",
+			Tags:       map[string]string{"source_type": sourceType},
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte("hello"), Type: t},
+			},
+		}
+	}
+
+	It("renders timestamps in the configured time zone", func() {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		f := format.NewFormatter(format.WithLocation(loc))
+
+		Expect(f.Line(logEnvelope("APP/PROC/WEB", loggregator_v2.Log_OUT))).To(Equal(
+			"2018-01-02T17:04:05.00+0200 [APP/PROC/WEB/0] OUT hello",
+		))
+	})
+
+	It("colors the origin by source type and stderr logs", func() {
+		f := format.NewFormatter(format.WithColor(true), format.WithLocation(time.UTC))
+
+		Expect(f.Line(logEnvelope("RTR", loggregator_v2.Log_ERR))).To(Equal(
+			"\x1b[2m2018-01-02T15:04:05.00+0000\x1b[0m \x1b[35m[RTR/0]\x1b[0m \x1b[31mERR hello\x1b[0m",
+		))
+	})
+
+	It("does not color unknown source types", func() {
+		f := format.NewFormatter(format.WithColor(true), format.WithLocation(time.UTC))
+
+		Expect(f.Line(logEnvelope("OTHER", loggregator_v2.Log_OUT))).To(ContainSubstring(" [OTHER/0] OUT hello"))
+	})
+
+	It("includes only the configured source types", func() {
+		f := format.NewFormatter(format.WithIncludeSourceTypes("APP", "RTR"))
+
+		Expect(f.Include(logEnvelope("APP/PROC/WEB", loggregator_v2.Log_OUT))).To(BeTrue())
+		Expect(f.Include(logEnvelope("RTR", loggregator_v2.Log_OUT))).To(BeTrue())
+		Expect(f.Include(logEnvelope("STG", loggregator_v2.Log_OUT))).To(BeFalse())
+	})
+
+	It("excludes the configured source types", func() {
+		f := format.NewFormatter(format.WithExcludeSourceTypes("RTR"))
+
+		Expect(f.Include(logEnvelope("APP/PROC/WEB", loggregator_v2.Log_OUT))).To(BeTrue())
+		Expect(f.Include(logEnvelope("RTR", loggregator_v2.Log_OUT))).To(BeFalse())
+	})
+})
@@ -0,0 +1,150 @@
+package loggregator
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// WithGaugeCoalescing configures the client to merge gauges that are emitted
+// within the given window into a single gauge envelope. Gauges are merged
+// when they share their source ID, instance ID and tags. When the same
+// metric is emitted more than once within a window, the last value wins.
+// Pending gauges are sent when the client is closed.
+func WithGaugeCoalescing(window time.Duration) IngressOption {
+	return func(c *IngressClient) {
+		c.gauges = newGaugeCoalescer(window)
+	}
+}
+
+// gaugeCoalescer holds the gauges of the current window. It is safe for
+// concurrent use.
+type gaugeCoalescer struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*loggregator_v2.Envelope
+	order   []string
+	stopped bool
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newGaugeCoalescer(window time.Duration) *gaugeCoalescer {
+	return &gaugeCoalescer{
+		window:  window,
+		pending: make(map[string]*loggregator_v2.Envelope),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// add merges the gauge envelope into the pending gauges. It returns false
+// once the coalescer has been stopped, in which case the envelope has to be
+// sent by the caller.
+func (g *gaugeCoalescer) add(e *loggregator_v2.Envelope) bool {
+	k := gaugeKey(e)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.stopped {
+		return false
+	}
+
+	p, ok := g.pending[k]
+	if !ok {
+		g.pending[k] = e
+		g.order = append(g.order, k)
+		return true
+	}
+
+	for name, v := range e.GetGauge().GetMetrics() {
+		p.GetGauge().Metrics[name] = v
+	}
+	if e.Timestamp > p.Timestamp {
+		p.Timestamp = e.Timestamp
+	}
+
+	return true
+}
+
+// take removes and returns the pending gauges in the order they were first
+// emitted.
+func (g *gaugeCoalescer) take() []*loggregator_v2.Envelope {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	envelopes := make([]*loggregator_v2.Envelope, 0, len(g.order))
+	for _, k := range g.order {
+		envelopes = append(envelopes, g.pending[k])
+	}
+
+	g.pending = make(map[string]*loggregator_v2.Envelope)
+	g.order = nil
+
+	return envelopes
+}
+
+// run hands the pending gauges to send at the end of every window until the
+// coalescer is stopped.
+func (g *gaugeCoalescer) run(send func(*loggregator_v2.Envelope)) {
+	defer close(g.done)
+
+	t := time.NewTicker(g.window)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-g.stop:
+			g.mu.Lock()
+			g.stopped = true
+			g.mu.Unlock()
+
+			for _, e := range g.take() {
+				send(e)
+			}
+			return
+		}
+
+		for _, e := range g.take() {
+			send(e)
+		}
+	}
+}
+
+// close stops the coalescer and waits until the pending gauges have been
+// sent.
+func (g *gaugeCoalescer) close() {
+	g.stopOnce.Do(func() {
+		close(g.stop)
+	})
+	<-g.done
+}
+
+func gaugeKey(e *loggregator_v2.Envelope) string {
+	names := make([]string, 0, len(e.Tags))
+	for name := range e.Tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	b.WriteString(e.SourceId)
+	b.WriteByte(0)
+	b.WriteString(e.InstanceId)
+	for _, name := range names {
+		b.WriteByte(0)
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.WriteString(e.Tags[name])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package loggregator
+
+import (
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/units"
+)
+
+// GaugeUnitMiddleware replaces the spellings of units of gauge metrics with
+// the units of the units package, e.g. "Bytes" with units.Bytes. If strict
+// is true, gauges with a unit that is not known to the units package are
+// dropped with ErrUnknownUnit. Empty units are always accepted.
+func GaugeUnitMiddleware(strict bool) Middleware {
+	return func(next EmitFunc) EmitFunc {
+		return func(e *loggregator_v2.Envelope) error {
+			g := e.GetGauge()
+			if g == nil {
+				return next(e)
+			}
+
+			changed := false
+			for _, m := range g.GetMetrics() {
+				if m.GetUnit() == "" {
+					continue
+				}
+
+				u, ok := units.Normalize(m.GetUnit())
+				if !ok && strict {
+					return ErrUnknownUnit
+				}
+				if u != m.GetUnit() {
+					changed = true
+				}
+			}
+
+			if !changed {
+				return next(e)
+			}
+
+			return next(withNormalizedUnits(e))
+		}
+	}
+}
+
+// withNormalizedUnits returns a copy of the gauge envelope with normalized
+// units. The envelope is copied since the message may be shared with the
+// caller of EmitEnvelope.
+func withNormalizedUnits(e *loggregator_v2.Envelope) *loggregator_v2.Envelope {
+	metrics := make(map[string]*loggregator_v2.GaugeValue, len(e.GetGauge().GetMetrics()))
+	for name, m := range e.GetGauge().GetMetrics() {
+		u, _ := units.Normalize(m.GetUnit())
+		metrics[name] = &loggregator_v2.GaugeValue{Unit: u, Value: m.GetValue()}
+	}
+
+	return &loggregator_v2.Envelope{
+		Timestamp:      e.Timestamp,
+		SourceId:       e.SourceId,
+		InstanceId:     e.InstanceId,
+		DeprecatedTags: e.DeprecatedTags,
+		Tags:           e.Tags,
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{Metrics: metrics},
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package loggregator_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/units"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GaugeUnitMiddleware", func() {
+	var (
+		emitted []*loggregator_v2.Envelope
+		next    loggregator.EmitFunc
+	)
+
+	BeforeEach(func() {
+		emitted = nil
+		next = func(e *loggregator_v2.Envelope) error {
+			emitted = append(emitted, e)
+			return nil
+		}
+	})
+
+	gauge := func(units map[string]string) *loggregator_v2.Envelope {
+		metrics := make(map[string]*loggregator_v2.GaugeValue)
+		for name, u := range units {
+			metrics[name] = &loggregator_v2.GaugeValue{Unit: u, Value: 1}
+		}
+
+		return &loggregator_v2.Envelope{
+			SourceId: "some-source",
+			Tags:     map[string]string{"some-tag": "some-value"},
+			Message: &loggregator_v2.Envelope_Gauge{
+				Gauge: &loggregator_v2.Gauge{Metrics: metrics},
+			},
+		}
+	}
+
+	It("normalizes the units of a copy of the gauge", func() {
+		e := gauge(map[string]string{"memory": "Bytes", "cpu": "percentage", "other": ""})
+
+		Expect(loggregator.GaugeUnitMiddleware(false)(next)(e)).To(Succeed())
+
+		Expect(emitted).To(HaveLen(1))
+		Expect(emitted[0].GetSourceId()).To(Equal("some-source"))
+		Expect(emitted[0].GetTags()).To(HaveKeyWithValue("some-tag", "some-value"))
+		Expect(emitted[0].GetGauge().GetMetrics()["memory"].GetUnit()).To(Equal(units.Bytes))
+		Expect(emitted[0].GetGauge().GetMetrics()["memory"].GetValue()).To(Equal(1.0))
+		Expect(emitted[0].GetGauge().GetMetrics()["cpu"].GetUnit()).To(Equal(units.Percentage))
+		Expect(emitted[0].GetGauge().GetMetrics()["other"].GetUnit()).To(BeEmpty())
+		Expect(e.GetGauge().GetMetrics()["memory"].GetUnit()).To(Equal("Bytes"))
+	})
+
+	It("passes gauges with normalized units unchanged", func() {
+		e := gauge(map[string]string{"memory": units.Bytes})
+
+		Expect(loggregator.GaugeUnitMiddleware(true)(next)(e)).To(Succeed())
+
+		Expect(emitted).To(ConsistOf(BeIdenticalTo(e)))
+	})
+
+	It("passes gauges with unknown units unless strict", func() {
+		e := gauge(map[string]string{"duration": "nanofortnights"})
+
+		Expect(loggregator.GaugeUnitMiddleware(false)(next)(e)).To(Succeed())
+		Expect(emitted).To(ConsistOf(BeIdenticalTo(e)))
+
+		emitted = nil
+		err := loggregator.GaugeUnitMiddleware(true)(next)(e)
+		Expect(err).To(MatchError(loggregator.ErrUnknownUnit))
+		Expect(emitted).To(BeEmpty())
+	})
+
+	It("passes other envelopes", func() {
+		e := &loggregator_v2.Envelope{
+			Message: &loggregator_v2.Envelope_Counter{
+				Counter: &loggregator_v2.Counter{Name: "some-counter"},
+			},
+		}
+
+		Expect(loggregator.GaugeUnitMiddleware(true)(next)(e)).To(Succeed())
+		Expect(emitted).To(ConsistOf(BeIdenticalTo(e)))
+	})
+})
@@ -0,0 +1,184 @@
+// Package heartbeatemitter provides an opt-in emitter that periodically
+// reports that a component is alive. Operators can alert on the absence of
+// heartbeats to detect components that have silently died even when no other
+// telemetry is flowing.
+package heartbeatemitter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+)
+
+// Sender is the interface of the client that can be used to emit heartbeats
+// as counter envelopes.
+type Sender interface {
+	EmitCounter(name string, opts ...loggregator.EmitCounterOption)
+}
+
+// EventSender is the interface of the client that can be used to emit
+// heartbeats as event envelopes.
+type EventSender interface {
+	EmitEvent(ctx context.Context, title, body string, opts ...loggregator.EmitEventOption) error
+}
+
+// Emitter will emit a heartbeat via the sender on the given interval. The
+// default interval is 30 seconds.
+type Emitter struct {
+	interval time.Duration
+	name     string
+	sourceID string
+	version  string
+	tags     map[string]string
+	start    time.Time
+
+	sender heartbeatSender
+}
+
+type heartbeatSender interface {
+	send(e *Emitter, tags map[string]string)
+}
+
+// HeartbeatEmitterOption is the option that provides configuration for an
+// Emitter.
+type HeartbeatEmitterOption func(e *Emitter)
+
+// WithInterval returns a HeartbeatEmitterOption to configure the interval at
+// which heartbeats are emitted.
+func WithInterval(d time.Duration) HeartbeatEmitterOption {
+	return func(e *Emitter) {
+		e.interval = d
+	}
+}
+
+// WithName returns a HeartbeatEmitterOption to configure the counter name or
+// event title of the heartbeat. It defaults to "heartbeat".
+func WithName(name string) HeartbeatEmitterOption {
+	return func(e *Emitter) {
+		e.name = name
+	}
+}
+
+// WithSourceID returns a HeartbeatEmitterOption for setting the source ID
+// that will be set on every heartbeat.
+func WithSourceID(id string) HeartbeatEmitterOption {
+	return func(e *Emitter) {
+		e.sourceID = id
+	}
+}
+
+// WithVersion returns a HeartbeatEmitterOption that adds a `version` tag to
+// every heartbeat.
+func WithVersion(version string) HeartbeatEmitterOption {
+	return func(e *Emitter) {
+		e.version = version
+	}
+}
+
+// WithTags returns a HeartbeatEmitterOption that adds the given tags to every
+// heartbeat.
+func WithTags(tags map[string]string) HeartbeatEmitterOption {
+	return func(e *Emitter) {
+		for k, v := range tags {
+			e.tags[k] = v
+		}
+	}
+}
+
+// New returns an Emitter that emits heartbeats as counter envelopes with a
+// delta of 1.
+func New(sender Sender, opts ...HeartbeatEmitterOption) *Emitter {
+	return newEmitter(counterSender{sender: sender}, opts)
+}
+
+// NewEvent returns an Emitter that emits heartbeats as event envelopes. The
+// body of each event describes the uptime of the component.
+func NewEvent(sender EventSender, opts ...HeartbeatEmitterOption) *Emitter {
+	return newEmitter(eventSender{sender: sender}, opts)
+}
+
+func newEmitter(s heartbeatSender, opts []HeartbeatEmitterOption) *Emitter {
+	e := &Emitter{
+		interval: 30 * time.Second,
+		name:     "heartbeat",
+		tags:     make(map[string]string),
+		start:    time.Now(),
+		sender:   s,
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+// Run starts the ticker with the configured interval and emits a heartbeat
+// on that interval. This method will block but the user may run in a go
+// routine.
+func (e *Emitter) Run() {
+	for range time.Tick(e.interval) {
+		e.sender.send(e, e.heartbeatTags())
+	}
+}
+
+func (e *Emitter) heartbeatTags() map[string]string {
+	tags := make(map[string]string, len(e.tags)+2)
+	for k, v := range e.tags {
+		tags[k] = v
+	}
+
+	tags["uptime"] = strconv.FormatInt(int64(e.uptime().Seconds()), 10)
+	if e.version != "" {
+		tags["version"] = e.version
+	}
+
+	return tags
+}
+
+func (e *Emitter) uptime() time.Duration {
+	return time.Since(e.start)
+}
+
+func (e *Emitter) sourceIDOption(p proto.Message) {
+	env, ok := p.(*loggregator_v2.Envelope)
+	if ok && e.sourceID != "" {
+		env.SourceId = e.sourceID
+	}
+}
+
+type counterSender struct {
+	sender Sender
+}
+
+func (s counterSender) send(e *Emitter, tags map[string]string) {
+	s.sender.EmitCounter(
+		e.name,
+		e.sourceIDOption,
+		loggregator.WithEnvelopeTags(tags),
+	)
+}
+
+type eventSender struct {
+	sender EventSender
+}
+
+func (s eventSender) send(e *Emitter, tags map[string]string) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+
+	// Errors are ignored: a missed heartbeat is exactly what operators are
+	// expected to alert on.
+	_ = s.sender.EmitEvent(
+		ctx,
+		e.name,
+		fmt.Sprintf("uptime: %s", e.uptime()),
+		e.sourceIDOption,
+		loggregator.WithEnvelopeTags(tags),
+	)
+}
@@ -0,0 +1,96 @@
+package heartbeatemitter_test
+
+import (
+	"context"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/heartbeatemitter"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HeartbeatEmitter", func() {
+	It("emits counter heartbeats on an interval", func() {
+		spy := newSpyClient()
+		emitter := heartbeatemitter.New(spy,
+			heartbeatemitter.WithInterval(10*time.Millisecond),
+			heartbeatemitter.WithSourceID("some-source"),
+			heartbeatemitter.WithVersion("1.2.3"),
+			heartbeatemitter.WithTags(map[string]string{"job": "some-job"}),
+		)
+
+		go emitter.Run()
+
+		var env *loggregator_v2.Envelope
+		Eventually(spy.envelopes).Should(Receive(&env))
+
+		Expect(env.GetCounter().GetName()).To(Equal("heartbeat"))
+		Expect(env.GetCounter().GetDelta()).To(Equal(uint64(1)))
+		Expect(env.GetSourceId()).To(Equal("some-source"))
+		Expect(env.GetTags()).To(HaveKeyWithValue("version", "1.2.3"))
+		Expect(env.GetTags()).To(HaveKeyWithValue("job", "some-job"))
+		Expect(env.GetTags()).To(HaveKey("uptime"))
+	})
+
+	It("emits event heartbeats on an interval", func() {
+		spy := newSpyClient()
+		emitter := heartbeatemitter.NewEvent(spy,
+			heartbeatemitter.WithInterval(10*time.Millisecond),
+			heartbeatemitter.WithName("some-component-alive"),
+		)
+
+		go emitter.Run()
+
+		var env *loggregator_v2.Envelope
+		Eventually(spy.envelopes).Should(Receive(&env))
+
+		Expect(env.GetEvent().GetTitle()).To(Equal("some-component-alive"))
+		Expect(env.GetEvent().GetBody()).To(HavePrefix("uptime: "))
+		Expect(env.GetTags()).To(HaveKey("uptime"))
+		Expect(env.GetTags()).ToNot(HaveKey("version"))
+	})
+})
+
+type spyClient struct {
+	envelopes chan *loggregator_v2.Envelope
+}
+
+func newSpyClient() *spyClient {
+	return &spyClient{
+		envelopes: make(chan *loggregator_v2.Envelope, 100),
+	}
+}
+
+func (s *spyClient) EmitCounter(name string, opts ...loggregator.EmitCounterOption) {
+	env := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: name, Delta: 1},
+		},
+		Tags: make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(env)
+	}
+
+	s.envelopes <- env
+}
+
+func (s *spyClient) EmitEvent(ctx context.Context, title, body string, opts ...loggregator.EmitEventOption) error {
+	env := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Event{
+			Event: &loggregator_v2.Event{Title: title, Body: body},
+		},
+		Tags: make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(env)
+	}
+
+	s.envelopes <- env
+	return nil
+}
@@ -0,0 +1,13 @@
+package heartbeatemitter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHeartbeatemitter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Heartbeat Emitter Suite")
+}
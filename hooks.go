@@ -0,0 +1,61 @@
+package loggregator
+
+import (
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// BeforeEmitHook is called with every envelope before it passes through the
+// emit pipeline. It must not modify the envelope.
+type BeforeEmitHook func(*loggregator_v2.Envelope)
+
+// AfterEmitHook is called with every envelope and the result of emitting it.
+// The error is nil if the envelope was queued for sending. It must not
+// modify the envelope.
+type AfterEmitHook func(*loggregator_v2.Envelope, error)
+
+// BeforeEmit registers a hook that is called before an envelope is
+// emitted. Hooks are called synchronously by the emitting go routine in the
+// order they were registered and may be registered at any time.
+func (c *IngressClient) BeforeEmit(hook BeforeEmitHook) {
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+
+	// The slices are copied so that hooks are called without holding the
+	// lock.
+	hooks := make([]BeforeEmitHook, len(c.beforeEmit), len(c.beforeEmit)+1)
+	copy(hooks, c.beforeEmit)
+	c.beforeEmit = append(hooks, hook)
+}
+
+// AfterEmit registers a hook that is called after an envelope is emitted,
+// e.g. for audit logging or metrics. Hooks are called synchronously by the
+// emitting go routine in the order they were registered and may be
+// registered at any time.
+func (c *IngressClient) AfterEmit(hook AfterEmitHook) {
+	c.hooksMu.Lock()
+	defer c.hooksMu.Unlock()
+
+	hooks := make([]AfterEmitHook, len(c.afterEmit), len(c.afterEmit)+1)
+	copy(hooks, c.afterEmit)
+	c.afterEmit = append(hooks, hook)
+}
+
+// emitWithHooks passes the envelope through the given emit pipeline and
+// calls the registered hooks around it.
+func (c *IngressClient) emitWithHooks(e *loggregator_v2.Envelope, emit EmitFunc) error {
+	c.hooksMu.RLock()
+	before, after := c.beforeEmit, c.afterEmit
+	c.hooksMu.RUnlock()
+
+	for _, h := range before {
+		h(e)
+	}
+
+	err := emit(e)
+
+	for _, h := range after {
+		h(e, err)
+	}
+
+	return err
+}
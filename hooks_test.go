@@ -0,0 +1,92 @@
+package loggregator_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Emit hooks", func() {
+	var (
+		server *testIngressServer
+		client *loggregator.IngressClient
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+
+		dropped := errors.New("dropped")
+		client, _, _ = buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithMiddleware(func(next loggregator.EmitFunc) loggregator.EmitFunc {
+				return func(e *loggregator_v2.Envelope) error {
+					if string(e.GetLog().GetPayload()) == "drop" {
+						return dropped
+					}
+					return next(e)
+				}
+			}),
+		)
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("calls the hooks around every emitted envelope", func() {
+		var (
+			mu     sync.Mutex
+			calls  []string
+			errs   []error
+			before []*loggregator_v2.Envelope
+		)
+		client.BeforeEmit(func(e *loggregator_v2.Envelope) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, "before")
+			before = append(before, e)
+		})
+		client.AfterEmit(func(e *loggregator_v2.Envelope, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, "after")
+			errs = append(errs, err)
+		})
+
+		client.EmitLog("drop")
+		client.EmitLog("keep")
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(calls).To(Equal([]string{"before", "after", "before", "after"}))
+		Expect(before[0].GetLog().Payload).To(Equal([]byte("drop")))
+		Expect(before[0].Tags).To(HaveKeyWithValue("string", "client-string-tag"))
+		Expect(errs[0]).To(MatchError("dropped"))
+		Expect(errs[1]).NotTo(HaveOccurred())
+	})
+
+	It("calls hooks in the order they were registered", func() {
+		var calls []int
+		client.AfterEmit(func(*loggregator_v2.Envelope, error) { calls = append(calls, 1) })
+		client.AfterEmit(func(*loggregator_v2.Envelope, error) { calls = append(calls, 2) })
+
+		Expect(client.EmitEnvelope(&loggregator_v2.Envelope{SourceId: "some-source"})).To(Succeed())
+
+		Expect(calls).To(Equal([]int{1, 2}))
+	})
+})
@@ -0,0 +1,128 @@
+package loggregator
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// PeerType is the role of the emitter of an HTTP timer in the request.
+type PeerType string
+
+// The peer types understood by the v1 conversion of HTTP timers.
+const (
+	PeerTypeClient PeerType = "Client"
+	PeerTypeServer PeerType = "Server"
+)
+
+// HTTPStartStop builds a v2 timer envelope with the layout that Loggregator
+// converts into a v1 HttpStartStop event: the timer is named "http" and the
+// request properties are deprecated tags of the types the conversion
+// expects. It should be created with NewHTTPStartStop. The setters return
+// the builder so that they can be chained.
+type HTTPStartStop struct {
+	e *loggregator_v2.Envelope
+}
+
+// NewHTTPStartStop returns a builder for an HTTP timer of a request that
+// started and stopped at the given times.
+func NewHTTPStartStop(start, stop time.Time) *HTTPStartStop {
+	return &HTTPStartStop{
+		e: &loggregator_v2.Envelope{
+			Timestamp:      stop.UnixNano(),
+			DeprecatedTags: make(map[string]*loggregator_v2.Value),
+			Message: &loggregator_v2.Envelope_Timer{
+				Timer: &loggregator_v2.Timer{
+					Name:  "http",
+					Start: start.UnixNano(),
+					Stop:  stop.UnixNano(),
+				},
+			},
+		},
+	}
+}
+
+// SourceID sets the source ID, which becomes the application ID of the v1
+// event.
+func (b *HTTPStartStop) SourceID(id string) *HTTPStartStop {
+	b.e.SourceId = id
+	return b
+}
+
+// RequestID sets the request ID, usually the X-Vcap-Request-Id header.
+func (b *HTTPStartStop) RequestID(id string) *HTTPStartStop {
+	return b.text("request_id", id)
+}
+
+// PeerType sets the role of the emitter in the request.
+func (b *HTTPStartStop) PeerType(t PeerType) *HTTPStartStop {
+	return b.text("peer_type", string(t))
+}
+
+// Method sets the HTTP method. It is upper-cased since the conversion only
+// recognizes upper-case methods.
+func (b *HTTPStartStop) Method(method string) *HTTPStartStop {
+	return b.text("method", strings.ToUpper(method))
+}
+
+// URI sets the URI of the request.
+func (b *HTTPStartStop) URI(uri string) *HTTPStartStop {
+	return b.text("uri", uri)
+}
+
+// RemoteAddress sets the address of the remote peer.
+func (b *HTTPStartStop) RemoteAddress(addr string) *HTTPStartStop {
+	return b.text("remote_address", addr)
+}
+
+// UserAgent sets the user agent of the request.
+func (b *HTTPStartStop) UserAgent(agent string) *HTTPStartStop {
+	return b.text("user_agent", agent)
+}
+
+// StatusCode sets the status code of the response.
+func (b *HTTPStartStop) StatusCode(code int) *HTTPStartStop {
+	return b.integer("status_code", int64(code))
+}
+
+// ContentLength sets the length of the response body.
+func (b *HTTPStartStop) ContentLength(n int64) *HTTPStartStop {
+	return b.integer("content_length", n)
+}
+
+// InstanceIndex sets the index of the application instance that served the
+// request. It also becomes the instance ID of the envelope.
+func (b *HTTPStartStop) InstanceIndex(index int) *HTTPStartStop {
+	b.e.InstanceId = strconv.Itoa(index)
+	return b.integer("instance_index", int64(index))
+}
+
+// InstanceID sets the ID of the application instance that served the
+// request.
+func (b *HTTPStartStop) InstanceID(id string) *HTTPStartStop {
+	return b.text("instance_id", id)
+}
+
+// Forwarded sets the addresses of the X-Forwarded-For header. They are
+// encoded with envelope.EncodeList.
+func (b *HTTPStartStop) Forwarded(addrs ...string) *HTTPStartStop {
+	return b.text("forwarded", envelope.EncodeList(addrs))
+}
+
+// Envelope returns the envelope. The builder must not be used afterwards.
+func (b *HTTPStartStop) Envelope() *loggregator_v2.Envelope {
+	return b.e
+}
+
+func (b *HTTPStartStop) text(name, value string) *HTTPStartStop {
+	envelope.DeprecatedTags(b.e).SetText(name, value)
+	return b
+}
+
+func (b *HTTPStartStop) integer(name string, value int64) *HTTPStartStop {
+	envelope.DeprecatedTags(b.e).SetInt(name, value)
+	return b
+}
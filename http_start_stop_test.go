@@ -0,0 +1,67 @@
+package loggregator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPStartStop", func() {
+	It("builds an HTTP timer with deprecated tags", func() {
+		start := time.Unix(0, 100)
+		stop := time.Unix(0, 200)
+
+		e := loggregator.NewHTTPStartStop(start, stop).
+			SourceID("some-app-id").
+			RequestID("some-request-id").
+			PeerType(loggregator.PeerTypeClient).
+			Method("get").
+			URI("https://app.example.com/path").
+			RemoteAddress("10.0.0.1:1234").
+			UserAgent("curl/7.54.0").
+			StatusCode(200).
+			ContentLength(1031).
+			InstanceIndex(2).
+			InstanceID("some-instance-id").
+			Forwarded("10.0.0.2", "10.0.0.3").
+			Envelope()
+
+		text := func(v string) *loggregator_v2.Value {
+			return &loggregator_v2.Value{Data: &loggregator_v2.Value_Text{Text: v}}
+		}
+		integer := func(v int64) *loggregator_v2.Value {
+			return &loggregator_v2.Value{Data: &loggregator_v2.Value_Integer{Integer: v}}
+		}
+
+		Expect(e.SourceId).To(Equal("some-app-id"))
+		Expect(e.InstanceId).To(Equal("2"))
+		Expect(e.Timestamp).To(Equal(int64(200)))
+		Expect(e.GetTimer().Name).To(Equal("http"))
+		Expect(e.GetTimer().Start).To(Equal(int64(100)))
+		Expect(e.GetTimer().Stop).To(Equal(int64(200)))
+		Expect(e.Tags).To(BeEmpty())
+
+		expected := map[string]*loggregator_v2.Value{
+			"request_id":     text("some-request-id"),
+			"peer_type":      text("Client"),
+			"method":         text("GET"),
+			"uri":            text("https://app.example.com/path"),
+			"remote_address": text("10.0.0.1:1234"),
+			"user_agent":     text("curl/7.54.0"),
+			"status_code":    integer(200),
+			"content_length": integer(1031),
+			"instance_index": integer(2),
+			"instance_id":    text("some-instance-id"),
+			"forwarded":      text("10.0.0.2\n10.0.0.3"),
+		}
+		Expect(e.DeprecatedTags).To(HaveLen(len(expected)))
+		for k, v := range expected {
+			Expect(proto.Equal(e.DeprecatedTags[k], v)).To(BeTrue(), k)
+		}
+	})
+})
@@ -0,0 +1,279 @@
+// Package httpingest provides an HTTP handler that accepts logs and metrics
+// as JSON and emits them as v2 envelopes. It is a building block for
+// sidecars that let workloads without a gRPC client inject telemetry.
+//
+// Logs are posted to /v1/logs:
+//
+//	{"logs": [{"source_id": "app", "message": "hello", "type": "ERR"}]}
+//
+// Metrics are posted to /v1/metrics:
+//
+//	{"metrics": [
+//	  {"type": "gauge", "name": "memory", "value": 1024, "unit": "bytes"},
+//	  {"type": "counter", "name": "requests", "delta": 3}
+//	]}
+//
+// All entries may carry a source_id, an instance_id, a timestamp in
+// nanoseconds since the epoch and tags. A request is rejected as a whole if
+// any of its entries is invalid.
+package httpingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// defaultMaxBodySize is the size of the largest request body accepted by
+// default.
+const defaultMaxBodySize = 1 << 20
+
+// HandlerOption configures a Handler.
+type HandlerOption func(*Handler)
+
+// WithSourceID configures the source ID of entries without one. Without a
+// default source ID, entries without one are rejected.
+func WithSourceID(id string) HandlerOption {
+	return func(h *Handler) {
+		h.sourceID = id
+	}
+}
+
+// WithTags adds the given tags to every envelope. Tags of the entries take
+// precedence.
+func WithTags(tags map[string]string) HandlerOption {
+	return func(h *Handler) {
+		for k, v := range tags {
+			h.tags[k] = v
+		}
+	}
+}
+
+// WithMaxBodySize configures the size of the largest request body accepted.
+// It defaults to 1MiB.
+func WithMaxBodySize(n int64) HandlerOption {
+	return func(h *Handler) {
+		h.maxBodySize = n
+	}
+}
+
+// Log is a log entry of a request to /v1/logs.
+type Log struct {
+	SourceID   string            `json:"source_id"`
+	InstanceID string            `json:"instance_id"`
+	Timestamp  int64             `json:"timestamp"`
+	Tags       map[string]string `json:"tags"`
+	Message    string            `json:"message"`
+
+	// Type is either "OUT" or "ERR". It defaults to "OUT".
+	Type string `json:"type"`
+}
+
+// Metric is a metric entry of a request to /v1/metrics.
+type Metric struct {
+	SourceID   string            `json:"source_id"`
+	InstanceID string            `json:"instance_id"`
+	Timestamp  int64             `json:"timestamp"`
+	Tags       map[string]string `json:"tags"`
+	Name       string            `json:"name"`
+
+	// Type is either "gauge" or "counter".
+	Type string `json:"type"`
+
+	// Value and Unit are the value of a gauge.
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+
+	// Delta is the increment of a counter.
+	Delta uint64 `json:"delta"`
+}
+
+// Handler serves /v1/logs and /v1/metrics and emits the posted entries to a
+// sink, e.g. an IngressClient. It should be created with the New
+// constructor.
+type Handler struct {
+	sink        loggregator.Sink
+	sourceID    string
+	tags        map[string]string
+	maxBodySize int64
+}
+
+// New returns a Handler that emits to the sink.
+func New(sink loggregator.Sink, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		sink:        sink,
+		tags:        make(map[string]string),
+		maxBodySize: defaultMaxBodySize,
+	}
+
+	for _, o := range opts {
+		o(h)
+	}
+
+	return h
+}
+
+// ServeHTTP accepts POST requests to /v1/logs and /v1/metrics. It responds
+// with 202 Accepted once all entries are emitted, with 400 Bad Request and
+// a JSON error if the body is invalid and with 503 Service Unavailable if
+// the sink fails.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var convert func(*json.Decoder) ([]*loggregator_v2.Envelope, error)
+	switch r.URL.Path {
+	case "/v1/logs":
+		convert = h.logs
+	case "/v1/metrics":
+		convert = h.metrics
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	d.DisallowUnknownFields()
+
+	envs, err := convert(d)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	for _, e := range envs {
+		if err := h.sink.EmitEnvelope(e); err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) logs(d *json.Decoder) ([]*loggregator_v2.Envelope, error) {
+	var body struct {
+		Logs []Log `json:"logs"`
+	}
+	if err := d.Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid body: %s", err)
+	}
+
+	envs := make([]*loggregator_v2.Envelope, 0, len(body.Logs))
+	for i, l := range body.Logs {
+		var t loggregator_v2.Log_Type
+		switch l.Type {
+		case "", "OUT":
+			t = loggregator_v2.Log_OUT
+		case "ERR":
+			t = loggregator_v2.Log_ERR
+		default:
+			return nil, fmt.Errorf("logs[%d]: invalid type %q", i, l.Type)
+		}
+
+		e, err := h.envelope(l.SourceID, l.InstanceID, l.Timestamp, l.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("logs[%d]: %s", i, err)
+		}
+		e.Message = &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{
+				Payload: []byte(l.Message),
+				Type:    t,
+			},
+		}
+
+		envs = append(envs, e)
+	}
+
+	return envs, nil
+}
+
+func (h *Handler) metrics(d *json.Decoder) ([]*loggregator_v2.Envelope, error) {
+	var body struct {
+		Metrics []Metric `json:"metrics"`
+	}
+	if err := d.Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid body: %s", err)
+	}
+
+	envs := make([]*loggregator_v2.Envelope, 0, len(body.Metrics))
+	for i, m := range body.Metrics {
+		if m.Name == "" {
+			return nil, fmt.Errorf("metrics[%d]: name is required", i)
+		}
+
+		e, err := h.envelope(m.SourceID, m.InstanceID, m.Timestamp, m.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("metrics[%d]: %s", i, err)
+		}
+
+		switch m.Type {
+		case "gauge":
+			if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+				return nil, fmt.Errorf("metrics[%d]: value must be finite", i)
+			}
+			e.Message = &loggregator_v2.Envelope_Gauge{
+				Gauge: &loggregator_v2.Gauge{
+					Metrics: map[string]*loggregator_v2.GaugeValue{
+						m.Name: {Value: m.Value, Unit: m.Unit},
+					},
+				},
+			}
+		case "counter":
+			e.Message = &loggregator_v2.Envelope_Counter{
+				Counter: &loggregator_v2.Counter{
+					Name:  m.Name,
+					Delta: m.Delta,
+				},
+			}
+		default:
+			return nil, fmt.Errorf("metrics[%d]: invalid type %q", i, m.Type)
+		}
+
+		envs = append(envs, e)
+	}
+
+	return envs, nil
+}
+
+func (h *Handler) envelope(sourceID, instanceID string, ts int64, tags map[string]string) (*loggregator_v2.Envelope, error) {
+	if sourceID == "" {
+		sourceID = h.sourceID
+	}
+	if sourceID == "" {
+		return nil, fmt.Errorf("source_id is required")
+	}
+
+	if ts == 0 {
+		ts = time.Now().UnixNano()
+	}
+
+	e := &loggregator_v2.Envelope{
+		SourceId:   sourceID,
+		InstanceId: instanceID,
+		Timestamp:  ts,
+		Tags:       make(map[string]string, len(h.tags)+len(tags)),
+	}
+	for k, v := range h.tags {
+		e.Tags[k] = v
+	}
+	for k, v := range tags {
+		e.Tags[k] = v
+	}
+
+	return e, nil
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
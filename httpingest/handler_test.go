@@ -0,0 +1,124 @@
+package httpingest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/go-loggregator/fakes"
+	"code.cloudfoundry.org/go-loggregator/httpingest"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		sink    *fakes.FakeSink
+		handler *httpingest.Handler
+	)
+
+	BeforeEach(func() {
+		sink = &fakes.FakeSink{}
+		handler = httpingest.New(
+			sink,
+			httpingest.WithSourceID("default-source"),
+			httpingest.WithTags(map[string]string{"sidecar": "true", "overridden": "false"}),
+		)
+	})
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	It("emits logs", func() {
+		rec := post("/v1/logs", `{"logs": [
+			{"source_id": "some-source", "instance_id": "1", "timestamp": 1234, "message": "some-message", "type": "ERR", "tags": {"overridden": "true"}},
+			{"message": "other-message"}
+		]}`)
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+
+		Expect(sink.EmitEnvelopeCallCount()).To(Equal(2))
+		Expect(sink.EmitEnvelopeArgsForCall(0)).To(Equal(&loggregator_v2.Envelope{
+			SourceId:   "some-source",
+			InstanceId: "1",
+			Timestamp:  1234,
+			Tags:       map[string]string{"sidecar": "true", "overridden": "true"},
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte("some-message"), Type: loggregator_v2.Log_ERR},
+			},
+		}))
+
+		e := sink.EmitEnvelopeArgsForCall(1)
+		Expect(e.SourceId).To(Equal("default-source"))
+		Expect(e.Timestamp).ToNot(BeZero())
+		Expect(e.GetLog().GetType()).To(Equal(loggregator_v2.Log_OUT))
+	})
+
+	It("emits gauges and counters", func() {
+		rec := post("/v1/metrics", `{"metrics": [
+			{"type": "gauge", "name": "memory", "value": 1024, "unit": "bytes"},
+			{"type": "counter", "name": "requests", "delta": 3}
+		]}`)
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+
+		Expect(sink.EmitEnvelopeCallCount()).To(Equal(2))
+		Expect(sink.EmitEnvelopeArgsForCall(0).GetGauge().GetMetrics()).To(Equal(map[string]*loggregator_v2.GaugeValue{
+			"memory": {Value: 1024, Unit: "bytes"},
+		}))
+		Expect(sink.EmitEnvelopeArgsForCall(1).GetCounter()).To(Equal(&loggregator_v2.Counter{Name: "requests", Delta: 3}))
+	})
+
+	DescribeTable("rejects invalid requests without emitting", func(path, body, msg string) {
+		rec := post(path, body)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(msg))
+		Expect(sink.EmitEnvelopeCallCount()).To(BeZero())
+	},
+		Entry("malformed JSON", "/v1/logs", `{"logs": [`, "invalid body"),
+		Entry("unknown fields", "/v1/logs", `{"logs": [{"msg": "x"}]}`, "invalid body"),
+		Entry("log type", "/v1/logs", `{"logs": [{"message": "x"}, {"message": "x", "type": "DEBUG"}]}`, `logs[1]: invalid type \"DEBUG\"`),
+		Entry("metric type", "/v1/metrics", `{"metrics": [{"name": "x", "type": "timer"}]}`, `metrics[0]: invalid type \"timer\"`),
+		Entry("metric name", "/v1/metrics", `{"metrics": [{"type": "gauge"}]}`, "metrics[0]: name is required"),
+		Entry("negative delta", "/v1/metrics", `{"metrics": [{"type": "counter", "name": "x", "delta": -1}]}`, "invalid body"),
+	)
+
+	It("rejects entries without a source ID if there is no default", func() {
+		handler = httpingest.New(sink)
+
+		rec := post("/v1/logs", `{"logs": [{"message": "x"}]}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring("logs[0]: source_id is required"))
+	})
+
+	It("rejects bodies larger than the maximum", func() {
+		handler = httpingest.New(sink, httpingest.WithSourceID("s"), httpingest.WithMaxBodySize(16))
+
+		rec := post("/v1/logs", `{"logs": [{"message": "some-long-message"}]}`)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("responds with 503 if the sink fails", func() {
+		sink.EmitEnvelopeReturns(errors.New("unavailable"))
+
+		rec := post("/v1/logs", `{"logs": [{"message": "x"}]}`)
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("only accepts POST requests", func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/logs", nil))
+		Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(rec.Header().Get("Allow")).To(Equal(http.MethodPost))
+	})
+
+	It("responds with 404 for other paths", func() {
+		rec := post("/v1/traces", `{}`)
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,13 @@
+package httpingest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHttpingest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTP Ingest Suite")
+}
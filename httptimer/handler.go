@@ -0,0 +1,133 @@
+// Package httptimer provides an HTTP middleware that emits a timer envelope
+// for every request it serves. The timers carry the same tags as the HTTP
+// timers emitted by gorouter, including the IDs of the request's trace, so
+// that the timers of all hops of a request line up downstream.
+package httptimer
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+)
+
+// Sender is the interface of the client that can be used to emit timers.
+type Sender interface {
+	EmitTimer(name string, start, stop time.Time, opts ...loggregator.EmitTimerOption)
+}
+
+// Handler wraps an http.Handler and emits a timer named "http" for every
+// request.
+type Handler struct {
+	sender     Sender
+	next       http.Handler
+	sourceID   string
+	instanceID string
+	tags       map[string]string
+}
+
+// HandlerOption is the option that provides configuration for a Handler.
+type HandlerOption func(*Handler)
+
+// WithSourceInfo returns a HandlerOption to configure the source ID and
+// instance ID of the emitted timers.
+func WithSourceInfo(sourceID, instanceID string) HandlerOption {
+	return func(h *Handler) {
+		h.sourceID = sourceID
+		h.instanceID = instanceID
+	}
+}
+
+// WithTags returns a HandlerOption that adds the given tags to every timer
+// emitted.
+func WithTags(tags map[string]string) HandlerOption {
+	return func(h *Handler) {
+		for k, v := range tags {
+			h.tags[k] = v
+		}
+	}
+}
+
+// New returns a Handler that serves requests with next and emits timers via
+// the given sender.
+func New(sender Sender, next http.Handler, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		sender: sender,
+		next:   next,
+		tags:   make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(h)
+	}
+
+	return h
+}
+
+// ServeHTTP serves the request with the wrapped handler and emits a timer
+// once it is done. Before the request is handed on, its trace headers are
+// completed: a request with a traceparent header gets b3 headers and vice
+// versa, and a request without either starts a new trace. This way the
+// wrapped handler can propagate the trace to outgoing requests.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	tc, ok := traceFromHeaders(r.Header)
+	if !ok {
+		tc = newTraceContext()
+	}
+	tc.setHeaders(r.Header)
+
+	rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+	h.next.ServeHTTP(rw, r)
+
+	tags := map[string]string{
+		"peer_type":      "Server",
+		"method":         r.Method,
+		"uri":            r.URL.String(),
+		"remote_address": r.RemoteAddr,
+		"user_agent":     r.UserAgent(),
+		"status_code":    strconv.Itoa(rw.status),
+		"content_length": strconv.FormatInt(rw.written, 10),
+		"trace_id":       tc.traceID,
+		"span_id":        tc.spanID,
+	}
+	if id := r.Header.Get("X-Vcap-Request-Id"); id != "" {
+		tags["request_id"] = id
+	}
+	for k, v := range h.tags {
+		tags[k] = v
+	}
+
+	h.sender.EmitTimer(
+		"http",
+		start,
+		time.Now(),
+		loggregator.WithTimerSourceInfo(h.sourceID, h.instanceID),
+		loggregator.WithEnvelopeTags(tags),
+	)
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	written     int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
@@ -0,0 +1,134 @@
+package httptimer_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/httptimer"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		sender   *spySender
+		upstream http.Header
+		handler  *httptimer.Handler
+	)
+
+	BeforeEach(func() {
+		sender = &spySender{}
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upstream = r.Header
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("hello"))
+		})
+		handler = httptimer.New(
+			sender,
+			next,
+			httptimer.WithSourceInfo("source-id", "instance-id"),
+			httptimer.WithTags(map[string]string{"some-tag": "some-value"}),
+		)
+	})
+
+	serve := func(req *http.Request) *loggregator_v2.Envelope {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		Expect(sender.envelopes).To(HaveLen(1))
+		return sender.envelopes[0]
+	}
+
+	It("emits a timer for the request", func() {
+		req := httptest.NewRequest("GET", "http://example.com/some/path?q=1", nil)
+		req.Header.Set("User-Agent", "some-agent")
+		req.Header.Set("X-Vcap-Request-Id", "some-request-id")
+
+		e := serve(req)
+
+		Expect(e.SourceId).To(Equal("source-id"))
+		Expect(e.InstanceId).To(Equal("instance-id"))
+		Expect(e.GetTimer().Name).To(Equal("http"))
+		Expect(e.GetTimer().Stop).To(BeNumerically(">=", e.GetTimer().Start))
+		Expect(e.Tags).To(HaveKeyWithValue("peer_type", "Server"))
+		Expect(e.Tags).To(HaveKeyWithValue("method", "GET"))
+		Expect(e.Tags).To(HaveKeyWithValue("uri", "http://example.com/some/path?q=1"))
+		Expect(e.Tags).To(HaveKeyWithValue("user_agent", "some-agent"))
+		Expect(e.Tags).To(HaveKeyWithValue("status_code", "418"))
+		Expect(e.Tags).To(HaveKeyWithValue("content_length", "5"))
+		Expect(e.Tags).To(HaveKeyWithValue("request_id", "some-request-id"))
+		Expect(e.Tags).To(HaveKeyWithValue("some-tag", "some-value"))
+	})
+
+	It("tags the timer with the trace of a traceparent header", func() {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		e := serve(req)
+
+		Expect(e.Tags).To(HaveKeyWithValue("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(e.Tags).To(HaveKeyWithValue("span_id", "00f067aa0ba902b7"))
+		Expect(upstream.Get("b3")).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"))
+		Expect(upstream.Get("X-B3-TraceId")).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(upstream.Get("X-B3-SpanId")).To(Equal("00f067aa0ba902b7"))
+	})
+
+	It("tags the timer with the trace of a b3 header", func() {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("b3", "a3ce929d0e0e4736-00f067aa0ba902b7-0")
+
+		e := serve(req)
+
+		Expect(e.Tags).To(HaveKeyWithValue("trace_id", "0000000000000000a3ce929d0e0e4736"))
+		Expect(e.Tags).To(HaveKeyWithValue("span_id", "00f067aa0ba902b7"))
+		Expect(upstream.Get("traceparent")).To(Equal("00-0000000000000000a3ce929d0e0e4736-00f067aa0ba902b7-00"))
+	})
+
+	It("tags the timer with the trace of the X-B3 headers", func() {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-B3-TraceId", "4bf92f3577b34da6a3ce929d0e0e4736")
+		req.Header.Set("X-B3-SpanId", "00f067aa0ba902b7")
+		req.Header.Set("X-B3-Sampled", "1")
+
+		e := serve(req)
+
+		Expect(e.Tags).To(HaveKeyWithValue("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(upstream.Get("traceparent")).To(Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	})
+
+	It("starts a new trace for requests without a valid trace", func() {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+
+		e := serve(req)
+
+		Expect(e.Tags["trace_id"]).To(MatchRegexp("^[0-9a-f]{32}$"))
+		Expect(e.Tags["trace_id"]).NotTo(Equal("00000000000000000000000000000000"))
+		Expect(e.Tags["span_id"]).To(MatchRegexp("^[0-9a-f]{16}$"))
+		Expect(upstream.Get("traceparent")).To(Equal("00-" + e.Tags["trace_id"] + "-" + e.Tags["span_id"] + "-01"))
+	})
+})
+
+type spySender struct {
+	envelopes []*loggregator_v2.Envelope
+}
+
+func (s *spySender) EmitTimer(name string, start, stop time.Time, opts ...loggregator.EmitTimerOption) {
+	e := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Timer{
+			Timer: &loggregator_v2.Timer{
+				Name:  name,
+				Start: start.UnixNano(),
+				Stop:  stop.UnixNano(),
+			},
+		},
+		Tags: make(map[string]string),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+
+	s.envelopes = append(s.envelopes, e)
+}
@@ -0,0 +1,13 @@
+package httptimer_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHttptimer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTP Timer Suite")
+}
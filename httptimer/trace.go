@@ -0,0 +1,159 @@
+package httptimer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// traceContext holds the hex encoded IDs of a trace and of the span of the
+// current request.
+type traceContext struct {
+	traceID string
+	spanID  string
+	sampled bool
+}
+
+// traceFromHeaders reads the trace context from the W3C traceparent header
+// or, if it is missing or invalid, from the b3 headers.
+func traceFromHeaders(h http.Header) (traceContext, bool) {
+	if tc, ok := parseTraceparent(h.Get("traceparent")); ok {
+		return tc, true
+	}
+
+	if tc, ok := parseB3Single(h.Get("b3")); ok {
+		return tc, true
+	}
+
+	return parseB3Multi(h)
+}
+
+// parseTraceparent parses a traceparent header of the form
+// version-traceid-spanid-flags, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func parseTraceparent(v string) (traceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return traceContext{}, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return traceContext{}, false
+	}
+
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if !isID(traceID, 32) || !isID(spanID, 16) || !isHex(flags, 2) {
+		return traceContext{}, false
+	}
+
+	f, _ := hex.DecodeString(flags)
+
+	return traceContext{
+		traceID: traceID,
+		spanID:  spanID,
+		sampled: f[0]&1 == 1,
+	}, true
+}
+
+// parseB3Single parses a b3 header of the form
+// traceid-spanid[-sampled[-parentspanid]].
+func parseB3Single(v string) (traceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return traceContext{}, false
+	}
+
+	tc := traceContext{
+		traceID: padTraceID(parts[0]),
+		spanID:  parts[1],
+	}
+	if !isID(tc.traceID, 32) || !isID(tc.spanID, 16) {
+		return traceContext{}, false
+	}
+	if len(parts) > 2 {
+		tc.sampled = parts[2] == "1" || parts[2] == "d"
+	}
+
+	return tc, true
+}
+
+// parseB3Multi parses the X-B3-TraceId, X-B3-SpanId and X-B3-Sampled
+// headers.
+func parseB3Multi(h http.Header) (traceContext, bool) {
+	tc := traceContext{
+		traceID: padTraceID(h.Get("X-B3-TraceId")),
+		spanID:  h.Get("X-B3-SpanId"),
+		sampled: h.Get("X-B3-Sampled") == "1" || h.Get("X-B3-Flags") == "1",
+	}
+	if !isID(tc.traceID, 32) || !isID(tc.spanID, 16) {
+		return traceContext{}, false
+	}
+
+	return tc, true
+}
+
+// newTraceContext starts a new sampled trace with random IDs.
+func newTraceContext() traceContext {
+	return traceContext{
+		traceID: randomID(16),
+		spanID:  randomID(8),
+		sampled: true,
+	}
+}
+
+// setHeaders sets the traceparent and b3 headers for the trace context.
+func (tc traceContext) setHeaders(h http.Header) {
+	flags, sampled := "00", "0"
+	if tc.sampled {
+		flags, sampled = "01", "1"
+	}
+
+	h.Set("traceparent", fmt.Sprintf("00-%s-%s-%s", tc.traceID, tc.spanID, flags))
+	h.Set("b3", fmt.Sprintf("%s-%s-%s", tc.traceID, tc.spanID, sampled))
+	h.Set("X-B3-TraceId", tc.traceID)
+	h.Set("X-B3-SpanId", tc.spanID)
+	h.Set("X-B3-Sampled", sampled)
+}
+
+// padTraceID left pads 64 bit b3 trace IDs to 128 bits.
+func padTraceID(id string) string {
+	if len(id) == 16 {
+		return strings.Repeat("0", 16) + id
+	}
+	return id
+}
+
+// isID reports whether s is a lower case hex string of length n that is not
+// all zeros.
+func isID(s string, n int) bool {
+	return isHex(s, n) && strings.Trim(s, "0") != ""
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			panic(fmt.Sprintf("failed to read random bytes: %s", err))
+		}
+
+		id := hex.EncodeToString(b)
+		if isID(id, 2*n) {
+			return id
+		}
+	}
+}
@@ -3,9 +3,11 @@ package loggregator
 import (
 	"crypto/tls"
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
 	"log"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -13,6 +15,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
+	"code.cloudfoundry.org/go-loggregator/envelope"
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 )
 
@@ -25,6 +28,45 @@ func WithDialOptions(opts ...grpc.DialOption) IngressOption {
 	}
 }
 
+// WithTransportDecorator wraps the gRPC client the envelopes are sent with,
+// e.g. to inject faults in tests with the chaos package.
+func WithTransportDecorator(d func(loggregator_v2.IngressClient) loggregator_v2.IngressClient) IngressOption {
+	return func(c *IngressClient) {
+		c.transportDecorator = d
+	}
+}
+
+// WithSenderShards spreads emitted envelopes over n buffers instead of one
+// to reduce contention when many go routines emit at high rates. Envelopes
+// are assigned to a buffer by their source ID and instance ID, so the order
+// of the envelopes of a source instance is preserved. Each buffer is drained
+// into the batch of the sender by its own go routine.
+func WithSenderShards(n uint) IngressOption {
+	return func(c *IngressClient) {
+		c.senderShards = n
+	}
+}
+
+// WithLoadBalancingPolicy configures the gRPC load balancing policy, e.g.
+// "round_robin", that is used when the address resolves to more than one
+// agent. By default, gRPC picks the first address. Envelopes are sent on a
+// long-lived stream, so the policy picks an agent whenever a stream is
+// opened, e.g. after the previous agent failed. To resolve every address of
+// a host name, use an address with the dns scheme, e.g.
+// "dns:///agents.example.com:3458".
+func WithLoadBalancingPolicy(policy string) IngressOption {
+	return WithServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, policy))
+}
+
+// WithServiceConfig configures the default gRPC service config of the
+// connection to the agent. The config is given as JSON, see
+// https://github.com/grpc/grpc/blob/master/doc/service_config.md.
+func WithServiceConfig(config string) IngressOption {
+	return func(c *IngressClient) {
+		c.dialOpts = append(c.dialOpts, grpc.WithDefaultServiceConfig(config))
+	}
+}
+
 // WithTag allows for the configuration of arbitrary string value
 // metadata which will be included in all data sent to Loggregator
 func WithTag(name, value string) IngressOption {
@@ -33,6 +75,28 @@ func WithTag(name, value string) IngressOption {
 	}
 }
 
+// WithDefaultSourceInfo configures the source ID and instance ID of
+// envelopes that are emitted without them.
+func WithDefaultSourceInfo(sourceID, instanceID string) IngressOption {
+	return func(c *IngressClient) {
+		c.sourceID = sourceID
+		c.instanceID = instanceID
+	}
+}
+
+// WithFrozenTags replaces the client's default tags with the given tags.
+// Tags configured by any other option, e.g. WithTag or a MetronConfig, are
+// ignored regardless of the order of the options. This is useful in tests
+// that assert on the default tags.
+func WithFrozenTags(tags map[string]string) IngressOption {
+	return func(c *IngressClient) {
+		c.frozenTags = make(map[string]string, len(tags))
+		for k, v := range tags {
+			c.frozenTags[k] = v
+		}
+	}
+}
+
 // WithBatchMaxSize allows for the configuration of the number of messages to
 // collect before emitting them into loggregator. By default, its value is 100
 // messages.
@@ -57,6 +121,94 @@ func WithBatchFlushInterval(d time.Duration) IngressOption {
 	}
 }
 
+// WithBatchPartitionedBySourceID configures the client to split each batch
+// into one EnvelopeBatch per source ID. This gives downstream consumers that
+// process envelopes per source better locality. The envelopes of each source
+// keep their relative order.
+func WithBatchPartitionedBySourceID() IngressOption {
+	return func(c *IngressClient) {
+		c.partitionBySourceID = true
+	}
+}
+
+// WithMaxInFlightBatches allows up to n batches to be in flight on the
+// BatchSender stream. Batches are handed off to a separate go routine that
+// writes them to the stream, so new envelopes are batched while earlier
+// batches are still being sent. This improves throughput on high-latency
+// links. It has no effect with ordered or at-least-once delivery, which
+// require the result of a batch before the next one is sent.
+func WithMaxInFlightBatches(n uint) IngressOption {
+	return func(c *IngressClient) {
+		c.maxInFlight = n
+	}
+}
+
+// WithOrderedDelivery guarantees that envelopes of the same source ID are
+// delivered in the order they were emitted, even across reconnects. Instead
+// of dropping a batch that failed to send, the client retries it ahead of any
+// newer envelopes. At most maxPending envelopes are retained for retry; when
+// this limit is exceeded the oldest envelopes are dropped.
+func WithOrderedDelivery(maxPending uint) IngressOption {
+	return func(c *IngressClient) {
+		c.orderedDelivery = true
+		c.maxRetained = int(maxPending)
+	}
+}
+
+// WithAtLeastOnceDelivery configures the client to only consider a batch
+// delivered once the agent has acknowledged it. Batches are sent via the
+// unary Send RPC and batches that were not acknowledged are re-sent ahead of
+// newer envelopes. Since a batch may be delivered more than once, every
+// envelope is tagged with a unique `delivery_id` that downstream consumers
+// can use to discard duplicates. At most maxPending envelopes are retained
+// for retry; when this limit is exceeded the oldest envelopes are dropped.
+func WithAtLeastOnceDelivery(maxPending uint) IngressOption {
+	return func(c *IngressClient) {
+		c.atLeastOnce = true
+		c.orderedDelivery = true
+		c.maxRetained = int(maxPending)
+	}
+}
+
+// WithSendTimeout configures how long emitting an envelope may block when the
+// client's buffer is full. When the timeout elapses the envelope is dropped
+// and ErrTimeout is logged. EmitEvent returns ErrTimeout when the given
+// context has no deadline and the timeout elapses. A timeout of zero disables
+// the timeout. It defaults to 5 seconds.
+func WithSendTimeout(d time.Duration) IngressOption {
+	return func(c *IngressClient) {
+		c.sendTimeout = d
+	}
+}
+
+// WithNonBlockingSend configures the client to never block when emitting
+// envelopes. Envelopes that do not fit into the client's buffer are dropped
+// and ErrQueueFull is logged.
+func WithNonBlockingSend() IngressOption {
+	return func(c *IngressClient) {
+		c.nonBlocking = true
+	}
+}
+
+// WithMaxEnvelopeSize configures the maximum size in bytes of a marshaled
+// envelope. Larger envelopes are dropped and ErrEnvelopeTooLarge is logged.
+// It defaults to 4MiB, the default maximum message size of gRPC servers.
+func WithMaxEnvelopeSize(bytes int) IngressOption {
+	return func(c *IngressClient) {
+		c.maxEnvelopeSize = bytes
+	}
+}
+
+// WithUnsafeNoCopy configures EmitLogBytes to use the given payload as is
+// instead of copying it. The caller must not modify the payload after it has
+// been emitted, because the envelope is only marshaled when its batch is
+// sent.
+func WithUnsafeNoCopy() IngressOption {
+	return func(c *IngressClient) {
+		c.unsafeNoCopy = true
+	}
+}
+
 // WithAddr allows for the configuration of the loggregator v2 address.
 // The value to defaults to localhost:3458, which happens to be the default
 // address in the loggregator server.
@@ -87,24 +239,118 @@ func WithContext(ctx context.Context) IngressOption {
 	}
 }
 
+// WithVersionInfo stamps every envelope with the name, version and commit of
+// the emitting component. Additionally, a single event envelope is emitted
+// when the client is created to announce the component's version.
+func WithVersionInfo(name, version, commit string) IngressOption {
+	return func(c *IngressClient) {
+		c.tags["component"] = name
+		c.tags["component_version"] = version
+		c.tags["component_commit"] = commit
+		c.versionInfo = &versionInfo{
+			name:    name,
+			version: version,
+			commit:  commit,
+		}
+	}
+}
+
+type versionInfo struct {
+	name    string
+	version string
+	commit  string
+}
+
 // IngressClient represents an emitter into loggregator. It should be created with the
 // NewIngressClient constructor.
 type IngressClient struct {
+	// expired and droppedResults are accessed atomically and therefore the
+	// first fields to keep them 64-bit aligned on 32-bit platforms.
+	expired        uint64
+	droppedResults uint64
+
 	client loggregator_v2.IngressClient
 	sender loggregator_v2.Ingress_BatchSenderClient
 
-	envelopes chan *loggregator_v2.Envelope
-	tags      map[string]string
-
+	envelopes         chan queued
+	shards            []chan queued
+	senderShards      uint
+	priorityEnvelopes chan queued
+	isPriority        func(*loggregator_v2.Envelope) bool
+	routes            []routingRule
+	defaultRoute      Route
+	frozenTags        map[string]string
+	sourceID          string
+	instanceID        string
+
+	// configMu guards the settings that may be changed with UpdateConfig.
+	configMu           sync.RWMutex
+	tags               map[string]string
 	batchMaxSize       uint
 	batchFlushInterval time.Duration
-	addr               string
-
-	dialOpts []grpc.DialOption
-
-	logger Logger
 
+	partitionBySourceID bool
+	maxInFlight         uint
+	orderedDelivery     bool
+	atLeastOnce         bool
+	maxRetained         int
+	sendTimeout         time.Duration
+	drainTimeout        time.Duration
+	nonBlocking         bool
+	maxEnvelopeSize     int
+	proxyURL            string
+	unsafeNoCopy        bool
+	addr                string
+
+	dialOpts           []grpc.DialOption
+	transportDecorator func(loggregator_v2.IngressClient) loggregator_v2.IngressClient
+
+	middleware []Middleware
+	sampler    *sampler
+	limiter    *tokenBucket
+	stages     []Middleware
+	emitFunc   EmitFunc
+	mirror     *logMirror
+
+	results chan SendResult
+	trackMu sync.Mutex
+	tracked map[*loggregator_v2.Envelope]EnvelopeID
+	lastID  EnvelopeID
+
+	hooksMu    sync.RWMutex
+	beforeEmit []BeforeEmitHook
+	afterEmit  []AfterEmitHook
+
+	logger         Logger
+	deadLetter     DeadLetterHandler
+	traceExtractor TraceExtractor
+
+	versionInfo *versionInfo
+
+	gauges *gaugeCoalescer
+
+	breaker       *circuitBreaker
+	breakerSilent bool
+	deliveryIDs   *deliveryIDGenerator
+	sequencer     *sequencer
+
+	emitTimestamps bool
+	clock          Clock
+
+	maxAge   time.Duration
+	deadline time.Duration
+
+	logChunking    bool
+	binaryPayloads BinaryPayloadMode
+
+	inFlight     chan []*loggregator_v2.Envelope
+	inFlightErrs chan error
+
+	closeMu     sync.RWMutex
+	closed      bool
 	closeErrors chan error
+	control     chan controlMsg
+	status      sendStatus
 
 	ctx    context.Context
 	cancel func()
@@ -114,13 +360,17 @@ type IngressClient struct {
 // must share a CA with the loggregator server.
 func NewIngressClient(tlsConfig *tls.Config, opts ...IngressOption) (*IngressClient, error) {
 	c := &IngressClient{
-		envelopes:          make(chan *loggregator_v2.Envelope, 100),
+		envelopes:          make(chan queued, 100),
 		tags:               make(map[string]string),
 		batchMaxSize:       100,
 		batchFlushInterval: 100 * time.Millisecond,
+		sendTimeout:        5 * time.Second,
+		drainTimeout:       5 * time.Second,
+		maxEnvelopeSize:    4 * 1024 * 1024,
 		addr:               "localhost:3458",
 		logger:             log.New(ioutil.Discard, "", 0),
-		closeErrors:        make(chan error),
+		closeErrors:        make(chan error, 1),
+		control:            make(chan controlMsg, 1),
 		ctx:                context.Background(),
 	}
 
@@ -128,7 +378,51 @@ func NewIngressClient(tlsConfig *tls.Config, opts ...IngressOption) (*IngressCli
 		o(c)
 	}
 
-	c.ctx, c.cancel = context.WithCancel(c.ctx)
+	if c.frozenTags != nil {
+		c.tags = c.frozenTags
+	}
+
+	if c.mirror != nil {
+		c.stages = append(c.stages, c.mirror.middleware)
+	}
+	if c.sampler != nil {
+		c.stages = append(c.stages, c.sampler.middleware)
+	}
+	if c.limiter != nil {
+		c.limiter.clock = c.clock
+		c.limiter.last = c.limiter.now()
+		c.stages = append(c.stages, c.limiter.middleware)
+	}
+	c.stages = append(c.stages, c.middleware...)
+	c.emitFunc = chain(c.stages, func(e *loggregator_v2.Envelope) error {
+		return c.queue(e, routing{})
+	})
+
+	if c.isPriority != nil {
+		c.priorityEnvelopes = make(chan queued, cap(c.envelopes))
+	}
+
+	if c.breaker != nil {
+		c.breaker.onStateChange = func(open bool) {
+			go c.emitCircuitBreakerEvent(open)
+		}
+	}
+
+	if c.atLeastOnce {
+		var err error
+		c.deliveryIDs, err = newDeliveryIDGenerator()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.proxyURL != "" {
+		opt, err := proxyDialOption(c.proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		c.dialOpts = append(c.dialOpts, opt)
+	}
 
 	c.dialOpts = append(c.dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 
@@ -139,13 +433,65 @@ func NewIngressClient(tlsConfig *tls.Config, opts ...IngressOption) (*IngressCli
 	if err != nil {
 		return nil, err
 	}
+
+	// The context is only created once nothing can fail anymore so that an
+	// error does not leak it.
+	c.ctx, c.cancel = context.WithCancel(c.ctx)
+
 	c.client = loggregator_v2.NewIngressClient(conn)
+	if c.transportDecorator != nil {
+		c.client = c.transportDecorator(c.client)
+	}
+
+	if c.maxInFlight > 1 && !c.orderedDelivery {
+		c.inFlight = make(chan []*loggregator_v2.Envelope, c.maxInFlight-1)
+		c.inFlightErrs = make(chan error)
+		go c.startWriter()
+	}
 
-	go c.startSender()
+	if c.senderShards > 1 {
+		c.startShards()
+	}
+
+	go c.startDispatcher()
+
+	if c.gauges != nil {
+		go c.gauges.run(c.enqueue)
+	}
+
+	if c.versionInfo != nil {
+		go c.emitStartupEvent()
+	}
 
 	return c, nil
 }
 
+func (c *IngressClient) emitStartupEvent() {
+	ctx, cancel := context.WithTimeout(c.ctx, 10*time.Second)
+	defer cancel()
+
+	err := c.EmitEvent(
+		ctx,
+		fmt.Sprintf("%s started", c.versionInfo.name),
+		fmt.Sprintf("version: %s, commit: %s", c.versionInfo.version, c.versionInfo.commit),
+	)
+	if err != nil {
+		c.logger.Printf("Error while emitting startup event: %s", err)
+	}
+}
+
+// Tags returns a copy of the tags that are added to every envelope emitted
+// by the client.
+func (c *IngressClient) Tags() map[string]string {
+	defaults := c.defaultTags()
+	tags := make(map[string]string, len(defaults))
+	for k, v := range defaults {
+		tags[k] = v
+	}
+
+	return tags
+}
+
 // protoEditor is required for v1 envelopes. It should be removed once v1
 // is removed. It is necessary to prevent any v1 dependency in the v2 path.
 type protoEditor interface {
@@ -156,6 +502,7 @@ type protoEditor interface {
 	SetLogToStdout()
 	SetGaugeValue(name string, value float64, unit string)
 	SetDelta(d uint64)
+	SetTotal(t uint64)
 	SetTag(name, value string)
 }
 
@@ -168,14 +515,16 @@ func WithAppInfo(appID, sourceType, sourceInstance string) EmitLogOption {
 	return WithSourceInfo(appID, sourceType, sourceInstance)
 }
 
-// WithSourceInfo configures the meta data associated with emitted data
+// WithSourceInfo configures the meta data associated with emitted data. The
+// source type should be one of the conventional source types defined in the
+// envelope package, e.g. envelope.SourceTypeApp, or a qualification of one.
 func WithSourceInfo(sourceID, sourceType, sourceInstance string) EmitLogOption {
 	return func(m proto.Message) {
 		switch e := m.(type) {
 		case *loggregator_v2.Envelope:
 			e.SourceId = sourceID
 			e.InstanceId = sourceInstance
-			e.Tags["source_type"] = sourceType
+			e.Tags[envelope.SourceTypeTag] = sourceType
 		case protoEditor:
 			e.SetLogAppInfo(sourceID, sourceType, sourceInstance)
 		default:
@@ -201,26 +550,26 @@ func WithStdout() EmitLogOption {
 
 // EmitLog sends a message to loggregator.
 func (c *IngressClient) EmitLog(message string, opts ...EmitLogOption) {
-	e := &loggregator_v2.Envelope{
-		Timestamp: time.Now().UnixNano(),
-		Message: &loggregator_v2.Envelope_Log{
-			Log: &loggregator_v2.Log{
-				Payload: []byte(message),
-				Type:    loggregator_v2.Log_ERR,
-			},
-		},
-		Tags: make(map[string]string),
-	}
+	c.enqueueLog(newLogEnvelope(c.now(), c.defaultTags(), []byte(message), opts))
+}
 
-	for k, v := range c.tags {
-		e.Tags[k] = v
+// EmitLogBytes sends a log with the given payload to loggregator. The payload
+// is copied unless the client is configured with WithUnsafeNoCopy.
+func (c *IngressClient) EmitLogBytes(payload []byte, opts ...EmitLogOption) {
+	if !c.unsafeNoCopy {
+		payload = append([]byte(nil), payload...)
 	}
 
-	for _, o := range opts {
-		o(e)
-	}
+	c.enqueueLog(newLogEnvelope(c.now(), c.defaultTags(), payload, opts))
+}
 
-	c.envelopes <- e
+// enqueueLog encodes the payload of the log envelope and enqueues it or, if
+// it is too large and chunking is enabled, its chunks.
+func (c *IngressClient) enqueueLog(e *loggregator_v2.Envelope) {
+	c.encodePayload(e)
+	for _, chunk := range c.chunk(e) {
+		c.enqueue(chunk)
+	}
 }
 
 // EmitGaugeOption is the option type passed into EmitGauge.
@@ -252,9 +601,9 @@ func WithGaugeSourceInfo(sourceID, instanceID string) EmitGaugeOption {
 // WithGaugeValue adds a gauge information. For example,
 // to send information about current CPU usage, one might use:
 //
-// WithGaugeValue("cpu", 3.0, "percent")
+// WithGaugeValue("cpu", 3.0, units.Percentage)
 //
-// An number of calls to WithGaugeValue may be passed into EmitGauge.
+// The units package enumerates the units to use. An number of calls to WithGaugeValue may be passed into EmitGauge.
 // If there are duplicate names in any of the options, i.e., "cpu" and "cpu",
 // then the last EmitGaugeOption will take precedence.
 func WithGaugeValue(name string, value float64, unit string) EmitGaugeOption {
@@ -274,25 +623,12 @@ func WithGaugeValue(name string, value float64, unit string) EmitGaugeOption {
 // If no EmitGaugeOption values are present, the client will emit
 // an empty gauge.
 func (c *IngressClient) EmitGauge(opts ...EmitGaugeOption) {
-	e := &loggregator_v2.Envelope{
-		Timestamp: time.Now().UnixNano(),
-		Message: &loggregator_v2.Envelope_Gauge{
-			Gauge: &loggregator_v2.Gauge{
-				Metrics: make(map[string]*loggregator_v2.GaugeValue),
-			},
-		},
-		Tags: make(map[string]string),
-	}
-
-	for k, v := range c.tags {
-		e.Tags[k] = v
-	}
-
-	for _, o := range opts {
-		o(e)
+	e := newGaugeEnvelope(c.now(), c.defaultTags(), opts)
+	if c.gauges != nil && c.gauges.add(e) {
+		return
 	}
 
-	c.envelopes <- e
+	c.enqueue(e)
 }
 
 // EmitCounterOption is the option type passed into EmitCounter.
@@ -312,6 +648,20 @@ func WithDelta(d uint64) EmitCounterOption {
 	}
 }
 
+// WithTotal is an option that sets the total for a counter.
+func WithTotal(t uint64) EmitCounterOption {
+	return func(m proto.Message) {
+		switch e := m.(type) {
+		case *loggregator_v2.Envelope:
+			e.GetCounter().Total = t
+		case protoEditor:
+			e.SetTotal(t)
+		default:
+			panic(fmt.Sprintf("unsupported Message type: %T", m))
+		}
+	}
+}
+
 // WithCounterAppInfo configures an envelope with both the app ID and index.
 // Exists for backward compatability. If possible, use WithCounterSourceInfo
 // instead.
@@ -337,26 +687,7 @@ func WithCounterSourceInfo(sourceID, instanceID string) EmitCounterOption {
 
 // EmitCounter sends a counter envelope with a delta of 1.
 func (c *IngressClient) EmitCounter(name string, opts ...EmitCounterOption) {
-	e := &loggregator_v2.Envelope{
-		Timestamp: time.Now().UnixNano(),
-		Message: &loggregator_v2.Envelope_Counter{
-			Counter: &loggregator_v2.Counter{
-				Name:  name,
-				Delta: uint64(1),
-			},
-		},
-		Tags: make(map[string]string),
-	}
-
-	for k, v := range c.tags {
-		e.Tags[k] = v
-	}
-
-	for _, o := range opts {
-		o(e)
-	}
-
-	c.envelopes <- e
+	c.enqueue(newCounterEnvelope(c.now(), c.defaultTags(), name, opts))
 }
 
 // EmitTimerOption is the option type passed into EmitTimer.
@@ -380,27 +711,78 @@ func WithTimerSourceInfo(sourceID, instanceID string) EmitTimerOption {
 
 // EmitTimer sends a timer envelope with the given name, start time and stop time.
 func (c *IngressClient) EmitTimer(name string, start, stop time.Time, opts ...EmitTimerOption) {
-	e := &loggregator_v2.Envelope{
-		Timestamp: time.Now().UnixNano(),
-		Message: &loggregator_v2.Envelope_Timer{
-			Timer: &loggregator_v2.Timer{
-				Name:  name,
-				Start: start.UnixNano(),
-				Stop:  stop.UnixNano(),
-			},
-		},
-		Tags: make(map[string]string),
+	c.enqueue(newTimerEnvelope(c.now(), c.defaultTags(), name, start, stop, opts))
+}
+
+// EmitEnvelope sends a prebuilt envelope to loggregator. The client's tags
+// are added to the envelope unless the envelope already has a tag of the same
+// name. Emitting is free of side effects: if the client has to change the
+// envelope, it sends a copy. The envelope must not be modified after it has
+// been emitted because it is only marshaled when its batch is sent. Unlike
+// the other Emit methods, the error of handing the envelope to the sender is
+// returned rather than logged.
+func (c *IngressClient) EmitEnvelope(e *loggregator_v2.Envelope) error {
+	e = c.copyOnWrite(e)
+
+	err := c.send(e)
+	if err != nil {
+		c.handleDeadLetters([]*loggregator_v2.Envelope{e}, err)
 	}
 
-	for k, v := range c.tags {
-		e.Tags[k] = v
+	return err
+}
+
+// copyOnWrite returns the envelope that is sent for the given envelope. If
+// the client's tags, its default source info, a delivery ID, a sequence
+// number or an emit timestamp have to be added, the envelope is copied
+// first. The copy shares the message with the
+// given envelope since the message is never modified.
+func (c *IngressClient) copyOnWrite(e *loggregator_v2.Envelope) *loggregator_v2.Envelope {
+	tags := c.defaultTags()
+	_, routed := e.Tags[batchingTag]
+	modified := c.deliveryIDs != nil || c.sequencer != nil || c.emitTimestamps ||
+		c.deadline > 0 || routed ||
+		(e.SourceId == "" && c.sourceID != "") ||
+		(e.InstanceId == "" && c.instanceID != "")
+	for k := range tags {
+		_, preferred := e.Tags[k]
+		_, deprecated := e.DeprecatedTags[k]
+		if !preferred && !deprecated {
+			modified = true
+			break
+		}
 	}
 
-	for _, o := range opts {
-		o(e)
+	if !modified {
+		return e
+	}
+
+	cp := &loggregator_v2.Envelope{
+		Timestamp:      e.Timestamp,
+		SourceId:       e.SourceId,
+		InstanceId:     e.InstanceId,
+		DeprecatedTags: e.DeprecatedTags,
+		Tags:           make(map[string]string, len(e.Tags)+len(tags)+1),
+		Message:        e.Message,
+	}
+	for k, v := range e.Tags {
+		cp.Tags[k] = v
+	}
+	envelope.MergeTags(cp, tags, false)
+
+	return cp
+}
+
+// handleDeadLetters hands the dropped envelopes to the dead-letter handler,
+// if one is configured.
+func (c *IngressClient) handleDeadLetters(envelopes []*loggregator_v2.Envelope, err error) {
+	if c.deadLetter == nil {
+		return
 	}
 
-	c.envelopes <- e
+	for _, e := range envelopes {
+		c.deadLetter(e, err)
+	}
 }
 
 // EmitEventOption is the option type passed into EmitEvent.
@@ -408,98 +790,288 @@ type EmitEventOption func(proto.Message)
 
 // EmitEvent sends an Event envelope.
 func (c *IngressClient) EmitEvent(ctx context.Context, title, body string, opts ...EmitEventOption) error {
-	e := &loggregator_v2.Envelope{
-		Timestamp: time.Now().UnixNano(),
-		Message: &loggregator_v2.Envelope_Event{
-			Event: &loggregator_v2.Event{
-				Title: title,
-				Body:  body,
-			},
-		},
-		Tags: make(map[string]string),
-	}
+	e := newEventEnvelope(c.now(), c.defaultTags(), title, body, opts)
+	// Events are never batched.
+	takeRouting(e)
 
-	for k, v := range c.tags {
-		e.Tags[k] = v
+	if c.isClosed() {
+		return ErrClosed
 	}
 
-	for _, o := range opts {
-		o(e)
+	if _, ok := ctx.Deadline(); !ok && c.sendTimeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, c.sendTimeout)
+		defer cancel()
 	}
 
 	_, err := c.client.Send(ctx, &loggregator_v2.EnvelopeBatch{
 		Batch: []*loggregator_v2.Envelope{e},
 	})
+	c.status.observe(err)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return ErrTimeout
+	}
 
 	return err
 }
 
+// enqueue hands the envelope to the sender go routine. If the sender does
+// not accept the envelope within the send timeout, the envelope is dropped.
+func (c *IngressClient) enqueue(e *loggregator_v2.Envelope) {
+	err := c.send(e)
+	if err != nil {
+		c.handleDeadLetters([]*loggregator_v2.Envelope{e}, err)
+	}
+
+	if err == ErrCircuitOpen && c.breakerSilent {
+		return
+	}
+
+	if err != nil {
+		c.logger.Printf("Error while emitting envelope: %s", err)
+	}
+}
+
+func (c *IngressClient) send(e *loggregator_v2.Envelope) error {
+	r := c.prepare(e)
+	if !r.set {
+		return c.emitWithHooks(e, c.emitFunc)
+	}
+
+	return c.emitWithHooks(e, chain(c.stages, func(e *loggregator_v2.Envelope) error {
+		return c.queue(e, r)
+	}))
+}
+
+// prepare adds the client's source info to the envelope and removes the
+// route chosen with WithBatching, which is returned.
+func (c *IngressClient) prepare(e *loggregator_v2.Envelope) routing {
+	if e.SourceId == "" {
+		e.SourceId = c.sourceID
+	}
+	if e.InstanceId == "" {
+		e.InstanceId = c.instanceID
+	}
+
+	return takeRouting(e)
+}
+
+// queue hands the envelope to the sender. It is the last stage of the emit
+// pipeline.
+func (c *IngressClient) queue(e *loggregator_v2.Envelope, r routing) error {
+	if proto.Size(e) > c.maxEnvelopeSize {
+		return ErrEnvelopeTooLarge
+	}
+
+	c.closeMu.RLock()
+	defer c.closeMu.RUnlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	if c.breaker != nil && !c.breaker.allow() {
+		return ErrCircuitOpen
+	}
+
+	envelopes := c.envelopes
+	if c.shards != nil {
+		h := fnv.New32a()
+		h.Write([]byte(e.SourceId))
+		h.Write([]byte{0})
+		h.Write([]byte(e.InstanceId))
+		envelopes = c.shards[h.Sum32()%uint32(len(c.shards))]
+	}
+	if c.isPriority != nil && c.isPriority(e) {
+		envelopes = c.priorityEnvelopes
+	}
+
+	q := queued{e: e, routing: r}
+	select {
+	case envelopes <- q:
+		return nil
+	default:
+	}
+
+	if c.nonBlocking {
+		return ErrQueueFull
+	}
+
+	if c.sendTimeout <= 0 {
+		envelopes <- q
+		return nil
+	}
+
+	t := time.NewTimer(c.sendTimeout)
+	defer t.Stop()
+
+	select {
+	case envelopes <- q:
+		return nil
+	case <-t.C:
+		return ErrTimeout
+	}
+}
+
 // CloseSend will flush the envelope buffers and close the stream to the
 // ingress server. This method will block until the buffers are flushed.
+// Envelopes emitted after CloseSend are dropped and calling CloseSend more
+// than once returns ErrClosed.
 func (c *IngressClient) CloseSend() error {
-	close(c.envelopes)
+	if c.gauges != nil {
+		c.gauges.close()
+	}
+
+	c.closeMu.Lock()
+	if c.closed {
+		c.closeMu.Unlock()
+		return ErrClosed
+	}
+	c.closed = true
+	if c.shards != nil {
+		for _, s := range c.shards {
+			close(s)
+		}
+	} else {
+		close(c.envelopes)
+	}
+	if c.priorityEnvelopes != nil {
+		close(c.priorityEnvelopes)
+	}
+	c.closeMu.Unlock()
 
 	return <-c.closeErrors
 }
 
-func (c *IngressClient) startSender() {
-	defer c.cancel()
+func (c *IngressClient) isClosed() bool {
+	c.closeMu.RLock()
+	defer c.closeMu.RUnlock()
 
-	t := time.NewTimer(c.batchFlushInterval)
+	return c.closed
+}
 
-	var batch []*loggregator_v2.Envelope
-	for {
-		select {
-		case env, ok := <-c.envelopes:
-			if !ok {
-				if len(batch) > 0 {
-					c.closeErrors <- c.flush(batch)
-				}
+// startShards creates the sender shards and drains each into the envelopes
+// channel. The envelopes channel is closed once all shards are closed and
+// drained.
+func (c *IngressClient) startShards() {
+	var wg sync.WaitGroup
+	c.shards = make([]chan queued, c.senderShards)
+	for i := range c.shards {
+		c.shards[i] = make(chan queued, cap(c.envelopes))
+
+		wg.Add(1)
+		go func(shard chan queued) {
+			defer wg.Done()
+			for q := range shard {
+				c.envelopes <- q
+			}
+		}(c.shards[i])
+	}
 
-				c.closeErrors <- nil
+	go func() {
+		wg.Wait()
+		close(c.envelopes)
+	}()
+}
 
-				return
-			}
+// flush sends the batch. It returns the envelopes that have to be retried
+// on the next flush, which is only ever non-empty when ordered delivery is
+// enabled.
+func (c *IngressClient) flush(batch []*loggregator_v2.Envelope) ([]*loggregator_v2.Envelope, error) {
+	batch = c.dropExpired(batch)
+	if len(batch) == 0 {
+		return nil, nil
+	}
 
-			batch = append(batch, env)
+	partitions := [][]*loggregator_v2.Envelope{batch}
+	if c.partitionBySourceID {
+		partitions = partitionBySourceID(batch)
+	}
 
-			if len(batch) >= int(c.batchMaxSize) {
-				c.flush(batch)
-				batch = nil
-				if !t.Stop() {
-					<-t.C
-				}
-				t.Reset(c.batchFlushInterval)
-			}
-		case <-t.C:
-			if len(batch) > 0 {
-				c.flush(batch)
-				batch = nil
+	var (
+		retry   []*loggregator_v2.Envelope
+		lastErr error
+	)
+	for _, b := range partitions {
+		if err := c.flushBatch(b); err != nil {
+			lastErr = err
+
+			if !c.orderedDelivery {
+				c.handleDeadLetters(b, err)
+				c.report(b, Failed, err)
+				continue
 			}
-			t.Reset(c.batchFlushInterval)
+			retry = append(retry, b...)
+			continue
 		}
+		c.report(b, Sent, nil)
+	}
+
+	if len(retry) > c.maxRetained {
+		dropped := len(retry) - c.maxRetained
+		c.logger.Printf("Dropped %d envelopes awaiting ordered delivery", dropped)
+		c.handleDeadLetters(retry[:dropped], lastErr)
+		c.report(retry[:dropped], Dropped, lastErr)
+		retry = retry[dropped:]
 	}
+
+	return retry, lastErr
 }
 
-func (c *IngressClient) flush(batch []*loggregator_v2.Envelope) error {
+func (c *IngressClient) flushBatch(batch []*loggregator_v2.Envelope) error {
 	err := c.emit(batch)
 	if err != nil {
 		c.logger.Printf("Error while flushing: %s", err)
 	}
 
+	if c.breaker != nil {
+		if err != nil {
+			c.breaker.failure()
+		} else {
+			c.breaker.success()
+		}
+	}
+
 	return err
 }
 
+// partitionBySourceID splits the batch into batches of a single source ID.
+// The partitions are ordered by the first appearance of each source ID.
+func partitionBySourceID(batch []*loggregator_v2.Envelope) [][]*loggregator_v2.Envelope {
+	var partitions [][]*loggregator_v2.Envelope
+	index := make(map[string]int)
+
+	for _, e := range batch {
+		i, ok := index[e.GetSourceId()]
+		if !ok {
+			i = len(partitions)
+			index[e.GetSourceId()] = i
+			partitions = append(partitions, nil)
+		}
+
+		partitions[i] = append(partitions[i], e)
+	}
+
+	return partitions
+}
+
 func (c *IngressClient) emit(batch []*loggregator_v2.Envelope) error {
+	if c.atLeastOnce {
+		return c.emitAcknowledged(batch)
+	}
+
 	if c.sender == nil {
 		var err error
 		c.sender, err = c.client.BatchSender(c.ctx)
 		if err != nil {
-			return err
+			c.logger.Printf("Error while connecting: %s", err)
+			c.status.observe(err)
+			return ErrNotConnected
 		}
 	}
 
 	err := c.sender.Send(&loggregator_v2.EnvelopeBatch{Batch: batch})
+	c.status.observe(err)
 	if err != nil {
 		c.sender = nil
 		return err
@@ -508,6 +1080,17 @@ func (c *IngressClient) emit(batch []*loggregator_v2.Envelope) error {
 	return nil
 }
 
+// emitAcknowledged sends the batch via the unary Send RPC. A nil error means
+// the agent acknowledged the batch.
+func (c *IngressClient) emitAcknowledged(batch []*loggregator_v2.Envelope) error {
+	ctx, cancel := context.WithTimeout(c.ctx, ackTimeout)
+	defer cancel()
+
+	_, err := c.client.Send(ctx, &loggregator_v2.EnvelopeBatch{Batch: batch})
+	c.status.observe(err)
+	return err
+}
+
 // WithEnvelopeTag adds a tag to the envelope.
 func WithEnvelopeTag(name, value string) func(proto.Message) {
 	return func(m proto.Message) {
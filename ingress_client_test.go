@@ -2,12 +2,21 @@ package loggregator_test
 
 import (
 	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"code.cloudfoundry.org/go-loggregator"
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 	"code.cloudfoundry.org/go-loggregator/runtimeemitter"
+	"code.cloudfoundry.org/go-loggregator/units"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -120,6 +129,76 @@ var _ = Describe("IngressClient", func() {
 		Expect(log.Type).To(Equal(loggregator_v2.Log_OUT))
 	})
 
+	It("sends logs from bytes", func() {
+		payload := []byte("message")
+		client.EmitLogBytes(payload, loggregator.WithSourceInfo("source-id", "source-type", "source-instance"))
+		copy(payload, "mutated")
+
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(env.SourceId).To(Equal("source-id"))
+		Expect(env.GetLog().Payload).To(Equal([]byte("message")))
+		Expect(env.GetLog().Type).To(Equal(loggregator_v2.Log_ERR))
+	})
+
+	It("sends logs from bytes without copying them", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithUnsafeNoCopy(),
+		)
+
+		client.EmitLogBytes([]byte("message"), loggregator.WithStdout())
+
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env.GetLog().Payload).To(Equal([]byte("message")))
+		Expect(env.GetLog().Type).To(Equal(loggregator_v2.Log_OUT))
+	})
+
+	It("sends logs with the tags of a context", func() {
+		tagsCtx := loggregator.WithTagsContext(context.Background(), map[string]string{
+			"request_id": "some-request",
+			"user":       "some-user",
+		})
+
+		client.EmitLogFromContext(tagsCtx, "message", loggregator.WithEnvelopeTag("user", "other-user"))
+
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env.GetLog().Payload).To(Equal([]byte("message")))
+		Expect(env.Tags).To(HaveKeyWithValue("request_id", "some-request"))
+		Expect(env.Tags).To(HaveKeyWithValue("user", "other-user"))
+	})
+
+	It("tags logs and timers with the IDs of the trace in a context", func() {
+		type spanKey struct{}
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithTraceExtractor(func(ctx context.Context) (string, string, bool) {
+				spanID, ok := ctx.Value(spanKey{}).(string)
+				return "some-trace", spanID, ok
+			}),
+		)
+		spanCtx := context.WithValue(context.Background(), spanKey{}, "some-span")
+
+		client.EmitLogFromContext(spanCtx, "message")
+		client.EmitTimerFromContext(spanCtx, "http", time.Now(), time.Now())
+		client.EmitLogFromContext(context.Background(), "message")
+
+		envelopes := receiveEnvelopes(server.receivers, 3)
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("trace_id", "some-trace"))
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("span_id", "some-span"))
+		Expect(envelopes[1].GetTimer()).NotTo(BeNil())
+		Expect(envelopes[1].Tags).To(HaveKeyWithValue("trace_id", "some-trace"))
+		Expect(envelopes[1].Tags).To(HaveKeyWithValue("span_id", "some-span"))
+		Expect(envelopes[2].Tags).NotTo(HaveKey("trace_id"))
+	})
+
 	It("sends app error logs", func() {
 		client.EmitLog(
 			"message",
@@ -209,6 +288,42 @@ var _ = Describe("IngressClient", func() {
 		Expect(timer.GetStop()).To(Equal(stopTime.UnixNano()))
 	})
 
+	Describe("stopwatch", func() {
+		It("emits a timer when stopped", func() {
+			sw := client.StartTimer(
+				"db_query",
+				loggregator.WithStopwatchTags(map[string]string{"table": "apps"}),
+			)
+			elapsed := sw.Stop(loggregator.WithTimerSourceInfo("source-id", "instance-id"))
+			sw.Stop()
+
+			env, err := getEnvelopeAt(server.receivers, 0)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(env.GetSourceId()).To(Equal("source-id"))
+			Expect(env.Tags).To(HaveKeyWithValue("table", "apps"))
+
+			timer := env.GetTimer()
+			Expect(timer).ToNot(BeNil())
+			Expect(timer.GetName()).To(Equal("db_query"))
+			Expect(timer.GetStop() - timer.GetStart()).To(BeNumerically("~", int64(elapsed), int64(time.Millisecond)))
+		})
+
+		It("emits a duration gauge when configured", func() {
+			sw := client.StartTimer("db_query", loggregator.WithStopwatchGauge())
+			elapsed := sw.Stop(loggregator.WithEnvelopeTag("status", "ok"))
+
+			env, err := getEnvelopeAt(server.receivers, 0)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(env.Tags).To(HaveKeyWithValue("status", "ok"))
+			metric := env.GetGauge().GetMetrics()["db_query"]
+			Expect(metric).ToNot(BeNil())
+			Expect(metric.Value).To(Equal(float64(elapsed)))
+			Expect(metric.Unit).To(Equal(units.Nanos))
+		})
+	})
+
 	It("works with the runtime emitter", func() {
 		// This test is to ensure that the v2 client satisfies the
 		// runtimeemitter.Sender interface. If it does not satisfy the
@@ -296,6 +411,805 @@ var _ = Describe("IngressClient", func() {
 		Expect(env.GetEvent().GetBody()).To(Equal("some-body"))
 	})
 
+	It("partitions batches by source ID", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithBatchMaxSize(3),
+			loggregator.WithBatchPartitionedBySourceID(),
+		)
+
+		client.EmitLog("a-1", loggregator.WithSourceInfo("source-a", "", ""))
+		client.EmitLog("b-1", loggregator.WithSourceInfo("source-b", "", ""))
+		client.EmitLog("a-2", loggregator.WithSourceInfo("source-a", "", ""))
+
+		var recv loggregator_v2.Ingress_BatchSenderServer
+		Eventually(server.receivers, 10).Should(Receive(&recv))
+
+		b, err := recv.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.Batch).To(HaveLen(2))
+		Expect(string(b.Batch[0].GetLog().GetPayload())).To(Equal("a-1"))
+		Expect(string(b.Batch[1].GetLog().GetPayload())).To(Equal("a-2"))
+
+		b, err = recv.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.Batch).To(HaveLen(1))
+		Expect(b.Batch[0].GetSourceId()).To(Equal("source-b"))
+	})
+
+	It("pipelines batches when multiple batches may be in flight", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithBatchMaxSize(1),
+			loggregator.WithMaxInFlightBatches(4),
+		)
+
+		for i := 0; i < 10; i++ {
+			client.EmitLog(fmt.Sprintf("message-%d", i))
+		}
+
+		var recv loggregator_v2.Ingress_BatchSenderServer
+		Eventually(server.receivers, 10).Should(Receive(&recv))
+
+		for i := 0; i < 10; i++ {
+			b, err := recv.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b.Batch).To(HaveLen(1))
+			Expect(string(b.Batch[0].GetLog().GetPayload())).To(Equal(fmt.Sprintf("message-%d", i)))
+		}
+
+		Expect(client.CloseSend()).To(Succeed())
+	})
+
+	It("sends through the transport decorator", func() {
+		var streams int64
+		client, _, _ := buildIngressClient(
+			server.addr,
+			10*time.Millisecond,
+			false,
+			loggregator.WithTransportDecorator(func(c loggregator_v2.IngressClient) loggregator_v2.IngressClient {
+				return &countingIngressClient{IngressClient: c, streams: &streams}
+			}),
+		)
+
+		client.EmitLog("message")
+
+		var recv loggregator_v2.Ingress_BatchSenderServer
+		Eventually(server.receivers, 10).Should(Receive(&recv))
+
+		b, err := recv.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.Batch).To(HaveLen(1))
+		Expect(atomic.LoadInt64(&streams)).To(Equal(int64(1)))
+	})
+
+	It("connects to agents with a load balancing policy", func() {
+		otherServer, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otherServer.start()).To(Succeed())
+		defer otherServer.stop()
+
+		r := manual.NewBuilderWithScheme("test")
+		r.InitialState(resolver.State{
+			Addresses: []resolver.Address{
+				{Addr: server.addr},
+				{Addr: otherServer.addr},
+			},
+		})
+
+		client, _, _ := buildIngressClient(
+			"test:///agents",
+			10*time.Millisecond,
+			false,
+			loggregator.WithDialOptions(grpc.WithResolvers(r)),
+			loggregator.WithLoadBalancingPolicy("round_robin"),
+		)
+
+		client.EmitLog("message")
+
+		var recv loggregator_v2.Ingress_BatchSenderServer
+		Eventually(func() bool {
+			select {
+			case recv = <-server.receivers:
+				return true
+			case recv = <-otherServer.receivers:
+				return true
+			default:
+				return false
+			}
+		}, 5).Should(BeTrue())
+
+		batch, err := recv.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batch.Batch[0].GetLog().Payload).To(Equal([]byte("message")))
+	})
+
+	It("sends envelopes emitted to sender shards", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			10*time.Millisecond,
+			false,
+			loggregator.WithSenderShards(4),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 25; j++ {
+					client.EmitCounter(
+						"some-counter",
+						loggregator.WithCounterSourceInfo(fmt.Sprintf("source-%d", i), "0"),
+						loggregator.WithDelta(uint64(j)),
+					)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		envelopes := receiveEnvelopes(server.receivers, 100)
+		Expect(envelopes).To(HaveLen(100))
+
+		deltas := make(map[string][]uint64)
+		for _, e := range envelopes {
+			deltas[e.SourceId] = append(deltas[e.SourceId], e.GetCounter().GetDelta())
+		}
+		Expect(deltas).To(HaveLen(4))
+		for _, d := range deltas {
+			Expect(d).To(HaveLen(25))
+			Expect(sort.SliceIsSorted(d, func(i, j int) bool { return d[i] < d[j] })).To(BeTrue())
+		}
+	})
+
+	It("retries failed batches in order when ordered delivery is enabled", func() {
+		lis, err := net.Listen("tcp4", "localhost:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := lis.Addr().String()
+		lis.Close()
+
+		client, _, _ := buildIngressClient(
+			addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithBatchMaxSize(1),
+			loggregator.WithOrderedDelivery(100),
+		)
+
+		for i := 0; i < 3; i++ {
+			client.EmitLog(fmt.Sprintf("message-%d", i))
+		}
+
+		// Give the client a chance to fail to send the envelopes.
+		time.Sleep(200 * time.Millisecond)
+
+		lateServer, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		lateServer.addr = addr
+		Expect(lateServer.start()).To(Succeed())
+		defer lateServer.stop()
+
+		var recv loggregator_v2.Ingress_BatchSenderServer
+		Eventually(lateServer.receivers, 10).Should(Receive(&recv))
+
+		var payloads []string
+		for len(payloads) < 3 {
+			b, err := recv.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			for _, e := range b.Batch {
+				payloads = append(payloads, string(e.GetLog().GetPayload()))
+			}
+		}
+		Expect(payloads).To(Equal([]string{"message-0", "message-1", "message-2"}))
+	})
+
+	It("re-sends unacknowledged batches when at-least-once delivery is enabled", func() {
+		lis, err := net.Listen("tcp4", "localhost:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := lis.Addr().String()
+		lis.Close()
+
+		client, _, _ := buildIngressClient(
+			addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithAtLeastOnceDelivery(100),
+		)
+
+		client.EmitLog("message-0")
+		client.EmitLog("message-1")
+
+		// Give the client a chance to fail to send the envelopes.
+		time.Sleep(200 * time.Millisecond)
+
+		lateServer, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		lateServer.addr = addr
+		Expect(lateServer.start()).To(Succeed())
+		defer lateServer.stop()
+
+		var envelopeBatch *loggregator_v2.EnvelopeBatch
+		Eventually(lateServer.sendReceiver, 5).Should(Receive(&envelopeBatch))
+
+		Expect(envelopeBatch.Batch).To(HaveLen(2))
+		Expect(envelopeBatch.Batch[0].GetLog().GetPayload()).To(Equal([]byte("message-0")))
+		Expect(envelopeBatch.Batch[1].GetLog().GetPayload()).To(Equal([]byte("message-1")))
+
+		id0 := envelopeBatch.Batch[0].Tags["delivery_id"]
+		id1 := envelopeBatch.Batch[1].Tags["delivery_id"]
+		Expect(id0).ToNot(BeEmpty())
+		Expect(id1).ToNot(BeEmpty())
+		Expect(id0).ToNot(Equal(id1))
+	})
+
+	It("times out instead of blocking when the sender is stuck", func() {
+		lis := newBlackholeListener()
+		defer lis.Close()
+
+		logger := newSpyLogger()
+		client, _, _ := buildIngressClient(
+			lis.Addr().String(),
+			time.Hour,
+			false,
+			loggregator.WithBatchMaxSize(1),
+			loggregator.WithSendTimeout(10*time.Millisecond),
+			loggregator.WithLogger(logger),
+		)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 110; i++ {
+				client.EmitLog("message")
+			}
+		}()
+
+		Eventually(done, 5).Should(BeClosed())
+		Expect(logger.messages()).To(ContainElement(ContainSubstring(loggregator.ErrTimeout.Error())))
+	})
+
+	Describe("typed errors", func() {
+		It("returns ErrClosed when closed more than once", func() {
+			Expect(client.CloseSend()).To(Succeed())
+			Expect(client.CloseSend()).To(Equal(loggregator.ErrClosed))
+		})
+
+		It("drops envelopes emitted after close", func() {
+			logger := newSpyLogger()
+			client, _, _ := buildIngressClient(server.addr, time.Hour, false, loggregator.WithLogger(logger))
+			Expect(client.CloseSend()).To(Succeed())
+
+			client.EmitLog("message")
+
+			Expect(logger.messages()).To(ContainElement(ContainSubstring(loggregator.ErrClosed.Error())))
+			Expect(client.EmitEvent(context.Background(), "title", "body")).To(Equal(loggregator.ErrClosed))
+		})
+
+		It("drops envelopes when the queue is full and sending is non-blocking", func() {
+			lis := newBlackholeListener()
+			defer lis.Close()
+
+			logger := newSpyLogger()
+			client, _, _ := buildIngressClient(
+				lis.Addr().String(),
+				time.Hour,
+				false,
+				loggregator.WithBatchMaxSize(1),
+				loggregator.WithNonBlockingSend(),
+				loggregator.WithLogger(logger),
+			)
+
+			for i := 0; i < 110; i++ {
+				client.EmitLog("message")
+			}
+
+			Expect(logger.messages()).To(ContainElement(ContainSubstring(loggregator.ErrQueueFull.Error())))
+		})
+
+		It("drops envelopes that are too large", func() {
+			logger := newSpyLogger()
+			client, _, _ := buildIngressClient(
+				server.addr,
+				time.Hour,
+				false,
+				loggregator.WithLogger(logger),
+				loggregator.WithMaxEnvelopeSize(10),
+			)
+
+			client.EmitLog("a message that is larger than ten bytes")
+
+			Expect(logger.messages()).To(ContainElement(ContainSubstring(loggregator.ErrEnvelopeTooLarge.Error())))
+		})
+
+		It("returns ErrNotConnected when no stream can be established", func() {
+			lis, err := net.Listen("tcp4", "localhost:0")
+			Expect(err).NotTo(HaveOccurred())
+			addr := lis.Addr().String()
+			lis.Close()
+
+			client, _, _ := buildIngressClient(addr, time.Hour, false)
+			client.EmitLog("message")
+
+			Expect(client.CloseSend()).To(Equal(loggregator.ErrNotConnected))
+		})
+	})
+
+	Describe("dead letters", func() {
+		var (
+			mu          sync.Mutex
+			deadLetters []error
+			handler     loggregator.DeadLetterHandler
+		)
+
+		BeforeEach(func() {
+			deadLetters = nil
+			handler = func(e *loggregator_v2.Envelope, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				deadLetters = append(deadLetters, err)
+			}
+		})
+
+		It("hands rejected envelopes to the dead-letter handler", func() {
+			client, _, _ := buildIngressClient(
+				server.addr,
+				time.Hour,
+				false,
+				loggregator.WithMaxEnvelopeSize(10),
+				loggregator.WithDeadLetterHandler(handler),
+			)
+
+			client.EmitLog("a message that is larger than ten bytes")
+			err := client.EmitEnvelope(&loggregator_v2.Envelope{SourceId: "a source ID that is too large"})
+
+			Expect(err).To(Equal(loggregator.ErrEnvelopeTooLarge))
+			Expect(deadLetters).To(Equal([]error{
+				loggregator.ErrEnvelopeTooLarge,
+				loggregator.ErrEnvelopeTooLarge,
+			}))
+		})
+
+		It("hands envelopes of batches that could not be sent to the dead-letter handler", func() {
+			lis, err := net.Listen("tcp4", "localhost:0")
+			Expect(err).NotTo(HaveOccurred())
+			addr := lis.Addr().String()
+			lis.Close()
+
+			client, _, _ := buildIngressClient(
+				addr,
+				time.Hour,
+				false,
+				loggregator.WithDeadLetterHandler(handler),
+			)
+			client.EmitLog("message")
+			client.EmitLog("message")
+			client.CloseSend()
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(deadLetters).To(Equal([]error{
+				loggregator.ErrNotConnected,
+				loggregator.ErrNotConnected,
+			}))
+		})
+
+		It("hands envelopes retained for ordered delivery to the dead-letter handler on close", func() {
+			lis, err := net.Listen("tcp4", "localhost:0")
+			Expect(err).NotTo(HaveOccurred())
+			addr := lis.Addr().String()
+			lis.Close()
+
+			client, _, _ := buildIngressClient(
+				addr,
+				time.Hour,
+				false,
+				loggregator.WithOrderedDelivery(10),
+				loggregator.WithDeadLetterHandler(handler),
+			)
+			client.EmitLog("message")
+			client.EmitLog("message")
+			Expect(client.CloseSend()).To(Equal(loggregator.ErrNotConnected))
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(deadLetters).To(Equal([]error{
+				loggregator.ErrNotConnected,
+				loggregator.ErrNotConnected,
+			}))
+		})
+	})
+
+	Describe("gauge coalescing", func() {
+		It("merges gauges emitted within the window into one envelope", func() {
+			client, _, _ := buildIngressClient(
+				server.addr,
+				10*time.Millisecond,
+				false,
+				loggregator.WithGaugeCoalescing(100*time.Millisecond),
+			)
+
+			client.EmitGauge(
+				loggregator.WithGaugeSourceInfo("source-id", "0"),
+				loggregator.WithGaugeValue("duration", 1, "ms"),
+			)
+			client.EmitGauge(
+				loggregator.WithGaugeSourceInfo("source-id", "0"),
+				loggregator.WithGaugeValue("memory", 2, "MiB"),
+			)
+			client.EmitGauge(
+				loggregator.WithGaugeSourceInfo("source-id", "0"),
+				loggregator.WithGaugeValue("duration", 3, "ms"),
+			)
+			client.EmitGauge(
+				loggregator.WithGaugeSourceInfo("other-source-id", "0"),
+				loggregator.WithGaugeValue("duration", 4, "ms"),
+			)
+
+			envelopes := receiveEnvelopes(server.receivers, 2)
+			Expect(envelopes[0].SourceId).To(Equal("source-id"))
+			Expect(envelopes[0].GetGauge().GetMetrics()).To(HaveLen(2))
+			Expect(envelopes[0].GetGauge().GetMetrics()["duration"].Value).To(Equal(3.0))
+			Expect(envelopes[0].GetGauge().GetMetrics()["memory"].Value).To(Equal(2.0))
+			Expect(envelopes[1].SourceId).To(Equal("other-source-id"))
+			Expect(envelopes[1].GetGauge().GetMetrics()["duration"].Value).To(Equal(4.0))
+		})
+
+		It("sends pending gauges when closed", func() {
+			client, _, _ := buildIngressClient(
+				server.addr,
+				10*time.Millisecond,
+				false,
+				loggregator.WithGaugeCoalescing(time.Hour),
+			)
+
+			client.EmitGauge(loggregator.WithGaugeValue("duration", 1, "ms"))
+
+			go client.CloseSend()
+
+			envelopes := receiveEnvelopes(server.receivers, 1)
+			Expect(envelopes[0].GetGauge().GetMetrics()["duration"].Value).To(Equal(1.0))
+		})
+	})
+
+	Describe("circuit breaker", func() {
+		It("drops envelopes while the circuit is open", func() {
+			lis, err := net.Listen("tcp4", "localhost:0")
+			Expect(err).NotTo(HaveOccurred())
+			addr := lis.Addr().String()
+			lis.Close()
+
+			logger := newSpyLogger()
+			client, _, _ := buildIngressClient(
+				addr,
+				10*time.Millisecond,
+				false,
+				loggregator.WithCircuitBreaker(2, time.Hour),
+				loggregator.WithLogger(logger),
+			)
+
+			Eventually(func() []string {
+				client.EmitLog("message")
+				return logger.messages()
+			}).Should(ContainElement(ContainSubstring(loggregator.ErrCircuitOpen.Error())))
+		})
+
+		It("closes the circuit once the agent recovers", func() {
+			lis, err := net.Listen("tcp4", "localhost:0")
+			Expect(err).NotTo(HaveOccurred())
+			addr := lis.Addr().String()
+			lis.Close()
+
+			client, _, _ := buildIngressClient(
+				addr,
+				10*time.Millisecond,
+				false,
+				loggregator.WithCircuitBreaker(1, 10*time.Millisecond),
+				loggregator.WithCircuitBreakerSilentDrop(),
+			)
+			client.EmitLog("message")
+
+			// Give the client a chance to open the circuit.
+			time.Sleep(100 * time.Millisecond)
+
+			lateServer, err := newTestIngressServer(
+				fixture("server.crt"),
+				fixture("server.key"),
+				fixture("CA.crt"),
+			)
+			Expect(err).NotTo(HaveOccurred())
+			lateServer.addr = addr
+			Expect(lateServer.start()).To(Succeed())
+			defer lateServer.stop()
+
+			Eventually(func() string {
+				client.EmitLog("message")
+
+				select {
+				case b := <-lateServer.sendReceiver:
+					return b.Batch[0].GetEvent().GetTitle()
+				default:
+					return ""
+				}
+			}, 5).Should(Equal("Loggregator client circuit breaker closed"))
+		})
+	})
+
+	It("does not block priority envelopes when congested with bulk envelopes", func() {
+		lis := newBlackholeListener()
+		defer lis.Close()
+
+		logger := newSpyLogger()
+		client, _, _ := buildIngressClient(
+			lis.Addr().String(),
+			time.Hour,
+			false,
+			loggregator.WithBatchMaxSize(1),
+			loggregator.WithNonBlockingSend(),
+			loggregator.WithPriorityEnvelopes(loggregator.IsCounter),
+			loggregator.WithLogger(logger),
+		)
+
+		for i := 0; i < 110; i++ {
+			client.EmitLog("message")
+		}
+		Expect(logger.messages()).To(ContainElement(ContainSubstring(loggregator.ErrQueueFull.Error())))
+
+		n := len(logger.messages())
+		client.EmitCounter("some-counter")
+		Expect(logger.messages()).To(HaveLen(n))
+	})
+
+	It("stamps version info and emits a startup event", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithVersionInfo("some-component", "1.2.3", "abc123"),
+		)
+
+		var envelopeBatch *loggregator_v2.EnvelopeBatch
+		Eventually(server.sendReceiver, 5).Should(Receive(&envelopeBatch))
+
+		env := envelopeBatch.GetBatch()[0]
+		Expect(env.GetEvent().GetTitle()).To(Equal("some-component started"))
+		Expect(env.GetEvent().GetBody()).To(Equal("version: 1.2.3, commit: abc123"))
+
+		client.EmitLog("message")
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env.Tags).To(HaveKeyWithValue("component", "some-component"))
+		Expect(env.Tags).To(HaveKeyWithValue("component_version", "1.2.3"))
+		Expect(env.Tags).To(HaveKeyWithValue("component_commit", "abc123"))
+	})
+
+	It("exposes its default tags", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithTag("deployment", "some-deployment"),
+		)
+
+		tags := client.Tags()
+		Expect(tags).To(HaveKeyWithValue("deployment", "some-deployment"))
+
+		tags["deployment"] = "mutated"
+		Expect(client.Tags()).To(HaveKeyWithValue("deployment", "some-deployment"))
+	})
+
+	It("does not share default tags between envelopes", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithTag("deployment", "some-deployment"),
+		)
+
+		client.EmitLog("message", loggregator.WithEnvelopeTag("deployment", "other-deployment"))
+		client.EmitLog("message")
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("deployment", "other-deployment"))
+		Expect(envelopes[1].Tags).To(HaveKeyWithValue("deployment", "some-deployment"))
+		Expect(client.Tags()).To(HaveKeyWithValue("deployment", "some-deployment"))
+	})
+
+	It("applies config updates without reconnecting", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithTag("deployment", "some-deployment"),
+		)
+
+		client.EmitLog("message")
+
+		var recv loggregator_v2.Ingress_BatchSenderServer
+		Eventually(server.receivers, 10).Should(Receive(&recv))
+		batch, err := recv.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batch.Batch[0].Tags).To(HaveKeyWithValue("deployment", "some-deployment"))
+
+		client.UpdateConfig(loggregator.RuntimeConfig{
+			Tags: map[string]string{"deployment": "other-deployment"},
+		})
+		client.EmitLog("message")
+
+		batch, err = recv.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batch.Batch[0].Tags).To(Equal(map[string]string{"deployment": "other-deployment"}))
+		Expect(client.Tags()).To(Equal(map[string]string{"deployment": "other-deployment"}))
+	})
+
+	It("applies updated batch sizes", func() {
+		client, _, _ := buildIngressClient(server.addr, time.Hour, false)
+
+		client.UpdateConfig(loggregator.RuntimeConfig{BatchMaxSize: 2})
+		client.EmitLog("message")
+		client.EmitLog("message")
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Expect(envelopes).To(HaveLen(2))
+	})
+
+	It("applies updated batch settings to the pending batch", func() {
+		client, _, _ := buildIngressClient(server.addr, time.Hour, false)
+
+		client.EmitLog("message")
+		Consistently(server.receivers, 200*time.Millisecond).ShouldNot(Receive())
+
+		client.UpdateConfig(loggregator.RuntimeConfig{BatchFlushInterval: 10 * time.Millisecond})
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes).To(HaveLen(1))
+	})
+
+	It("applies updated rate limits and sampling rates", func() {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		client, _, _ := buildIngressClient(
+			server.addr,
+			10*time.Millisecond,
+			false,
+			loggregator.WithClock(clock),
+			loggregator.WithRateLimit(0, 1),
+			loggregator.WithSampling(0.5),
+		)
+
+		for i := 0; i < 4; i++ {
+			client.EmitCounter("before")
+		}
+
+		client.UpdateConfig(loggregator.RuntimeConfig{
+			RateLimit:  &loggregator.RateLimit{PerSecond: 1, Burst: 10},
+			SampleRate: 1,
+		})
+		clock.add(time.Hour)
+		for i := 0; i < 4; i++ {
+			client.EmitCounter("after")
+		}
+
+		envelopes := receiveEnvelopes(server.receivers, 5)
+		Expect(envelopes).To(HaveLen(5))
+		Expect(envelopes[0].GetCounter().GetName()).To(Equal("before"))
+		for _, e := range envelopes[1:] {
+			Expect(e.GetCounter().GetName()).To(Equal("after"))
+		}
+	})
+
+	It("emits as a Cloud Foundry application instance", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithCFApplication(loggregator.CFApplication{
+				ApplicationID:   "some-app-id",
+				ApplicationName: "some-app",
+				InstanceIndex:   "2",
+				InstanceGUID:    "some-instance-guid",
+			}),
+		)
+
+		client.EmitLog("message")
+		client.EmitLog("message", loggregator.WithSourceInfo("source-id", "source-type", "0"))
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Expect(envelopes[0].SourceId).To(Equal("some-app-id"))
+		Expect(envelopes[0].InstanceId).To(Equal("2"))
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("app_name", "some-app"))
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("instance_guid", "some-instance-guid"))
+		Expect(envelopes[0].Tags).NotTo(HaveKey("space_name"))
+		Expect(envelopes[1].SourceId).To(Equal("source-id"))
+		Expect(envelopes[1].InstanceId).To(Equal("0"))
+	})
+
+	It("replaces its default tags with frozen tags", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithFrozenTags(map[string]string{"job": "some-job"}),
+			loggregator.WithTag("deployment", "some-deployment"),
+		)
+
+		Expect(client.Tags()).To(Equal(map[string]string{"job": "some-job"}))
+
+		client.EmitLog("message")
+
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env.Tags).To(Equal(map[string]string{"job": "some-job"}))
+	})
+
+	It("emits prebuilt envelopes", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithTag("some-tag", "client-value"),
+			loggregator.WithTag("other-tag", "client-value"),
+		)
+
+		e := &loggregator_v2.Envelope{
+			SourceId: "some-source",
+			Tags:     map[string]string{"some-tag": "envelope-value"},
+		}
+		err := client.EmitEnvelope(e)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Tags).To(Equal(map[string]string{"some-tag": "envelope-value"}))
+
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env.SourceId).To(Equal("some-source"))
+		Expect(env.Tags).To(HaveKeyWithValue("some-tag", "envelope-value"))
+		Expect(env.Tags).To(HaveKeyWithValue("other-tag", "client-value"))
+	})
+
+	It("does not modify emitted envelopes", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithFrozenTags(map[string]string{"some-tag": "client-value"}),
+			loggregator.WithDefaultSourceInfo("default-source", "0"),
+			loggregator.WithAtLeastOnceDelivery(10),
+		)
+
+		e := &loggregator_v2.Envelope{
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte("message")},
+			},
+		}
+		err := client.EmitEnvelope(e)
+		Expect(err).NotTo(HaveOccurred())
+
+		var envelopeBatch *loggregator_v2.EnvelopeBatch
+		Eventually(server.sendReceiver, 5).Should(Receive(&envelopeBatch))
+		env := envelopeBatch.Batch[0]
+		Expect(env.SourceId).To(Equal("default-source"))
+		Expect(env.Tags).To(HaveKeyWithValue("some-tag", "client-value"))
+		Expect(env.Tags).To(HaveKey("delivery_id"))
+
+		Expect(e.SourceId).To(BeEmpty())
+		Expect(e.InstanceId).To(BeEmpty())
+		Expect(e.Tags).To(BeNil())
+	})
+
 	It("flushes current batch and sends", func() {
 		client, _, _ := buildIngressClient(server.addr, time.Hour, false)
 
@@ -324,6 +1238,64 @@ var _ = Describe("IngressClient", func() {
 	})
 })
 
+// newBlackholeListener returns a listener that accepts connections but never
+// completes a TLS handshake, leaving clients stuck establishing a stream.
+func newBlackholeListener() net.Listener {
+	lis, err := net.Listen("tcp4", "localhost:0")
+	Expect(err).NotTo(HaveOccurred())
+
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				for _, c := range conns {
+					c.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	return lis
+}
+
+type spyLogger struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func newSpyLogger() *spyLogger {
+	return &spyLogger{}
+}
+
+func (l *spyLogger) Printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.msgs = append(l.msgs, fmt.Sprintf(format, args...))
+}
+
+func (l *spyLogger) messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.msgs...)
+}
+
+func receiveEnvelopes(receivers chan loggregator_v2.Ingress_BatchSenderServer, n int) []*loggregator_v2.Envelope {
+	var recv loggregator_v2.Ingress_BatchSenderServer
+	Eventually(receivers, 10).Should(Receive(&recv))
+
+	var envelopes []*loggregator_v2.Envelope
+	for len(envelopes) < n {
+		batch, err := recv.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		envelopes = append(envelopes, batch.Batch...)
+	}
+
+	return envelopes
+}
+
 func getEnvelopeAt(receivers chan loggregator_v2.Ingress_BatchSenderServer, idx int) (*loggregator_v2.Envelope, error) {
 	var recv loggregator_v2.Ingress_BatchSenderServer
 	Eventually(receivers, 10).Should(Receive(&recv))
@@ -340,7 +1312,7 @@ func getEnvelopeAt(receivers chan loggregator_v2.Ingress_BatchSenderServer, idx
 	return envBatch.Batch[idx], nil
 }
 
-func buildIngressClient(serverAddr string, flushInterval time.Duration, addContext bool) (*loggregator.IngressClient, context.Context, func()) {
+func buildIngressClient(serverAddr string, flushInterval time.Duration, addContext bool, extraOpts ...loggregator.IngressOption) (*loggregator.IngressClient, context.Context, func()) {
 	tlsConfig, err := loggregator.NewIngressTLSConfig(
 		fixture("CA.crt"),
 		fixture("client.crt"),
@@ -361,6 +1333,7 @@ func buildIngressClient(serverAddr string, flushInterval time.Duration, addConte
 	if addContext {
 		opts = append(opts, loggregator.WithContext(ctx))
 	}
+	opts = append(opts, extraOpts...)
 
 	client, err := loggregator.NewIngressClient(
 		tlsConfig,
@@ -372,3 +1345,13 @@ func buildIngressClient(serverAddr string, flushInterval time.Duration, addConte
 
 	return client, ctx, cancel
 }
+
+type countingIngressClient struct {
+	loggregator_v2.IngressClient
+	streams *int64
+}
+
+func (c *countingIngressClient) BatchSender(ctx context.Context, opts ...grpc.CallOption) (loggregator_v2.Ingress_BatchSenderClient, error) {
+	atomic.AddInt64(c.streams, 1)
+	return c.IngressClient.BatchSender(ctx, opts...)
+}
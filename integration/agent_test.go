@@ -0,0 +1,102 @@
+//go:build integration
+// +build integration
+
+package integration_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/integration"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// The tests run with `go test -tags integration ./integration`. They need
+// LOGGREGATOR_AGENT_IMAGE and INTEGRATION_CERTS_DIR, a directory with
+// ca.crt and the agent, doppler and client certificates and keys.
+var _ = Describe("Agent", func() {
+	var h *integration.Harness
+
+	BeforeEach(func() {
+		dir := os.Getenv("INTEGRATION_CERTS_DIR")
+		if dir == "" {
+			Skip("INTEGRATION_CERTS_DIR is not set")
+		}
+
+		h = integration.New(integration.Certs{
+			CA:          filepath.Join(dir, "ca.crt"),
+			AgentCert:   filepath.Join(dir, "agent.crt"),
+			AgentKey:    filepath.Join(dir, "agent.key"),
+			DopplerCert: filepath.Join(dir, "doppler.crt"),
+			DopplerKey:  filepath.Join(dir, "doppler.key"),
+			ClientCert:  filepath.Join(dir, "client.crt"),
+			ClientKey:   filepath.Join(dir, "client.key"),
+		})
+		if !h.Available() {
+			Skip("docker or the agent image is not available")
+		}
+
+		Expect(h.Start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if h != nil {
+			h.Stop()
+		}
+	})
+
+	It("forwards envelopes to doppler", func() {
+		client, err := h.NewIngressClient(
+			loggregator.WithBatchFlushInterval(10 * time.Millisecond),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		defer client.CloseSend()
+
+		client.EmitLog("integration", loggregator.WithSourceInfo("integration-test", "APP", "0"))
+
+		Eventually(func() string {
+			select {
+			case e := <-h.Envelopes():
+				if e.GetSourceId() == "integration-test" {
+					return string(e.GetLog().GetPayload())
+				}
+			default:
+			}
+			return ""
+		}, 10*time.Second).Should(Equal("integration"))
+	})
+
+	It("does not send envelopes when the agent cannot be verified", func() {
+		tlsConfig, err := loggregator.NewIngressTLSConfig(
+			filepath.Join(os.Getenv("INTEGRATION_CERTS_DIR"), "ca.crt"),
+			filepath.Join(os.Getenv("INTEGRATION_CERTS_DIR"), "doppler.crt"),
+			filepath.Join(os.Getenv("INTEGRATION_CERTS_DIR"), "doppler.key"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		tlsConfig.ServerName = "wrong-name"
+
+		client, err := loggregator.NewIngressClient(tlsConfig,
+			loggregator.WithAddr(h.AgentAddr()),
+			loggregator.WithBatchFlushInterval(10*time.Millisecond),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		client.EmitLog("rejected", loggregator.WithSourceInfo("integration-test", "APP", "0"))
+		Consistently(func() []*loggregator_v2.Envelope {
+			var received []*loggregator_v2.Envelope
+			select {
+			case e := <-h.Envelopes():
+				if e.GetSourceId() == "integration-test" {
+					received = append(received, e)
+				}
+			default:
+			}
+			return received
+		}, time.Second).Should(BeEmpty())
+	})
+})
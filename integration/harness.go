@@ -0,0 +1,333 @@
+// Package integration runs ingress clients against a real Loggregator agent.
+// The Harness starts the agent in a docker container and forwards it to a
+// fake doppler in the test process, so that TLS and stream behavior can be
+// verified end-to-end.
+//
+// The agent image is not published with this package. It is taken from the
+// LOGGREGATOR_AGENT_IMAGE environment variable or the WithAgentImage option,
+// e.g. an image built from loggregator-agent-release.
+package integration
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// AgentImageEnv is the environment variable with the default agent image.
+const AgentImageEnv = "LOGGREGATOR_AGENT_IMAGE"
+
+const agentPort = 3458
+
+// Certs holds the paths of the certificates used by the harness. All
+// certificates must be signed by the CA. The agent certificate must be
+// valid for "metron" and the doppler certificate for "doppler", the names
+// the clients verify.
+type Certs struct {
+	CA string
+
+	AgentCert string
+	AgentKey  string
+
+	DopplerCert string
+	DopplerKey  string
+
+	ClientCert string
+	ClientKey  string
+}
+
+// HarnessOption configures a Harness.
+type HarnessOption func(*Harness)
+
+// WithAgentImage configures the docker image of the agent.
+func WithAgentImage(image string) HarnessOption {
+	return func(h *Harness) {
+		h.image = image
+	}
+}
+
+// WithAgentEnv sets an environment variable of the agent container, adding
+// to or overriding the defaults.
+func WithAgentEnv(name, value string) HarnessOption {
+	return func(h *Harness) {
+		h.env[name] = value
+	}
+}
+
+// WithDocker configures the path of the docker binary. It defaults to
+// "docker" in the PATH.
+func WithDocker(path string) HarnessOption {
+	return func(h *Harness) {
+		h.docker = path
+	}
+}
+
+// WithStartTimeout configures how long Start waits for the agent to accept
+// connections. It defaults to 30 seconds.
+func WithStartTimeout(d time.Duration) HarnessOption {
+	return func(h *Harness) {
+		h.startTimeout = d
+	}
+}
+
+// Harness runs an agent container and a fake doppler. It should be created
+// with the New constructor.
+type Harness struct {
+	certs        Certs
+	image        string
+	docker       string
+	env          map[string]string
+	startTimeout time.Duration
+
+	envelopes   chan *loggregator_v2.Envelope
+	doppler     *loggregator_v2.EnvelopeServer
+	containerID string
+	agentAddr   string
+}
+
+// New returns a Harness that uses the given certificates.
+func New(certs Certs, opts ...HarnessOption) *Harness {
+	h := &Harness{
+		certs:        certs,
+		image:        os.Getenv(AgentImageEnv),
+		docker:       "docker",
+		startTimeout: 30 * time.Second,
+		envelopes:    make(chan *loggregator_v2.Envelope, 10000),
+		env: map[string]string{
+			"AGENT_PORT":      strconv.Itoa(agentPort),
+			"AGENT_CA_FILE":   "/certs/ca.crt",
+			"AGENT_CERT_FILE": "/certs/agent.crt",
+			"AGENT_KEY_FILE":  "/certs/agent.key",
+			"DEPLOYMENT":      "integration",
+			"JOB":             "agent",
+			"INDEX":           "0",
+			"IP":              "127.0.0.1",
+		},
+	}
+
+	for _, o := range opts {
+		o(h)
+	}
+
+	return h
+}
+
+// Available reports whether docker can be used and an agent image is
+// configured. Tests should be skipped otherwise.
+func (h *Harness) Available() bool {
+	if h.image == "" {
+		return false
+	}
+
+	return exec.Command(h.docker, "info").Run() == nil
+}
+
+// Start starts the fake doppler and the agent container and waits until the
+// agent accepts connections.
+func (h *Harness) Start() error {
+	if h.image == "" {
+		return fmt.Errorf("no agent image configured, set %s", AgentImageEnv)
+	}
+
+	dopplerAddr, err := h.startDoppler()
+	if err != nil {
+		return err
+	}
+
+	_, port, err := net.SplitHostPort(dopplerAddr)
+	if err != nil {
+		h.Stop()
+		return err
+	}
+	routerAddr := net.JoinHostPort("host.docker.internal", port)
+
+	args := []string{
+		"run", "--detach",
+		"--publish", fmt.Sprintf("127.0.0.1::%d", agentPort),
+		"--add-host", "host.docker.internal:host-gateway",
+		"--env", "ROUTER_ADDR=" + routerAddr,
+		"--env", "ROUTER_ADDR_WITH_AZ=" + routerAddr,
+	}
+	for k, v := range h.env {
+		args = append(args, "--env", k+"="+v)
+	}
+	mounts, err := h.certMounts()
+	if err != nil {
+		h.Stop()
+		return err
+	}
+	args = append(args, mounts...)
+	args = append(args, h.image)
+
+	out, err := h.run(args...)
+	if err != nil {
+		h.Stop()
+		return err
+	}
+	h.containerID = strings.TrimSpace(out)
+
+	out, err = h.run("port", h.containerID, fmt.Sprintf("%d/tcp", agentPort))
+	if err != nil {
+		h.Stop()
+		return err
+	}
+	h.agentAddr = strings.TrimSpace(strings.Split(out, "\n")[0])
+
+	if err := h.waitForAgent(); err != nil {
+		h.Stop()
+		return err
+	}
+
+	return nil
+}
+
+// AgentAddr returns the address of the agent's ingress endpoint.
+func (h *Harness) AgentAddr() string {
+	return h.agentAddr
+}
+
+// Envelopes returns the envelopes the fake doppler received from the agent.
+func (h *Harness) Envelopes() <-chan *loggregator_v2.Envelope {
+	return h.envelopes
+}
+
+// NewIngressClient returns a client connected to the agent with the client
+// certificate.
+func (h *Harness) NewIngressClient(opts ...loggregator.IngressOption) (*loggregator.IngressClient, error) {
+	tlsConfig, err := loggregator.NewIngressTLSConfig(h.certs.CA, h.certs.ClientCert, h.certs.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]loggregator.IngressOption{loggregator.WithAddr(h.agentAddr)}, opts...)
+
+	return loggregator.NewIngressClient(tlsConfig, opts...)
+}
+
+// Logs returns the output of the agent container, which helps to explain
+// failing tests.
+func (h *Harness) Logs() (string, error) {
+	if h.containerID == "" {
+		return "", errors.New("agent is not running")
+	}
+
+	return h.run("logs", h.containerID)
+}
+
+// Stop removes the agent container and stops the fake doppler.
+func (h *Harness) Stop() error {
+	var err error
+	if h.containerID != "" {
+		_, err = h.run("rm", "--force", h.containerID)
+		h.containerID = ""
+	}
+
+	if h.doppler != nil {
+		h.doppler.Stop()
+		h.doppler = nil
+	}
+
+	return err
+}
+
+func (h *Harness) startDoppler() (string, error) {
+	cert, err := tls.LoadX509KeyPair(h.certs.DopplerCert, h.certs.DopplerKey)
+	if err != nil {
+		return "", err
+	}
+
+	ca, err := ioutil.ReadFile(h.certs.CA)
+	if err != nil {
+		return "", err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return "", errors.New("cannot parse ca cert")
+	}
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+
+	lis, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		return "", err
+	}
+
+	h.doppler = loggregator_v2.NewIngressServer(func(e *loggregator_v2.Envelope) {
+		select {
+		case h.envelopes <- e:
+		default:
+		}
+	}, grpc.Creds(creds))
+	go h.doppler.Serve(lis)
+
+	return lis.Addr().String(), nil
+}
+
+func (h *Harness) certMounts() ([]string, error) {
+	files := map[string]string{
+		h.certs.CA:        "ca.crt",
+		h.certs.AgentCert: "agent.crt",
+		h.certs.AgentKey:  "agent.key",
+	}
+
+	var args []string
+	for src, dst := range files {
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--volume", abs+":/certs/"+dst+":ro")
+	}
+
+	return args, nil
+}
+
+func (h *Harness) waitForAgent() error {
+	deadline := time.Now().Add(h.startTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", h.agentAddr, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			logs, _ := h.Logs()
+			return fmt.Errorf("agent did not start within %s: %s\n%s", h.startTimeout, err, logs)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (h *Harness) run(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(h.docker, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %s: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,323 @@
+// Package journaldemitter provides an emitter that follows the systemd
+// journal and emits its entries as logs, so that the host logs of BOSH VMs
+// can be shipped through Loggregator. It reads the journal with journalctl
+// and persists the position in the journal, so that entries are neither
+// lost nor emitted twice across restarts.
+package journaldemitter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// priorities are the names of the syslog priorities of journal entries.
+var priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// errPriority is the lowest priority that is emitted as stderr.
+const errPriority = 3
+
+// Sender is the interface of the client that can be used to emit the
+// journal entries.
+type Sender interface {
+	EmitEnvelope(*loggregator_v2.Envelope) error
+}
+
+// Emitter follows the journal and emits its entries via the sender. It
+// should be created with the New constructor.
+type Emitter struct {
+	sender             Sender
+	journalctl         string
+	units              []string
+	sourceID           string
+	cursorFile         string
+	cursorSaveInterval time.Duration
+	tags               map[string]string
+}
+
+// JournaldEmitterOption is the option that provides configuration for an
+// Emitter.
+type JournaldEmitterOption func(e *Emitter)
+
+// WithUnits returns a JournaldEmitterOption that only follows the entries
+// of the given systemd units. By default, every entry is followed.
+func WithUnits(units ...string) JournaldEmitterOption {
+	return func(e *Emitter) {
+		e.units = append(e.units, units...)
+	}
+}
+
+// WithSourceID returns a JournaldEmitterOption for setting the source ID of
+// the logs. By default, the source ID is the unit of the entry or, if it has
+// none, its syslog identifier.
+func WithSourceID(id string) JournaldEmitterOption {
+	return func(e *Emitter) {
+		e.sourceID = id
+	}
+}
+
+// WithCursorFile returns a JournaldEmitterOption that persists the cursor
+// of the last emitted entry in the given file. Run continues after that
+// entry. Without a cursor file, Run starts with the entries added after it
+// is called.
+func WithCursorFile(path string) JournaldEmitterOption {
+	return func(e *Emitter) {
+		e.cursorFile = path
+	}
+}
+
+// WithCursorSaveInterval returns a JournaldEmitterOption to configure how
+// often the cursor is persisted. It defaults to 1 second. The entries
+// emitted after the last save are emitted again after a crash.
+func WithCursorSaveInterval(d time.Duration) JournaldEmitterOption {
+	return func(e *Emitter) {
+		e.cursorSaveInterval = d
+	}
+}
+
+// WithTags returns a JournaldEmitterOption that adds the given tags to every
+// log.
+func WithTags(tags map[string]string) JournaldEmitterOption {
+	return func(e *Emitter) {
+		for k, v := range tags {
+			e.tags[k] = v
+		}
+	}
+}
+
+// WithJournalctl returns a JournaldEmitterOption to configure the path of
+// journalctl. It defaults to journalctl in the PATH.
+func WithJournalctl(path string) JournaldEmitterOption {
+	return func(e *Emitter) {
+		e.journalctl = path
+	}
+}
+
+// New returns an Emitter that emits via the sender.
+func New(sender Sender, opts ...JournaldEmitterOption) *Emitter {
+	e := &Emitter{
+		sender:             sender,
+		journalctl:         "journalctl",
+		cursorSaveInterval: time.Second,
+		tags:               make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+// Run follows the journal until the context is done or journalctl exits.
+// It returns the error of journalctl or of persisting the cursor.
+func (e *Emitter) Run(ctx context.Context) error {
+	cursor, err := e.loadCursor()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, e.journalctl, e.args(cursor)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	lastSave := time.Now()
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		env, entryCursor, ok := parseEntry(scanner.Bytes())
+		if !ok {
+			continue
+		}
+
+		e.send(env)
+		cursor = entryCursor
+
+		if time.Since(lastSave) >= e.cursorSaveInterval {
+			if err := e.saveCursor(cursor); err != nil {
+				cmd.Process.Kill()
+				cmd.Wait()
+				return err
+			}
+			lastSave = time.Now()
+		}
+	}
+
+	waitErr := cmd.Wait()
+	if err := e.saveCursor(cursor); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return waitErr
+}
+
+func (e *Emitter) args(cursor string) []string {
+	args := []string{"--output=json", "--follow", "--no-pager"}
+	if cursor != "" {
+		args = append(args, "--after-cursor="+cursor)
+	} else {
+		args = append(args, "--lines=0")
+	}
+
+	for _, u := range e.units {
+		args = append(args, "--unit="+u)
+	}
+
+	return args
+}
+
+func (e *Emitter) send(env *loggregator_v2.Envelope) {
+	if e.sourceID != "" {
+		env.SourceId = e.sourceID
+	}
+	for k, v := range e.tags {
+		env.Tags[k] = v
+	}
+
+	e.sender.EmitEnvelope(env)
+}
+
+func (e *Emitter) loadCursor() (string, error) {
+	if e.cursorFile == "" {
+		return "", nil
+	}
+
+	b, err := ioutil.ReadFile(e.cursorFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// saveCursor replaces the cursor file atomically, so that a crash never
+// leaves a partial cursor behind.
+func (e *Emitter) saveCursor(cursor string) error {
+	if e.cursorFile == "" || cursor == "" {
+		return nil
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(e.cursorFile), ".cursor")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(cursor + "\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), e.cursorFile)
+}
+
+// parseEntry converts an entry of journalctl's JSON output to a log
+// envelope. It returns the entry's cursor.
+func parseEntry(line []byte) (*loggregator_v2.Envelope, string, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, "", false
+	}
+
+	cursor := stringField(fields, "__CURSOR")
+	message, err := bytesField(fields, "MESSAGE")
+	if cursor == "" || err != nil {
+		return nil, "", false
+	}
+
+	env := &loggregator_v2.Envelope{
+		Tags: make(map[string]string),
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{
+				Payload: message,
+				Type:    loggregator_v2.Log_OUT,
+			},
+		},
+	}
+
+	if us, err := strconv.ParseInt(stringField(fields, "__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+		env.Timestamp = us * int64(time.Microsecond)
+	} else {
+		env.Timestamp = time.Now().UnixNano()
+	}
+
+	unit := stringField(fields, "_SYSTEMD_UNIT")
+	identifier := stringField(fields, "SYSLOG_IDENTIFIER")
+	if unit != "" {
+		env.Tags["unit"] = unit
+		env.SourceId = unit
+	}
+	if identifier != "" {
+		env.Tags["syslog_identifier"] = identifier
+		if env.SourceId == "" {
+			env.SourceId = identifier
+		}
+	}
+	if host := stringField(fields, "_HOSTNAME"); host != "" {
+		env.Tags["hostname"] = host
+	}
+
+	if p, err := strconv.Atoi(stringField(fields, "PRIORITY")); err == nil && p >= 0 && p < len(priorities) {
+		env.Tags["priority"] = priorities[p]
+		if p <= errPriority {
+			env.GetLog().Type = loggregator_v2.Log_ERR
+		}
+	}
+
+	return env, cursor, true
+}
+
+func stringField(fields map[string]json.RawMessage, name string) string {
+	var s string
+	json.Unmarshal(fields[name], &s)
+	return s
+}
+
+// bytesField returns a field that journalctl encodes as a string or, if it
+// is not valid UTF-8, as an array of bytes.
+func bytesField(fields map[string]json.RawMessage, name string) ([]byte, error) {
+	raw, ok := fields[name]
+	if !ok {
+		return nil, errors.New("missing field " + name)
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []byte(s), nil
+	}
+
+	var b []byte
+	var ints []int
+	if err := json.Unmarshal(raw, &ints); err != nil {
+		return nil, err
+	}
+	for _, i := range ints {
+		b = append(b, byte(i))
+	}
+
+	return b, nil
+}
@@ -0,0 +1,164 @@
+package journaldemitter_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/journaldemitter"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const entries = `{"__CURSOR":"c1","__REALTIME_TIMESTAMP":"1500000000000001","_SYSTEMD_UNIT":"ssh.service","SYSLOG_IDENTIFIER":"sshd","_HOSTNAME":"vm-1","PRIORITY":"6","MESSAGE":"accepted"}
+not json
+{"__CURSOR":"c2","__REALTIME_TIMESTAMP":"1500000000000002","SYSLOG_IDENTIFIER":"kernel","PRIORITY":"3","MESSAGE":[111,111,112,115]}
+`
+
+var _ = Describe("Emitter", func() {
+	var (
+		dir        string
+		journalctl string
+		argsFile   string
+		spy        *spySender
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "journald")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "entries"), []byte(entries), 0644)).To(Succeed())
+		argsFile = filepath.Join(dir, "args")
+		journalctl = writeScript(dir, "cat "+filepath.Join(dir, "entries"))
+
+		spy = &spySender{}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("emits journal entries as logs", func() {
+		e := journaldemitter.New(spy, journaldemitter.WithJournalctl(journalctl))
+		Expect(e.Run(context.Background())).To(Succeed())
+
+		envelopes := spy.received()
+		Expect(envelopes).To(HaveLen(2))
+
+		Expect(envelopes[0].GetSourceId()).To(Equal("ssh.service"))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(1500000000000001000)))
+		Expect(string(envelopes[0].GetLog().GetPayload())).To(Equal("accepted"))
+		Expect(envelopes[0].GetLog().GetType()).To(Equal(loggregator_v2.Log_OUT))
+		Expect(envelopes[0].GetTags()).To(Equal(map[string]string{
+			"unit":              "ssh.service",
+			"syslog_identifier": "sshd",
+			"hostname":          "vm-1",
+			"priority":          "info",
+		}))
+
+		Expect(envelopes[1].GetSourceId()).To(Equal("kernel"))
+		Expect(string(envelopes[1].GetLog().GetPayload())).To(Equal("oops"))
+		Expect(envelopes[1].GetLog().GetType()).To(Equal(loggregator_v2.Log_ERR))
+		Expect(envelopes[1].GetTags()).To(HaveKeyWithValue("priority", "err"))
+
+		Expect(readArgs(argsFile)).To(ConsistOf("--output=json", "--follow", "--no-pager", "--lines=0"))
+	})
+
+	It("persists the cursor and continues after it", func() {
+		cursorFile := filepath.Join(dir, "cursor")
+		e := journaldemitter.New(spy,
+			journaldemitter.WithJournalctl(journalctl),
+			journaldemitter.WithCursorFile(cursorFile),
+			journaldemitter.WithUnits("ssh.service"),
+		)
+
+		Expect(e.Run(context.Background())).To(Succeed())
+		Expect(ioutil.ReadFile(cursorFile)).To(Equal([]byte("c2\n")))
+
+		Expect(e.Run(context.Background())).To(Succeed())
+		Expect(readArgs(argsFile)).To(ContainElement("--after-cursor=c2"))
+		Expect(readArgs(argsFile)).To(ContainElement("--unit=ssh.service"))
+	})
+
+	It("uses the configured source ID and tags", func() {
+		e := journaldemitter.New(spy,
+			journaldemitter.WithJournalctl(journalctl),
+			journaldemitter.WithSourceID("host"),
+			journaldemitter.WithTags(map[string]string{"job": "router"}),
+		)
+		Expect(e.Run(context.Background())).To(Succeed())
+
+		envelopes := spy.received()
+		Expect(envelopes[0].GetSourceId()).To(Equal("host"))
+		Expect(envelopes[0].GetTags()).To(HaveKeyWithValue("job", "router"))
+	})
+
+	It("stops when the context is done", func() {
+		follow := writeScript(dir, "cat "+filepath.Join(dir, "entries")+"; exec sleep 10")
+		cursorFile := filepath.Join(dir, "cursor")
+		e := journaldemitter.New(spy,
+			journaldemitter.WithJournalctl(follow),
+			journaldemitter.WithCursorFile(cursorFile),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errs := make(chan error, 1)
+		go func() {
+			errs <- e.Run(ctx)
+		}()
+
+		Eventually(spy.received).Should(HaveLen(2))
+		cancel()
+
+		Eventually(errs, 5*time.Second).Should(Receive(BeNil()))
+		Expect(ioutil.ReadFile(cursorFile)).To(Equal([]byte("c2\n")))
+	})
+
+	It("returns the error of journalctl", func() {
+		e := journaldemitter.New(spy, journaldemitter.WithJournalctl(writeScript(dir, "exit 1")))
+
+		Expect(e.Run(context.Background())).To(HaveOccurred())
+	})
+})
+
+// writeScript writes a fake journalctl that records its arguments and then
+// runs the given shell commands.
+func writeScript(dir, commands string) string {
+	path := filepath.Join(dir, "journalctl")
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\n" + commands + "\n"
+	Expect(ioutil.WriteFile(path, []byte(script), 0755)).To(Succeed())
+	return path
+}
+
+func readArgs(path string) []string {
+	b, err := ioutil.ReadFile(path)
+	Expect(err).NotTo(HaveOccurred())
+	return strings.Fields(string(b))
+}
+
+type spySender struct {
+	mu        sync.Mutex
+	envelopes []*loggregator_v2.Envelope
+}
+
+func (s *spySender) EmitEnvelope(e *loggregator_v2.Envelope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.envelopes = append(s.envelopes, e)
+	return nil
+}
+
+func (s *spySender) received() []*loggregator_v2.Envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*loggregator_v2.Envelope(nil), s.envelopes...)
+}
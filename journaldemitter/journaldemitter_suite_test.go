@@ -0,0 +1,13 @@
+package journaldemitter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestJournaldemitter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Journald Emitter Suite")
+}
@@ -0,0 +1,61 @@
+package loggregator
+
+import "os"
+
+// KubernetesPod describes the Kubernetes pod the process runs in.
+type KubernetesPod struct {
+	Name      string
+	Namespace string
+	NodeName  string
+}
+
+// DetectKubernetesPod reads the pod from the POD_NAME, NAMESPACE and
+// NODE_NAME environment variables, which are commonly populated from the
+// downward API. POD_NAMESPACE is read if NAMESPACE is not set. It returns
+// false if the process does not run on Kubernetes, i.e.
+// KUBERNETES_SERVICE_HOST is not set, or POD_NAME is not set.
+func DetectKubernetesPod() (KubernetesPod, bool) {
+	var pod KubernetesPod
+
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return pod, false
+	}
+
+	pod.Name = os.Getenv("POD_NAME")
+	pod.Namespace = os.Getenv("NAMESPACE")
+	if pod.Namespace == "" {
+		pod.Namespace = os.Getenv("POD_NAMESPACE")
+	}
+	pod.NodeName = os.Getenv("NODE_NAME")
+
+	return pod, pod.Name != ""
+}
+
+// WithKubernetesPod configures the client to tag every envelope with the
+// name, namespace and node of the pod. It is the Kubernetes counterpart of
+// the job properties of a MetronConfig. Empty values are not tagged.
+func WithKubernetesPod(pod KubernetesPod) IngressOption {
+	tags := map[string]string{
+		"pod_name":  pod.Name,
+		"namespace": pod.Namespace,
+		"node_name": pod.NodeName,
+	}
+
+	return func(c *IngressClient) {
+		for k, v := range tags {
+			if v != "" {
+				WithTag(k, v)(c)
+			}
+		}
+	}
+}
+
+// WithDetectedKubernetesPod configures the client with WithKubernetesPod if
+// DetectKubernetesPod finds a pod. Otherwise it does not change the client.
+func WithDetectedKubernetesPod() IngressOption {
+	return func(c *IngressClient) {
+		if pod, ok := DetectKubernetesPod(); ok {
+			WithKubernetesPod(pod)(c)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package loggregator_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Kubernetes", func() {
+	var env map[string]string
+
+	BeforeEach(func() {
+		env = make(map[string]string)
+		for _, k := range []string{"KUBERNETES_SERVICE_HOST", "POD_NAME", "NAMESPACE", "POD_NAMESPACE", "NODE_NAME"} {
+			env[k] = os.Getenv(k)
+			os.Unsetenv(k)
+		}
+	})
+
+	AfterEach(func() {
+		for k, v := range env {
+			os.Setenv(k, v)
+		}
+	})
+
+	setPodEnv := func() {
+		os.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		os.Setenv("POD_NAME", "some-pod")
+		os.Setenv("NAMESPACE", "some-namespace")
+		os.Setenv("NODE_NAME", "some-node")
+	}
+
+	Describe("DetectKubernetesPod", func() {
+		It("reads the pod from the environment", func() {
+			setPodEnv()
+
+			pod, ok := loggregator.DetectKubernetesPod()
+
+			Expect(ok).To(BeTrue())
+			Expect(pod).To(Equal(loggregator.KubernetesPod{
+				Name:      "some-pod",
+				Namespace: "some-namespace",
+				NodeName:  "some-node",
+			}))
+		})
+
+		It("falls back to POD_NAMESPACE", func() {
+			setPodEnv()
+			os.Unsetenv("NAMESPACE")
+			os.Setenv("POD_NAMESPACE", "other-namespace")
+
+			pod, _ := loggregator.DetectKubernetesPod()
+
+			Expect(pod.Namespace).To(Equal("other-namespace"))
+		})
+
+		It("returns false when not running on Kubernetes", func() {
+			os.Setenv("POD_NAME", "some-pod")
+
+			_, ok := loggregator.DetectKubernetesPod()
+
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns false without a pod name", func() {
+			setPodEnv()
+			os.Unsetenv("POD_NAME")
+
+			_, ok := loggregator.DetectKubernetesPod()
+
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	It("tags envelopes with the pod", func() {
+		client, _, _ := buildIngressClient(
+			"localhost:0",
+			time.Hour,
+			false,
+			loggregator.WithKubernetesPod(loggregator.KubernetesPod{
+				Name:      "some-pod",
+				Namespace: "some-namespace",
+			}),
+		)
+
+		Expect(client.Tags()).To(HaveKeyWithValue("pod_name", "some-pod"))
+		Expect(client.Tags()).To(HaveKeyWithValue("namespace", "some-namespace"))
+		Expect(client.Tags()).NotTo(HaveKey("node_name"))
+	})
+
+	Describe("NewIngressClientFromConfig", func() {
+		var config loggregator.MetronConfig
+
+		BeforeEach(func() {
+			setPodEnv()
+			config = loggregator.MetronConfig{
+				APIPort:    3458,
+				CACertPath: fixture("CA.crt"),
+				CertPath:   fixture("client.crt"),
+				KeyPath:    fixture("client.key"),
+				Tags:       map[string]string{"namespace": "configured-namespace"},
+			}
+		})
+
+		It("tags envelopes with the pod when no job is configured", func() {
+			client, err := loggregator.NewIngressClientFromConfig(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.Tags()).To(Equal(map[string]string{
+				"pod_name":  "some-pod",
+				"namespace": "configured-namespace",
+				"node_name": "some-node",
+			}))
+		})
+
+		It("prefers the job properties", func() {
+			config.JobName = "some-job"
+
+			client, err := loggregator.NewIngressClientFromConfig(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.Tags()).NotTo(HaveKey("pod_name"))
+			Expect(client.Tags()).To(HaveKeyWithValue("job", "some-job"))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package lagermetrics_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLagermetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lager Metrics Suite")
+}
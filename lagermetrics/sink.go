@@ -0,0 +1,170 @@
+// Package lagermetrics provides a lager sink that counts the log lines
+// written at error and fatal level and emits the counts as counters. Once
+// registered with a component's logger it gives every component an error
+// log rate without changing how it logs.
+package lagermetrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/lager"
+)
+
+// Sender is the interface of the client that is used to emit the counters.
+type Sender interface {
+	EmitCounter(name string, opts ...loggregator.EmitCounterOption)
+}
+
+// levelNames are the values of the level tag.
+var levelNames = map[lager.LogLevel]string{
+	lager.DEBUG: "debug",
+	lager.INFO:  "info",
+	lager.ERROR: "error",
+	lager.FATAL: "fatal",
+}
+
+// Sink is a lager.Sink that counts log lines by level. The counts are
+// accumulated and emitted as the deltas of counters on an interval rather
+// than once per log line, so that a burst of errors does not turn into a
+// burst of envelopes. The default interval is 15 seconds. It is safe for
+// concurrent use.
+type Sink struct {
+	sender   Sender
+	interval time.Duration
+	name     string
+	sourceID string
+	tags     map[string]string
+	levels   map[lager.LogLevel]bool
+
+	mu     sync.Mutex
+	counts map[lager.LogLevel]uint64
+}
+
+// SinkOption is the option that provides configuration for a Sink.
+type SinkOption func(s *Sink)
+
+// WithInterval returns a SinkOption to configure the interval at which the
+// counters are emitted.
+func WithInterval(d time.Duration) SinkOption {
+	return func(s *Sink) {
+		s.interval = d
+	}
+}
+
+// WithName returns a SinkOption to configure the name of the counters. It
+// defaults to "log_lines".
+func WithName(name string) SinkOption {
+	return func(s *Sink) {
+		s.name = name
+	}
+}
+
+// WithSourceID returns a SinkOption for setting the source ID of the
+// counters.
+func WithSourceID(id string) SinkOption {
+	return func(s *Sink) {
+		s.sourceID = id
+	}
+}
+
+// WithTags returns a SinkOption that adds the given tags to every counter.
+func WithTags(tags map[string]string) SinkOption {
+	return func(s *Sink) {
+		for k, v := range tags {
+			s.tags[k] = v
+		}
+	}
+}
+
+// WithLevels returns a SinkOption to configure the levels whose log lines
+// are counted. It defaults to lager.ERROR and lager.FATAL.
+func WithLevels(levels ...lager.LogLevel) SinkOption {
+	return func(s *Sink) {
+		s.levels = make(map[lager.LogLevel]bool, len(levels))
+		for _, l := range levels {
+			s.levels[l] = true
+		}
+	}
+}
+
+// New returns a Sink that emits counters via the sender. The sink has to be
+// registered with a logger and Run has to be called to emit the counters.
+func New(sender Sender, opts ...SinkOption) *Sink {
+	s := &Sink{
+		sender:   sender,
+		interval: 15 * time.Second,
+		name:     "log_lines",
+		tags:     make(map[string]string),
+		levels: map[lager.LogLevel]bool{
+			lager.ERROR: true,
+			lager.FATAL: true,
+		},
+		counts: make(map[lager.LogLevel]uint64),
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	return s
+}
+
+// Log implements lager.Sink. It counts the log line if its level is
+// counted.
+func (s *Sink) Log(f lager.LogFormat) {
+	if !s.levels[f.LogLevel] {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts[f.LogLevel]++
+}
+
+// Run starts the ticker with the configured interval and emits the counts
+// accumulated since the previous tick. Levels without log lines are not
+// emitted. This method will block but the user may run in a go routine.
+func (s *Sink) Run() {
+	for range time.Tick(s.interval) {
+		s.Flush()
+	}
+}
+
+// Flush emits the counts accumulated since the previous flush, e.g. before
+// the component exits.
+func (s *Sink) Flush() {
+	s.mu.Lock()
+	counts := s.counts
+	s.counts = make(map[lager.LogLevel]uint64, len(counts))
+	s.mu.Unlock()
+
+	for level, n := range counts {
+		tags := make(map[string]string, len(s.tags)+1)
+		for k, v := range s.tags {
+			tags[k] = v
+		}
+		tags["level"] = levelName(level)
+
+		opts := []loggregator.EmitCounterOption{
+			loggregator.WithDelta(n),
+			loggregator.WithEnvelopeTags(tags),
+		}
+		if s.sourceID != "" {
+			opts = append(opts, loggregator.WithCounterSourceInfo(s.sourceID, ""))
+		}
+
+		s.sender.EmitCounter(s.name, opts...)
+	}
+}
+
+func levelName(l lager.LogLevel) string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(l))
+}
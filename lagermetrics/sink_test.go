@@ -0,0 +1,121 @@
+package lagermetrics_test
+
+import (
+	"errors"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/lagermetrics"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/lager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sink", func() {
+	var (
+		spy    *spyClient
+		logger lager.Logger
+	)
+
+	BeforeEach(func() {
+		spy = newSpyClient()
+		logger = lager.NewLogger("some-component")
+	})
+
+	countsByLevel := func() map[string]uint64 {
+		counts := make(map[string]uint64)
+		for {
+			select {
+			case env := <-spy.envelopes:
+				counts[env.GetTags()["level"]] += env.GetCounter().GetDelta()
+			default:
+				return counts
+			}
+		}
+	}
+
+	It("counts error and fatal log lines", func() {
+		sink := lagermetrics.New(spy,
+			lagermetrics.WithSourceID("some-source"),
+			lagermetrics.WithTags(map[string]string{"job": "some-job"}),
+		)
+		logger.RegisterSink(sink)
+
+		logger.Info("some-info")
+		logger.Error("some-error", errors.New("boom"))
+		logger.Error("other-error", errors.New("boom"))
+		func() {
+			defer func() { recover() }()
+			logger.Fatal("some-fatal", errors.New("boom"))
+		}()
+
+		sink.Flush()
+
+		var env *loggregator_v2.Envelope
+		Expect(spy.envelopes).To(Receive(&env))
+		Expect(env.GetCounter().GetName()).To(Equal("log_lines"))
+		Expect(env.GetSourceId()).To(Equal("some-source"))
+		Expect(env.GetTags()).To(HaveKeyWithValue("job", "some-job"))
+		spy.envelopes <- env
+
+		Expect(countsByLevel()).To(Equal(map[string]uint64{
+			"error": 2,
+			"fatal": 1,
+		}))
+	})
+
+	It("emits the counts accumulated since the previous interval", func() {
+		sink := lagermetrics.New(spy,
+			lagermetrics.WithInterval(10*time.Millisecond),
+			lagermetrics.WithName("errors"),
+		)
+		logger.RegisterSink(sink)
+		go sink.Run()
+
+		logger.Error("some-error", errors.New("boom"))
+
+		var env *loggregator_v2.Envelope
+		Eventually(spy.envelopes).Should(Receive(&env))
+		Expect(env.GetCounter().GetName()).To(Equal("errors"))
+		Expect(env.GetCounter().GetDelta()).To(Equal(uint64(1)))
+		Consistently(spy.envelopes, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("counts the configured levels", func() {
+		sink := lagermetrics.New(spy, lagermetrics.WithLevels(lager.INFO))
+		logger.RegisterSink(sink)
+
+		logger.Info("some-info")
+		logger.Error("some-error", errors.New("boom"))
+		sink.Flush()
+
+		Expect(countsByLevel()).To(Equal(map[string]uint64{"info": 1}))
+	})
+})
+
+type spyClient struct {
+	envelopes chan *loggregator_v2.Envelope
+}
+
+func newSpyClient() *spyClient {
+	return &spyClient{
+		envelopes: make(chan *loggregator_v2.Envelope, 100),
+	}
+}
+
+func (s *spyClient) EmitCounter(name string, opts ...loggregator.EmitCounterOption) {
+	env := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: name, Delta: 1},
+		},
+		Tags: make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(env)
+	}
+
+	s.envelopes <- env
+}
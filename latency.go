@@ -0,0 +1,148 @@
+package loggregator
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// emittedAtTag is the tag that holds the time in nanoseconds since the Unix
+// epoch at which the client queued an envelope for sending.
+const emittedAtTag = "emitted_at"
+
+// WithEmitTimestamps configures the client to tag every envelope with the
+// time of the client's clock at which it is queued for sending. Consumers
+// may use a LatencyTracker to measure the delay of the pipeline between the
+// client and themselves. The clocks of the emitting and the consuming hosts
+// have to be in sync.
+func WithEmitTimestamps() IngressOption {
+	return func(c *IngressClient) {
+		c.emitTimestamps = true
+	}
+}
+
+// latencyBuckets are the upper bounds of the buckets of a LatencyTracker.
+var latencyBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// LatencyTracker records the end-to-end latency of envelopes emitted by a
+// client created with WithEmitTimestamps. Envelopes without a timestamp are
+// ignored. It is safe for concurrent use.
+type LatencyTracker struct {
+	mu     sync.Mutex
+	counts []uint64
+	dist   LatencyDistribution
+}
+
+// NewLatencyTracker returns an empty LatencyTracker.
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{
+		counts: make([]uint64, len(latencyBuckets)+1),
+	}
+}
+
+// Observe records the time passed since the envelope was emitted.
+func (t *LatencyTracker) Observe(e *loggregator_v2.Envelope) {
+	ns, err := strconv.ParseInt(e.GetTags()[emittedAtTag], 10, 64)
+	if err != nil {
+		return
+	}
+
+	latency := time.Since(time.Unix(0, ns))
+	if latency < 0 {
+		latency = 0
+	}
+
+	i := 0
+	for i < len(latencyBuckets) && latency > latencyBuckets[i] {
+		i++
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.counts[i]++
+	if t.dist.Count == 0 || latency < t.dist.Min {
+		t.dist.Min = latency
+	}
+	if latency > t.dist.Max {
+		t.dist.Max = latency
+	}
+	t.dist.Count++
+	t.dist.Sum += latency
+}
+
+// Distribution returns the latencies recorded so far.
+func (t *LatencyTracker) Distribution() LatencyDistribution {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	d := t.dist
+	d.counts = append([]uint64(nil), t.counts...)
+
+	return d
+}
+
+// LatencyDistribution summarizes the latencies recorded by a LatencyTracker.
+type LatencyDistribution struct {
+	Count uint64
+	Min   time.Duration
+	Max   time.Duration
+	Sum   time.Duration
+
+	counts []uint64
+}
+
+// Mean returns the average latency.
+func (d LatencyDistribution) Mean() time.Duration {
+	if d.Count == 0 {
+		return 0
+	}
+
+	return d.Sum / time.Duration(d.Count)
+}
+
+// Quantile returns an upper bound of the latency below which the given
+// fraction, between 0 and 1, of the envelopes fall. The bound is the upper
+// bound of a bucket and is never larger than Max.
+func (d LatencyDistribution) Quantile(q float64) time.Duration {
+	if d.Count == 0 {
+		return 0
+	}
+
+	rank := uint64(q*float64(d.Count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, n := range d.counts {
+		seen += n
+		if seen < rank {
+			continue
+		}
+		if i < len(latencyBuckets) && latencyBuckets[i] < d.Max {
+			return latencyBuckets[i]
+		}
+		break
+	}
+
+	return d.Max
+}
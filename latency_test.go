@@ -0,0 +1,71 @@
+package loggregator_test
+
+import (
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Latency tracking", func() {
+	It("tags envelopes with the time they are emitted", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false, loggregator.WithEmitTimestamps())
+
+		client.EmitLog("message")
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		ns, err := strconv.ParseInt(envelopes[0].Tags["emitted_at"], 10, 64)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Unix(0, ns)).To(BeTemporally("~", time.Now(), time.Second))
+	})
+
+	Describe("LatencyTracker", func() {
+		emittedAgo := func(d time.Duration) *loggregator_v2.Envelope {
+			return &loggregator_v2.Envelope{
+				Tags: map[string]string{
+					"emitted_at": strconv.FormatInt(time.Now().Add(-d).UnixNano(), 10),
+				},
+			}
+		}
+
+		It("records the latency distribution", func() {
+			tracker := loggregator.NewLatencyTracker()
+			for i := 0; i < 90; i++ {
+				tracker.Observe(emittedAgo(15 * time.Millisecond))
+			}
+			for i := 0; i < 10; i++ {
+				tracker.Observe(emittedAgo(3 * time.Second))
+			}
+
+			d := tracker.Distribution()
+			Expect(d.Count).To(Equal(uint64(100)))
+			Expect(d.Min).To(BeNumerically("~", 15*time.Millisecond, 5*time.Millisecond))
+			Expect(d.Max).To(BeNumerically("~", 3*time.Second, 100*time.Millisecond))
+			Expect(d.Mean()).To(BeNumerically("~", 313500*time.Microsecond, 20*time.Millisecond))
+			Expect(d.Quantile(0.5)).To(Equal(20 * time.Millisecond))
+			Expect(d.Quantile(0.99)).To(Equal(d.Max))
+		})
+
+		It("ignores envelopes without a timestamp", func() {
+			tracker := loggregator.NewLatencyTracker()
+			tracker.Observe(&loggregator_v2.Envelope{})
+
+			d := tracker.Distribution()
+			Expect(d.Count).To(BeZero())
+			Expect(d.Quantile(0.5)).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package loggregator
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// MirrorFormat formats a log envelope for WithLogMirror. The format.Line
+// and format.Multiline functions of the format package are MirrorFormats.
+type MirrorFormat func(*loggregator_v2.Envelope) string
+
+// MirrorPayload formats a log envelope as its payload, so that the output is
+// the same as if the application had written to stdout or stderr itself.
+func MirrorPayload(e *loggregator_v2.Envelope) string {
+	return string(e.GetLog().GetPayload())
+}
+
+// MirrorJSON formats a log envelope as a single line of JSON.
+func MirrorJSON(e *loggregator_v2.Envelope) string {
+	s, err := (&jsonpb.Marshaler{}).MarshalToString(e)
+	if err != nil {
+		return MirrorPayload(e)
+	}
+
+	return s
+}
+
+// WithLogMirror writes every log envelope that is emitted to stdout or, if
+// it is an error log, to stderr, formatted with format. This helps when
+// debugging and on platforms that scrape stdout in addition to Loggregator.
+// Envelopes are mirrored once the client's default tags and source info
+// have been added, before they pass through any middleware. A nil format
+// defaults to MirrorPayload.
+//
+// Typically, os.Stdout and os.Stderr are passed as stdout and stderr.
+func WithLogMirror(stdout, stderr io.Writer, format MirrorFormat) IngressOption {
+	return func(c *IngressClient) {
+		if format == nil {
+			format = MirrorPayload
+		}
+
+		c.mirror = &logMirror{
+			stdout: stdout,
+			stderr: stderr,
+			format: format,
+		}
+	}
+}
+
+type logMirror struct {
+	mu     sync.Mutex
+	stdout io.Writer
+	stderr io.Writer
+	format MirrorFormat
+}
+
+// middleware writes log envelopes before handing them on. The errors of
+// writing are ignored, mirroring must not affect sending.
+func (m *logMirror) middleware(next EmitFunc) EmitFunc {
+	return func(e *loggregator_v2.Envelope) error {
+		if l := e.GetLog(); l != nil {
+			w := m.stdout
+			if l.GetType() == loggregator_v2.Log_ERR {
+				w = m.stderr
+			}
+
+			line := m.format(e)
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n"
+			}
+
+			m.mu.Lock()
+			io.WriteString(w, line)
+			m.mu.Unlock()
+		}
+
+		return next(e)
+	}
+}
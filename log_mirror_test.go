@@ -0,0 +1,85 @@
+package loggregator_test
+
+import (
+	"bytes"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/format"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithLogMirror", func() {
+	var (
+		server         *testIngressServer
+		stdout, stderr *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("writes logs to stdout or stderr and still sends them", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithLogMirror(stdout, stderr, nil),
+		)
+
+		client.EmitLog("some-out", loggregator.WithStdout())
+		client.EmitLog("some-err\n")
+		client.EmitCounter("some-counter")
+
+		Expect(stdout.String()).To(Equal("some-out\n"))
+		Expect(stderr.String()).To(Equal("some-err\n"))
+
+		envelopes := receiveEnvelopes(server.receivers, 3)
+		Expect(envelopes).To(HaveLen(3))
+	})
+
+	It("formats logs with the given format", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithLogMirror(stdout, stderr, loggregator.MirrorFormat(format.Line)),
+		)
+
+		client.EmitLog(
+			"some-out",
+			loggregator.WithStdout(),
+			loggregator.WithSourceInfo("some-source", "APP/PROC/WEB", "0"),
+		)
+
+		Expect(stdout.String()).To(HaveSuffix(" [APP/PROC/WEB/0] OUT some-out\n"))
+	})
+
+	It("formats logs as JSON", func() {
+		e := &loggregator_v2.Envelope{
+			SourceId: "some-source",
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte("some-message")},
+			},
+		}
+
+		Expect(loggregator.MirrorJSON(e)).To(MatchJSON(`{"sourceId":"some-source","log":{"payload":"c29tZS1tZXNzYWdl"}}`))
+	})
+})
@@ -0,0 +1,81 @@
+package loggregator
+
+import (
+	"bytes"
+	"sync"
+)
+
+// LogEmitter is the interface of the client a LogWriter emits logs with.
+type LogEmitter interface {
+	EmitLog(message string, opts ...EmitLogOption)
+}
+
+// maxLogLine is the length after which a line without a newline is emitted
+// by a LogWriter anyway.
+const maxLogLine = 64 * 1024
+
+// LogWriter is an io.WriteCloser that emits every line written to it as a
+// log, e.g. to forward the output of a child process. It is safe for
+// concurrent use. It should be created with the NewLogWriter constructor.
+type LogWriter struct {
+	emitter LogEmitter
+	opts    []EmitLogOption
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewLogWriter returns a LogWriter that emits logs with the given options,
+// e.g. WithStdout or WithSourceInfo.
+func NewLogWriter(e LogEmitter, opts ...EmitLogOption) *LogWriter {
+	return &LogWriter{
+		emitter: e,
+		opts:    opts,
+	}
+}
+
+// Write emits the complete lines in p. A trailing partial line is kept until
+// it is completed by a following write or the writer is closed.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+
+	for len(w.buf) >= maxLogLine {
+		w.emit(w.buf[:maxLogLine])
+		w.buf = w.buf[maxLogLine:]
+	}
+
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+
+	return len(p), nil
+}
+
+// Close emits the partial line that is left, if any.
+func (w *LogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+	}
+	w.buf = nil
+
+	return nil
+}
+
+func (w *LogWriter) emit(line []byte) {
+	w.emitter.EmitLog(string(bytes.TrimSuffix(line, []byte{'\r'})), w.opts...)
+}
@@ -0,0 +1,62 @@
+package loggregator_test
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogWriter", func() {
+	var (
+		emitter *fakes.FakeEmitter
+		w       *loggregator.LogWriter
+	)
+
+	BeforeEach(func() {
+		emitter = &fakes.FakeEmitter{}
+		w = loggregator.NewLogWriter(emitter, loggregator.WithStdout())
+	})
+
+	messages := func() []string {
+		var m []string
+		for i := 0; i < emitter.EmitLogCallCount(); i++ {
+			msg, _ := emitter.EmitLogArgsForCall(i)
+			m = append(m, msg)
+		}
+		return m
+	}
+
+	It("emits every complete line", func() {
+		n, err := w.Write([]byte("first\nsec"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(9))
+		Expect(messages()).To(Equal([]string{"first"}))
+
+		w.Write([]byte("ond\r\nthird\n"))
+		Expect(messages()).To(Equal([]string{"first", "second", "third"}))
+
+		_, opts := emitter.EmitLogArgsForCall(0)
+		Expect(opts).To(HaveLen(1))
+	})
+
+	It("emits the partial line on close", func() {
+		w.Write([]byte("partial"))
+		Expect(messages()).To(BeEmpty())
+
+		Expect(w.Close()).To(Succeed())
+		Expect(messages()).To(Equal([]string{"partial"}))
+	})
+
+	It("splits very long lines", func() {
+		w.Write([]byte(strings.Repeat("a", 64*1024+1)))
+		Expect(messages()).To(HaveLen(1))
+
+		w.Close()
+		Expect(messages()).To(HaveLen(2))
+		Expect(messages()[1]).To(Equal("a"))
+	})
+})
@@ -0,0 +1,318 @@
+//go:build linux
+// +build linux
+
+// Package logdriver implements a docker logging driver plugin that forwards
+// the stdout and stderr of containers as v2 logs, e.g. for workloads that
+// are not run by Diego. The Driver serves the plugin API and is usually
+// served on the plugin's unix socket, see cmd/loggregator-log-driver.
+// Docker only runs logging plugins on Linux, so the package is only built
+// there.
+//
+// The driver is configured per container with log options:
+//
+//	source-id    the source ID of the logs, defaults to the container name
+//	instance-id  the instance ID of the logs, defaults to the container ID
+//	source-type  the source_type tag of the logs
+//	tag.<name>   adds the tag <name> to the logs
+package logdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// maxLine is the length after which partial lines are emitted although
+// docker did not complete them.
+const maxLine = 64 * 1024
+
+// stopGracePeriod is how long StopLogging waits for docker to close the
+// FIFO.
+const stopGracePeriod = time.Second
+
+const contentType = "application/vnd.docker.plugins.v1+json"
+
+// DriverOption configures a Driver.
+type DriverOption func(*Driver)
+
+// WithLogger configures the logger for errors while reading container logs.
+// It defaults to discarding them.
+func WithLogger(l loggregator.Logger) DriverOption {
+	return func(d *Driver) {
+		d.logger = l
+	}
+}
+
+// Info is the information docker passes about a container when it starts
+// logging.
+type Info struct {
+	Config             map[string]string `json:"Config"`
+	ContainerID        string            `json:"ContainerID"`
+	ContainerName      string            `json:"ContainerName"`
+	ContainerImageName string            `json:"ContainerImageName"`
+}
+
+// Driver forwards container logs to a sink, e.g. an IngressClient. It
+// should be created with the New constructor.
+type Driver struct {
+	sink   loggregator.Sink
+	logger loggregator.Logger
+
+	mu      sync.Mutex
+	streams map[string]*stream
+}
+
+// New returns a Driver that emits to sink.
+func New(sink loggregator.Sink, opts ...DriverOption) *Driver {
+	d := &Driver{
+		sink:    sink,
+		logger:  log.New(ioutil.Discard, "", 0),
+		streams: make(map[string]*stream),
+	}
+
+	for _, o := range opts {
+		o(d)
+	}
+
+	return d
+}
+
+// ServeHTTP implements the plugin API.
+func (d *Driver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var resp interface{}
+	switch r.URL.Path {
+	case "/Plugin.Activate":
+		resp = map[string][]string{"Implements": {"LogDriver"}}
+	case "/LogDriver.Capabilities":
+		resp = map[string]map[string]bool{"Cap": {"ReadLogs": false}}
+	case "/LogDriver.StartLogging":
+		var req struct {
+			File string `json:"File"`
+			Info Info   `json:"Info"`
+		}
+		resp = errorResponse(decode(r.Body, &req, func() error {
+			return d.StartLogging(req.File, req.Info)
+		}))
+	case "/LogDriver.StopLogging":
+		var req struct {
+			File string `json:"File"`
+		}
+		resp = errorResponse(decode(r.Body, &req, func() error {
+			return d.StopLogging(req.File)
+		}))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// StartLogging reads the log entries docker writes to the FIFO at file
+// and emits them as logs.
+func (d *Driver) StartLogging(file string, info Info) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.streams[file]; ok {
+		return fmt.Errorf("already logging %s", file)
+	}
+
+	s := &stream{
+		file:   file,
+		sink:   d.sink,
+		logger: d.logger,
+		base:   baseEnvelope(info),
+		done:   make(chan struct{}),
+	}
+	d.streams[file] = s
+	go s.run()
+
+	return nil
+}
+
+// StopLogging stops reading the FIFO at file. Entries that docker has
+// written before are emitted.
+func (d *Driver) StopLogging(file string) error {
+	d.mu.Lock()
+	s, ok := d.streams[file]
+	delete(d.streams, file)
+	d.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("not logging %s", file)
+	}
+
+	s.stop()
+	return nil
+}
+
+// baseEnvelope returns the envelope the logs of a container are copied
+// from.
+func baseEnvelope(info Info) *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		SourceId:   strings.TrimPrefix(info.ContainerName, "/"),
+		InstanceId: info.ContainerID,
+		Tags: map[string]string{
+			"container_id":   info.ContainerID,
+			"container_name": strings.TrimPrefix(info.ContainerName, "/"),
+		},
+	}
+	if len(e.InstanceId) > 12 {
+		e.InstanceId = e.InstanceId[:12]
+	}
+	if info.ContainerImageName != "" {
+		e.Tags["image"] = info.ContainerImageName
+	}
+
+	for k, v := range info.Config {
+		switch {
+		case k == "source-id":
+			e.SourceId = v
+		case k == "instance-id":
+			e.InstanceId = v
+		case k == "source-type":
+			e.Tags["source_type"] = v
+		case strings.HasPrefix(k, "tag."):
+			e.Tags[strings.TrimPrefix(k, "tag.")] = v
+		}
+	}
+
+	return e
+}
+
+type stream struct {
+	file   string
+	sink   loggregator.Sink
+	logger loggregator.Logger
+	base   *loggregator_v2.Envelope
+
+	mu      sync.Mutex
+	f       *os.File
+	stopped bool
+	done    chan struct{}
+}
+
+func (s *stream) run() {
+	defer close(s.done)
+
+	// Opening a FIFO blocks until docker opens it for writing.
+	f, err := os.OpenFile(s.file, os.O_RDONLY, 0)
+	if err != nil {
+		s.logger.Printf("Error while opening %s: %s", s.file, err)
+		return
+	}
+	defer f.Close()
+
+	s.mu.Lock()
+	s.f = f
+	s.mu.Unlock()
+
+	var (
+		buf     []byte
+		pending []byte
+	)
+	for {
+		var e entry
+		e, buf, err = readEntry(f, buf)
+		if err != nil {
+			if err != io.EOF && !s.isStopped() {
+				s.logger.Printf("Error while reading %s: %s", s.file, err)
+			}
+			return
+		}
+
+		pending = append(pending, e.line...)
+		if e.partial && len(pending) < maxLine {
+			continue
+		}
+
+		s.emit(e, pending)
+		pending = nil
+	}
+}
+
+func (s *stream) emit(e entry, line []byte) {
+	env := &loggregator_v2.Envelope{
+		Timestamp:  e.timeNano,
+		SourceId:   s.base.SourceId,
+		InstanceId: s.base.InstanceId,
+		Tags:       make(map[string]string, len(s.base.Tags)),
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{
+				Payload: line,
+				Type:    loggregator_v2.Log_OUT,
+			},
+		},
+	}
+	if e.source == "stderr" {
+		env.GetLog().Type = loggregator_v2.Log_ERR
+	}
+	for k, v := range s.base.Tags {
+		env.Tags[k] = v
+	}
+
+	if err := s.sink.EmitEnvelope(env); err != nil {
+		s.logger.Printf("Error while emitting log of %s: %s", s.file, err)
+	}
+}
+
+// stop waits for docker to close the FIFO, so that the entries written
+// before are emitted, and closes it after a grace period. A stream that is
+// still waiting for docker to open the FIFO ends once it is opened.
+func (s *stream) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return
+	case <-time.After(stopGracePeriod):
+	}
+
+	s.mu.Lock()
+	f := s.f
+	s.mu.Unlock()
+
+	if f == nil {
+		return
+	}
+
+	f.Close()
+	<-s.done
+}
+
+func (s *stream) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stopped
+}
+
+func decode(r io.Reader, v interface{}, f func() error) error {
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return err
+	}
+
+	return f()
+}
+
+func errorResponse(err error) map[string]string {
+	if err != nil {
+		return map[string]string{"Err": err.Error()}
+	}
+
+	return map[string]string{"Err": ""}
+}
@@ -0,0 +1,174 @@
+//go:build linux
+// +build linux
+
+package logdriver_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/logdriver"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Driver", func() {
+	var (
+		dir       string
+		fifo      string
+		envelopes chan *loggregator_v2.Envelope
+		server    *httptest.Server
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "logdriver")
+		Expect(err).NotTo(HaveOccurred())
+
+		fifo = filepath.Join(dir, "container")
+		Expect(syscall.Mkfifo(fifo, 0600)).To(Succeed())
+
+		envelopes = make(chan *loggregator_v2.Envelope, 100)
+		d := logdriver.New(loggregator.SinkFunc(func(e *loggregator_v2.Envelope) error {
+			envelopes <- e
+			return nil
+		}))
+		server = httptest.NewServer(d)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	post := func(path string, body interface{}) map[string]interface{} {
+		b, err := json.Marshal(body)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(string(b)))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var result map[string]interface{}
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		return result
+	}
+
+	startLogging := func(config map[string]string) {
+		resp := post("/LogDriver.StartLogging", map[string]interface{}{
+			"File": fifo,
+			"Info": map[string]interface{}{
+				"Config":             config,
+				"ContainerID":        "0123456789abcdef",
+				"ContainerName":      "/web",
+				"ContainerImageName": "nginx:latest",
+			},
+		})
+		Expect(resp).To(HaveKeyWithValue("Err", ""))
+	}
+
+	It("activates as a log driver", func() {
+		Expect(post("/Plugin.Activate", nil)).To(HaveKeyWithValue("Implements", ConsistOf("LogDriver")))
+		Expect(post("/LogDriver.Capabilities", nil)).To(HaveKeyWithValue("Cap", HaveKeyWithValue("ReadLogs", false)))
+	})
+
+	It("emits the entries of a container as logs", func() {
+		startLogging(nil)
+
+		w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		Expect(err).NotTo(HaveOccurred())
+		writeEntry(w, "stdout", 1000, "hello", false)
+		writeEntry(w, "stderr", 2000, "oops", false)
+		w.Close()
+
+		var e *loggregator_v2.Envelope
+		Eventually(envelopes).Should(Receive(&e))
+		Expect(e.GetTimestamp()).To(Equal(int64(1000)))
+		Expect(e.GetSourceId()).To(Equal("web"))
+		Expect(e.GetInstanceId()).To(Equal("0123456789ab"))
+		Expect(string(e.GetLog().GetPayload())).To(Equal("hello"))
+		Expect(e.GetLog().GetType()).To(Equal(loggregator_v2.Log_OUT))
+		Expect(e.GetTags()).To(Equal(map[string]string{
+			"container_id":   "0123456789abcdef",
+			"container_name": "web",
+			"image":          "nginx:latest",
+		}))
+
+		Eventually(envelopes).Should(Receive(&e))
+		Expect(string(e.GetLog().GetPayload())).To(Equal("oops"))
+		Expect(e.GetLog().GetType()).To(Equal(loggregator_v2.Log_ERR))
+
+		Expect(post("/LogDriver.StopLogging", map[string]string{"File": fifo})).To(HaveKeyWithValue("Err", ""))
+	})
+
+	It("joins partial entries", func() {
+		startLogging(nil)
+
+		w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		Expect(err).NotTo(HaveOccurred())
+		writeEntry(w, "stdout", 1000, "hel", true)
+		writeEntry(w, "stdout", 1001, "lo", false)
+		w.Close()
+
+		var e *loggregator_v2.Envelope
+		Eventually(envelopes).Should(Receive(&e))
+		Expect(string(e.GetLog().GetPayload())).To(Equal("hello"))
+	})
+
+	It("uses the log options", func() {
+		startLogging(map[string]string{
+			"source-id":   "some-source",
+			"instance-id": "7",
+			"source-type": "CNT",
+			"tag.zone":    "z1",
+		})
+
+		w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		Expect(err).NotTo(HaveOccurred())
+		writeEntry(w, "stdout", 1000, "hello", false)
+		w.Close()
+
+		var e *loggregator_v2.Envelope
+		Eventually(envelopes).Should(Receive(&e))
+		Expect(e.GetSourceId()).To(Equal("some-source"))
+		Expect(e.GetInstanceId()).To(Equal("7"))
+		Expect(e.GetTags()).To(HaveKeyWithValue("source_type", "CNT"))
+		Expect(e.GetTags()).To(HaveKeyWithValue("zone", "z1"))
+	})
+
+	It("reports errors to docker", func() {
+		resp := post("/LogDriver.StopLogging", map[string]string{"File": fifo})
+		Expect(resp["Err"]).To(ContainSubstring("not logging"))
+	})
+})
+
+func writeEntry(w *os.File, source string, timeNano int64, line string, partial bool) {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, source)
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(timeNano))
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendString(b, line)
+	if partial {
+		b = protowire.AppendTag(b, 4, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(b)))
+	_, err := w.Write(append(size[:], b...))
+	Expect(err).NotTo(HaveOccurred())
+}
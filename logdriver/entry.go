@@ -0,0 +1,112 @@
+//go:build linux
+// +build linux
+
+package logdriver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// maxEntrySize is the largest log entry accepted from docker. Docker itself
+// splits lines at 16KiB.
+const maxEntrySize = 1 << 20
+
+// entry is a log entry as written by docker to the FIFO of a container,
+// the LogEntry message of docker's logdriver package.
+type entry struct {
+	source   string
+	timeNano int64
+	line     []byte
+	partial  bool
+}
+
+// The field numbers of docker's LogEntry message.
+const (
+	sourceField   = 1
+	timeNanoField = 2
+	lineField     = 3
+	partialField  = 4
+)
+
+// readEntry reads an entry prefixed with its big-endian uint32 size.
+func readEntry(r io.Reader, buf []byte) (entry, []byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return entry{}, buf, err
+	}
+
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxEntrySize {
+		return entry{}, buf, fmt.Errorf("log entry of %d bytes is too large", n)
+	}
+
+	if cap(buf) < int(n) {
+		buf = make([]byte, n)
+	}
+	buf = buf[:n]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return entry{}, buf, err
+	}
+
+	e, err := unmarshalEntry(buf)
+	return e, buf, err
+}
+
+func unmarshalEntry(b []byte) (entry, error) {
+	var e entry
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return entry{}, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch {
+		case num == sourceField && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return entry{}, protowire.ParseError(n)
+			}
+			e.source = string(v)
+			b = b[n:]
+		case num == timeNanoField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return entry{}, protowire.ParseError(n)
+			}
+			e.timeNano = int64(v)
+			b = b[n:]
+		case num == lineField && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return entry{}, protowire.ParseError(n)
+			}
+			e.line = append([]byte(nil), v...)
+			b = b[n:]
+		case num == partialField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return entry{}, protowire.ParseError(n)
+			}
+			e.partial = v != 0
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return entry{}, protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+
+	if e.source == "" {
+		return entry{}, errors.New("log entry without source")
+	}
+
+	return e, nil
+}
@@ -0,0 +1,16 @@
+//go:build linux
+// +build linux
+
+package logdriver_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLogdriver(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Log Driver Suite")
+}
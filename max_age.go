@@ -0,0 +1,57 @@
+package loggregator
+
+import (
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// WithMaxEnvelopeAge configures the client to drop envelopes whose
+// timestamp is older than the given age when their batch is sent, e.g.
+// because they were stuck in the queue or were retried for too long. Late
+// envelopes would otherwise confuse downstream alerting. Dropped envelopes
+// are counted (see Expired) and handed to the dead-letter handler with
+// ErrEnvelopeExpired. The age is measured with the client's clock.
+func WithMaxEnvelopeAge(d time.Duration) IngressOption {
+	return func(c *IngressClient) {
+		c.maxAge = d
+	}
+}
+
+// Expired returns the number of envelopes dropped because they exceeded
+// the age configured with WithMaxEnvelopeAge or their deadline passed (see
+// WithDeadline).
+func (c *IngressClient) Expired() uint64 {
+	return atomic.LoadUint64(&c.expired)
+}
+
+// dropExpired removes the envelopes that exceed the maximum age or whose
+// deadline passed from the batch. The batch is modified in place.
+func (c *IngressClient) dropExpired(batch []*loggregator_v2.Envelope) []*loggregator_v2.Envelope {
+	now := c.now()
+	var expired []*loggregator_v2.Envelope
+	fresh := batch[:0]
+	for _, e := range batch {
+		if c.maxAge > 0 && e.GetTimestamp() < now.Add(-c.maxAge).UnixNano() {
+			expired = append(expired, e)
+			continue
+		}
+		if d, ok := deadline(e); ok && now.After(d) {
+			expired = append(expired, e)
+			continue
+		}
+		fresh = append(fresh, e)
+	}
+
+	if len(expired) == 0 {
+		return fresh
+	}
+
+	atomic.AddUint64(&c.expired, uint64(len(expired)))
+	c.logger.Printf("Dropped %d expired envelopes", len(expired))
+	c.handleDeadLetters(expired, ErrEnvelopeExpired)
+	c.report(expired, Dropped, ErrEnvelopeExpired)
+
+	return fresh
+}
@@ -0,0 +1,59 @@
+package loggregator_test
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Max envelope age", func() {
+	It("drops envelopes that are too old", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		var (
+			mu   sync.Mutex
+			errs []error
+		)
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithMaxEnvelopeAge(time.Minute),
+			loggregator.WithDeadLetterHandler(func(_ *loggregator_v2.Envelope, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			}),
+		)
+
+		Expect(client.EmitEnvelope(&loggregator_v2.Envelope{
+			SourceId:  "stale",
+			Timestamp: time.Now().Add(-time.Hour).UnixNano(),
+		})).To(Succeed())
+		Expect(client.EmitEnvelope(&loggregator_v2.Envelope{
+			SourceId:  "fresh",
+			Timestamp: time.Now().UnixNano(),
+		})).To(Succeed())
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes).To(HaveLen(1))
+		Expect(envelopes[0].SourceId).To(Equal("fresh"))
+		Expect(client.Expired()).To(Equal(uint64(1)))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(errs).To(ConsistOf(loggregator.ErrEnvelopeExpired))
+	})
+})
@@ -0,0 +1,187 @@
+// Package metrics provides definitions of the metrics a component emits.
+// Defining every metric once at startup validates the names before anything
+// is emitted and lets the component export a machine-readable catalog of
+// its metrics, e.g. to generate documentation and alerts.
+//
+//	var requests = metrics.Define("http.requests", metrics.Counter, "requests served")
+//
+//	client.EmitCounter(requests.Name)
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// Kind is the type of envelope a metric is emitted as.
+type Kind int
+
+// The kinds of metrics.
+const (
+	Counter Kind = iota
+	Gauge
+	Timer
+)
+
+var kindNames = map[Kind]string{
+	Counter: "counter",
+	Gauge:   "gauge",
+	Timer:   "timer",
+}
+
+// String returns the lower case name of the kind, e.g. "counter".
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k Kind) MarshalText() ([]byte, error) {
+	if _, ok := kindNames[k]; !ok {
+		return nil, fmt.Errorf("unknown metric kind %d", int(k))
+	}
+
+	return []byte(k.String()), nil
+}
+
+// maxNameLength is the maximum length of a metric name.
+const maxNameLength = 256
+
+// validName matches metric names. Names start with a letter and consist of
+// letters, digits, underscores, dots and dashes.
+var validName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.\-]*$`)
+
+// Definition describes a metric.
+type Definition struct {
+	Name        string `json:"name"`
+	Kind        Kind   `json:"type"`
+	Description string `json:"description"`
+	Unit        string `json:"unit,omitempty"`
+}
+
+// DefinitionOption is the option that provides additional information about
+// a metric.
+type DefinitionOption func(d *Definition)
+
+// WithUnit returns a DefinitionOption that sets the unit of a metric, e.g.
+// "bytes" for a gauge or "ms" for a timer.
+func WithUnit(unit string) DefinitionOption {
+	return func(d *Definition) {
+		d.Unit = unit
+	}
+}
+
+// Registry holds the definitions of metrics. It is safe for concurrent use.
+type Registry struct {
+	mu   sync.RWMutex
+	defs map[string]Definition
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		defs: make(map[string]Definition),
+	}
+}
+
+// Register validates and adds the definition of a metric. It returns an
+// error if the name is invalid, the kind is unknown or a metric of the same
+// name is already registered.
+func (r *Registry) Register(name string, kind Kind, description string, opts ...DefinitionOption) (Definition, error) {
+	d := Definition{
+		Name:        name,
+		Kind:        kind,
+		Description: description,
+	}
+	for _, o := range opts {
+		o(&d)
+	}
+
+	if len(name) > maxNameLength || !validName.MatchString(name) {
+		return d, fmt.Errorf("invalid metric name %q", name)
+	}
+	if _, ok := kindNames[kind]; !ok {
+		return d, fmt.Errorf("metric %q has unknown kind %d", name, int(kind))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.defs[name]; ok {
+		return d, fmt.Errorf("metric %q is already defined", name)
+	}
+	r.defs[name] = d
+
+	return d, nil
+}
+
+// Define is like Register but panics if the definition is invalid. It is
+// meant to be used to initialize package variables so that invalid
+// definitions are found at startup.
+func (r *Registry) Define(name string, kind Kind, description string, opts ...DefinitionOption) Definition {
+	d, err := r.Register(name, kind, description, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// Lookup returns the definition of the metric with the given name.
+func (r *Registry) Lookup(name string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.defs[name]
+	return d, ok
+}
+
+// Catalog returns the registered definitions ordered by name.
+func (r *Registry) Catalog() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]Definition, 0, len(r.defs))
+	for _, d := range r.defs {
+		defs = append(defs, d)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+
+	return defs
+}
+
+// WriteCatalog writes the catalog as a JSON array to w.
+func (r *Registry) WriteCatalog(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r.Catalog())
+}
+
+// DefaultRegistry is the registry used by the package level functions.
+var DefaultRegistry = NewRegistry()
+
+// Define defines a metric in the DefaultRegistry. See Registry.Define.
+func Define(name string, kind Kind, description string, opts ...DefinitionOption) Definition {
+	return DefaultRegistry.Define(name, kind, description, opts...)
+}
+
+// Catalog returns the definitions of the DefaultRegistry ordered by name.
+func Catalog() []Definition {
+	return DefaultRegistry.Catalog()
+}
+
+// WriteCatalog writes the catalog of the DefaultRegistry as a JSON array to
+// w.
+func WriteCatalog(w io.Writer) error {
+	return DefaultRegistry.WriteCatalog(w)
+}
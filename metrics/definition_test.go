@@ -0,0 +1,73 @@
+package metrics_test
+
+import (
+	"bytes"
+
+	"code.cloudfoundry.org/go-loggregator/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var r *metrics.Registry
+
+	BeforeEach(func() {
+		r = metrics.NewRegistry()
+	})
+
+	It("defines metrics", func() {
+		d := r.Define("http.requests", metrics.Counter, "requests served")
+
+		Expect(d).To(Equal(metrics.Definition{
+			Name:        "http.requests",
+			Kind:        metrics.Counter,
+			Description: "requests served",
+		}))
+		found, ok := r.Lookup("http.requests")
+		Expect(ok).To(BeTrue())
+		Expect(found).To(Equal(d))
+	})
+
+	It("rejects invalid names", func() {
+		for _, name := range []string{"", "1requests", "http requests", "http/requests"} {
+			_, err := r.Register(name, metrics.Counter, "")
+			Expect(err).To(MatchError(ContainSubstring("invalid metric name")), name)
+		}
+	})
+
+	It("rejects unknown kinds", func() {
+		_, err := r.Register("requests", metrics.Kind(42), "")
+		Expect(err).To(MatchError(ContainSubstring("unknown kind")))
+	})
+
+	It("rejects duplicate names", func() {
+		r.Define("requests", metrics.Counter, "")
+
+		_, err := r.Register("requests", metrics.Gauge, "")
+		Expect(err).To(MatchError(ContainSubstring("already defined")))
+	})
+
+	It("panics when a definition is invalid", func() {
+		Expect(func() {
+			r.Define("http requests", metrics.Counter, "")
+		}).To(Panic())
+	})
+
+	It("exports a catalog ordered by name", func() {
+		r.Define("memory", metrics.Gauge, "resident memory", metrics.WithUnit("bytes"))
+		r.Define("http.latency", metrics.Timer, "request latency")
+
+		Expect(r.Catalog()).To(Equal([]metrics.Definition{
+			{Name: "http.latency", Kind: metrics.Timer, Description: "request latency"},
+			{Name: "memory", Kind: metrics.Gauge, Description: "resident memory", Unit: "bytes"},
+		}))
+
+		var buf bytes.Buffer
+		Expect(r.WriteCatalog(&buf)).To(Succeed())
+		Expect(buf.String()).To(MatchJSON(`[
+			{"name": "http.latency", "type": "timer", "description": "request latency"},
+			{"name": "memory", "type": "gauge", "description": "resident memory", "unit": "bytes"}
+		]`))
+	})
+})
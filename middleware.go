@@ -0,0 +1,257 @@
+package loggregator
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// EmitFunc hands an envelope to the next stage of the emit pipeline.
+type EmitFunc func(*loggregator_v2.Envelope) error
+
+// Middleware is a stage of the emit pipeline. It may inspect, modify or
+// drop an envelope before passing it to next. An envelope is dropped by
+// returning an error without calling next.
+type Middleware func(next EmitFunc) EmitFunc
+
+// WithMiddleware adds stages to the emit pipeline of the client. Every
+// envelope passes through the stages in the given order after the client's
+// default tags and source info have been added and before it is queued for
+// sending. Errors returned by a stage are handled like any other send error,
+// i.e. they are logged and passed to the dead-letter handler.
+func WithMiddleware(mw ...Middleware) IngressOption {
+	return func(c *IngressClient) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// chain wraps the given EmitFunc with the middleware so that the first
+// middleware is called first.
+func chain(mw []Middleware, f EmitFunc) EmitFunc {
+	for i := len(mw) - 1; i >= 0; i-- {
+		f = mw[i](f)
+	}
+
+	return f
+}
+
+// LoggingMiddleware logs every envelope and the result of sending it.
+func LoggingMiddleware(l Logger) Middleware {
+	return func(next EmitFunc) EmitFunc {
+		return func(e *loggregator_v2.Envelope) error {
+			err := next(e)
+			if err != nil {
+				l.Printf("Failed to emit %s envelope for source %q: %s", envelope.Type(e), e.GetSourceId(), err)
+				return err
+			}
+
+			l.Printf("Emitted %s envelope for source %q", envelope.Type(e), e.GetSourceId())
+			return nil
+		}
+	}
+}
+
+// PipelineMetrics counts the envelopes passing through a MetricsMiddleware.
+// It is safe for concurrent use.
+type PipelineMetrics struct {
+	emitted uint64
+	failed  uint64
+}
+
+// Emitted returns the number of envelopes that were passed on successfully.
+func (m *PipelineMetrics) Emitted() uint64 {
+	return atomic.LoadUint64(&m.emitted)
+}
+
+// Failed returns the number of envelopes that the following stages
+// returned an error for.
+func (m *PipelineMetrics) Failed() uint64 {
+	return atomic.LoadUint64(&m.failed)
+}
+
+// MetricsMiddleware counts the envelopes emitted by the following stages in
+// the given PipelineMetrics.
+func MetricsMiddleware(m *PipelineMetrics) Middleware {
+	return func(next EmitFunc) EmitFunc {
+		return func(e *loggregator_v2.Envelope) error {
+			err := next(e)
+			if err != nil {
+				atomic.AddUint64(&m.failed, 1)
+				return err
+			}
+
+			atomic.AddUint64(&m.emitted, 1)
+			return nil
+		}
+	}
+}
+
+// WithRateLimit adds a stage to the emit pipeline that drops envelopes
+// exceeding the given rate per second with ErrRateLimited, like
+// RateLimitMiddleware. Unlike the middleware, the limit can be changed with
+// UpdateConfig. The client's clock, see WithClock, measures the time passed
+// between envelopes.
+func WithRateLimit(perSecond float64, burst int) IngressOption {
+	return func(c *IngressClient) {
+		c.limiter = newTokenBucket(perSecond, burst)
+	}
+}
+
+// WithSampling adds a stage to the emit pipeline that passes on the given
+// fraction of envelopes, like SamplingMiddleware. Unlike the middleware,
+// the rate can be changed with UpdateConfig.
+func WithSampling(rate float64) IngressOption {
+	return func(c *IngressClient) {
+		c.sampler = newSampler(rate)
+	}
+}
+
+// RateLimitOption is the option type passed into RateLimitMiddleware.
+type RateLimitOption func(*tokenBucket)
+
+// WithRateLimitClock configures the rate limit to measure the time passed
+// between envelopes with the given clock instead of the host clock.
+func WithRateLimitClock(clock Clock) RateLimitOption {
+	return func(b *tokenBucket) {
+		b.clock = clock
+	}
+}
+
+// RateLimitMiddleware drops envelopes that exceed the given rate per second
+// with ErrRateLimited. Up to burst envelopes may be emitted at once.
+func RateLimitMiddleware(perSecond float64, burst int, opts ...RateLimitOption) Middleware {
+	b := newTokenBucket(perSecond, burst)
+	for _, o := range opts {
+		o(b)
+	}
+	b.last = b.now()
+
+	return b.middleware
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	clock  Clock
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket. Its refill starts at the time last
+// is set to.
+func newTokenBucket(perSecond float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+func (b *tokenBucket) middleware(next EmitFunc) EmitFunc {
+	return func(e *loggregator_v2.Envelope) error {
+		if !b.take(b.now()) {
+			return ErrRateLimited
+		}
+
+		return next(e)
+	}
+}
+
+func (b *tokenBucket) now() time.Time {
+	if b.clock == nil {
+		return time.Now()
+	}
+
+	return b.clock.Now()
+}
+
+// take refills the bucket for the time passed since the last call and
+// takes a token if one is available.
+func (b *tokenBucket) take(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// set changes the rate and the burst. Tokens beyond the new burst are
+// discarded.
+func (b *tokenBucket) set(perSecond float64, burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rate = perSecond
+	b.burst = float64(burst)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// SamplingMiddleware passes on the given fraction of envelopes, e.g. one in
+// four for a rate of 0.25, spread evenly. The other envelopes are discarded
+// without an error, so they are neither logged nor passed to the
+// dead-letter handler. A rate of 1 or more passes on every envelope.
+func SamplingMiddleware(rate float64) Middleware {
+	return newSampler(rate).middleware
+}
+
+type sampler struct {
+	mu     sync.Mutex
+	rate   float64
+	credit float64
+}
+
+func newSampler(rate float64) *sampler {
+	return &sampler{rate: rate}
+}
+
+func (s *sampler) middleware(next EmitFunc) EmitFunc {
+	return func(e *loggregator_v2.Envelope) error {
+		if !s.sample() {
+			return nil
+		}
+
+		return next(e)
+	}
+}
+
+// sample reports whether the next envelope is passed on. Every envelope
+// earns the rate as credit and one credit is spent per envelope passed on.
+func (s *sampler) sample() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.credit += s.rate
+	if s.credit < 1 {
+		return false
+	}
+	s.credit--
+	if s.credit > 1 {
+		s.credit = 1
+	}
+
+	return true
+}
+
+// set changes the rate.
+func (s *sampler) set(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rate = rate
+}
@@ -0,0 +1,184 @@
+package loggregator_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var (
+		emitted []*loggregator_v2.Envelope
+		emitErr error
+		next    loggregator.EmitFunc
+	)
+
+	BeforeEach(func() {
+		emitted = nil
+		emitErr = nil
+		next = func(e *loggregator_v2.Envelope) error {
+			emitted = append(emitted, e)
+			return emitErr
+		}
+	})
+
+	It("passes envelopes through the stages of a client in order", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		tagWith := func(value string) loggregator.Middleware {
+			return func(next loggregator.EmitFunc) loggregator.EmitFunc {
+				return func(e *loggregator_v2.Envelope) error {
+					e.Tags["stages"] += value
+					return next(e)
+				}
+			}
+		}
+		dropDebug := func(next loggregator.EmitFunc) loggregator.EmitFunc {
+			return func(e *loggregator_v2.Envelope) error {
+				if e.Tags["level"] == "debug" {
+					return errors.New("dropped")
+				}
+				return next(e)
+			}
+		}
+
+		client, _, _ := buildIngressClient(
+			server.addr,
+			50*time.Millisecond,
+			false,
+			loggregator.WithMiddleware(tagWith("a"), dropDebug),
+			loggregator.WithMiddleware(tagWith("b")),
+		)
+
+		client.EmitLog("debug", loggregator.WithEnvelopeTag("level", "debug"))
+		client.EmitLog("info", loggregator.WithEnvelopeTag("level", "info"))
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes).To(HaveLen(1))
+		Expect(envelopes[0].GetLog().Payload).To(Equal([]byte("info")))
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("stages", "ab"))
+	})
+
+	It("logs emitted envelopes", func() {
+		logger := newSpyLogger()
+		emit := loggregator.LoggingMiddleware(logger)(next)
+
+		Expect(emit(&loggregator_v2.Envelope{
+			SourceId: "some-source",
+			Message:  &loggregator_v2.Envelope_Log{Log: &loggregator_v2.Log{}},
+		})).To(Succeed())
+		emitErr = errors.New("some-error")
+		Expect(emit(&loggregator_v2.Envelope{SourceId: "other-source"})).To(MatchError("some-error"))
+
+		Expect(logger.messages()).To(HaveLen(2))
+		Expect(logger.messages()[0]).To(ContainSubstring(`log envelope for source "some-source"`))
+		Expect(logger.messages()[1]).To(ContainSubstring("some-error"))
+	})
+
+	It("counts emitted and failed envelopes", func() {
+		var metrics loggregator.PipelineMetrics
+		emit := loggregator.MetricsMiddleware(&metrics)(next)
+
+		Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		emitErr = errors.New("some-error")
+		Expect(emit(&loggregator_v2.Envelope{})).NotTo(Succeed())
+
+		Expect(metrics.Emitted()).To(Equal(uint64(2)))
+		Expect(metrics.Failed()).To(Equal(uint64(1)))
+	})
+
+	It("drops envelopes that exceed the rate limit", func() {
+		emit := loggregator.RateLimitMiddleware(0, 2)(next)
+
+		Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		Expect(emit(&loggregator_v2.Envelope{})).To(MatchError(loggregator.ErrRateLimited))
+		Expect(emitted).To(HaveLen(2))
+	})
+
+	It("refills the rate limit over time", func() {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		emit := loggregator.RateLimitMiddleware(10, 1, loggregator.WithRateLimitClock(clock))(next)
+
+		Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		Expect(emit(&loggregator_v2.Envelope{})).To(MatchError(loggregator.ErrRateLimited))
+
+		clock.add(50 * time.Millisecond)
+		Expect(emit(&loggregator_v2.Envelope{})).To(MatchError(loggregator.ErrRateLimited))
+
+		clock.add(50 * time.Millisecond)
+		Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		Expect(emit(&loggregator_v2.Envelope{})).To(MatchError(loggregator.ErrRateLimited))
+	})
+
+	It("refills the rate limit up to the burst", func() {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		emit := loggregator.RateLimitMiddleware(10, 3, loggregator.WithRateLimitClock(clock))(next)
+
+		for i := 0; i < 3; i++ {
+			Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		}
+		Expect(emit(&loggregator_v2.Envelope{})).To(MatchError(loggregator.ErrRateLimited))
+
+		clock.add(time.Hour)
+		for i := 0; i < 3; i++ {
+			Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		}
+		Expect(emit(&loggregator_v2.Envelope{})).To(MatchError(loggregator.ErrRateLimited))
+		Expect(emitted).To(HaveLen(6))
+	})
+
+	It("passes on the sampled fraction of envelopes", func() {
+		emit := loggregator.SamplingMiddleware(0.25)(next)
+
+		for i := 0; i < 100; i++ {
+			Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		}
+
+		Expect(emitted).To(HaveLen(25))
+	})
+
+	It("passes on every envelope with a sampling rate of one", func() {
+		emit := loggregator.SamplingMiddleware(1)(next)
+
+		for i := 0; i < 10; i++ {
+			Expect(emit(&loggregator_v2.Envelope{})).To(Succeed())
+		}
+
+		Expect(emitted).To(HaveLen(10))
+	})
+})
+
+// manualClock is a Clock that only advances when told to.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *manualClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,29 @@
+// Package oteltrace extracts the IDs of OpenTelemetry spans for the
+// loggregator IngressClient. For example:
+//
+//	client, err := loggregator.NewIngressClient(
+//		tlsConfig,
+//		loggregator.WithTraceExtractor(oteltrace.Extract),
+//	)
+//
+// Logs and timers emitted with EmitLogFromContext and EmitTimerFromContext
+// are then tagged with the trace_id and span_id of the span in the context.
+package oteltrace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Extract returns the hex encoded trace and span IDs of the OpenTelemetry
+// span carried by ctx. It returns false if ctx does not carry a valid span
+// context. It satisfies loggregator.TraceExtractor.
+func Extract(ctx context.Context) (traceID, spanID string, ok bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", "", false
+	}
+
+	return sc.TraceID().String(), sc.SpanID().String(), true
+}
@@ -0,0 +1,13 @@
+package oteltrace_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestOteltrace(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OpenTelemetry Trace Suite")
+}
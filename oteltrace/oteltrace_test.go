@@ -0,0 +1,37 @@
+package oteltrace_test
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/oteltrace"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ loggregator.TraceExtractor = oteltrace.Extract
+
+var _ = Describe("Extract", func() {
+	It("returns the IDs of the span in the context", func() {
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			TraceFlags: trace.FlagsSampled,
+		})
+		ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+		traceID, spanID, ok := oteltrace.Extract(ctx)
+
+		Expect(ok).To(BeTrue())
+		Expect(traceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(spanID).To(Equal("00f067aa0ba902b7"))
+	})
+
+	It("returns false without a span", func() {
+		_, _, ok := oteltrace.Extract(context.Background())
+
+		Expect(ok).To(BeFalse())
+	})
+})
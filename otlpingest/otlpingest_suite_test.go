@@ -0,0 +1,13 @@
+package otlpingest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestOtlpingest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OTLP Ingest Suite")
+}
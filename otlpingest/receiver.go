@@ -0,0 +1,322 @@
+// Package otlpingest provides a receiver for the OTLP/gRPC logs and metrics
+// services. It converts what OpenTelemetry-instrumented apps export into v2
+// envelopes, so that they can send to Loggregator without code changes:
+//
+//	r := otlpingest.New(client)
+//	s := grpc.NewServer()
+//	r.Register(s)
+//	s.Serve(lis)
+//
+// The service.name and service.instance.id resource attributes become the
+// source ID and instance ID of the envelopes, all other attributes become
+// tags. Log records with a severity of ERROR or more severe are emitted as
+// stderr. Gauges and non-monotonic sums become gauges, monotonic sums become
+// counters. Histograms and summaries are not supported and are reported as
+// rejected.
+package otlpingest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	serviceNameAttribute       = "service.name"
+	serviceInstanceIDAttribute = "service.instance.id"
+)
+
+// ReceiverOption configures a Receiver.
+type ReceiverOption func(*Receiver)
+
+// WithSourceID configures the source ID of envelopes whose resource has no
+// service.name attribute.
+func WithSourceID(id string) ReceiverOption {
+	return func(r *Receiver) {
+		r.sourceID = id
+	}
+}
+
+// WithTags adds the given tags to every envelope. Attributes take
+// precedence.
+func WithTags(tags map[string]string) ReceiverOption {
+	return func(r *Receiver) {
+		for k, v := range tags {
+			r.tags[k] = v
+		}
+	}
+}
+
+// Receiver implements the OTLP logs and metrics services and emits what it
+// receives to a sink, e.g. an IngressClient. It should be created with the
+// New constructor.
+type Receiver struct {
+	sink     loggregator.Sink
+	sourceID string
+	tags     map[string]string
+}
+
+// New returns a Receiver that emits to the sink.
+func New(sink loggregator.Sink, opts ...ReceiverOption) *Receiver {
+	r := &Receiver{
+		sink: sink,
+		tags: make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r
+}
+
+// Register registers the logs and metrics services with the gRPC server.
+func (r *Receiver) Register(s *grpc.Server) {
+	collogspb.RegisterLogsServiceServer(s, logsService{r: r})
+	colmetricspb.RegisterMetricsServiceServer(s, metricsService{r: r})
+}
+
+// ExportLogs emits a log for every log record of the request.
+func (r *Receiver) ExportLogs(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	for _, rl := range req.GetResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			for _, lr := range sl.GetLogRecords() {
+				e := r.envelope(rl.GetResource(), lr.GetAttributes(), lr.GetTimeUnixNano())
+				if lr.GetTimeUnixNano() == 0 && lr.GetObservedTimeUnixNano() != 0 {
+					e.Timestamp = int64(lr.GetObservedTimeUnixNano())
+				}
+				if len(lr.GetTraceId()) > 0 {
+					e.Tags["trace_id"] = hex.EncodeToString(lr.GetTraceId())
+				}
+				if len(lr.GetSpanId()) > 0 {
+					e.Tags["span_id"] = hex.EncodeToString(lr.GetSpanId())
+				}
+
+				body, _ := formatValue(lr.GetBody())
+				t := loggregator_v2.Log_OUT
+				if lr.GetSeverityNumber() >= logspb.SeverityNumber_SEVERITY_NUMBER_ERROR {
+					t = loggregator_v2.Log_ERR
+				}
+				e.Message = &loggregator_v2.Envelope_Log{
+					Log: &loggregator_v2.Log{
+						Payload: []byte(body),
+						Type:    t,
+					},
+				}
+
+				if err := r.sink.EmitEnvelope(e); err != nil {
+					return nil, status.Error(codes.Unavailable, err.Error())
+				}
+			}
+		}
+	}
+
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// ExportMetrics emits a gauge or counter for every data point of the
+// request. Data points of unsupported types are reported as rejected.
+func (r *Receiver) ExportMetrics(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	var (
+		rejected int64
+		reason   string
+	)
+
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				envs, n, err := r.metricEnvelopes(rm.GetResource(), m)
+				if n > 0 {
+					rejected += n
+					reason = err.Error()
+				}
+
+				for _, e := range envs {
+					if err := r.sink.EmitEnvelope(e); err != nil {
+						return nil, status.Error(codes.Unavailable, err.Error())
+					}
+				}
+			}
+		}
+	}
+
+	resp := &colmetricspb.ExportMetricsServiceResponse{}
+	if rejected > 0 {
+		resp.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected,
+			ErrorMessage:       reason,
+		}
+	}
+
+	return resp, nil
+}
+
+// metricEnvelopes converts the data points of a metric. It returns the
+// number of data points that could not be converted and why.
+func (r *Receiver) metricEnvelopes(res *resourcepb.Resource, m *metricspb.Metric) ([]*loggregator_v2.Envelope, int64, error) {
+	switch d := m.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		var envs []*loggregator_v2.Envelope
+		for _, dp := range d.Gauge.GetDataPoints() {
+			envs = append(envs, r.gauge(res, m, dp))
+		}
+		return envs, 0, nil
+	case *metricspb.Metric_Sum:
+		var (
+			envs     []*loggregator_v2.Envelope
+			rejected int64
+			err      error
+		)
+		for _, dp := range d.Sum.GetDataPoints() {
+			if !d.Sum.GetIsMonotonic() {
+				envs = append(envs, r.gauge(res, m, dp))
+				continue
+			}
+
+			v := value(dp)
+			if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+				rejected++
+				err = fmt.Errorf("metric %s: invalid monotonic sum %v", m.GetName(), v)
+				continue
+			}
+
+			e := r.envelope(res, dp.GetAttributes(), dp.GetTimeUnixNano())
+			c := &loggregator_v2.Counter{Name: m.GetName()}
+			if d.Sum.GetAggregationTemporality() == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+				c.Delta = uint64(math.Round(v))
+			} else {
+				c.Total = uint64(math.Round(v))
+			}
+			e.Message = &loggregator_v2.Envelope_Counter{Counter: c}
+			envs = append(envs, e)
+		}
+		return envs, rejected, err
+	case *metricspb.Metric_Histogram:
+		return nil, int64(len(d.Histogram.GetDataPoints())), unsupported(m, "histogram")
+	case *metricspb.Metric_ExponentialHistogram:
+		return nil, int64(len(d.ExponentialHistogram.GetDataPoints())), unsupported(m, "exponential histogram")
+	case *metricspb.Metric_Summary:
+		return nil, int64(len(d.Summary.GetDataPoints())), unsupported(m, "summary")
+	default:
+		return nil, 0, nil
+	}
+}
+
+func (r *Receiver) gauge(res *resourcepb.Resource, m *metricspb.Metric, dp *metricspb.NumberDataPoint) *loggregator_v2.Envelope {
+	e := r.envelope(res, dp.GetAttributes(), dp.GetTimeUnixNano())
+	e.Message = &loggregator_v2.Envelope_Gauge{
+		Gauge: &loggregator_v2.Gauge{
+			Metrics: map[string]*loggregator_v2.GaugeValue{
+				m.GetName(): {Value: value(dp), Unit: m.GetUnit()},
+			},
+		},
+	}
+
+	return e
+}
+
+func (r *Receiver) envelope(res *resourcepb.Resource, attrs []*commonpb.KeyValue, ts uint64) *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		SourceId:  r.sourceID,
+		Timestamp: int64(ts),
+		Tags:      make(map[string]string, len(r.tags)),
+	}
+	if ts == 0 {
+		e.Timestamp = time.Now().UnixNano()
+	}
+
+	for k, v := range r.tags {
+		e.Tags[k] = v
+	}
+
+	for _, kv := range res.GetAttributes() {
+		v, ok := formatValue(kv.GetValue())
+		if !ok {
+			continue
+		}
+
+		switch kv.GetKey() {
+		case serviceNameAttribute:
+			e.SourceId = v
+		case serviceInstanceIDAttribute:
+			e.InstanceId = v
+		default:
+			e.Tags[kv.GetKey()] = v
+		}
+	}
+
+	for _, kv := range attrs {
+		if v, ok := formatValue(kv.GetValue()); ok {
+			e.Tags[kv.GetKey()] = v
+		}
+	}
+
+	return e
+}
+
+func value(dp *metricspb.NumberDataPoint) float64 {
+	if v, ok := dp.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		return float64(v.AsInt)
+	}
+
+	return dp.GetAsDouble()
+}
+
+// formatValue formats scalar values. It returns false for arrays, maps and
+// empty values.
+func formatValue(v *commonpb.AnyValue) (string, bool) {
+	switch x := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return x.StringValue, true
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(x.BoolValue), true
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(x.IntValue, 10), true
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(x.DoubleValue, 'g', -1, 64), true
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(x.BytesValue), true
+	default:
+		return "", false
+	}
+}
+
+func unsupported(m *metricspb.Metric, kind string) error {
+	return fmt.Errorf("metric %s: %s is not supported", m.GetName(), kind)
+}
+
+// logsService and metricsService adapt the Receiver to the generated
+// service interfaces, whose methods are both named Export.
+type logsService struct {
+	collogspb.UnimplementedLogsServiceServer
+	r *Receiver
+}
+
+func (s logsService) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	return s.r.ExportLogs(ctx, req)
+}
+
+type metricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	r *Receiver
+}
+
+func (s metricsService) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	return s.r.ExportMetrics(ctx, req)
+}
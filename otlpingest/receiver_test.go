@@ -0,0 +1,218 @@
+package otlpingest_test
+
+import (
+	"context"
+	"errors"
+
+	"code.cloudfoundry.org/go-loggregator/fakes"
+	"code.cloudfoundry.org/go-loggregator/otlpingest"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Receiver", func() {
+	var (
+		sink     *fakes.FakeSink
+		receiver *otlpingest.Receiver
+		resource *resourcepb.Resource
+	)
+
+	BeforeEach(func() {
+		sink = &fakes.FakeSink{}
+		receiver = otlpingest.New(
+			sink,
+			otlpingest.WithSourceID("default-source"),
+			otlpingest.WithTags(map[string]string{"ingest": "otlp"}),
+		)
+		resource = &resourcepb.Resource{
+			Attributes: []*commonpb.KeyValue{
+				stringAttr("service.name", "some-service"),
+				stringAttr("service.instance.id", "1"),
+				stringAttr("deployment", "some-deployment"),
+			},
+		}
+	})
+
+	Describe("ExportLogs", func() {
+		export := func(records ...*logspb.LogRecord) error {
+			_, err := receiver.ExportLogs(context.Background(), &collogspb.ExportLogsServiceRequest{
+				ResourceLogs: []*logspb.ResourceLogs{{
+					Resource:  resource,
+					ScopeLogs: []*logspb.ScopeLogs{{LogRecords: records}},
+				}},
+			})
+			return err
+		}
+
+		It("emits a log for every record", func() {
+			err := export(&logspb.LogRecord{
+				TimeUnixNano:   1234,
+				SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+				Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "some-message"}},
+				Attributes: []*commonpb.KeyValue{
+					{Key: "retries", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 3}}},
+				},
+				TraceId: []byte{0x01, 0x02},
+				SpanId:  []byte{0x03},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(sink.EmitEnvelopeCallCount()).To(Equal(1))
+			Expect(sink.EmitEnvelopeArgsForCall(0)).To(Equal(&loggregator_v2.Envelope{
+				SourceId:   "some-service",
+				InstanceId: "1",
+				Timestamp:  1234,
+				Tags: map[string]string{
+					"ingest":     "otlp",
+					"deployment": "some-deployment",
+					"retries":    "3",
+					"trace_id":   "0102",
+					"span_id":    "03",
+				},
+				Message: &loggregator_v2.Envelope_Log{
+					Log: &loggregator_v2.Log{Payload: []byte("some-message"), Type: loggregator_v2.Log_OUT},
+				},
+			}))
+		})
+
+		It("emits records with a severity of ERROR or more severe as stderr", func() {
+			err := export(
+				&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN},
+				&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_ERROR},
+				&logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_FATAL},
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(sink.EmitEnvelopeArgsForCall(0).GetLog().GetType()).To(Equal(loggregator_v2.Log_OUT))
+			Expect(sink.EmitEnvelopeArgsForCall(1).GetLog().GetType()).To(Equal(loggregator_v2.Log_ERR))
+			Expect(sink.EmitEnvelopeArgsForCall(2).GetLog().GetType()).To(Equal(loggregator_v2.Log_ERR))
+		})
+
+		It("falls back to the observed time and the default source ID", func() {
+			resource = nil
+
+			err := export(&logspb.LogRecord{ObservedTimeUnixNano: 5678})
+			Expect(err).ToNot(HaveOccurred())
+
+			e := sink.EmitEnvelopeArgsForCall(0)
+			Expect(e.SourceId).To(Equal("default-source"))
+			Expect(e.Timestamp).To(Equal(int64(5678)))
+		})
+
+		It("returns Unavailable if the sink fails", func() {
+			sink.EmitEnvelopeReturns(errors.New("some-error"))
+
+			err := export(&logspb.LogRecord{})
+			Expect(status.Code(err)).To(Equal(codes.Unavailable))
+		})
+	})
+
+	Describe("ExportMetrics", func() {
+		export := func(metrics ...*metricspb.Metric) *colmetricspb.ExportMetricsServiceResponse {
+			resp, err := receiver.ExportMetrics(context.Background(), &colmetricspb.ExportMetricsServiceRequest{
+				ResourceMetrics: []*metricspb.ResourceMetrics{{
+					Resource:     resource,
+					ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: metrics}},
+				}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		It("emits gauges", func() {
+			resp := export(&metricspb.Metric{
+				Name: "memory",
+				Unit: "By",
+				Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+					DataPoints: []*metricspb.NumberDataPoint{{
+						TimeUnixNano: 1234,
+						Attributes:   []*commonpb.KeyValue{stringAttr("pool", "heap")},
+						Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: 1.5},
+					}},
+				}},
+			})
+			Expect(resp.PartialSuccess).To(BeNil())
+
+			Expect(sink.EmitEnvelopeCallCount()).To(Equal(1))
+			e := sink.EmitEnvelopeArgsForCall(0)
+			Expect(e.SourceId).To(Equal("some-service"))
+			Expect(e.Timestamp).To(Equal(int64(1234)))
+			Expect(e.Tags).To(HaveKeyWithValue("pool", "heap"))
+			Expect(e.GetGauge().GetMetrics()).To(Equal(map[string]*loggregator_v2.GaugeValue{
+				"memory": {Value: 1.5, Unit: "By"},
+			}))
+		})
+
+		It("emits monotonic sums as counters", func() {
+			export(
+				&metricspb.Metric{
+					Name: "requests",
+					Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+						IsMonotonic:            true,
+						AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+						DataPoints: []*metricspb.NumberDataPoint{{
+							Value: &metricspb.NumberDataPoint_AsInt{AsInt: 42},
+						}},
+					}},
+				},
+				&metricspb.Metric{
+					Name: "errors",
+					Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+						IsMonotonic:            true,
+						AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+						DataPoints: []*metricspb.NumberDataPoint{{
+							Value: &metricspb.NumberDataPoint_AsInt{AsInt: 2},
+						}},
+					}},
+				},
+			)
+
+			Expect(sink.EmitEnvelopeCallCount()).To(Equal(2))
+			Expect(sink.EmitEnvelopeArgsForCall(0).GetCounter()).To(Equal(&loggregator_v2.Counter{Name: "requests", Total: 42}))
+			Expect(sink.EmitEnvelopeArgsForCall(1).GetCounter()).To(Equal(&loggregator_v2.Counter{Name: "errors", Delta: 2}))
+		})
+
+		It("emits non-monotonic sums as gauges", func() {
+			export(&metricspb.Metric{
+				Name: "queue_depth",
+				Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+					DataPoints: []*metricspb.NumberDataPoint{{
+						Value: &metricspb.NumberDataPoint_AsInt{AsInt: -3},
+					}},
+				}},
+			})
+
+			Expect(sink.EmitEnvelopeArgsForCall(0).GetGauge().GetMetrics()).To(HaveKeyWithValue("queue_depth", &loggregator_v2.GaugeValue{Value: -3}))
+		})
+
+		It("reports unsupported data points as rejected", func() {
+			resp := export(&metricspb.Metric{
+				Name: "latency",
+				Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+					DataPoints: []*metricspb.HistogramDataPoint{{}, {}},
+				}},
+			})
+
+			Expect(sink.EmitEnvelopeCallCount()).To(BeZero())
+			Expect(resp.PartialSuccess.RejectedDataPoints).To(Equal(int64(2)))
+			Expect(resp.PartialSuccess.ErrorMessage).To(ContainSubstring("histogram is not supported"))
+		})
+	})
+})
+
+func stringAttr(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   k,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}},
+	}
+}
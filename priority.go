@@ -0,0 +1,84 @@
+package loggregator
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+)
+
+// priorityTag is the tag that holds the priority of an envelope.
+const priorityTag = "loggregator_priority"
+
+// Priority is the importance of an envelope to its consumers. Together with
+// a deadline, see WithDeadline, it tells the pipeline which envelopes to
+// prefer and lets consumers break down late envelopes by importance.
+type Priority int
+
+// The priorities of envelopes. Envelopes without a priority tag have the
+// normal priority.
+const (
+	PriorityNormal Priority = iota
+	PriorityLow
+	PriorityHigh
+)
+
+// String returns the value of the priority tag for the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+}
+
+// WithPriority tags the envelope with the given priority.
+func WithPriority(p Priority) func(proto.Message) {
+	return WithEnvelopeTag(priorityTag, p.String())
+}
+
+// PriorityOf returns the priority the envelope is tagged with. Envelopes
+// without a priority tag or with an unknown priority have the normal
+// priority.
+func PriorityOf(e *loggregator_v2.Envelope) Priority {
+	switch e.GetTags()[priorityTag] {
+	case "low":
+		return PriorityLow
+	case "high":
+		return PriorityHigh
+	default:
+		return PriorityNormal
+	}
+}
+
+// IsHighPriority reports whether the envelope is tagged with the high
+// priority.
+func IsHighPriority(e *loggregator_v2.Envelope) bool {
+	return PriorityOf(e) == PriorityHigh
+}
+
+// WithPriorityEnvelopes configures a separate, preferred lane for envelopes
+// that match isPriority. Priority envelopes have their own buffer and are
+// always batched before bulk envelopes, so they are neither blocked nor
+// delayed when the client is congested with bulk envelopes. IsErrorLog,
+// IsCounter and IsHighPriority may be used as predicates.
+func WithPriorityEnvelopes(isPriority func(*loggregator_v2.Envelope) bool) IngressOption {
+	return func(c *IngressClient) {
+		c.isPriority = isPriority
+	}
+}
+
+// IsErrorLog reports whether the envelope is a log written to stderr.
+func IsErrorLog(e *loggregator_v2.Envelope) bool {
+	return e.GetLog() != nil && e.GetLog().GetType() == loggregator_v2.Log_ERR
+}
+
+// IsCounter reports whether the envelope is a counter.
+func IsCounter(e *loggregator_v2.Envelope) bool {
+	return e.GetCounter() != nil
+}
@@ -0,0 +1,251 @@
+// Package processemitter provides an emitter that reports resource usage of
+// the current process (CPU, resident memory, file descriptors, threads and
+// uptime) as gauges. It reads the proc filesystem and is therefore only
+// useful on Linux.
+package processemitter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/units"
+)
+
+// clockTicks is the number of clock ticks per second used by the kernel when
+// reporting CPU times in /proc. This is USER_HZ, which is 100 on every
+// mainstream Linux architecture.
+const clockTicks = 100
+
+// Sender is the interface of the client that can be used to emit gauge
+// metrics.
+type Sender interface {
+	EmitGauge(opts ...loggregator.EmitGaugeOption)
+}
+
+// Emitter will emit a gauge with process stats via the sender on the given
+// interval. The default interval is 10 seconds.
+type Emitter struct {
+	interval time.Duration
+	sender   Sender
+	procRoot string
+	pid      string
+	tags     map[string]string
+
+	lastCPUTicks uint64
+	lastSample   time.Time
+}
+
+// ProcessEmitterOption is the option that provides configuration for an
+// Emitter.
+type ProcessEmitterOption func(e *Emitter)
+
+// WithInterval returns a ProcessEmitterOption to configure the interval at
+// which the process emitter emits gauges.
+func WithInterval(d time.Duration) ProcessEmitterOption {
+	return func(e *Emitter) {
+		e.interval = d
+	}
+}
+
+// WithTags returns a ProcessEmitterOption that adds the given tags to every
+// gauge emitted.
+func WithTags(tags map[string]string) ProcessEmitterOption {
+	return func(e *Emitter) {
+		for k, v := range tags {
+			e.tags[k] = v
+		}
+	}
+}
+
+// WithProcRoot returns a ProcessEmitterOption to configure where the proc
+// filesystem is mounted. It defaults to /proc.
+func WithProcRoot(path string) ProcessEmitterOption {
+	return func(e *Emitter) {
+		e.procRoot = path
+	}
+}
+
+// New returns an Emitter that is configured with the given sender and
+// ProcessEmitterOptions.
+func New(sender Sender, opts ...ProcessEmitterOption) *Emitter {
+	e := &Emitter{
+		sender:   sender,
+		interval: 10 * time.Second,
+		procRoot: "/proc",
+		pid:      strconv.Itoa(os.Getpid()),
+		tags:     make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+// Run starts the ticker with the configured interval and emits a gauge on
+// that interval. This method will block but the user may run in a go routine.
+// If the proc filesystem can not be read, nothing is emitted for that
+// interval.
+func (e *Emitter) Run() {
+	for range time.Tick(e.interval) {
+		e.emit(time.Now())
+	}
+}
+
+func (e *Emitter) emit(now time.Time) {
+	s, err := readStats(e.procRoot, e.pid)
+	if err != nil {
+		return
+	}
+
+	e.sender.EmitGauge(
+		loggregator.WithGaugeValue("processStats.cpuPercentage", e.cpuPercentage(s, now), units.Percentage),
+		loggregator.WithGaugeValue("processStats.residentMemoryBytes", float64(s.rssBytes), units.Bytes),
+		loggregator.WithGaugeValue("processStats.numFileDescriptors", float64(s.fds), units.Count),
+		loggregator.WithGaugeValue("processStats.numThreads", float64(s.threads), units.Count),
+		loggregator.WithGaugeValue("processStats.uptime", s.uptime.Seconds(), units.Seconds),
+		loggregator.WithEnvelopeTags(e.tags),
+	)
+}
+
+// cpuPercentage computes the CPU usage since the previous sample. The first
+// sample reports the average usage since the process started.
+func (e *Emitter) cpuPercentage(s stats, now time.Time) float64 {
+	ticks := s.cpuTicks - e.lastCPUTicks
+	elapsed := now.Sub(e.lastSample)
+	if e.lastSample.IsZero() {
+		elapsed = s.uptime
+	}
+
+	e.lastCPUTicks = s.cpuTicks
+	e.lastSample = now
+
+	if elapsed <= 0 {
+		return 0
+	}
+
+	cpu := time.Duration(ticks) * time.Second / clockTicks
+	return 100 * cpu.Seconds() / elapsed.Seconds()
+}
+
+type stats struct {
+	cpuTicks uint64
+	rssBytes uint64
+	fds      int
+	threads  uint64
+	uptime   time.Duration
+}
+
+func readStats(procRoot, pid string) (stats, error) {
+	var s stats
+
+	stat, err := ioutil.ReadFile(filepath.Join(procRoot, pid, "stat"))
+	if err != nil {
+		return s, err
+	}
+
+	// The second field is the executable name in parentheses and may
+	// contain spaces, so fields are counted from the closing parenthesis.
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return s, errors.New("malformed stat file")
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 20 {
+		return s, errors.New("malformed stat file")
+	}
+
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return s, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return s, err
+	}
+	s.threads, err = strconv.ParseUint(fields[17], 10, 64)
+	if err != nil {
+		return s, err
+	}
+	startTicks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return s, err
+	}
+	s.cpuTicks = utime + stime
+
+	systemUptime, err := readSystemUptime(procRoot)
+	if err != nil {
+		return s, err
+	}
+	s.uptime = systemUptime - time.Duration(startTicks)*time.Second/clockTicks
+
+	s.rssBytes, err = readRSS(filepath.Join(procRoot, pid, "status"))
+	if err != nil {
+		return s, err
+	}
+
+	fds, err := ioutil.ReadDir(filepath.Join(procRoot, pid, "fd"))
+	if err != nil {
+		return s, err
+	}
+	s.fds = len(fds)
+
+	return s, nil
+}
+
+func readSystemUptime(procRoot string) (time.Duration, error) {
+	data, err := ioutil.ReadFile(filepath.Join(procRoot, "uptime"))
+	if err != nil {
+		return 0, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 1 {
+		return 0, errors.New("malformed uptime file")
+	}
+
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func readRSS(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "VmRSS:" {
+			continue
+		}
+
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		return kb * 1024, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, errors.New("VmRSS not found in status file")
+}
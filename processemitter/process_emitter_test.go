@@ -0,0 +1,140 @@
+package processemitter_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/processemitter"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/units"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProcessEmitter", func() {
+	var (
+		procRoot string
+		spy      *spyV2Client
+	)
+
+	BeforeEach(func() {
+		var err error
+		procRoot, err = ioutil.TempDir("", "proc")
+		Expect(err).ToNot(HaveOccurred())
+
+		pidDir := filepath.Join(procRoot, strconv.Itoa(os.Getpid()))
+		Expect(os.MkdirAll(filepath.Join(pidDir, "fd"), 0755)).To(Succeed())
+		for _, fd := range []string{"0", "1", "2"} {
+			writeFile(filepath.Join(pidDir, "fd", fd), "")
+		}
+
+		writeFile(
+			filepath.Join(pidDir, "stat"),
+			"1234 (some (odd) name) S 1 1234 1234 0 -1 4194560 100 0 0 0 150 50 0 0 20 0 7 0 1000 0 0",
+		)
+		writeFile(
+			filepath.Join(pidDir, "status"),
+			"Name:\tsome\nVmRSS:\t    2048 kB\nThreads:\t7\n",
+		)
+		writeFile(filepath.Join(procRoot, "uptime"), "30.00 60.00\n")
+
+		spy = newSpyV2Client()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(procRoot)
+	})
+
+	It("emits process metrics on an interval", func() {
+		emitter := processemitter.New(spy,
+			processemitter.WithInterval(10*time.Millisecond),
+			processemitter.WithProcRoot(procRoot),
+		)
+
+		go emitter.Run()
+
+		Eventually(spy.envelopes).Should(Receive())
+		Eventually(spy.envelopes).Should(Receive())
+	})
+
+	It("emits the expected process metrics", func() {
+		emitter := processemitter.New(spy,
+			processemitter.WithInterval(10*time.Millisecond),
+			processemitter.WithProcRoot(procRoot),
+			processemitter.WithTags(map[string]string{"job": "some-job"}),
+		)
+
+		go emitter.Run()
+
+		var env *loggregator_v2.Envelope
+		Eventually(spy.envelopes).Should(Receive(&env))
+		Expect(env.GetTags()).To(HaveKeyWithValue("job", "some-job"))
+
+		metrics := env.GetGauge().GetMetrics()
+		Expect(metrics["processStats.residentMemoryBytes"].Value).To(Equal(2048.0 * 1024))
+		Expect(metrics["processStats.residentMemoryBytes"].Unit).To(Equal(units.Bytes))
+
+		Expect(metrics["processStats.numFileDescriptors"].Value).To(Equal(3.0))
+		Expect(metrics["processStats.numFileDescriptors"].Unit).To(Equal(units.Count))
+
+		Expect(metrics["processStats.numThreads"].Value).To(Equal(7.0))
+		Expect(metrics["processStats.numThreads"].Unit).To(Equal(units.Count))
+
+		// The process started 10s after boot and the system has been up
+		// for 30s.
+		Expect(metrics["processStats.uptime"].Value).To(BeNumerically("~", 20.0, 0.001))
+		Expect(metrics["processStats.uptime"].Unit).To(Equal(units.Seconds))
+
+		// 200 ticks of CPU (2s) over 20s of uptime.
+		Expect(metrics["processStats.cpuPercentage"].Value).To(BeNumerically("~", 10.0, 0.001))
+		Expect(metrics["processStats.cpuPercentage"].Unit).To(Equal(units.Percentage))
+	})
+
+	It("does not emit when the proc filesystem is unavailable", func() {
+		emitter := processemitter.New(spy,
+			processemitter.WithInterval(10*time.Millisecond),
+			processemitter.WithProcRoot(filepath.Join(procRoot, "missing")),
+		)
+
+		go emitter.Run()
+
+		Consistently(spy.envelopes).Should(BeEmpty())
+	})
+})
+
+func writeFile(path, contents string) {
+	err := ioutil.WriteFile(path, []byte(contents), 0644)
+	Expect(err).ToNot(HaveOccurred())
+}
+
+type spyV2Client struct {
+	envelopes chan *loggregator_v2.Envelope
+}
+
+func newSpyV2Client() *spyV2Client {
+	return &spyV2Client{
+		envelopes: make(chan *loggregator_v2.Envelope, 100),
+	}
+}
+
+func (s *spyV2Client) EmitGauge(opts ...loggregator.EmitGaugeOption) {
+	env := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: make(map[string]*loggregator_v2.GaugeValue),
+			},
+		},
+		Tags: make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(env)
+	}
+
+	s.envelopes <- env
+}
@@ -0,0 +1,13 @@
+package processemitter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestProcessemitter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Process Emitter Suite")
+}
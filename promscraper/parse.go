@@ -0,0 +1,168 @@
+package promscraper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// metricType is the type of a metric family as declared by a TYPE line.
+type metricType string
+
+const (
+	counterType   metricType = "counter"
+	gaugeType     metricType = "gauge"
+	histogramType metricType = "histogram"
+	summaryType   metricType = "summary"
+	untypedType   metricType = "untyped"
+)
+
+// sample is a line of the Prometheus text exposition format.
+type sample struct {
+	name   string
+	labels map[string]string
+	value  float64
+	typ    metricType
+
+	// timestamp is in milliseconds since the epoch. It is zero if the
+	// sample has none.
+	timestamp int64
+}
+
+// parse reads samples in the Prometheus text exposition format, see
+// https://prometheus.io/docs/instrumenting/exposition_formats/.
+func parse(r io.Reader) ([]sample, error) {
+	var samples []sample
+	types := make(map[string]metricType)
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(line)
+			if len(fields) >= 4 && fields[1] == "TYPE" {
+				types[fields[2]] = metricType(fields[3])
+			}
+			continue
+		}
+
+		smp, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		smp.typ = typeOf(smp.name, types)
+		samples = append(samples, smp)
+	}
+
+	return samples, s.Err()
+}
+
+// typeOf returns the type of the family of the sample. The samples of
+// histograms and summaries carry suffixes.
+func typeOf(name string, types map[string]metricType) metricType {
+	if t, ok := types[name]; ok {
+		return t
+	}
+
+	for _, suffix := range []string{"_bucket", "_count", "_sum"} {
+		if t, ok := types[strings.TrimSuffix(name, suffix)]; ok && (t == histogramType || t == summaryType) {
+			return t
+		}
+	}
+
+	return untypedType
+}
+
+func parseSample(line string) (sample, error) {
+	smp := sample{labels: make(map[string]string)}
+
+	i := strings.IndexAny(line, "{ \t")
+	if i <= 0 {
+		return smp, fmt.Errorf("malformed sample %q", line)
+	}
+	smp.name = line[:i]
+	rest := line[i:]
+
+	if rest[0] == '{' {
+		var err error
+		rest, err = parseLabels(rest[1:], smp.labels)
+		if err != nil {
+			return smp, err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return smp, fmt.Errorf("malformed sample %q", line)
+	}
+
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return smp, fmt.Errorf("invalid value %q", fields[0])
+	}
+	smp.value = v
+
+	if len(fields) == 2 {
+		smp.timestamp, err = strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return smp, fmt.Errorf("invalid timestamp %q", fields[1])
+		}
+	}
+
+	return smp, nil
+}
+
+// parseLabels reads `name="value",...}` into labels and returns what
+// follows the closing brace.
+func parseLabels(s string, labels map[string]string) (string, error) {
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return "", fmt.Errorf("unterminated labels")
+		}
+		if s[0] == '}' {
+			return s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 || len(s) < eq+2 || s[eq+1] != '"' {
+			return "", fmt.Errorf("malformed label %q", s)
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = s[eq+2:]
+
+		var value strings.Builder
+		closed := false
+		for i := 0; i < len(s); i++ {
+			c := s[i]
+			if c == '"' {
+				s = s[i+1:]
+				closed = true
+				break
+			}
+			if c == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(c)
+		}
+		if !closed {
+			return "", fmt.Errorf("unterminated value of label %q", name)
+		}
+
+		labels[name] = value.String()
+	}
+}
@@ -0,0 +1,101 @@
+package promscraper
+
+import (
+	"regexp"
+	"strings"
+)
+
+// nameLabel is the label that holds the metric name during relabeling.
+const nameLabel = "__name__"
+
+// Action is what a RelabelRule does with the labels of a sample.
+type Action string
+
+// The actions of relabeling rules. They behave like their namesakes in the
+// metric_relabel_configs of Prometheus.
+const (
+	// Keep drops samples whose source label does not match.
+	Keep Action = "keep"
+	// Drop drops samples whose source label matches.
+	Drop Action = "drop"
+	// Replace sets the target label to the expanded replacement if the
+	// source label matches.
+	Replace Action = "replace"
+	// LabelDrop removes the labels whose names match.
+	LabelDrop Action = "labeldrop"
+)
+
+// RelabelRule rewrites the labels of the samples of a scrape. The metric
+// name can be read and written as the label __name__. Labels that start
+// with "__" are removed once all rules are applied.
+type RelabelRule struct {
+	Action Action
+
+	// SourceLabel is the label the regex is matched against. It is not
+	// used by LabelDrop.
+	SourceLabel string
+
+	// Regex must match the complete value of the source label, or the
+	// complete label name for LabelDrop.
+	Regex *regexp.Regexp
+
+	// TargetLabel and Replacement are used by Replace. The replacement may
+	// refer to the groups of the regex, e.g. "${1}".
+	TargetLabel string
+	Replacement string
+}
+
+// apply applies the rule to labels. It returns false if the sample is
+// dropped.
+func (r RelabelRule) apply(labels map[string]string) bool {
+	switch r.Action {
+	case Keep:
+		return r.Regex.MatchString(labels[r.SourceLabel])
+	case Drop:
+		return !r.Regex.MatchString(labels[r.SourceLabel])
+	case Replace:
+		v := labels[r.SourceLabel]
+		if m := r.Regex.FindStringSubmatchIndex(v); m != nil {
+			labels[r.TargetLabel] = string(r.Regex.ExpandString(nil, r.Replacement, v, m))
+		}
+	case LabelDrop:
+		for name := range labels {
+			if name != nameLabel && r.Regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	}
+
+	return true
+}
+
+// anchor returns a copy of the rule whose regex only matches complete
+// values.
+func (r RelabelRule) anchor() RelabelRule {
+	r.Regex = regexp.MustCompile("^(?:" + r.Regex.String() + ")$")
+	return r
+}
+
+// relabel applies the rules to the labels of smp. It returns false if the
+// sample is dropped.
+func relabel(smp *sample, rules []RelabelRule) bool {
+	if len(rules) == 0 {
+		return true
+	}
+
+	smp.labels[nameLabel] = smp.name
+	for _, r := range rules {
+		if !r.apply(smp.labels) {
+			return false
+		}
+	}
+
+	smp.name = smp.labels[nameLabel]
+	for name := range smp.labels {
+		if strings.HasPrefix(name, "__") {
+			delete(smp.labels, name)
+		}
+	}
+
+	return smp.name != ""
+}
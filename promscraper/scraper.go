@@ -0,0 +1,271 @@
+// Package promscraper scrapes Prometheus endpoints and forwards their
+// samples as v2 envelopes, so that components that only expose Prometheus
+// metrics can be observed through Loggregator.
+//
+// Counters and the buckets and counts of histograms and summaries are
+// emitted as counters with their total, all other samples as gauges. The
+// labels of a sample become tags and can be rewritten with relabeling rules.
+//
+// Samples that carry a timestamp are only forwarded when the timestamp
+// changed since the previous scrape and is younger than the staleness
+// period. This keeps metrics that an exporter no longer updates, e.g. those
+// of a Pushgateway, from being forwarded forever.
+package promscraper
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+)
+
+// Sender is the interface of the client that can be used to emit gauges and
+// counters.
+type Sender interface {
+	EmitGauge(opts ...loggregator.EmitGaugeOption)
+	EmitCounter(name string, opts ...loggregator.EmitCounterOption)
+}
+
+// Target is an endpoint that is scraped.
+type Target struct {
+	// URL is the URL of the metrics endpoint, e.g.
+	// "http://localhost:9100/metrics".
+	URL string
+
+	// SourceID and InstanceID are the source ID and instance ID of the
+	// envelopes.
+	SourceID   string
+	InstanceID string
+
+	// Tags are added to every envelope. They take precedence over labels
+	// with the same name.
+	Tags map[string]string
+}
+
+// ScraperOption is the option that provides configuration for a Scraper.
+type ScraperOption func(*Scraper)
+
+// WithInterval returns a ScraperOption to configure the interval at which
+// the targets are scraped. It defaults to 15 seconds.
+func WithInterval(d time.Duration) ScraperOption {
+	return func(s *Scraper) {
+		s.interval = d
+	}
+}
+
+// WithHTTPClient returns a ScraperOption to configure the client used to
+// scrape, e.g. to configure TLS. It defaults to a client whose timeout is
+// the interval.
+func WithHTTPClient(c *http.Client) ScraperOption {
+	return func(s *Scraper) {
+		s.client = c
+	}
+}
+
+// WithRelabelRules returns a ScraperOption that applies the rules in order
+// to every sample.
+func WithRelabelRules(rules ...RelabelRule) ScraperOption {
+	return func(s *Scraper) {
+		s.rules = append(s.rules, rules...)
+	}
+}
+
+// WithStalenessPeriod returns a ScraperOption to configure the age after
+// which samples with a timestamp are no longer forwarded. It defaults to 5
+// minutes.
+func WithStalenessPeriod(d time.Duration) ScraperOption {
+	return func(s *Scraper) {
+		s.stalenessPeriod = d
+	}
+}
+
+// WithLogger returns a ScraperOption to configure the logger for failed
+// scrapes. It defaults to discarding them.
+func WithLogger(l loggregator.Logger) ScraperOption {
+	return func(s *Scraper) {
+		s.logger = l
+	}
+}
+
+// Scraper scrapes its targets on an interval and emits their samples via
+// the sender. It should be created with the New constructor.
+type Scraper struct {
+	sender          Sender
+	targets         []Target
+	interval        time.Duration
+	client          *http.Client
+	rules           []RelabelRule
+	stalenessPeriod time.Duration
+	logger          loggregator.Logger
+
+	// timestamps holds the timestamps of the samples of the previous
+	// scrape of every target by series.
+	timestamps []map[string]int64
+}
+
+// New returns a Scraper for the given targets.
+func New(sender Sender, targets []Target, opts ...ScraperOption) *Scraper {
+	s := &Scraper{
+		sender:          sender,
+		targets:         targets,
+		interval:        15 * time.Second,
+		stalenessPeriod: 5 * time.Minute,
+		logger:          log.New(ioutil.Discard, "", 0),
+		timestamps:      make([]map[string]int64, len(targets)),
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	if s.client == nil {
+		s.client = &http.Client{Timeout: s.interval}
+	}
+
+	for i, r := range s.rules {
+		s.rules[i] = r.anchor()
+	}
+
+	return s
+}
+
+// Run scrapes all targets immediately and then on the configured interval
+// until the context is done.
+func (s *Scraper) Run(ctx context.Context) {
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+
+	for {
+		s.Scrape(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Scrape scrapes all targets once. Failed scrapes are logged. It must not
+// be called concurrently with Run.
+func (s *Scraper) Scrape(ctx context.Context) {
+	for i, t := range s.targets {
+		samples, err := s.fetch(ctx, t.URL)
+		if err != nil {
+			s.logger.Printf("failed to scrape %s: %s", t.URL, err)
+			s.timestamps[i] = nil
+			continue
+		}
+
+		s.timestamps[i] = s.emit(t, samples, s.timestamps[i], time.Now())
+	}
+}
+
+func (s *Scraper) fetch(ctx context.Context, url string) ([]sample, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return parse(resp.Body)
+}
+
+// emit emits the samples of a scrape. It returns the timestamps of the
+// samples by series to compare the next scrape against.
+func (s *Scraper) emit(t Target, samples []sample, previous map[string]int64, now time.Time) map[string]int64 {
+	timestamps := make(map[string]int64)
+	stale := now.Add(-s.stalenessPeriod).UnixNano() / int64(time.Millisecond)
+
+	for _, smp := range samples {
+		if !relabel(&smp, s.rules) {
+			continue
+		}
+
+		if smp.timestamp != 0 {
+			key := seriesKey(smp)
+			timestamps[key] = smp.timestamp
+
+			if ts, ok := previous[key]; (ok && ts == smp.timestamp) || smp.timestamp < stale {
+				continue
+			}
+		}
+
+		tags := make(map[string]string, len(smp.labels)+len(t.Tags))
+		for k, v := range smp.labels {
+			tags[k] = v
+		}
+		for k, v := range t.Tags {
+			tags[k] = v
+		}
+
+		if isCounter(smp) {
+			if smp.value < 0 || math.IsNaN(smp.value) || math.IsInf(smp.value, 0) {
+				continue
+			}
+			s.sender.EmitCounter(
+				smp.name,
+				loggregator.WithTotal(uint64(smp.value)),
+				loggregator.WithCounterSourceInfo(t.SourceID, t.InstanceID),
+				loggregator.WithEnvelopeTags(tags),
+			)
+			continue
+		}
+
+		if math.IsNaN(smp.value) {
+			continue
+		}
+		s.sender.EmitGauge(
+			loggregator.WithGaugeValue(smp.name, smp.value, ""),
+			loggregator.WithGaugeSourceInfo(t.SourceID, t.InstanceID),
+			loggregator.WithEnvelopeTags(tags),
+		)
+	}
+
+	return timestamps
+}
+
+func isCounter(smp sample) bool {
+	switch smp.typ {
+	case counterType:
+		return true
+	case histogramType, summaryType:
+		return strings.HasSuffix(smp.name, "_bucket") || strings.HasSuffix(smp.name, "_count")
+	default:
+		return false
+	}
+}
+
+// seriesKey identifies a series by its name and labels.
+func seriesKey(smp sample) string {
+	names := make([]string, 0, len(smp.labels))
+	for name := range smp.labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(smp.name)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\xff%s=%s", name, smp.labels[name])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,222 @@
+package promscraper_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/promscraper"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scraper", func() {
+	var (
+		sender  *spySender
+		server  *httptest.Server
+		mu      sync.Mutex
+		body    string
+		targets []promscraper.Target
+	)
+
+	setBody := func(b string) {
+		mu.Lock()
+		defer mu.Unlock()
+		body = b
+	}
+
+	BeforeEach(func() {
+		sender = &spySender{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Fprint(w, body)
+		}))
+		targets = []promscraper.Target{{
+			URL:        server.URL,
+			SourceID:   "some-source",
+			InstanceID: "1",
+			Tags:       map[string]string{"job": "node"},
+		}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("emits counters and gauges with labels as tags", func() {
+		setBody(`# HELP requests_total The number of requests.
+# TYPE requests_total counter
+requests_total{method="GET",path="/a \"b\""} 42
+# TYPE temperature gauge
+temperature -3.5
+# TYPE latency histogram
+latency_bucket{le="+Inf"} 7
+latency_sum 1.25
+latency_count 7
+`)
+
+		promscraper.New(sender, targets).Scrape(context.Background())
+
+		Expect(sender.envelopes).To(HaveLen(5))
+		Expect(sender.envelopes[0]).To(Equal(&loggregator_v2.Envelope{
+			SourceId:   "some-source",
+			InstanceId: "1",
+			Tags:       map[string]string{"job": "node", "method": "GET", "path": `/a "b"`},
+			Message: &loggregator_v2.Envelope_Counter{
+				Counter: &loggregator_v2.Counter{Name: "requests_total", Total: 42},
+			},
+		}))
+		Expect(sender.envelopes[1].GetGauge().GetMetrics()).To(HaveKeyWithValue("temperature", &loggregator_v2.GaugeValue{Value: -3.5}))
+		Expect(sender.envelopes[2].GetCounter()).To(Equal(&loggregator_v2.Counter{Name: "latency_bucket", Total: 7}))
+		Expect(sender.envelopes[2].Tags).To(HaveKeyWithValue("le", "+Inf"))
+		Expect(sender.envelopes[3].GetGauge().GetMetrics()).To(HaveKey("latency_sum"))
+		Expect(sender.envelopes[4].GetCounter()).To(Equal(&loggregator_v2.Counter{Name: "latency_count", Total: 7}))
+	})
+
+	It("lets target tags take precedence over labels", func() {
+		setBody(`up{job="other"} 1`)
+
+		promscraper.New(sender, targets).Scrape(context.Background())
+
+		Expect(sender.envelopes).To(HaveLen(1))
+		Expect(sender.envelopes[0].Tags).To(HaveKeyWithValue("job", "node"))
+	})
+
+	It("applies relabeling rules", func() {
+		setBody(`go_goroutines 10
+http_requests{code="200",instance="a"} 1
+http_requests{code="500",instance="a"} 2
+`)
+
+		promscraper.New(sender, targets, promscraper.WithRelabelRules(
+			promscraper.RelabelRule{Action: promscraper.Drop, SourceLabel: "__name__", Regex: regexp.MustCompile("go_.*")},
+			promscraper.RelabelRule{Action: promscraper.Keep, SourceLabel: "code", Regex: regexp.MustCompile("5..")},
+			promscraper.RelabelRule{Action: promscraper.Replace, SourceLabel: "code", Regex: regexp.MustCompile("(.).."), TargetLabel: "class", Replacement: "${1}xx"},
+			promscraper.RelabelRule{Action: promscraper.Replace, SourceLabel: "__name__", Regex: regexp.MustCompile("http_(.*)"), TargetLabel: "__name__", Replacement: "$1"},
+			promscraper.RelabelRule{Action: promscraper.LabelDrop, Regex: regexp.MustCompile("instance")},
+		)).Scrape(context.Background())
+
+		Expect(sender.envelopes).To(HaveLen(1))
+		Expect(sender.envelopes[0].GetGauge().GetMetrics()).To(HaveKey("requests"))
+		Expect(sender.envelopes[0].Tags).To(Equal(map[string]string{"job": "node", "code": "500", "class": "5xx"}))
+	})
+
+	It("only forwards samples whose timestamp changed and is not stale", func() {
+		now := time.Now().UnixNano() / int64(time.Millisecond)
+		stale := time.Now().Add(-time.Hour).UnixNano() / int64(time.Millisecond)
+		s := promscraper.New(sender, targets, promscraper.WithStalenessPeriod(time.Minute))
+
+		setBody(fmt.Sprintf("pushed %d %d\nold 1 %d\nlive 1\n", now, now, stale))
+		s.Scrape(context.Background())
+		Expect(names(sender.envelopes)).To(ConsistOf("pushed", "live"))
+
+		sender.envelopes = nil
+		s.Scrape(context.Background())
+		Expect(names(sender.envelopes)).To(ConsistOf("live"))
+
+		sender.envelopes = nil
+		setBody(fmt.Sprintf("pushed 2 %d\n", now+1))
+		s.Scrape(context.Background())
+		Expect(names(sender.envelopes)).To(ConsistOf("pushed"))
+	})
+
+	It("logs failed scrapes and emits nothing", func() {
+		setBody("malformed{")
+		logger := &spyLogger{}
+
+		promscraper.New(sender, targets, promscraper.WithLogger(logger)).Scrape(context.Background())
+
+		Expect(sender.envelopes).To(BeEmpty())
+		Expect(logger.messages).To(ConsistOf(ContainSubstring("failed to scrape " + server.URL)))
+	})
+
+	It("scrapes on the interval until the context is done", func() {
+		setBody("up 1")
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		s := promscraper.New(sender, targets, promscraper.WithInterval(10*time.Millisecond))
+
+		go func() {
+			s.Run(ctx)
+			close(done)
+		}()
+
+		Eventually(sender.count).Should(BeNumerically(">=", 2))
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
+
+func names(envs []*loggregator_v2.Envelope) []string {
+	var n []string
+	for _, e := range envs {
+		for name := range e.GetGauge().GetMetrics() {
+			n = append(n, name)
+		}
+		if e.GetCounter() != nil {
+			n = append(n, e.GetCounter().GetName())
+		}
+	}
+	return n
+}
+
+type spySender struct {
+	mu        sync.Mutex
+	envelopes []*loggregator_v2.Envelope
+}
+
+func (s *spySender) EmitGauge(opts ...loggregator.EmitGaugeOption) {
+	e := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: make(map[string]*loggregator_v2.GaugeValue),
+			},
+		},
+		Tags: make(map[string]string),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	s.add(e)
+}
+
+func (s *spySender) EmitCounter(name string, opts ...loggregator.EmitCounterOption) {
+	e := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: name},
+		},
+		Tags: make(map[string]string),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	s.add(e)
+}
+
+func (s *spySender) add(e *loggregator_v2.Envelope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.envelopes = append(s.envelopes, e)
+}
+
+func (s *spySender) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.envelopes)
+}
+
+type spyLogger struct {
+	messages []string
+}
+
+func (l *spyLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,113 @@
+package loggregator
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+)
+
+// WithProxy configures the client to connect to the agent through the proxy
+// at the given URL. The URL's scheme selects the protocol: "http" for an
+// HTTP CONNECT proxy and "socks5" for a SOCKS5 proxy. Credentials in the URL
+// are used to authenticate with the proxy. NewIngressClient returns an error
+// if the URL is invalid or its scheme is not supported.
+func WithProxy(proxyURL string) IngressOption {
+	return func(c *IngressClient) {
+		c.proxyURL = proxyURL
+	}
+}
+
+// proxyDialOption returns the dial option that connects through the proxy
+// at the given URL.
+func proxyDialOption(proxyURL string) (grpc.DialOption, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %s", err)
+	}
+
+	switch u.Scheme {
+	case "http":
+		return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialHTTPConnect(ctx, u, addr)
+		}), nil
+	case "socks5":
+		d, err := proxy.FromURL(u, proxy.Direct)
+		if err != nil {
+			return nil, err
+		}
+
+		return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			if cd, ok := d.(proxy.ContextDialer); ok {
+				return cd.DialContext(ctx, "tcp", addr)
+			}
+			return d.Dial("tcp", addr)
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %q", u.Scheme)
+	}
+}
+
+// dialHTTPConnect opens a tunnel to addr through the HTTP proxy at u.
+func dialHTTPConnect(ctx context.Context, u *url.URL, addr string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused connection to %s: %s", addr, resp.Status)
+	}
+
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+// bufferedConn reads the data that was buffered while reading the proxy's
+// response before reading from the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
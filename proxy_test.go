@@ -0,0 +1,146 @@
+package loggregator_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithProxy", func() {
+	var server *testIngressServer
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("connects through an HTTP CONNECT proxy", func() {
+		p := newConnectProxy()
+		defer p.close()
+
+		client, _, _ := buildIngressClient(
+			server.addr,
+			10*time.Millisecond,
+			false,
+			loggregator.WithProxy("http://user:secret@"+p.addr()),
+		)
+
+		client.EmitLog("message")
+
+		env, err := getEnvelopeAt(server.receivers, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env.GetLog().Payload).To(Equal([]byte("message")))
+		Expect(p.requests()).To(ContainElement(server.addr))
+		Expect(p.authorization()).To(Equal("Basic dXNlcjpzZWNyZXQ="))
+	})
+
+	It("returns an error for unsupported proxy schemes", func() {
+		tlsConfig, err := loggregator.NewIngressTLSConfig(
+			fixture("CA.crt"),
+			fixture("client.crt"),
+			fixture("client.key"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = loggregator.NewIngressClient(
+			tlsConfig,
+			loggregator.WithAddr(server.addr),
+			loggregator.WithProxy("ftp://proxy.example.com"),
+		)
+		Expect(err).To(MatchError(ContainSubstring("unsupported proxy scheme")))
+	})
+})
+
+type connectProxy struct {
+	lis net.Listener
+
+	mu      sync.Mutex
+	targets []string
+	auth    string
+}
+
+func newConnectProxy() *connectProxy {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+
+	p := &connectProxy{lis: lis}
+	go p.serve()
+
+	return p
+}
+
+func (p *connectProxy) serve() {
+	for {
+		conn, err := p.lis.Accept()
+		if err != nil {
+			return
+		}
+
+		go p.handle(conn)
+	}
+}
+
+func (p *connectProxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	br := bufio.NewReader(conn)
+	req, err := http.ReadRequest(br)
+	if err != nil || req.Method != http.MethodConnect {
+		return
+	}
+
+	p.mu.Lock()
+	p.targets = append(p.targets, req.Host)
+	p.auth = req.Header.Get("Proxy-Authorization")
+	p.mu.Unlock()
+
+	target, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+		return
+	}
+	defer target.Close()
+
+	conn.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+
+	go io.Copy(target, br)
+	io.Copy(conn, target)
+}
+
+func (p *connectProxy) addr() string {
+	return p.lis.Addr().String()
+}
+
+func (p *connectProxy) requests() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.targets...)
+}
+
+func (p *connectProxy) authorization() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.auth
+}
+
+func (p *connectProxy) close() {
+	p.lis.Close()
+}
@@ -0,0 +1,48 @@
+package loggregator
+
+import "sync"
+
+// sendStatus records the outcome of the most recent attempt to send to the
+// agent. It is safe for concurrent use.
+type sendStatus struct {
+	mu   sync.RWMutex
+	sent bool
+	err  error
+}
+
+func (s *sendStatus) observe(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+	if err == nil {
+		s.sent = true
+	}
+}
+
+func (s *sendStatus) get() (sent bool, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sent, s.err
+}
+
+// Ready reports whether telemetry is flowing, i.e. the client has not been
+// closed and the most recent attempt to send to the agent succeeded. A
+// client is not ready until it has sent its first batch or event.
+func (c *IngressClient) Ready() bool {
+	if c.isClosed() {
+		return false
+	}
+
+	sent, err := c.status.get()
+	return sent && err == nil
+}
+
+// LastError returns the error of the most recent attempt to connect or send
+// to the agent. It returns nil if the attempt succeeded or no attempt has
+// been made yet.
+func (c *IngressClient) LastError() error {
+	_, err := c.status.get()
+	return err
+}
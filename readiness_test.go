@@ -0,0 +1,90 @@
+package loggregator_test
+
+import (
+	"net"
+	"time"
+
+	"golang.org/x/net/context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Readiness", func() {
+	var server *testIngressServer
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("is ready once a batch was sent", func() {
+		client, _, _ := buildIngressClient(server.addr, 10*time.Millisecond, false)
+		Expect(client.Ready()).To(BeFalse())
+		Expect(client.LastError()).ToNot(HaveOccurred())
+
+		client.EmitLog("message")
+		receiveEnvelopes(server.receivers, 1)
+
+		Eventually(client.Ready).Should(BeTrue())
+		Expect(client.LastError()).ToNot(HaveOccurred())
+	})
+
+	It("is not ready after CloseSend", func() {
+		client, _, _ := buildIngressClient(server.addr, 10*time.Millisecond, false)
+		client.EmitLog("message")
+		receiveEnvelopes(server.receivers, 1)
+		Eventually(client.Ready).Should(BeTrue())
+
+		Expect(client.CloseSend()).To(Succeed())
+		Expect(client.Ready()).To(BeFalse())
+	})
+
+	It("reports the error of the last failed send", func() {
+		client, _, _ := buildIngressClient(server.addr, 10*time.Millisecond, false)
+		client.EmitLog("message")
+		receiveEnvelopes(server.receivers, 1)
+		Eventually(client.Ready).Should(BeTrue())
+
+		server.stop()
+		Eventually(func() error {
+			client.EmitLog("message")
+			return client.LastError()
+		}).Should(HaveOccurred())
+		Expect(client.Ready()).To(BeFalse())
+	})
+
+	It("reports a failure to connect", func() {
+		lis, err := net.Listen("tcp4", "localhost:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := lis.Addr().String()
+		lis.Close()
+
+		client, _, _ := buildIngressClient(addr, 10*time.Millisecond, false)
+
+		Eventually(func() error {
+			client.EmitLog("message")
+			return client.LastError()
+		}).Should(HaveOccurred())
+		Expect(client.Ready()).To(BeFalse())
+	})
+
+	It("reports the result of emitting events", func() {
+		client, _, _ := buildIngressClient(server.addr, time.Hour, false)
+
+		Eventually(func() error {
+			return client.EmitEvent(context.Background(), "title", "body")
+		}).Should(Succeed())
+		Expect(client.Ready()).To(BeTrue())
+	})
+})
@@ -0,0 +1,294 @@
+// Package recorder captures envelopes into a bounded ring of files on disk,
+// similar to the ring buffer of a packet capture. It lets operators keep the
+// last minutes of a stream, e.g. the firehose, and snapshot them around an
+// incident.
+package recorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+)
+
+const (
+	segmentPrefix = "segment-"
+	segmentSuffix = ".env"
+)
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithSegmentDuration configures how long envelopes are written to a
+// segment file before the next segment is started. It defaults to a
+// minute.
+func WithSegmentDuration(d time.Duration) RecorderOption {
+	return func(r *Recorder) {
+		r.segmentDuration = d
+	}
+}
+
+// WithMaxSegments configures the number of segment files kept. Once it is
+// exceeded, the oldest segment is removed. Together with the segment
+// duration it determines how far back the recording reaches. It defaults
+// to 10.
+func WithMaxSegments(n int) RecorderOption {
+	return func(r *Recorder) {
+		r.maxSegments = n
+	}
+}
+
+// WithMaxSegmentBytes configures the size after which a segment is
+// completed early, bounding the disk usage to roughly the maximum number of
+// segments times this size. It defaults to 64MiB.
+func WithMaxSegmentBytes(n int64) RecorderOption {
+	return func(r *Recorder) {
+		r.maxSegmentBytes = n
+	}
+}
+
+// Recorder writes envelopes to a ring of segment files in a directory. It is
+// safe for concurrent use. It should be created with the New constructor.
+type Recorder struct {
+	dir             string
+	segmentDuration time.Duration
+	maxSegments     int
+	maxSegmentBytes int64
+
+	mu       sync.Mutex
+	segments []string
+	seq      int
+	file     *os.File
+	w        *bufio.Writer
+	started  time.Time
+	written  int64
+}
+
+// New returns a Recorder that writes to the given directory, which is
+// created if it does not exist. Segments left in the directory by a previous
+// Recorder become part of the ring.
+func New(dir string, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		dir:             dir,
+		segmentDuration: time.Minute,
+		maxSegments:     10,
+		maxSegmentBytes: 64 * 1024 * 1024,
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	segments, err := filepath.Glob(filepath.Join(dir, segmentPrefix+"*"+segmentSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(segments)
+	r.segments = segments
+
+	return r, nil
+}
+
+// Record appends the envelopes to the current segment.
+func (r *Recorder) Record(envelopes ...*loggregator_v2.Envelope) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range envelopes {
+		if err := r.rotateIfNeeded(); err != nil {
+			return err
+		}
+
+		n, err := writeEnvelope(r.w, e)
+		if err != nil {
+			return err
+		}
+		r.written += int64(n)
+	}
+
+	return nil
+}
+
+// Dump writes all recorded envelopes, oldest first, to w. The result can be
+// read with Read.
+func (r *Recorder) Dump(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.w != nil {
+		if err := r.w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range r.segments {
+		f, err := os.Open(s)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(w, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DumpOnSignal dumps the recording to a new file in dir whenever one of the
+// given signals is received, e.g. syscall.SIGUSR1. The path of every dump,
+// or the error if it failed, is passed to report, which may be nil. It
+// blocks until the context is done.
+func (r *Recorder) DumpOnSignal(ctx context.Context, dir string, report func(path string, err error), sigs ...os.Signal) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	defer signal.Stop(c)
+
+	for {
+		select {
+		case <-c:
+			path, err := r.dumpFile(dir)
+			if report != nil {
+				report(path, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Recorder) dumpFile(dir string) (string, error) {
+	f, err := ioutil.TempFile(dir, "dump-"+time.Now().UTC().Format("20060102T150405")+"-*"+segmentSuffix)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := r.Dump(f); err != nil {
+		return f.Name(), err
+	}
+
+	return f.Name(), nil
+}
+
+// Close completes the current segment.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.closeSegment()
+}
+
+// rotateIfNeeded starts a new segment if there is none or the current one
+// is complete, and removes the oldest segments that exceed the limit.
+func (r *Recorder) rotateIfNeeded() error {
+	if r.file != nil && time.Since(r.started) < r.segmentDuration && r.written < r.maxSegmentBytes {
+		return nil
+	}
+
+	if err := r.closeSegment(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.seq++
+	name := filepath.Join(r.dir, fmt.Sprintf("%s%020d-%06d%s", segmentPrefix, now.UnixNano(), r.seq%1000000, segmentSuffix))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	r.file = f
+	r.w = bufio.NewWriter(f)
+	r.started = now
+	r.written = 0
+	r.segments = append(r.segments, name)
+
+	for len(r.segments) > r.maxSegments {
+		if err := os.Remove(r.segments[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		r.segments = r.segments[1:]
+	}
+
+	return nil
+}
+
+func (r *Recorder) closeSegment() error {
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.w.Flush()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file, r.w = nil, nil
+
+	return err
+}
+
+// writeEnvelope writes the envelope prefixed with its length as a uvarint.
+// It returns the number of bytes written.
+func writeEnvelope(w io.Writer, e *loggregator_v2.Envelope) (int, error) {
+	b, err := proto.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(len(b)))
+
+	if _, err := w.Write(prefix[:n]); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(b); err != nil {
+		return 0, err
+	}
+
+	return n + len(b), nil
+}
+
+// Read reads the envelopes of a segment or a dump.
+func Read(r io.Reader) ([]*loggregator_v2.Envelope, error) {
+	br := bufio.NewReader(r)
+
+	var envelopes []*loggregator_v2.Envelope
+	for {
+		size, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return envelopes, nil
+		}
+		if err != nil {
+			return envelopes, err
+		}
+
+		b := make([]byte, size)
+		if _, err := io.ReadFull(br, b); err != nil {
+			return envelopes, err
+		}
+
+		var e loggregator_v2.Envelope
+		if err := proto.Unmarshal(b, &e); err != nil {
+			return envelopes, err
+		}
+		envelopes = append(envelopes, &e)
+	}
+}
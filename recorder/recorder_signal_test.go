@@ -0,0 +1,70 @@
+//go:build !windows
+// +build !windows
+
+package recorder_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/recorder"
+	"golang.org/x/net/context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recorder signals", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "recorder")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("dumps to a file when it receives a signal", func() {
+		r, err := recorder.New(filepath.Join(dir, "ring"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Record(buildLog("a"))).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dumps := make(chan string, 1)
+		go r.DumpOnSignal(ctx, dir, func(path string, err error) {
+			defer GinkgoRecover()
+			Expect(err).NotTo(HaveOccurred())
+			dumps <- path
+		}, syscall.SIGUSR1)
+
+		Eventually(func() string {
+			syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+			select {
+			case p := <-dumps:
+				return p
+			case <-time.After(10 * time.Millisecond):
+				return ""
+			}
+		}).ShouldNot(BeEmpty())
+
+		matches, err := filepath.Glob(filepath.Join(dir, "dump-*"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).NotTo(BeEmpty())
+
+		b, err := ioutil.ReadFile(matches[0])
+		Expect(err).NotTo(HaveOccurred())
+		envelopes, err := recorder.Read(bytes.NewReader(b))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(envelopes).To(HaveLen(1))
+		Expect(string(envelopes[0].GetLog().GetPayload())).To(Equal("a"))
+	})
+})
@@ -0,0 +1,101 @@
+package recorder_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/recorder"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recorder", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "recorder")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("dumps the recorded envelopes in order", func() {
+		r, err := recorder.New(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Record(buildLog("a"), buildLog("b"))).To(Succeed())
+		Expect(r.Record(buildLog("c"))).To(Succeed())
+
+		Expect(dumpedPayloads(r)).To(Equal([]string{"a", "b", "c"}))
+	})
+
+	It("drops the oldest segments", func() {
+		r, err := recorder.New(dir,
+			recorder.WithMaxSegmentBytes(1),
+			recorder.WithMaxSegments(2),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, p := range []string{"a", "b", "c", "d"} {
+			Expect(r.Record(buildLog(p))).To(Succeed())
+		}
+
+		Expect(dumpedPayloads(r)).To(Equal([]string{"c", "d"}))
+		Expect(filepath.Glob(filepath.Join(dir, "segment-*"))).To(HaveLen(2))
+	})
+
+	It("starts a new segment after the segment duration", func() {
+		r, err := recorder.New(dir, recorder.WithSegmentDuration(time.Millisecond))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(r.Record(buildLog("a"))).To(Succeed())
+		time.Sleep(5 * time.Millisecond)
+		Expect(r.Record(buildLog("b"))).To(Succeed())
+
+		Expect(filepath.Glob(filepath.Join(dir, "segment-*"))).To(HaveLen(2))
+	})
+
+	It("keeps the segments of a previous recorder", func() {
+		r, err := recorder.New(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Record(buildLog("a"))).To(Succeed())
+		Expect(r.Close()).To(Succeed())
+
+		r, err = recorder.New(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Record(buildLog("b"))).To(Succeed())
+
+		Expect(dumpedPayloads(r)).To(Equal([]string{"a", "b"}))
+	})
+})
+
+func buildLog(payload string) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId: "some-source",
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{Payload: []byte(payload)},
+		},
+	}
+}
+
+func dumpedPayloads(r *recorder.Recorder) []string {
+	var buf bytes.Buffer
+	Expect(r.Dump(&buf)).To(Succeed())
+
+	envelopes, err := recorder.Read(&buf)
+	Expect(err).NotTo(HaveOccurred())
+
+	var payloads []string
+	for _, e := range envelopes {
+		payloads = append(payloads, string(e.GetLog().GetPayload()))
+	}
+	return payloads
+}
@@ -0,0 +1,205 @@
+// Package routerlog converts gorouter access log lines into v2 timer
+// envelopes. The timers carry the same HTTP tags as the timers gorouter
+// emits itself, so that components ingesting router logs produce envelopes
+// that line up with the rest of the platform's HTTP metrics.
+package routerlog
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// timeLayouts are the layouts of the start time gorouter has used over
+// time.
+var timeLayouts = []string{
+	"2006-01-02T15:04:05.000-0700",
+	time.RFC3339Nano,
+	"02/01/2006:15:04:05.000 -0700",
+}
+
+// Parse converts a gorouter access log line into a timer named "http". The
+// timer starts at the start time of the request and lasts its response
+// time. The source ID and instance ID are the app ID and app index of the
+// line.
+func Parse(line string) (*loggregator_v2.Envelope, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) < 11 {
+		return nil, errors.New("routerlog: too few fields")
+	}
+
+	start, err := parseTime(tokens[2])
+	if err != nil {
+		return nil, err
+	}
+
+	request := strings.SplitN(tokens[3], " ", 3)
+	if len(request) < 2 {
+		return nil, fmt.Errorf("routerlog: invalid request %q", tokens[3])
+	}
+
+	if _, err := strconv.Atoi(tokens[4]); err != nil {
+		return nil, fmt.Errorf("routerlog: invalid status code %q", tokens[4])
+	}
+
+	fields := make(map[string]string)
+	for _, t := range tokens[11:] {
+		i := strings.IndexByte(t, ':')
+		if i < 0 {
+			continue
+		}
+		fields[t[:i]] = unquote(t[i+1:])
+	}
+
+	responseTime, err := strconv.ParseFloat(fields["response_time"], 64)
+	if err != nil {
+		return nil, fmt.Errorf("routerlog: invalid response time %q", fields["response_time"])
+	}
+	stop := start.Add(time.Duration(responseTime * float64(time.Second)))
+
+	scheme := value(fields["x_forwarded_proto"])
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	tags := map[string]string{
+		"peer_type":      "Client",
+		"method":         request[0],
+		"uri":            scheme + "://" + tokens[0] + request[1],
+		"remote_address": value(tokens[9]),
+		"user_agent":     value(tokens[8]),
+		"status_code":    tokens[4],
+		"content_length": tokens[6],
+	}
+	optional := map[string]string{
+		"request_id":     fields["vcap_request_id"],
+		"forwarded":      forwarded(fields["x_forwarded_for"]),
+		"instance_id":    fields["instance_id"],
+		"instance_index": fields["app_index"],
+		"trace_id":       fields["x_b3_traceid"],
+		"span_id":        fields["x_b3_spanid"],
+	}
+	for k, v := range optional {
+		if v = value(v); v != "" {
+			tags[k] = v
+		}
+	}
+
+	return &loggregator_v2.Envelope{
+		Timestamp:  stop.UnixNano(),
+		SourceId:   value(fields["app_id"]),
+		InstanceId: value(fields["app_index"]),
+		Tags:       tags,
+		Message: &loggregator_v2.Envelope_Timer{
+			Timer: &loggregator_v2.Timer{
+				Name:  "http",
+				Start: start.UnixNano(),
+				Stop:  stop.UnixNano(),
+			},
+		},
+	}, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, l := range timeLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("routerlog: invalid start time %q", s)
+}
+
+// forwarded encodes the comma separated addresses of the X-Forwarded-For
+// header as a list.
+func forwarded(header string) string {
+	if header == "" || header == "-" {
+		return ""
+	}
+
+	addrs := strings.Split(header, ",")
+	for i, a := range addrs {
+		addrs[i] = strings.TrimSpace(a)
+	}
+
+	return envelope.EncodeList(addrs)
+}
+
+// value returns an empty string for fields gorouter logs as "-".
+func value(s string) string {
+	if s == "-" {
+		return ""
+	}
+
+	return s
+}
+
+// tokenize splits the line at spaces that are not within double quotes or
+// square brackets. Quotes and brackets that enclose a whole token are
+// removed. Quotes within a token, e.g. in key:"value", are kept.
+func tokenize(line string) ([]string, error) {
+	var (
+		tokens []string
+		cur    strings.Builder
+		quoted bool
+		depth  int
+	)
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(line):
+			cur.WriteByte(c)
+			i++
+			cur.WriteByte(line[i])
+			continue
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == '[':
+			depth++
+		case !quoted && c == ']' && depth > 0:
+			depth--
+		case !quoted && depth == 0 && c == ' ':
+			if cur.Len() > 0 {
+				tokens = append(tokens, unquote(cur.String()))
+				cur.Reset()
+			}
+			continue
+		}
+		cur.WriteByte(c)
+	}
+
+	if quoted || depth > 0 {
+		return nil, errors.New("routerlog: unterminated quote or bracket")
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, unquote(cur.String()))
+	}
+
+	return tokens, nil
+}
+
+// unquote removes the double quotes or square brackets that enclose the
+// token.
+func unquote(t string) string {
+	if len(t) < 2 {
+		return t
+	}
+
+	switch {
+	case t[0] == '"' && t[len(t)-1] == '"':
+		return strings.Replace(t[1:len(t)-1], `\"`, `"`, -1)
+	case t[0] == '[' && t[len(t)-1] == ']':
+		return t[1 : len(t)-1]
+	default:
+		return t
+	}
+}
@@ -0,0 +1,65 @@
+package routerlog_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/routerlog"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	const line = `app.example.com - [2019-07-26T20:52:33.180+0000] "GET /v2/info?a=b HTTP/1.1" 200 12 1031 "-" "curl/7.54.0" "10.0.2.15:54310" "10.0.1.5:9022" x_forwarded_for:"10.0.2.15, 10.0.0.1" x_forwarded_proto:"https" vcap_request_id:"7d8b7f4e-8c0e-4d23-6a1b-8e1b37bb4a2c" response_time:0.003412 gorouter_time:0.000169 app_id:"some-app-id" app_index:"2" instance_id:"some-instance-id" x_cf_routererror:"-" x_b3_traceid:"0af7651916cd43dd8448eb211c80319c" x_b3_spanid:"b7ad6b7169203331" x_b3_parentspanid:"-" b3:"0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331"`
+
+	It("converts an access log line into a timer", func() {
+		e, err := routerlog.Parse(line)
+		Expect(err).NotTo(HaveOccurred())
+
+		start := time.Date(2019, 7, 26, 20, 52, 33, 180000000, time.UTC)
+		Expect(e.SourceId).To(Equal("some-app-id"))
+		Expect(e.InstanceId).To(Equal("2"))
+		Expect(e.GetTimer().Name).To(Equal("http"))
+		Expect(e.GetTimer().Start).To(Equal(start.UnixNano()))
+		Expect(e.GetTimer().Stop).To(Equal(start.Add(3412 * time.Microsecond).UnixNano()))
+		Expect(e.Tags).To(Equal(map[string]string{
+			"peer_type":      "Client",
+			"method":         "GET",
+			"uri":            "https://app.example.com/v2/info?a=b",
+			"remote_address": "10.0.2.15:54310",
+			"user_agent":     "curl/7.54.0",
+			"status_code":    "200",
+			"content_length": "1031",
+			"request_id":     "7d8b7f4e-8c0e-4d23-6a1b-8e1b37bb4a2c",
+			"forwarded":      "10.0.2.15\n10.0.0.1",
+			"instance_id":    "some-instance-id",
+			"instance_index": "2",
+			"trace_id":       "0af7651916cd43dd8448eb211c80319c",
+			"span_id":        "b7ad6b7169203331",
+		}))
+	})
+
+	It("supports the legacy time format and unquoted addresses", func() {
+		e, err := routerlog.Parse(`app.example.com - [26/07/2019:20:52:33.180 +0000] "POST / HTTP/1.1" 201 0 5 "-" "agent \"x\"" 10.0.2.15:54310 10.0.1.5:9022 vcap_request_id:- response_time:1 app_id:- app_index:-`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(e.SourceId).To(BeEmpty())
+		Expect(e.GetTimer().Stop - e.GetTimer().Start).To(Equal(int64(time.Second)))
+		Expect(e.Tags).To(HaveKeyWithValue("uri", "http://app.example.com/"))
+		Expect(e.Tags).To(HaveKeyWithValue("user_agent", `agent "x"`))
+		Expect(e.Tags).To(HaveKeyWithValue("remote_address", "10.0.2.15:54310"))
+		Expect(e.Tags).NotTo(HaveKey("request_id"))
+	})
+
+	DescribeTable("rejects invalid lines",
+		func(line string) {
+			_, err := routerlog.Parse(line)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", `app.example.com - [2019-07-26T20:52:33.180+0000] "GET / HTTP/1.1" 200`),
+		Entry("unterminated quote", `app.example.com - [2019-07-26T20:52:33.180+0000] "GET / HTTP/1.1`),
+		Entry("invalid time", `app.example.com - [yesterday] "GET / HTTP/1.1" 200 0 0 "-" "-" "-" "-" response_time:1`),
+		Entry("missing response time", `app.example.com - [2019-07-26T20:52:33.180+0000] "GET / HTTP/1.1" 200 0 0 "-" "-" "-" "-"`),
+	)
+})
@@ -0,0 +1,13 @@
+package routerlog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRouterlog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Router Log Suite")
+}
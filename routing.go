@@ -0,0 +1,131 @@
+package loggregator
+
+import (
+	"fmt"
+	"strconv"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+)
+
+// batchingTag carries the route chosen with WithBatching from the option to
+// the client, which removes it before the envelope reaches any hook,
+// middleware or sink.
+const batchingTag = "loggregator_batching"
+
+// Route is the path an envelope takes to the agent.
+type Route int
+
+const (
+	// Batched envelopes are sent once the batch is full or the flush
+	// interval has passed. This is the default route unless changed with
+	// WithDefaultBatching.
+	Batched Route = iota
+
+	// Unbatched envelopes are sent as soon as the sender receives them,
+	// together with the envelopes batched before them so that the order of
+	// envelopes is preserved. This trades throughput for latency.
+	Unbatched
+)
+
+type routingRule struct {
+	match func(*loggregator_v2.Envelope) bool
+	route Route
+}
+
+// WithRoute routes envelopes that match to the given route. Rules are
+// evaluated in the order they are added and the first matching rule wins.
+// Envelopes that match no rule take the default route. For example, to send error logs
+// with low latency and everything else in batches:
+//
+//	loggregator.WithRoute(loggregator.IsErrorLog, loggregator.Unbatched)
+//
+// Combine with WithPriorityEnvelopes to also keep these envelopes from
+// queueing behind bulk envelopes.
+func WithRoute(match func(*loggregator_v2.Envelope) bool, route Route) IngressOption {
+	return func(c *IngressClient) {
+		c.routes = append(c.routes, routingRule{match: match, route: route})
+	}
+}
+
+// WithDefaultBatching configures the route of envelopes that neither match
+// a rule added with WithRoute nor were emitted with WithBatching. If
+// disabled, every envelope is sent as soon as the sender receives it. It is
+// enabled by default.
+func WithDefaultBatching(enabled bool) IngressOption {
+	return func(c *IngressClient) {
+		c.defaultRoute = routeOf(enabled)
+	}
+}
+
+// WithBatching is an option for any Emit method that chooses the route of
+// a single envelope, overriding the routing rules and the default route.
+// The route is never sent as a tag; hooks, middleware and sinks do not see
+// it.
+func WithBatching(enabled bool) func(proto.Message) {
+	return func(m proto.Message) {
+		switch e := m.(type) {
+		case *loggregator_v2.Envelope:
+			if e.Tags == nil {
+				e.Tags = make(map[string]string)
+			}
+			e.Tags[batchingTag] = strconv.FormatBool(enabled)
+		case protoEditor:
+			// v1 envelopes are not batched.
+		default:
+			panic(fmt.Sprintf("unsupported Message type: %T", m))
+		}
+	}
+}
+
+func routeOf(batched bool) Route {
+	if batched {
+		return Batched
+	}
+
+	return Unbatched
+}
+
+// routing is the route chosen for a single envelope with WithBatching. It
+// travels next to the envelope from the emitting go routine to the sender
+// so that the envelope itself is never changed by the sender.
+type routing struct {
+	route Route
+	set   bool
+}
+
+// takeRouting removes the tag set by WithBatching from the envelope and
+// returns the route it carried. The envelope must be owned by the client,
+// i.e. be built by an Emit method or be a copy made by copyOnWrite.
+func takeRouting(e *loggregator_v2.Envelope) routing {
+	v, ok := e.Tags[batchingTag]
+	if !ok {
+		return routing{}
+	}
+	delete(e.Tags, batchingTag)
+
+	return routing{route: routeOf(v != "false"), set: true}
+}
+
+// queued is an envelope on its way to the sender.
+type queued struct {
+	e *loggregator_v2.Envelope
+	routing
+}
+
+// route returns the route of the queued envelope: the route chosen with
+// WithBatching, else the route of the first matching rule, else the default
+// route.
+func (c *IngressClient) route(q queued) Route {
+	if q.set {
+		return q.route
+	}
+
+	for _, r := range c.routes {
+		if r.match(q.e) {
+			return r.route
+		}
+	}
+
+	return c.defaultRoute
+}
@@ -0,0 +1,164 @@
+package loggregator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routing", func() {
+	var server *testIngressServer
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("sends unbatched envelopes without waiting for the flush interval", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithRoute(loggregator.IsErrorLog, loggregator.Unbatched),
+		)
+
+		client.EmitLog("some-out", loggregator.WithStdout())
+		Consistently(server.receivers, 200*time.Millisecond).ShouldNot(Receive())
+
+		client.EmitLog("some-err")
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetLog().GetPayload()).To(Equal([]byte("some-out")))
+		Expect(envelopes[1].GetLog().GetPayload()).To(Equal([]byte("some-err")))
+	})
+
+	It("applies the first matching rule", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithRoute(loggregator.IsErrorLog, loggregator.Batched),
+			loggregator.WithRoute(loggregator.IsErrorLog, loggregator.Unbatched),
+		)
+
+		client.EmitLog("some-err")
+
+		Consistently(server.receivers, 200*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("lets a single envelope choose its route", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithRoute(loggregator.IsErrorLog, loggregator.Batched),
+		)
+
+		client.EmitLog("some-err", loggregator.WithBatching(false))
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes).To(HaveLen(1))
+		Expect(envelopes[0].Tags).ToNot(HaveKey("loggregator_batching"))
+	})
+
+	It("sends every envelope unbatched if batching is disabled by default", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithDefaultBatching(false),
+		)
+
+		client.EmitCounter("some-counter", loggregator.WithBatching(true))
+		Consistently(server.receivers, 200*time.Millisecond).ShouldNot(Receive())
+
+		client.EmitGauge(loggregator.WithGaugeValue("some-gauge", 1, "unit"))
+
+		envelopes := receiveEnvelopes(server.receivers, 2)
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetCounter().GetName()).To(Equal("some-counter"))
+		Expect(envelopes[0].Tags).ToNot(HaveKey("loggregator_batching"))
+	})
+
+	It("never hands the route to hooks or middleware", func() {
+		var seen []map[string]string
+		spy := func(next loggregator.EmitFunc) loggregator.EmitFunc {
+			return func(e *loggregator_v2.Envelope) error {
+				seen = append(seen, e.Tags)
+				return next(e)
+			}
+		}
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithMiddleware(spy),
+		)
+		client.BeforeEmit(func(e *loggregator_v2.Envelope) {
+			seen = append(seen, e.Tags)
+		})
+
+		client.EmitLog("some-log", loggregator.WithBatching(false))
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes[0].Tags).ToNot(HaveKey("loggregator_batching"))
+		Expect(seen).To(HaveLen(2))
+		for _, tags := range seen {
+			Expect(tags).ToNot(HaveKey("loggregator_batching"))
+		}
+	})
+
+	It("does not modify the tags of emitted envelopes", func() {
+		client, _, _ := buildIngressClient(
+			server.addr,
+			time.Hour,
+			false,
+			loggregator.WithUnsafeNoCopy(),
+		)
+		e := &loggregator_v2.Envelope{
+			SourceId: "some-source",
+			Tags:     map[string]string{"loggregator_batching": "false"},
+		}
+
+		Expect(client.EmitEnvelope(e)).To(Succeed())
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes[0].Tags).ToNot(HaveKey("loggregator_batching"))
+		Expect(e.Tags).To(HaveKeyWithValue("loggregator_batching", "false"))
+	})
+
+	It("does not hand the route to the sinks of a TeeClient", func() {
+		client, _, _ := buildIngressClient(server.addr, time.Hour, false)
+		var sunk []*loggregator_v2.Envelope
+		tee := loggregator.NewTeeClient(
+			loggregator.WithSink("ingress", client),
+			loggregator.WithSink("spy", loggregator.SinkFunc(func(e *loggregator_v2.Envelope) error {
+				sunk = append(sunk, e)
+				return nil
+			})),
+		)
+
+		tee.EmitLog("some-log", loggregator.WithBatching(false))
+
+		Expect(sunk).To(HaveLen(1))
+		Expect(sunk[0].Tags).ToNot(HaveKey("loggregator_batching"))
+
+		envelopes := receiveEnvelopes(server.receivers, 1)
+		Expect(envelopes[0].Tags).ToNot(HaveKey("loggregator_batching"))
+	})
+})
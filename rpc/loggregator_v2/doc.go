@@ -1,3 +1,7 @@
+// Package loggregator_v2 contains the Go bindings of the Loggregator v2 API
+// (envelope.proto, ingress.proto and egress.proto). The bindings are
+// generated with protoc-gen-go and protoc-gen-go-grpc against the
+// google.golang.org/protobuf runtime; see generate.sh.
 package loggregator_v2
 
 //go:generate ./generate.sh
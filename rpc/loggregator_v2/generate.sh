@@ -12,14 +12,29 @@ TARGET=`dirname $0`
 TARGET=`dir_resolve $TARGET`
 cd $TARGET
 
-go get github.com/golang/protobuf/{proto,protoc-gen-go}
+go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.33.0
+go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.3.0
 
 
 tmp_dir=$(mktemp -d)
 mkdir -p $tmp_dir/loggregator
 
-cp $GOPATH/src/github.com/cloudfoundry/loggregator-api/v2/*proto $tmp_dir/loggregator
+LOGGREGATOR_API_DIR=${LOGGREGATOR_API_DIR:-$GOPATH/src/github.com/cloudfoundry/loggregator-api}
+cp $LOGGREGATOR_API_DIR/v2/*proto $tmp_dir/loggregator
 
-protoc $tmp_dir/loggregator/*.proto --go_out=plugins=grpc:. --proto_path=$tmp_dir/loggregator
+go_package=code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2
+go_opts=paths=source_relative
+for proto in envelope.proto ingress.proto egress.proto; do
+    go_opts=$go_opts,M$proto=$go_package
+done
+
+protoc $tmp_dir/loggregator/*.proto \
+    --proto_path=$tmp_dir/loggregator \
+    --go_out=$go_opts:. \
+    --go-grpc_out=$go_opts,require_unimplemented_servers=false:.
 
 rm -r $tmp_dir
+
+# Verify that the regenerated bindings are wire compatible with the previous
+# ones. Run with UPDATE_GOLDEN=1 after an intentional change to the protos.
+go test .
@@ -0,0 +1,38 @@
+package loggregator_v2
+
+import "google.golang.org/protobuf/proto"
+
+// The methods below let the envelope types satisfy the Marshal/Unmarshal/Size
+// interfaces that gogo/protobuf based code expects from generated messages.
+// They delegate to the google.golang.org/protobuf runtime and produce the
+// same wire format.
+
+// Marshal returns the wire encoding of the envelope.
+func (m *Envelope) Marshal() ([]byte, error) {
+	return proto.Marshal(m)
+}
+
+// Unmarshal parses the wire encoded envelope in data into m.
+func (m *Envelope) Unmarshal(data []byte) error {
+	return proto.Unmarshal(data, m)
+}
+
+// Size returns the size of the wire encoding of the envelope.
+func (m *Envelope) Size() int {
+	return proto.Size(m)
+}
+
+// Marshal returns the wire encoding of the batch.
+func (m *EnvelopeBatch) Marshal() ([]byte, error) {
+	return proto.Marshal(m)
+}
+
+// Unmarshal parses the wire encoded batch in data into m.
+func (m *EnvelopeBatch) Unmarshal(data []byte) error {
+	return proto.Unmarshal(data, m)
+}
+
+// Size returns the size of the wire encoding of the batch.
+func (m *EnvelopeBatch) Size() int {
+	return proto.Size(m)
+}
@@ -0,0 +1,50 @@
+package loggregator_v2_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("gogo compatibility", func() {
+	It("marshals envelopes like the protobuf runtime", func() {
+		e := &loggregator_v2.Envelope{
+			SourceId: "some-source",
+			Tags:     map[string]string{"a": "b"},
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte("hello")},
+			},
+		}
+
+		data, err := e.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Size()).To(Equal(len(data)))
+
+		expected, err := proto.Marshal(e)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(expected))
+
+		var actual loggregator_v2.Envelope
+		Expect(actual.Unmarshal(data)).To(Succeed())
+		Expect(proto.Equal(&actual, e)).To(BeTrue())
+	})
+
+	It("round trips envelope batches", func() {
+		b := &loggregator_v2.EnvelopeBatch{
+			Batch: []*loggregator_v2.Envelope{
+				{SourceId: "a"},
+				{SourceId: "b"},
+			},
+		}
+
+		data, err := b.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.Size()).To(Equal(len(data)))
+
+		var actual loggregator_v2.EnvelopeBatch
+		Expect(actual.Unmarshal(data)).To(Succeed())
+		Expect(proto.Equal(&actual, b)).To(BeTrue())
+	})
+})
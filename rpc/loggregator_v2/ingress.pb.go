@@ -1,274 +1,269 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        (unknown)
 // source: ingress.proto
 
 package loggregator_v2
 
-import proto "github.com/golang/protobuf/proto"
-import fmt "fmt"
-import math "math"
-
 import (
-	context "golang.org/x/net/context"
-	grpc "google.golang.org/grpc"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
 )
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ = proto.Marshal
-var _ = fmt.Errorf
-var _ = math.Inf
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
 
 type IngressResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 }
 
-func (m *IngressResponse) Reset()                    { *m = IngressResponse{} }
-func (m *IngressResponse) String() string            { return proto.CompactTextString(m) }
-func (*IngressResponse) ProtoMessage()               {}
-func (*IngressResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
-
-type BatchSenderResponse struct {
-}
-
-func (m *BatchSenderResponse) Reset()                    { *m = BatchSenderResponse{} }
-func (m *BatchSenderResponse) String() string            { return proto.CompactTextString(m) }
-func (*BatchSenderResponse) ProtoMessage()               {}
-func (*BatchSenderResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
-
-type SendResponse struct {
-}
-
-func (m *SendResponse) Reset()                    { *m = SendResponse{} }
-func (m *SendResponse) String() string            { return proto.CompactTextString(m) }
-func (*SendResponse) ProtoMessage()               {}
-func (*SendResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
-
-func init() {
-	proto.RegisterType((*IngressResponse)(nil), "loggregator.v2.IngressResponse")
-	proto.RegisterType((*BatchSenderResponse)(nil), "loggregator.v2.BatchSenderResponse")
-	proto.RegisterType((*SendResponse)(nil), "loggregator.v2.SendResponse")
-}
-
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// Client API for Ingress service
-
-type IngressClient interface {
-	Sender(ctx context.Context, opts ...grpc.CallOption) (Ingress_SenderClient, error)
-	BatchSender(ctx context.Context, opts ...grpc.CallOption) (Ingress_BatchSenderClient, error)
-	Send(ctx context.Context, in *EnvelopeBatch, opts ...grpc.CallOption) (*SendResponse, error)
-}
-
-type ingressClient struct {
-	cc *grpc.ClientConn
-}
-
-func NewIngressClient(cc *grpc.ClientConn) IngressClient {
-	return &ingressClient{cc}
-}
-
-func (c *ingressClient) Sender(ctx context.Context, opts ...grpc.CallOption) (Ingress_SenderClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_Ingress_serviceDesc.Streams[0], c.cc, "/loggregator.v2.Ingress/Sender", opts...)
-	if err != nil {
-		return nil, err
+func (x *IngressResponse) Reset() {
+	*x = IngressResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ingress_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	x := &ingressSenderClient{stream}
-	return x, nil
 }
 
-type Ingress_SenderClient interface {
-	Send(*Envelope) error
-	CloseAndRecv() (*IngressResponse, error)
-	grpc.ClientStream
+func (x *IngressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type ingressSenderClient struct {
-	grpc.ClientStream
-}
-
-func (x *ingressSenderClient) Send(m *Envelope) error {
-	return x.ClientStream.SendMsg(m)
-}
-
-func (x *ingressSenderClient) CloseAndRecv() (*IngressResponse, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	m := new(IngressResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
-}
+func (*IngressResponse) ProtoMessage() {}
 
-func (c *ingressClient) BatchSender(ctx context.Context, opts ...grpc.CallOption) (Ingress_BatchSenderClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_Ingress_serviceDesc.Streams[1], c.cc, "/loggregator.v2.Ingress/BatchSender", opts...)
-	if err != nil {
-		return nil, err
+func (x *IngressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ingress_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	x := &ingressBatchSenderClient{stream}
-	return x, nil
+	return mi.MessageOf(x)
 }
 
-type Ingress_BatchSenderClient interface {
-	Send(*EnvelopeBatch) error
-	CloseAndRecv() (*BatchSenderResponse, error)
-	grpc.ClientStream
+// Deprecated: Use IngressResponse.ProtoReflect.Descriptor instead.
+func (*IngressResponse) Descriptor() ([]byte, []int) {
+	return file_ingress_proto_rawDescGZIP(), []int{0}
 }
 
-type ingressBatchSenderClient struct {
-	grpc.ClientStream
-}
-
-func (x *ingressBatchSenderClient) Send(m *EnvelopeBatch) error {
-	return x.ClientStream.SendMsg(m)
+type BatchSenderResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 }
 
-func (x *ingressBatchSenderClient) CloseAndRecv() (*BatchSenderResponse, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	m := new(BatchSenderResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *BatchSenderResponse) Reset() {
+	*x = BatchSenderResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ingress_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return m, nil
 }
 
-func (c *ingressClient) Send(ctx context.Context, in *EnvelopeBatch, opts ...grpc.CallOption) (*SendResponse, error) {
-	out := new(SendResponse)
-	err := grpc.Invoke(ctx, "/loggregator.v2.Ingress/Send", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (x *BatchSenderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-// Server API for Ingress service
-
-type IngressServer interface {
-	Sender(Ingress_SenderServer) error
-	BatchSender(Ingress_BatchSenderServer) error
-	Send(context.Context, *EnvelopeBatch) (*SendResponse, error)
-}
+func (*BatchSenderResponse) ProtoMessage() {}
 
-func RegisterIngressServer(s *grpc.Server, srv IngressServer) {
-	s.RegisterService(&_Ingress_serviceDesc, srv)
-}
-
-func _Ingress_Sender_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(IngressServer).Sender(&ingressSenderServer{stream})
-}
-
-type Ingress_SenderServer interface {
-	SendAndClose(*IngressResponse) error
-	Recv() (*Envelope, error)
-	grpc.ServerStream
+func (x *BatchSenderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ingress_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-type ingressSenderServer struct {
-	grpc.ServerStream
+// Deprecated: Use BatchSenderResponse.ProtoReflect.Descriptor instead.
+func (*BatchSenderResponse) Descriptor() ([]byte, []int) {
+	return file_ingress_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *ingressSenderServer) SendAndClose(m *IngressResponse) error {
-	return x.ServerStream.SendMsg(m)
+type SendResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 }
 
-func (x *ingressSenderServer) Recv() (*Envelope, error) {
-	m := new(Envelope)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *SendResponse) Reset() {
+	*x = SendResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ingress_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return m, nil
 }
 
-func _Ingress_BatchSender_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(IngressServer).BatchSender(&ingressBatchSenderServer{stream})
+func (x *SendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type Ingress_BatchSenderServer interface {
-	SendAndClose(*BatchSenderResponse) error
-	Recv() (*EnvelopeBatch, error)
-	grpc.ServerStream
-}
+func (*SendResponse) ProtoMessage() {}
 
-type ingressBatchSenderServer struct {
-	grpc.ServerStream
-}
-
-func (x *ingressBatchSenderServer) SendAndClose(m *BatchSenderResponse) error {
-	return x.ServerStream.SendMsg(m)
-}
-
-func (x *ingressBatchSenderServer) Recv() (*EnvelopeBatch, error) {
-	m := new(EnvelopeBatch)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
+func (x *SendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ingress_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return m, nil
-}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendResponse.ProtoReflect.Descriptor instead.
+func (*SendResponse) Descriptor() ([]byte, []int) {
+	return file_ingress_proto_rawDescGZIP(), []int{2}
+}
+
+var File_ingress_proto protoreflect.FileDescriptor
+
+var file_ingress_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x69, 0x6e, 0x67, 0x72, 0x65, 0x73, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0e, 0x6c, 0x6f, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x32, 0x1a,
+	0x0e, 0x65, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0x11, 0x0a, 0x0f, 0x49, 0x6e, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x15, 0x0a, 0x13, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x6e, 0x64, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x0e, 0x0a, 0x0c, 0x53, 0x65, 0x6e,
+	0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xf0, 0x01, 0x0a, 0x07, 0x49, 0x6e,
+	0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x47, 0x0a, 0x06, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12,
+	0x18, 0x2e, 0x6c, 0x6f, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x32,
+	0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x1a, 0x1f, 0x2e, 0x6c, 0x6f, 0x67, 0x67,
+	0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x49, 0x6e, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x28, 0x01, 0x12, 0x55,
+	0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x1d, 0x2e,
+	0x6c, 0x6f, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x45,
+	0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x1a, 0x23, 0x2e, 0x6c,
+	0x6f, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x28, 0x01, 0x12, 0x45, 0x0a, 0x04, 0x53, 0x65, 0x6e, 0x64, 0x12, 0x1d, 0x2e,
+	0x6c, 0x6f, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x45,
+	0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x1a, 0x1c, 0x2e, 0x6c,
+	0x6f, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x65,
+	0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x7d, 0x0a, 0x1f,
+	0x6f, 0x72, 0x67, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x72, 0x79,
+	0x2e, 0x6c, 0x6f, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x76, 0x32, 0x42,
+	0x12, 0x4c, 0x6f, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x49, 0x6e, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x5a, 0x46, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x66,
+	0x6f, 0x75, 0x6e, 0x64, 0x72, 0x79, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x67, 0x6f, 0x2d, 0x6c, 0x6f,
+	0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x6c, 0x6f,
+	0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x76, 0x32, 0x3b, 0x6c, 0x6f, 0x67,
+	0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x76, 0x32, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_ingress_proto_rawDescOnce sync.Once
+	file_ingress_proto_rawDescData = file_ingress_proto_rawDesc
+)
 
-func _Ingress_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(EnvelopeBatch)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(IngressServer).Send(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/loggregator.v2.Ingress/Send",
+func file_ingress_proto_rawDescGZIP() []byte {
+	file_ingress_proto_rawDescOnce.Do(func() {
+		file_ingress_proto_rawDescData = protoimpl.X.CompressGZIP(file_ingress_proto_rawDescData)
+	})
+	return file_ingress_proto_rawDescData
+}
+
+var file_ingress_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_ingress_proto_goTypes = []interface{}{
+	(*IngressResponse)(nil),     // 0: loggregator.v2.IngressResponse
+	(*BatchSenderResponse)(nil), // 1: loggregator.v2.BatchSenderResponse
+	(*SendResponse)(nil),        // 2: loggregator.v2.SendResponse
+	(*Envelope)(nil),            // 3: loggregator.v2.Envelope
+	(*EnvelopeBatch)(nil),       // 4: loggregator.v2.EnvelopeBatch
+}
+var file_ingress_proto_depIdxs = []int32{
+	3, // 0: loggregator.v2.Ingress.Sender:input_type -> loggregator.v2.Envelope
+	4, // 1: loggregator.v2.Ingress.BatchSender:input_type -> loggregator.v2.EnvelopeBatch
+	4, // 2: loggregator.v2.Ingress.Send:input_type -> loggregator.v2.EnvelopeBatch
+	0, // 3: loggregator.v2.Ingress.Sender:output_type -> loggregator.v2.IngressResponse
+	1, // 4: loggregator.v2.Ingress.BatchSender:output_type -> loggregator.v2.BatchSenderResponse
+	2, // 5: loggregator.v2.Ingress.Send:output_type -> loggregator.v2.SendResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_ingress_proto_init() }
+func file_ingress_proto_init() {
+	if File_ingress_proto != nil {
+		return
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(IngressServer).Send(ctx, req.(*EnvelopeBatch))
+	file_envelope_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_ingress_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IngressResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ingress_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchSenderResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ingress_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SendResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
-	return interceptor(ctx, in, info, handler)
-}
-
-var _Ingress_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "loggregator.v2.Ingress",
-	HandlerType: (*IngressServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Send",
-			Handler:    _Ingress_Send_Handler,
-		},
-	},
-	Streams: []grpc.StreamDesc{
-		{
-			StreamName:    "Sender",
-			Handler:       _Ingress_Sender_Handler,
-			ClientStreams: true,
-		},
-		{
-			StreamName:    "BatchSender",
-			Handler:       _Ingress_BatchSender_Handler,
-			ClientStreams: true,
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_ingress_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
 		},
-	},
-	Metadata: "ingress.proto",
-}
-
-func init() { proto.RegisterFile("ingress.proto", fileDescriptor2) }
-
-var fileDescriptor2 = []byte{
-	// 208 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xcd, 0xcc, 0x4b, 0x2f,
-	0x4a, 0x2d, 0x2e, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0xcb, 0xc9, 0x4f, 0x4f, 0x2f,
-	0x4a, 0x4d, 0x4f, 0x2c, 0xc9, 0x2f, 0xd2, 0x2b, 0x33, 0x92, 0xe2, 0x4b, 0xcd, 0x2b, 0x4b, 0xcd,
-	0xc9, 0x2f, 0x48, 0x85, 0xc8, 0x2b, 0x09, 0x72, 0xf1, 0x7b, 0x42, 0x34, 0x04, 0xa5, 0x16, 0x17,
-	0xe4, 0xe7, 0x15, 0xa7, 0x2a, 0x89, 0x72, 0x09, 0x3b, 0x25, 0x96, 0x24, 0x67, 0x04, 0xa7, 0xe6,
-	0xa5, 0xa4, 0x16, 0xc1, 0x85, 0xf9, 0xb8, 0x78, 0x40, 0x22, 0x30, 0xbe, 0xd1, 0x07, 0x46, 0x2e,
-	0x76, 0xa8, 0x56, 0x21, 0x77, 0x2e, 0x36, 0x88, 0x6a, 0x21, 0x09, 0x3d, 0x54, 0x0b, 0xf5, 0x5c,
-	0xa1, 0xf6, 0x49, 0xc9, 0xa3, 0xcb, 0xa0, 0xdb, 0xcb, 0xa0, 0xc1, 0x28, 0x14, 0xca, 0xc5, 0x8d,
-	0x64, 0xb7, 0x90, 0x2c, 0x2e, 0xd3, 0xc0, 0x8a, 0xa4, 0x94, 0xd1, 0xa5, 0xb1, 0xb9, 0x1b, 0x64,
-	0xac, 0x2b, 0x17, 0x0b, 0x48, 0x94, 0x90, 0x79, 0x32, 0xe8, 0xd2, 0xc8, 0x1e, 0x56, 0x62, 0x70,
-	0x32, 0xe5, 0x92, 0xcf, 0x2f, 0x4a, 0xd7, 0x4b, 0xce, 0xc9, 0x2f, 0x4d, 0x49, 0xcb, 0x2f, 0xcd,
-	0x4b, 0x29, 0xaa, 0x44, 0xd3, 0xe1, 0x24, 0xe4, 0x83, 0xe0, 0x43, 0x3d, 0x98, 0xc4, 0x06, 0x0e,
-	0x6a, 0x63, 0x40, 0x00, 0x00, 0x00, 0xff, 0xff, 0x87, 0x97, 0x11, 0x79, 0x9b, 0x01, 0x00, 0x00,
+		GoTypes:           file_ingress_proto_goTypes,
+		DependencyIndexes: file_ingress_proto_depIdxs,
+		MessageInfos:      file_ingress_proto_msgTypes,
+	}.Build()
+	File_ingress_proto = out.File
+	file_ingress_proto_rawDesc = nil
+	file_ingress_proto_goTypes = nil
+	file_ingress_proto_depIdxs = nil
 }
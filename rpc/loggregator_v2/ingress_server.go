@@ -0,0 +1,86 @@
+package loggregator_v2
+
+import (
+	"io"
+	"net"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// EnvelopeHandler is called with every envelope received by an
+// EnvelopeServer. It may be called from several go routines at once.
+type EnvelopeHandler func(*Envelope)
+
+// EnvelopeServer implements the Ingress service. It takes care of the
+// Sender, BatchSender and Send RPCs and hands every received envelope to a
+// handler. It is meant for writing agents or test doubles of an agent.
+type EnvelopeServer struct {
+	handler    EnvelopeHandler
+	grpcServer *grpc.Server
+}
+
+// NewIngressServer creates an EnvelopeServer that hands every received
+// envelope to h. The gRPC server options, e.g. grpc.Creds, are used to create
+// the underlying gRPC server.
+func NewIngressServer(h EnvelopeHandler, opts ...grpc.ServerOption) *EnvelopeServer {
+	s := &EnvelopeServer{
+		handler:    h,
+		grpcServer: grpc.NewServer(opts...),
+	}
+	RegisterIngressServer(s.grpcServer, s)
+
+	return s
+}
+
+// Serve accepts connections on the listener. It blocks until Stop is called
+// or the listener fails.
+func (s *EnvelopeServer) Serve(lis net.Listener) error {
+	return s.grpcServer.Serve(lis)
+}
+
+// Stop closes all connections and stops the server.
+func (s *EnvelopeServer) Stop() {
+	s.grpcServer.Stop()
+}
+
+// Sender implements IngressServer.
+func (s *EnvelopeServer) Sender(srv Ingress_SenderServer) error {
+	for {
+		e, err := srv.Recv()
+		if err == io.EOF {
+			return srv.SendAndClose(&IngressResponse{})
+		}
+		if err != nil {
+			return err
+		}
+
+		s.handler(e)
+	}
+}
+
+// BatchSender implements IngressServer.
+func (s *EnvelopeServer) BatchSender(srv Ingress_BatchSenderServer) error {
+	for {
+		b, err := srv.Recv()
+		if err == io.EOF {
+			return srv.SendAndClose(&BatchSenderResponse{})
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, e := range b.GetBatch() {
+			s.handler(e)
+		}
+	}
+}
+
+// Send implements IngressServer.
+func (s *EnvelopeServer) Send(_ context.Context, b *EnvelopeBatch) (*SendResponse, error) {
+	for _, e := range b.GetBatch() {
+		s.handler(e)
+	}
+
+	return &SendResponse{}, nil
+}
@@ -0,0 +1,84 @@
+package loggregator_v2_test
+
+import (
+	"net"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnvelopeServer", func() {
+	var (
+		envelopes chan *loggregator_v2.Envelope
+		server    *loggregator_v2.EnvelopeServer
+		client    loggregator_v2.IngressClient
+	)
+
+	BeforeEach(func() {
+		envelopes = make(chan *loggregator_v2.Envelope, 10)
+		server = loggregator_v2.NewIngressServer(func(e *loggregator_v2.Envelope) {
+			envelopes <- e
+		})
+
+		lis, err := net.Listen("tcp4", "localhost:0")
+		Expect(err).NotTo(HaveOccurred())
+		go server.Serve(lis)
+
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+		Expect(err).NotTo(HaveOccurred())
+		client = loggregator_v2.NewIngressClient(conn)
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	It("delivers envelopes received via Sender", func() {
+		sender, err := client.Sender(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sender.Send(&loggregator_v2.Envelope{SourceId: "some-source"})).To(Succeed())
+		_, err = sender.CloseAndRecv()
+		Expect(err).NotTo(HaveOccurred())
+
+		var e *loggregator_v2.Envelope
+		Eventually(envelopes).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("some-source"))
+	})
+
+	It("delivers envelopes received via BatchSender", func() {
+		sender, err := client.BatchSender(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		err = sender.Send(&loggregator_v2.EnvelopeBatch{
+			Batch: []*loggregator_v2.Envelope{
+				{SourceId: "source-a"},
+				{SourceId: "source-b"},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = sender.CloseAndRecv()
+		Expect(err).NotTo(HaveOccurred())
+
+		var e *loggregator_v2.Envelope
+		Eventually(envelopes).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("source-a"))
+		Eventually(envelopes).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("source-b"))
+	})
+
+	It("delivers envelopes received via Send", func() {
+		_, err := client.Send(context.Background(), &loggregator_v2.EnvelopeBatch{
+			Batch: []*loggregator_v2.Envelope{{SourceId: "some-source"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var e *loggregator_v2.Envelope
+		Eventually(envelopes).Should(Receive(&e))
+		Expect(e.SourceId).To(Equal("some-source"))
+	})
+})
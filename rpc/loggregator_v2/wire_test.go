@@ -0,0 +1,181 @@
+package loggregator_v2_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// The golden files in testdata protect consumers from silent wire
+// incompatibilities when the bindings are regenerated. Set UPDATE_GOLDEN=1
+// to rewrite them after an intentional change.
+var updateGolden = os.Getenv("UPDATE_GOLDEN") != ""
+
+var _ = Describe("Wire compatibility", func() {
+	DescribeTable("envelopes match the golden wire format",
+		func(name string, e *loggregator_v2.Envelope) {
+			path := filepath.Join("testdata", name+".bin")
+
+			data, err := proto.Marshal(e)
+			Expect(err).NotTo(HaveOccurred())
+
+			if updateGolden {
+				Expect(ioutil.WriteFile(path, data, 0644)).To(Succeed())
+			}
+
+			golden, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			var decoded loggregator_v2.Envelope
+			Expect(proto.Unmarshal(golden, &decoded)).To(Succeed())
+			Expect(proto.Equal(&decoded, e)).To(BeTrue(), "decoded %s does not match %s", &decoded, e)
+
+			if len(e.GetTags()) <= 1 {
+				// Map entries are not ordered on the wire, so only envelopes
+				// with at most one tag are compared byte by byte.
+				Expect(data).To(Equal(golden))
+			}
+		},
+		Entry("log", "log", &loggregator_v2.Envelope{
+			Timestamp:  1257894000000000000,
+			SourceId:   "some-source",
+			InstanceId: "0",
+			Tags:       map[string]string{"source_type": "APP/PROC/WEB"},
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte("hello"), Type: loggregator_v2.Log_OUT},
+			},
+		}),
+		Entry("counter", "counter", &loggregator_v2.Envelope{
+			Timestamp: 1257894000000000000,
+			SourceId:  "some-source",
+			Message: &loggregator_v2.Envelope_Counter{
+				Counter: &loggregator_v2.Counter{Name: "requests", Delta: 3, Total: 42},
+			},
+		}),
+		Entry("gauge", "gauge", &loggregator_v2.Envelope{
+			Timestamp: 1257894000000000000,
+			SourceId:  "some-source",
+			Message: &loggregator_v2.Envelope_Gauge{
+				Gauge: &loggregator_v2.Gauge{
+					Metrics: map[string]*loggregator_v2.GaugeValue{
+						"cpu": {Value: 0.5, Unit: "percentage"},
+					},
+				},
+			},
+		}),
+		Entry("timer", "timer", &loggregator_v2.Envelope{
+			Timestamp: 1257894000000000000,
+			SourceId:  "some-source",
+			Message: &loggregator_v2.Envelope_Timer{
+				Timer: &loggregator_v2.Timer{Name: "http", Start: 1257894000000000000, Stop: 1257894000150000000},
+			},
+		}),
+		Entry("event", "event", &loggregator_v2.Envelope{
+			Timestamp: 1257894000000000000,
+			SourceId:  "some-source",
+			Message: &loggregator_v2.Envelope_Event{
+				Event: &loggregator_v2.Event{Title: "deployed", Body: "version 2"},
+			},
+		}),
+		Entry("deprecated tags", "deprecated_tags", &loggregator_v2.Envelope{
+			Timestamp: 1257894000000000000,
+			DeprecatedTags: map[string]*loggregator_v2.Value{
+				"index": {Data: &loggregator_v2.Value_Integer{Integer: 1}},
+			},
+			Message: &loggregator_v2.Envelope_Counter{
+				Counter: &loggregator_v2.Counter{Name: "requests", Delta: 1},
+			},
+		}),
+	)
+
+	It("has not drifted in field numbers or enum values", func() {
+		path := filepath.Join("testdata", "fields.golden")
+
+		var fields []string
+		for _, file := range []string{"envelope.proto", "ingress.proto", "egress.proto"} {
+			fields = append(fields, describeFile(file)...)
+		}
+		sort.Strings(fields)
+
+		var b bytes.Buffer
+		for _, f := range fields {
+			fmt.Fprintln(&b, f)
+		}
+
+		if updateGolden {
+			Expect(ioutil.WriteFile(path, b.Bytes(), 0644)).To(Succeed())
+		}
+
+		golden, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.String()).To(Equal(string(golden)))
+	})
+})
+
+// describeFile lists every field number and enum value of the registered
+// proto file.
+func describeFile(name string) []string {
+	gz := proto.FileDescriptor(name)
+	Expect(gz).NotTo(BeNil(), "proto file %s is not registered", name)
+
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	Expect(err).NotTo(HaveOccurred())
+	data, err := ioutil.ReadAll(r)
+	Expect(err).NotTo(HaveOccurred())
+
+	var fd descriptor.FileDescriptorProto
+	Expect(proto.Unmarshal(data, &fd)).To(Succeed())
+
+	var lines []string
+	for _, m := range fd.GetMessageType() {
+		lines = append(lines, describeMessage(fd.GetPackage(), m)...)
+	}
+	for _, e := range fd.GetEnumType() {
+		lines = append(lines, describeEnum(fd.GetPackage(), e)...)
+	}
+	for _, s := range fd.GetService() {
+		for _, m := range s.GetMethod() {
+			lines = append(lines, fmt.Sprintf("rpc %s.%s.%s(%s) %s", fd.GetPackage(), s.GetName(), m.GetName(), m.GetInputType(), m.GetOutputType()))
+		}
+	}
+
+	return lines
+}
+
+func describeMessage(prefix string, m *descriptor.DescriptorProto) []string {
+	name := prefix + "." + m.GetName()
+
+	var lines []string
+	for _, f := range m.GetField() {
+		lines = append(lines, fmt.Sprintf("field %s.%s = %d %s %s", name, f.GetName(), f.GetNumber(), f.GetLabel(), f.GetType()))
+	}
+	for _, nested := range m.GetNestedType() {
+		lines = append(lines, describeMessage(name, nested)...)
+	}
+	for _, e := range m.GetEnumType() {
+		lines = append(lines, describeEnum(name, e)...)
+	}
+
+	return lines
+}
+
+func describeEnum(prefix string, e *descriptor.EnumDescriptorProto) []string {
+	var lines []string
+	for _, v := range e.GetValue() {
+		lines = append(lines, fmt.Sprintf("enum %s.%s.%s = %d", prefix, e.GetName(), v.GetName(), v.GetNumber()))
+	}
+
+	return lines
+}
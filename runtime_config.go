@@ -0,0 +1,97 @@
+package loggregator
+
+import (
+	"time"
+)
+
+// RuntimeConfig holds the settings of an IngressClient that may be changed
+// while it is running. See UpdateConfig.
+type RuntimeConfig struct {
+	// Tags replace the default tags of the client, including the tags
+	// configured with WithTag, WithFrozenTags or WithVersionInfo. If nil,
+	// the default tags are kept.
+	Tags map[string]string
+
+	// BatchMaxSize replaces the value set with WithBatchMaxSize. If zero,
+	// the current value is kept.
+	BatchMaxSize uint
+
+	// BatchFlushInterval replaces the value set with
+	// WithBatchFlushInterval. If zero, the current value is kept.
+	BatchFlushInterval time.Duration
+
+	// RateLimit replaces the limit set with WithRateLimit. If nil, the
+	// current limit is kept. It has no effect unless the client was created
+	// with WithRateLimit.
+	RateLimit *RateLimit
+
+	// SampleRate replaces the rate set with WithSampling. If zero, the
+	// current rate is kept. It has no effect unless the client was created
+	// with WithSampling.
+	SampleRate float64
+}
+
+// RateLimit is the rate limit of a client created with WithRateLimit.
+type RateLimit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// UpdateConfig atomically applies the given config without reconnecting to
+// the agent. Envelopes emitted after UpdateConfig returns carry the new
+// tags and pass the new rate limit and sampling rate. The new batch
+// settings apply to the pending batch right away: it is flushed if it is
+// full by the new maximum size and the new flush interval starts over. This
+// is useful for components that watch their config files.
+func (c *IngressClient) UpdateConfig(config RuntimeConfig) {
+	var tags map[string]string
+	if config.Tags != nil {
+		tags = make(map[string]string, len(config.Tags))
+		for k, v := range config.Tags {
+			tags[k] = v
+		}
+	}
+
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+
+	if tags != nil {
+		c.tags = tags
+	}
+	if config.BatchMaxSize > 0 {
+		c.batchMaxSize = config.BatchMaxSize
+	}
+	if config.BatchFlushInterval > 0 {
+		c.batchFlushInterval = config.BatchFlushInterval
+	}
+	if config.RateLimit != nil && c.limiter != nil {
+		c.limiter.set(config.RateLimit.PerSecond, config.RateLimit.Burst)
+	}
+	if config.SampleRate > 0 && c.sampler != nil {
+		c.sampler.set(config.SampleRate)
+	}
+
+	if config.BatchMaxSize > 0 || config.BatchFlushInterval > 0 {
+		select {
+		case c.control <- reconfigure:
+		default:
+		}
+	}
+}
+
+// defaultTags returns the tags added to every envelope. The returned map is
+// never modified since UpdateConfig replaces it instead.
+func (c *IngressClient) defaultTags() map[string]string {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+
+	return c.tags
+}
+
+// batchConfig returns the maximum batch size and the flush interval.
+func (c *IngressClient) batchConfig() (uint, time.Duration) {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+
+	return c.batchMaxSize, c.batchFlushInterval
+}
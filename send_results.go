@@ -0,0 +1,181 @@
+package loggregator
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// EnvelopeID identifies an envelope emitted with EmitTracked. IDs are unique
+// per client.
+type EnvelopeID uint64
+
+// SendStatus is the outcome of sending an envelope.
+type SendStatus int
+
+// The outcomes of sending an envelope.
+const (
+	// Sent means the envelope was written to the stream to the agent or,
+	// with at-least-once delivery, acknowledged by the agent.
+	Sent SendStatus = iota
+
+	// Dropped means the envelope was rejected before it was sent, e.g.
+	// because the queue was full or the client was closed.
+	Dropped
+
+	// Failed means the batch holding the envelope could not be sent.
+	Failed
+)
+
+// String returns the name of the status.
+func (s SendStatus) String() string {
+	switch s {
+	case Sent:
+		return "sent"
+	case Dropped:
+		return "dropped"
+	case Failed:
+		return "failed"
+	default:
+		return fmt.Sprintf("SendStatus(%d)", int(s))
+	}
+}
+
+// SendResult is the outcome of sending an envelope emitted with
+// EmitTracked. Err is nil if the envelope was sent or if a middleware
+// discarded it without an error.
+type SendResult struct {
+	ID     EnvelopeID
+	Status SendStatus
+	Err    error
+}
+
+// WithSendResults enables the delivery of a SendResult for every envelope
+// emitted with EmitTracked on the channel returned by Results. The channel
+// buffers up to size results. Results that do not fit are discarded rather
+// than blocking the sender and counted by DroppedResults.
+func WithSendResults(size int) IngressOption {
+	return func(c *IngressClient) {
+		c.results = make(chan SendResult, size)
+		c.tracked = make(map[*loggregator_v2.Envelope]EnvelopeID)
+	}
+}
+
+// Results returns the channel the outcomes of envelopes emitted with
+// EmitTracked are delivered on. It returns nil unless the client was created
+// with WithSendResults.
+func (c *IngressClient) Results() <-chan SendResult {
+	return c.results
+}
+
+// DroppedResults returns the number of results that were discarded because
+// the Results channel was full.
+func (c *IngressClient) DroppedResults() uint64 {
+	return atomic.LoadUint64(&c.droppedResults)
+}
+
+// EmitTracked emits the envelope like EmitEnvelope and returns the ID its
+// outcome is reported with on the Results channel. At most one result is
+// delivered per ID, and exactly one unless it is dropped because the channel
+// is full. The ID follows the envelope through middleware, even if a
+// middleware replaces the envelope. The envelope must not be emitted again
+// while its result is pending.
+func (c *IngressClient) EmitTracked(e *loggregator_v2.Envelope) EnvelopeID {
+	e = c.copyOnWrite(e)
+	id := c.nextID()
+	r := c.prepare(e)
+
+	// The ID is assigned to the envelope that reaches the queue. Should a
+	// middleware emit more than one envelope, only the first is tracked.
+	var pending bool
+	err := c.emitWithHooks(e, chain(c.stages, func(e *loggregator_v2.Envelope) error {
+		if pending {
+			return c.queue(e, r)
+		}
+
+		c.track(e, id)
+		if err := c.queue(e, r); err != nil {
+			c.untrack(e)
+			return err
+		}
+		pending = true
+
+		return nil
+	}))
+	if err != nil {
+		c.handleDeadLetters([]*loggregator_v2.Envelope{e}, err)
+	}
+	if !pending {
+		c.deliver(SendResult{ID: id, Status: Dropped, Err: err})
+	}
+
+	return id
+}
+
+// nextID returns the next envelope ID.
+func (c *IngressClient) nextID() EnvelopeID {
+	c.trackMu.Lock()
+	defer c.trackMu.Unlock()
+
+	c.lastID++
+	return c.lastID
+}
+
+// track assigns the ID to the envelope that is queued for sending. It must
+// be called before the envelope is queued since the sender may report it
+// right away.
+func (c *IngressClient) track(e *loggregator_v2.Envelope, id EnvelopeID) {
+	c.trackMu.Lock()
+	defer c.trackMu.Unlock()
+
+	if c.tracked != nil {
+		c.tracked[e] = id
+	}
+}
+
+// untrack removes the ID of an envelope that could not be queued.
+func (c *IngressClient) untrack(e *loggregator_v2.Envelope) {
+	c.trackMu.Lock()
+	defer c.trackMu.Unlock()
+
+	delete(c.tracked, e)
+}
+
+// report delivers the outcome of every tracked envelope of the given
+// envelopes.
+func (c *IngressClient) report(envelopes []*loggregator_v2.Envelope, status SendStatus, err error) {
+	if c.results == nil {
+		return
+	}
+
+	var results []SendResult
+	c.trackMu.Lock()
+	for _, e := range envelopes {
+		id, ok := c.tracked[e]
+		if !ok {
+			continue
+		}
+		delete(c.tracked, e)
+		results = append(results, SendResult{ID: id, Status: status, Err: err})
+	}
+	c.trackMu.Unlock()
+
+	for _, r := range results {
+		c.deliver(r)
+	}
+}
+
+// deliver hands the result to the Results channel without blocking the
+// sender. If the channel is full, the result is counted and discarded.
+func (c *IngressClient) deliver(r SendResult) {
+	if c.results == nil {
+		return
+	}
+
+	select {
+	case c.results <- r:
+	default:
+		atomic.AddUint64(&c.droppedResults, 1)
+	}
+}
@@ -0,0 +1,136 @@
+package loggregator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/units"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Send results", func() {
+	var server *testIngressServer
+
+	BeforeEach(func() {
+		var err error
+		server, err = newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.stop()
+	})
+
+	It("reports sent envelopes", func() {
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false, loggregator.WithSendResults(10))
+
+		first := client.EmitTracked(&loggregator_v2.Envelope{SourceId: "some-source"})
+		second := client.EmitTracked(&loggregator_v2.Envelope{SourceId: "some-source"})
+		Expect(first).NotTo(Equal(second))
+
+		receiveEnvelopes(server.receivers, 2)
+
+		var result loggregator.SendResult
+		Eventually(client.Results()).Should(Receive(&result))
+		Expect(result).To(Equal(loggregator.SendResult{ID: first, Status: loggregator.Sent}))
+		Eventually(client.Results()).Should(Receive(&result))
+		Expect(result).To(Equal(loggregator.SendResult{ID: second, Status: loggregator.Sent}))
+	})
+
+	It("reports dropped envelopes", func() {
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false, loggregator.WithSendResults(10))
+		Expect(client.CloseSend()).To(Succeed())
+
+		id := client.EmitTracked(&loggregator_v2.Envelope{SourceId: "some-source"})
+
+		var result loggregator.SendResult
+		Expect(client.Results()).To(Receive(&result))
+		Expect(result).To(Equal(loggregator.SendResult{
+			ID:     id,
+			Status: loggregator.Dropped,
+			Err:    loggregator.ErrClosed,
+		}))
+	})
+
+	It("reports envelopes that could not be sent", func() {
+		server.stop()
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false, loggregator.WithSendResults(10))
+
+		id := client.EmitTracked(&loggregator_v2.Envelope{SourceId: "some-source"})
+
+		var result loggregator.SendResult
+		Eventually(client.Results(), 5).Should(Receive(&result))
+		Expect(result.ID).To(Equal(id))
+		Expect(result.Status).To(Equal(loggregator.Failed))
+		Expect(result.Err).To(HaveOccurred())
+	})
+
+	It("does not report envelopes that are not tracked", func() {
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false, loggregator.WithSendResults(10))
+
+		client.EmitLog("message")
+		receiveEnvelopes(server.receivers, 1)
+
+		Consistently(client.Results()).ShouldNot(Receive())
+	})
+
+	It("reports envelopes that a middleware replaced", func() {
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false,
+			loggregator.WithSendResults(10),
+			loggregator.WithMiddleware(loggregator.GaugeUnitMiddleware(false)),
+		)
+
+		id := client.EmitTracked(&loggregator_v2.Envelope{
+			SourceId: "some-source",
+			Message: &loggregator_v2.Envelope_Gauge{
+				Gauge: &loggregator_v2.Gauge{
+					Metrics: map[string]*loggregator_v2.GaugeValue{
+						"memory": {Unit: "Bytes", Value: 1},
+					},
+				},
+			},
+		})
+
+		env := receiveEnvelopes(server.receivers, 1)[0]
+		Expect(env.GetGauge().GetMetrics()["memory"].GetUnit()).To(Equal(units.Bytes))
+
+		var result loggregator.SendResult
+		Eventually(client.Results()).Should(Receive(&result))
+		Expect(result).To(Equal(loggregator.SendResult{ID: id, Status: loggregator.Sent}))
+	})
+
+	It("reports envelopes that a middleware discarded", func() {
+		discard := func(loggregator.EmitFunc) loggregator.EmitFunc {
+			return func(*loggregator_v2.Envelope) error { return nil }
+		}
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false,
+			loggregator.WithSendResults(10),
+			loggregator.WithMiddleware(discard),
+		)
+
+		id := client.EmitTracked(&loggregator_v2.Envelope{SourceId: "some-source"})
+
+		var result loggregator.SendResult
+		Expect(client.Results()).To(Receive(&result))
+		Expect(result).To(Equal(loggregator.SendResult{ID: id, Status: loggregator.Dropped}))
+	})
+
+	It("counts the results that do not fit the channel", func() {
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false, loggregator.WithSendResults(1))
+		Expect(client.CloseSend()).To(Succeed())
+
+		client.EmitTracked(&loggregator_v2.Envelope{SourceId: "some-source"})
+		client.EmitTracked(&loggregator_v2.Envelope{SourceId: "some-source"})
+
+		Expect(client.Results()).To(Receive())
+		Expect(client.DroppedResults()).To(Equal(uint64(1)))
+	})
+})
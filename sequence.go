@@ -0,0 +1,127 @@
+package loggregator
+
+import (
+	"strconv"
+	"sync"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// sequenceTag is the tag that holds the sequence number of an envelope.
+const sequenceTag = "sequence"
+
+// WithSequenceNumbers configures the client to tag every envelope with a
+// sequence number that starts at 1 and is incremented for every envelope
+// of the same source ID and instance ID. Consumers may use a GapDetector to
+// find lost envelopes.
+func WithSequenceNumbers() IngressOption {
+	return func(c *IngressClient) {
+		c.sequencer = newSequencer()
+	}
+}
+
+// sequencer assigns sequence numbers per source. It is only used from the
+// sender go routine and is therefore not safe for concurrent use.
+type sequencer struct {
+	last map[sourceKey]uint64
+}
+
+type sourceKey struct {
+	sourceID   string
+	instanceID string
+}
+
+func newSequencer() *sequencer {
+	return &sequencer{
+		last: make(map[sourceKey]uint64),
+	}
+}
+
+// tag sets the next sequence number of the envelope's source on the
+// envelope.
+func (s *sequencer) tag(e *loggregator_v2.Envelope) {
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
+	}
+
+	k := sourceKey{sourceID: e.GetSourceId(), instanceID: e.GetInstanceId()}
+	s.last[k]++
+	e.Tags[sequenceTag] = strconv.FormatUint(s.last[k], 10)
+}
+
+// Gap is a range of envelopes of a source that were not received.
+type Gap struct {
+	SourceID   string
+	InstanceID string
+
+	// From and To are the first and the last missing sequence number.
+	From uint64
+	To   uint64
+}
+
+// Count returns the number of missing envelopes.
+func (g Gap) Count() uint64 {
+	return g.To - g.From + 1
+}
+
+// GapDetector finds envelopes that were lost between a client created with
+// WithSequenceNumbers and a consumer. Envelopes without a sequence number
+// are ignored. A sequence number that is not larger than the previous one of
+// the same source is considered a restart of the emitting client. It is safe
+// for concurrent use.
+type GapDetector struct {
+	onGap func(Gap)
+
+	mu   sync.Mutex
+	last map[sourceKey]uint64
+	lost uint64
+}
+
+// NewGapDetector returns a GapDetector that calls onGap for every gap it
+// finds. onGap may be nil.
+func NewGapDetector(onGap func(Gap)) *GapDetector {
+	return &GapDetector{
+		onGap: onGap,
+		last:  make(map[sourceKey]uint64),
+	}
+}
+
+// Observe checks the sequence number of the envelope against the previous
+// envelope of its source.
+func (d *GapDetector) Observe(e *loggregator_v2.Envelope) {
+	seq, err := strconv.ParseUint(e.GetTags()[sequenceTag], 10, 64)
+	if err != nil {
+		return
+	}
+
+	k := sourceKey{sourceID: e.GetSourceId(), instanceID: e.GetInstanceId()}
+
+	d.mu.Lock()
+	last, ok := d.last[k]
+	d.last[k] = seq
+	if !ok || seq <= last+1 {
+		d.mu.Unlock()
+		return
+	}
+
+	g := Gap{
+		SourceID:   k.sourceID,
+		InstanceID: k.instanceID,
+		From:       last + 1,
+		To:         seq - 1,
+	}
+	d.lost += g.Count()
+	d.mu.Unlock()
+
+	if d.onGap != nil {
+		d.onGap(g)
+	}
+}
+
+// Lost returns the total number of missing envelopes found so far.
+func (d *GapDetector) Lost() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.lost
+}
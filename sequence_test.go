@@ -0,0 +1,88 @@
+package loggregator_test
+
+import (
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sequence numbers", func() {
+	It("tags envelopes with sequence numbers per source", func() {
+		server, err := newTestIngressServer(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.start()).To(Succeed())
+		defer server.stop()
+
+		client, _, _ := buildIngressClient(server.addr, 50*time.Millisecond, false, loggregator.WithSequenceNumbers())
+
+		client.EmitLog("message", loggregator.WithSourceInfo("source-a", "type", "0"))
+		client.EmitLog("message", loggregator.WithSourceInfo("source-b", "type", "0"))
+		client.EmitLog("message", loggregator.WithSourceInfo("source-a", "type", "0"))
+		client.EmitLog("message", loggregator.WithSourceInfo("source-a", "type", "1"))
+
+		envelopes := receiveEnvelopes(server.receivers, 4)
+		Expect(envelopes[0].Tags).To(HaveKeyWithValue("sequence", "1"))
+		Expect(envelopes[1].Tags).To(HaveKeyWithValue("sequence", "1"))
+		Expect(envelopes[2].Tags).To(HaveKeyWithValue("sequence", "2"))
+		Expect(envelopes[3].Tags).To(HaveKeyWithValue("sequence", "1"))
+	})
+
+	Describe("GapDetector", func() {
+		var (
+			gaps     []loggregator.Gap
+			detector *loggregator.GapDetector
+		)
+
+		BeforeEach(func() {
+			gaps = nil
+			detector = loggregator.NewGapDetector(func(g loggregator.Gap) {
+				gaps = append(gaps, g)
+			})
+		})
+
+		observe := func(sourceID string, seqs ...int) {
+			for _, seq := range seqs {
+				detector.Observe(&loggregator_v2.Envelope{
+					SourceId: sourceID,
+					Tags:     map[string]string{"sequence": strconv.Itoa(seq)},
+				})
+			}
+		}
+
+		It("reports missing ranges per source", func() {
+			observe("source-a", 1, 2, 5)
+			observe("source-b", 3, 4)
+			observe("source-a", 6, 10)
+
+			Expect(gaps).To(Equal([]loggregator.Gap{
+				{SourceID: "source-a", From: 3, To: 4},
+				{SourceID: "source-a", From: 7, To: 9},
+			}))
+			Expect(gaps[1].Count()).To(Equal(uint64(3)))
+			Expect(detector.Lost()).To(Equal(uint64(5)))
+		})
+
+		It("treats a lower sequence number as a restart", func() {
+			observe("source-a", 1, 2, 3, 1, 2)
+
+			Expect(gaps).To(BeEmpty())
+		})
+
+		It("ignores envelopes without a sequence number", func() {
+			observe("source-a", 1)
+			detector.Observe(&loggregator_v2.Envelope{SourceId: "source-a"})
+			observe("source-a", 2)
+
+			Expect(gaps).To(BeEmpty())
+		})
+	})
+})
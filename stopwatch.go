@@ -0,0 +1,90 @@
+package loggregator
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/units"
+)
+
+// StopwatchOption is the option type passed into StartTimer.
+type StopwatchOption func(*Stopwatch)
+
+// WithStopwatchGauge configures the stopwatch to emit the elapsed time as a
+// gauge in nanoseconds instead of a timer. The gauge has the name of the
+// stopwatch.
+func WithStopwatchGauge() StopwatchOption {
+	return func(s *Stopwatch) {
+		s.asGauge = true
+	}
+}
+
+// WithStopwatchTags adds the given tags to the envelope emitted by the
+// stopwatch.
+func WithStopwatchTags(tags map[string]string) StopwatchOption {
+	return func(s *Stopwatch) {
+		for k, v := range tags {
+			s.tags[k] = v
+		}
+	}
+}
+
+// Stopwatch measures the time between its creation by StartTimer and Stop
+// and emits it. For example:
+//
+//	sw := client.StartTimer("db_query")
+//	defer sw.Stop()
+type Stopwatch struct {
+	client  *IngressClient
+	name    string
+	start   time.Time
+	asGauge bool
+	tags    map[string]string
+
+	once sync.Once
+}
+
+// StartTimer returns a running Stopwatch with the given name.
+func (c *IngressClient) StartTimer(name string, opts ...StopwatchOption) *Stopwatch {
+	s := &Stopwatch{
+		client: c,
+		name:   name,
+		tags:   make(map[string]string),
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+	s.start = time.Now()
+
+	return s
+}
+
+// Stop emits the time elapsed since the stopwatch was started and returns
+// it. The options are applied to the emitted envelope, e.g. to add tags that
+// are only known once the measured operation is done. Only the first call to
+// Stop emits an envelope.
+func (s *Stopwatch) Stop(opts ...EmitTimerOption) time.Duration {
+	stop := time.Now()
+	elapsed := stop.Sub(s.start)
+
+	s.once.Do(func() {
+		if s.asGauge {
+			gaugeOpts := []EmitGaugeOption{
+				WithGaugeValue(s.name, float64(elapsed), units.Nanos),
+				WithEnvelopeTags(s.tags),
+			}
+			for _, o := range opts {
+				gaugeOpts = append(gaugeOpts, EmitGaugeOption(o))
+			}
+			s.client.EmitGauge(gaugeOpts...)
+
+			return
+		}
+
+		timerOpts := append([]EmitTimerOption{WithEnvelopeTags(s.tags)}, opts...)
+		s.client.EmitTimer(s.name, s.start, stop, timerOpts...)
+	})
+
+	return elapsed
+}
@@ -0,0 +1,224 @@
+// Package supportbundle samples envelopes into an archive that can be
+// attached to a support request. Payloads are redacted, so the archive shows
+// the shape of the telemetry without the customers' logs.
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// Redacted replaces the values of redacted tags.
+const Redacted = "[REDACTED]"
+
+// SamplerOption configures a Sampler.
+type SamplerOption func(*Sampler)
+
+// WithSamplesPerType configures the number of envelopes kept for every
+// source ID and message type. It defaults to 10.
+func WithSamplesPerType(k int) SamplerOption {
+	return func(s *Sampler) {
+		s.k = k
+	}
+}
+
+// WithRedactedTags configures tags whose values are replaced with Redacted,
+// e.g. tags that carry user names or URLs.
+func WithRedactedTags(names ...string) SamplerOption {
+	return func(s *Sampler) {
+		for _, n := range names {
+			s.redactedTags[n] = true
+		}
+	}
+}
+
+// Stream identifies the envelopes of one message type from one source.
+type Stream struct {
+	SourceID string `json:"source_id"`
+	Type     string `json:"type"`
+}
+
+// Summary counts the envelopes of a stream.
+type Summary struct {
+	Stream
+	Seen    uint64 `json:"seen"`
+	Sampled int    `json:"sampled"`
+}
+
+// Sampler keeps the first envelopes of every source ID and message type. It
+// is safe for concurrent use. It should be created with the NewSampler
+// constructor.
+type Sampler struct {
+	k            int
+	redactedTags map[string]bool
+
+	mu      sync.Mutex
+	seen    map[Stream]uint64
+	samples map[Stream][]*loggregator_v2.Envelope
+}
+
+// NewSampler returns an empty Sampler.
+func NewSampler(opts ...SamplerOption) *Sampler {
+	s := &Sampler{
+		k:            10,
+		redactedTags: make(map[string]bool),
+		seen:         make(map[Stream]uint64),
+		samples:      make(map[Stream][]*loggregator_v2.Envelope),
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	return s
+}
+
+// Add counts the envelopes and keeps a redacted copy of those that fit into
+// the sample of their stream.
+func (s *Sampler) Add(envelopes ...*loggregator_v2.Envelope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range envelopes {
+		st := Stream{SourceID: e.GetSourceId(), Type: envelope.Type(e).String()}
+		s.seen[st]++
+
+		if len(s.samples[st]) < s.k {
+			s.samples[st] = append(s.samples[st], s.redact(e))
+		}
+	}
+}
+
+// Summaries returns the counts of every stream, ordered by source ID and
+// type.
+func (s *Sampler) Summaries() []Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := make([]Summary, 0, len(s.seen))
+	for st, n := range s.seen {
+		summaries = append(summaries, Summary{
+			Stream:  st,
+			Seen:    n,
+			Sampled: len(s.samples[st]),
+		})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].SourceID != summaries[j].SourceID {
+			return summaries[i].SourceID < summaries[j].SourceID
+		}
+		return summaries[i].Type < summaries[j].Type
+	})
+
+	return summaries
+}
+
+// Samples returns the sampled envelopes, ordered like the summaries.
+func (s *Sampler) Samples() []*loggregator_v2.Envelope {
+	summaries := s.Summaries()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var envelopes []*loggregator_v2.Envelope
+	for _, summary := range summaries {
+		envelopes = append(envelopes, s.samples[summary.Stream]...)
+	}
+
+	return envelopes
+}
+
+// WriteBundle writes a gzipped tar archive with summary.json, the counts of
+// every stream, and envelopes.json, the sampled envelopes as one JSON object
+// per line.
+func (s *Sampler) WriteBundle(w io.Writer) error {
+	summary, err := json.MarshalIndent(s.Summaries(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var envelopes bytes.Buffer
+	m := jsonpb.Marshaler{OrigName: true}
+	for _, e := range s.Samples() {
+		if err := m.Marshal(&envelopes, e); err != nil {
+			return err
+		}
+		envelopes.WriteByte('\n')
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	now := time.Now()
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"summary.json", append(summary, '\n')},
+		{"envelopes.json", envelopes.Bytes()},
+	}
+
+	for _, f := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0644,
+			Size:    int64(len(f.data)),
+			ModTime: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// redact returns a copy of the envelope without log or event payloads and
+// with the redacted tags replaced.
+func (s *Sampler) redact(e *loggregator_v2.Envelope) *loggregator_v2.Envelope {
+	e = envelope.DeepCopy(e)
+
+	switch m := e.GetMessage().(type) {
+	case *loggregator_v2.Envelope_Log:
+		m.Log.Payload = []byte(redactedBytes(len(m.Log.GetPayload())))
+	case *loggregator_v2.Envelope_Event:
+		m.Event.Body = redactedBytes(len(m.Event.GetBody()))
+	}
+
+	for name := range s.redactedTags {
+		if _, ok := e.Tags[name]; ok {
+			e.Tags[name] = Redacted
+		}
+		if _, ok := e.DeprecatedTags[name]; ok {
+			e.DeprecatedTags[name] = &loggregator_v2.Value{
+				Data: &loggregator_v2.Value_Text{Text: Redacted},
+			}
+		}
+	}
+
+	return e
+}
+
+func redactedBytes(n int) string {
+	return fmt.Sprintf("[REDACTED %d bytes]", n)
+}
@@ -0,0 +1,120 @@
+package supportbundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/supportbundle"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sampler", func() {
+	It("keeps k envelopes per source and type", func() {
+		s := supportbundle.NewSampler(supportbundle.WithSamplesPerType(2))
+
+		for i := 0; i < 3; i++ {
+			s.Add(buildLog("a", "secret"), buildCounter("a"), buildLog("b", "secret"))
+		}
+
+		Expect(s.Summaries()).To(Equal([]supportbundle.Summary{
+			{Stream: supportbundle.Stream{SourceID: "a", Type: "counter"}, Seen: 3, Sampled: 2},
+			{Stream: supportbundle.Stream{SourceID: "a", Type: "log"}, Seen: 3, Sampled: 2},
+			{Stream: supportbundle.Stream{SourceID: "b", Type: "log"}, Seen: 3, Sampled: 2},
+		}))
+		Expect(s.Samples()).To(HaveLen(6))
+	})
+
+	It("redacts payloads and configured tags", func() {
+		s := supportbundle.NewSampler(supportbundle.WithRedactedTags("user", "uri"))
+
+		e := buildLog("a", "secret")
+		e.Tags = map[string]string{"user": "alice", "job": "router"}
+		e.DeprecatedTags = map[string]*loggregator_v2.Value{
+			"uri": {Data: &loggregator_v2.Value_Text{Text: "/private"}},
+		}
+		event := &loggregator_v2.Envelope{
+			SourceId: "a",
+			Message: &loggregator_v2.Envelope_Event{
+				Event: &loggregator_v2.Event{Title: "title", Body: "body"},
+			},
+		}
+		s.Add(e, event)
+
+		samples := s.Samples()
+		Expect(samples).To(HaveLen(2))
+		Expect(samples[0].GetEvent().GetTitle()).To(Equal("title"))
+		Expect(samples[0].GetEvent().GetBody()).To(Equal("[REDACTED 4 bytes]"))
+		Expect(string(samples[1].GetLog().GetPayload())).To(Equal("[REDACTED 6 bytes]"))
+		Expect(samples[1].Tags).To(Equal(map[string]string{"user": supportbundle.Redacted, "job": "router"}))
+		Expect(samples[1].DeprecatedTags["uri"].GetText()).To(Equal(supportbundle.Redacted))
+
+		Expect(string(e.GetLog().GetPayload())).To(Equal("secret"))
+		Expect(e.Tags["user"]).To(Equal("alice"))
+	})
+
+	It("writes a bundle with the summary and the samples", func() {
+		s := supportbundle.NewSampler()
+		s.Add(buildLog("a", "secret"), buildCounter("a"))
+
+		var buf bytes.Buffer
+		Expect(s.WriteBundle(&buf)).To(Succeed())
+
+		files := readBundle(&buf)
+		Expect(files).To(HaveKey("summary.json"))
+		Expect(files).To(HaveKey("envelopes.json"))
+
+		var summaries []supportbundle.Summary
+		Expect(json.Unmarshal(files["summary.json"], &summaries)).To(Succeed())
+		Expect(summaries).To(HaveLen(2))
+
+		lines := strings.Split(strings.TrimSpace(string(files["envelopes.json"])), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(ContainSubstring(`"source_id":"a"`))
+		Expect(string(files["envelopes.json"])).NotTo(ContainSubstring("secret"))
+	})
+})
+
+func buildLog(sourceID, payload string) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId: sourceID,
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{Payload: []byte(payload)},
+		},
+	}
+}
+
+func buildCounter(sourceID string) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId: sourceID,
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: "requests", Delta: 1},
+		},
+	}
+}
+
+func readBundle(buf *bytes.Buffer) map[string][]byte {
+	gz, err := gzip.NewReader(buf)
+	Expect(err).NotTo(HaveOccurred())
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err != nil {
+			break
+		}
+
+		b, err := ioutil.ReadAll(tr)
+		Expect(err).NotTo(HaveOccurred())
+		files[h.Name] = b
+	}
+
+	return files
+}
@@ -0,0 +1,13 @@
+package supportbundle_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSupportbundle(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Support Bundle Suite")
+}
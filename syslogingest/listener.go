@@ -0,0 +1,176 @@
+// Package syslogingest accepts syslog messages over UDP and TCP and emits
+// them as logs, so that legacy appliances that only speak syslog can inject
+// logs into the v2 pipeline. Both RFC 5424 and RFC 3164 messages are
+// accepted.
+package syslogingest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+)
+
+// maxMessageSize is the size of the largest message accepted.
+const maxMessageSize = 64 * 1024
+
+// ListenerOption configures a Listener.
+type ListenerOption func(*Listener)
+
+// WithSourceID configures the source ID of every log. By default, it is
+// the app name or hostname of the message.
+func WithSourceID(id string) ListenerOption {
+	return func(l *Listener) {
+		l.sourceID = id
+	}
+}
+
+// WithTags adds the given tags to every log.
+func WithTags(tags map[string]string) ListenerOption {
+	return func(l *Listener) {
+		for k, v := range tags {
+			l.tags[k] = v
+		}
+	}
+}
+
+// WithLogger configures the logger for malformed messages and failed
+// connections. It defaults to discarding them.
+func WithLogger(logger loggregator.Logger) ListenerOption {
+	return func(l *Listener) {
+		l.logger = logger
+	}
+}
+
+// Listener converts syslog messages to logs and emits them to a sink,
+// e.g. an IngressClient. It should be created with the NewListener
+// constructor.
+type Listener struct {
+	sink     loggregator.Sink
+	sourceID string
+	tags     map[string]string
+	logger   loggregator.Logger
+}
+
+// NewListener returns a Listener that emits to the sink.
+func NewListener(sink loggregator.Sink, opts ...ListenerOption) *Listener {
+	l := &Listener{
+		sink:   sink,
+		tags:   make(map[string]string),
+		logger: log.New(ioutil.Discard, "", 0),
+	}
+
+	for _, o := range opts {
+		o(l)
+	}
+
+	return l
+}
+
+// ServeUDP reads a message from every datagram received on conn. It blocks
+// until reading from conn fails, e.g. because it is closed, and returns
+// that error.
+func (l *Listener) ServeUDP(conn net.PacketConn) error {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		l.handle(buf[:n])
+	}
+}
+
+// ServeTCP accepts connections on lis and reads messages framed by octet
+// counting or terminated by newlines, see RFC 6587. It blocks until
+// accepting fails, e.g. because lis is closed, and returns that error.
+func (l *Listener) ServeTCP(lis net.Listener) error {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+
+		go l.serveConn(conn)
+	}
+}
+
+func (l *Listener) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReaderSize(conn, maxMessageSize)
+	for {
+		msg, err := readFrame(r)
+		if err != nil {
+			if err != io.EOF {
+				l.logger.Printf("Error while reading syslog from %s: %s", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		l.handle(msg)
+	}
+}
+
+// readFrame reads a message that is prefixed with its length or terminated
+// by a newline.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if first[0] < '0' || first[0] > '9' {
+		msg, err := r.ReadSlice('\n')
+		if err == io.EOF && len(msg) > 0 {
+			return msg, nil
+		}
+		return msg, err
+	}
+
+	prefix, err := r.ReadSlice(' ')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(string(bytes.TrimSuffix(prefix, []byte(" "))))
+	if err != nil {
+		return nil, err
+	}
+	if n > maxMessageSize {
+		return nil, fmt.Errorf("message length %d exceeds %d bytes", n, maxMessageSize)
+	}
+
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func (l *Listener) handle(msg []byte) {
+	e, err := Parse(msg, time.Now())
+	if err != nil {
+		return
+	}
+
+	if l.sourceID != "" {
+		e.SourceId = l.sourceID
+	}
+	for k, v := range l.tags {
+		e.Tags[k] = v
+	}
+
+	if err := l.sink.EmitEnvelope(e); err != nil {
+		l.logger.Printf("Error while emitting syslog message: %s", err)
+	}
+}
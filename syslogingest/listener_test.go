@@ -0,0 +1,80 @@
+package syslogingest_test
+
+import (
+	"net"
+	"strconv"
+
+	"code.cloudfoundry.org/go-loggregator/fakes"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/syslogingest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Listener", func() {
+	var (
+		sink     *fakes.FakeSink
+		listener *syslogingest.Listener
+	)
+
+	BeforeEach(func() {
+		sink = &fakes.FakeSink{}
+		listener = syslogingest.NewListener(
+			sink,
+			syslogingest.WithSourceID("some-source"),
+			syslogingest.WithTags(map[string]string{"appliance": "router"}),
+		)
+	})
+
+	payloads := func() []string {
+		var p []string
+		for i := 0; i < sink.EmitEnvelopeCallCount(); i++ {
+			e := sink.EmitEnvelopeArgsForCall(i)
+			p = append(p, string(e.GetLog().GetPayload()))
+		}
+		return p
+	}
+
+	It("emits a log for every datagram", func() {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		go listener.ServeUDP(conn)
+
+		client, err := net.Dial("udp", conn.LocalAddr().String())
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		_, err = client.Write([]byte("<14>Mar  4 09:30:00 some-host app: some-message"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(sink.EmitEnvelopeCallCount).Should(Equal(1))
+		e := sink.EmitEnvelopeArgsForCall(0)
+		Expect(e.SourceId).To(Equal("some-source"))
+		Expect(e.Tags).To(HaveKeyWithValue("appliance", "router"))
+		Expect(e.Tags).To(HaveKeyWithValue("app_name", "app"))
+		Expect(e.GetLog().GetType()).To(Equal(loggregator_v2.Log_OUT))
+	})
+
+	It("reads octet counted and newline delimited messages over TCP", func() {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer lis.Close()
+		go listener.ServeTCP(lis)
+
+		client, err := net.Dial("tcp", lis.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		msg := "<14>1 2019-03-04T09:30:00Z some-host app - - - counted\nmessage"
+		_, err = client.Write([]byte(strconv.Itoa(len(msg)) + " " + msg + "<14>first\n<14>second\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Close()).To(Succeed())
+
+		Eventually(payloads).Should(Equal([]string{
+			"counted\nmessage",
+			"first",
+			"second",
+		}))
+	})
+})
@@ -0,0 +1,198 @@
+package syslogingest
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/rfc5424"
+)
+
+// tagsStructuredDataID is the ID of the structured data that
+// loggregator_v2.Envelope.Syslog writes the tags of an envelope to. Its
+// parameters become tags without a prefix.
+const tagsStructuredDataID = "tags@47450"
+
+// defaultPriority is the priority of messages without one, user.notice as
+// defined by RFC 3164.
+const defaultPriority = 13
+
+// errSeverity is the lowest severity that is emitted as stderr.
+const errSeverity = 3
+
+var severities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+var facilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// ErrEmptyMessage is returned by Parse for messages without content.
+var ErrEmptyMessage = errors.New("syslogingest: empty message")
+
+// Parse converts an RFC 5424 or RFC 3164 syslog message to a log envelope.
+// Messages with a severity of err or more severe are emitted as stderr.
+// The header fields and the structured data become tags, the latter named
+// "<SD-ID>.<param>" except for the tags written by
+// loggregator_v2.Envelope.Syslog. The source ID is the app name or, if the
+// message has none, the hostname. Messages that do not follow RFC 3164 are
+// taken as they are.
+func Parse(b []byte, now time.Time) (*loggregator_v2.Envelope, error) {
+	b = bytes.TrimRight(b, "\r\n\x00")
+	if len(b) == 0 {
+		return nil, ErrEmptyMessage
+	}
+
+	if isRFC5424(b) {
+		var m rfc5424.Message
+		if err := m.UnmarshalBinary(b); err == nil {
+			return fromRFC5424(m, now), nil
+		}
+	}
+
+	return parseRFC3164(b, now), nil
+}
+
+// isRFC5424 reports whether the priority of the message is followed by the
+// version of RFC 5424.
+func isRFC5424(b []byte) bool {
+	_, rest, ok := parsePriority(b)
+	return ok && bytes.HasPrefix(rest, []byte("1 "))
+}
+
+func parsePriority(b []byte) (int, []byte, bool) {
+	if len(b) < 3 || b[0] != '<' {
+		return 0, b, false
+	}
+
+	// PRIVAL has at most 3 digits.
+	head := b
+	if len(head) > 5 {
+		head = head[:5]
+	}
+	end := bytes.IndexByte(head, '>')
+	if end < 2 {
+		return 0, b, false
+	}
+
+	p, err := strconv.Atoi(string(b[1:end]))
+	if err != nil || p < 0 || p > 191 {
+		return 0, b, false
+	}
+
+	return p, b[end+1:], true
+}
+
+func fromRFC5424(m rfc5424.Message, now time.Time) *loggregator_v2.Envelope {
+	e := newEnvelope(int(m.Priority), m.Timestamp, now, bytes.TrimRight(m.Message, "\n"))
+	setTag(e, "hostname", m.Hostname)
+	setTag(e, "app_name", m.AppName)
+	setTag(e, "proc_id", m.ProcessID)
+	setTag(e, "msg_id", m.MessageID)
+
+	for _, sd := range m.StructuredData {
+		for _, p := range sd.Parameters {
+			if sd.ID == tagsStructuredDataID {
+				e.Tags[p.Name] = p.Value
+				continue
+			}
+			e.Tags[sd.ID+"."+p.Name] = p.Value
+		}
+	}
+
+	e.SourceId = m.AppName
+	if e.SourceId == "" {
+		e.SourceId = m.Hostname
+	}
+
+	return e
+}
+
+// parseRFC3164 parses "<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG". The
+// timestamp has no year and no time zone, the current year and the location
+// of now are assumed.
+func parseRFC3164(b []byte, now time.Time) *loggregator_v2.Envelope {
+	pri, rest, ok := parsePriority(b)
+	if !ok {
+		pri = defaultPriority
+	}
+
+	const stampLen = len(time.Stamp)
+	if len(rest) <= stampLen || rest[stampLen] != ' ' {
+		return newEnvelope(pri, time.Time{}, now, rest)
+	}
+
+	ts, err := time.ParseInLocation(time.Stamp, string(rest[:stampLen]), now.Location())
+	if err != nil {
+		return newEnvelope(pri, time.Time{}, now, rest)
+	}
+	ts = ts.AddDate(now.Year(), 0, 0)
+	rest = rest[stampLen+1:]
+
+	var hostname, appName, procID string
+	if i := bytes.IndexByte(rest, ' '); i > 0 {
+		hostname = string(rest[:i])
+		rest = rest[i+1:]
+	}
+
+	if i := bytes.IndexAny(rest, ":[ "); i > 0 && rest[i] != ' ' {
+		appName = string(rest[:i])
+		rest = rest[i:]
+
+		if rest[0] == '[' {
+			if j := bytes.IndexByte(rest, ']'); j > 0 {
+				procID = string(rest[1:j])
+				rest = rest[j+1:]
+			}
+		}
+		rest = bytes.TrimPrefix(rest, []byte(":"))
+		rest = bytes.TrimPrefix(rest, []byte(" "))
+	}
+
+	e := newEnvelope(pri, ts, now, rest)
+	setTag(e, "hostname", hostname)
+	setTag(e, "app_name", appName)
+	setTag(e, "proc_id", procID)
+
+	e.SourceId = appName
+	if e.SourceId == "" {
+		e.SourceId = hostname
+	}
+
+	return e
+}
+
+func newEnvelope(priority int, ts, now time.Time, message []byte) *loggregator_v2.Envelope {
+	if ts.IsZero() {
+		ts = now
+	}
+
+	e := &loggregator_v2.Envelope{
+		Timestamp: ts.UnixNano(),
+		Tags: map[string]string{
+			"severity": severities[priority%8],
+			"facility": facilities[priority/8],
+		},
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{
+				Payload: append([]byte(nil), message...),
+				Type:    loggregator_v2.Log_OUT,
+			},
+		},
+	}
+
+	if priority%8 <= errSeverity {
+		e.GetLog().Type = loggregator_v2.Log_ERR
+	}
+
+	return e
+}
+
+func setTag(e *loggregator_v2.Envelope, name, value string) {
+	if value != "" && value != "-" {
+		e.Tags[name] = value
+	}
+}
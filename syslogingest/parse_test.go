@@ -0,0 +1,104 @@
+package syslogingest_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/syslogingest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	now := time.Date(2019, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+	It("parses RFC 5424 messages", func() {
+		e, err := syslogingest.Parse([]byte(`<14>1 2019-03-04T09:30:00.5Z some-host some-app 123 ID47 [origin@123 ip="10.0.0.1"] some-message`+"\n"), now)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(e.SourceId).To(Equal("some-app"))
+		Expect(e.Timestamp).To(Equal(time.Date(2019, time.March, 4, 9, 30, 0, 5e8, time.UTC).UnixNano()))
+		Expect(e.GetLog().GetPayload()).To(Equal([]byte("some-message")))
+		Expect(e.GetLog().GetType()).To(Equal(loggregator_v2.Log_OUT))
+		Expect(e.Tags).To(Equal(map[string]string{
+			"severity":      "info",
+			"facility":      "user",
+			"hostname":      "some-host",
+			"app_name":      "some-app",
+			"proc_id":       "123",
+			"msg_id":        "ID47",
+			"origin@123.ip": "10.0.0.1",
+		}))
+	})
+
+	It("parses the syslog written by envelopes", func() {
+		in := &loggregator_v2.Envelope{
+			Timestamp:  time.Date(2019, time.March, 4, 9, 0, 0, 0, time.UTC).UnixNano(),
+			SourceId:   "some-source",
+			InstanceId: "1",
+			Tags:       map[string]string{"deployment": "some-deployment"},
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte("some-message"), Type: loggregator_v2.Log_ERR},
+			},
+		}
+		msgs, err := in.Syslog()
+		Expect(err).ToNot(HaveOccurred())
+
+		e, err := syslogingest.Parse(msgs[0], now)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(e.SourceId).To(Equal("some-source"))
+		Expect(e.Timestamp).To(Equal(in.Timestamp))
+		Expect(e.GetLog()).To(Equal(in.GetLog()))
+		Expect(e.Tags).To(HaveKeyWithValue("deployment", "some-deployment"))
+		Expect(e.Tags).To(HaveKeyWithValue("proc_id", "1"))
+	})
+
+	It("parses RFC 3164 messages", func() {
+		e, err := syslogingest.Parse([]byte("<11>Mar  4 09:30:00 some-host sshd[42]: some-message"), now)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(e.SourceId).To(Equal("sshd"))
+		Expect(e.Timestamp).To(Equal(time.Date(2019, time.March, 4, 9, 30, 0, 0, time.UTC).UnixNano()))
+		Expect(e.GetLog().GetPayload()).To(Equal([]byte("some-message")))
+		Expect(e.GetLog().GetType()).To(Equal(loggregator_v2.Log_ERR))
+		Expect(e.Tags).To(Equal(map[string]string{
+			"severity": "err",
+			"facility": "user",
+			"hostname": "some-host",
+			"app_name": "sshd",
+			"proc_id":  "42",
+		}))
+	})
+
+	It("takes messages that do not follow RFC 3164 as they are", func() {
+		e, err := syslogingest.Parse([]byte("some-message"), now)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(e.Timestamp).To(Equal(now.UnixNano()))
+		Expect(e.GetLog().GetPayload()).To(Equal([]byte("some-message")))
+		Expect(e.Tags).To(Equal(map[string]string{
+			"severity": "notice",
+			"facility": "user",
+		}))
+	})
+
+	It("maps severities of err or more severe to stderr", func() {
+		for pri, typ := range map[string]loggregator_v2.Log_Type{
+			"<8>":   loggregator_v2.Log_ERR,
+			"<163>": loggregator_v2.Log_ERR,
+			"<12>":  loggregator_v2.Log_OUT,
+			"<15>":  loggregator_v2.Log_OUT,
+		} {
+			e, err := syslogingest.Parse([]byte(pri+"some-message"), now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(e.GetLog().GetType()).To(Equal(typ), pri)
+		}
+	})
+
+	It("returns an error for empty messages", func() {
+		_, err := syslogingest.Parse([]byte("\n"), now)
+		Expect(err).To(Equal(syslogingest.ErrEmptyMessage))
+	})
+})
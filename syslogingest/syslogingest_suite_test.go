@@ -0,0 +1,13 @@
+package syslogingest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSyslogingest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Syslog Ingest Suite")
+}
@@ -0,0 +1,21 @@
+package loggregator
+
+// envelopeTagHeadroom is the number of per-envelope tags, e.g. source_id and
+// instance_id, that an envelope's tag map is sized for in addition to the
+// client's default tags. Sizing the map up front avoids growing it while the
+// options are applied.
+const envelopeTagHeadroom = 4
+
+// envelopeTags returns a new tag map for an envelope that holds the given
+// default tags. The defaults are copied rather than shared because emit
+// options, middleware and the client itself write to the tags of every
+// envelope. Copying does not copy the tag strings, which keep pointing at
+// the data of the defaults.
+func envelopeTags(defaults map[string]string) map[string]string {
+	tags := make(map[string]string, len(defaults)+envelopeTagHeadroom)
+	for k, v := range defaults {
+		tags[k] = v
+	}
+
+	return tags
+}
@@ -0,0 +1,49 @@
+package loggregator_test
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+)
+
+func BenchmarkEmitLog(b *testing.B) {
+	server, err := newTestIngressServer(
+		fixture("server.crt"),
+		fixture("server.key"),
+		fixture("CA.crt"),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := server.start(); err != nil {
+		b.Fatal(err)
+	}
+	defer server.stop()
+
+	go func() {
+		recv := <-server.receivers
+		for {
+			if _, err := recv.Recv(); err != nil {
+				return
+			}
+		}
+	}()
+
+	client, _, _ := buildIngressClient(
+		server.addr,
+		10*time.Millisecond,
+		false,
+		loggregator.WithTag("deployment", "some-deployment"),
+		loggregator.WithTag("job", "some-job"),
+		loggregator.WithTag("index", "some-index"),
+		loggregator.WithTag("ip", "10.0.0.1"),
+	)
+	defer client.CloseSend()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		client.EmitLog("message", loggregator.WithAppInfo("some-app", "APP", "0"))
+	}
+}
@@ -0,0 +1,189 @@
+package loggregator
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// Sink receives the envelopes of a TeeClient. IngressClient implements Sink.
+type Sink interface {
+	EmitEnvelope(*loggregator_v2.Envelope) error
+}
+
+// SinkFunc is an adapter to allow the use of an ordinary function as a Sink.
+type SinkFunc func(*loggregator_v2.Envelope) error
+
+// EmitEnvelope calls f(e).
+func (f SinkFunc) EmitEnvelope(e *loggregator_v2.Envelope) error {
+	return f(e)
+}
+
+// NewWriterSink returns a Sink that writes every envelope as a single line
+// of JSON to w. It is safe for concurrent use.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+	m  jsonpb.Marshaler
+}
+
+func (s *writerSink) EmitEnvelope(e *loggregator_v2.Envelope) error {
+	data, err := s.m.MarshalToString(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = io.WriteString(s.w, data+"\n")
+	return err
+}
+
+// TeeOption is the option type passed into NewTeeClient.
+type TeeOption func(*TeeClient)
+
+// SinkOption configures how a TeeClient emits to a single sink.
+type SinkOption func(*teeSink)
+
+// WithSink adds a sink to the TeeClient. The name identifies the sink in
+// log messages.
+func WithSink(name string, s Sink, opts ...SinkOption) TeeOption {
+	return func(c *TeeClient) {
+		ts := &teeSink{
+			name: name,
+			sink: s,
+		}
+
+		for _, o := range opts {
+			o(ts)
+		}
+
+		c.sinks = append(c.sinks, ts)
+	}
+}
+
+// WithSinkFilter configures the sink to only receive envelopes for which
+// filter returns true.
+func WithSinkFilter(filter func(*loggregator_v2.Envelope) bool) SinkOption {
+	return func(s *teeSink) {
+		s.filter = filter
+	}
+}
+
+// WithSinkErrorHandler configures a handler for the errors of the sink. By
+// default, errors are logged.
+func WithSinkErrorHandler(handler func(error)) SinkOption {
+	return func(s *teeSink) {
+		s.onError = handler
+	}
+}
+
+// WithTeeTag adds a tag to every envelope emitted by the TeeClient.
+func WithTeeTag(name, value string) TeeOption {
+	return func(c *TeeClient) {
+		c.tags[name] = value
+	}
+}
+
+// WithTeeLogger allows for the configuration of a logger.
+// By default, the logger is disabled.
+func WithTeeLogger(l Logger) TeeOption {
+	return func(c *TeeClient) {
+		c.logger = l
+	}
+}
+
+type teeSink struct {
+	name    string
+	sink    Sink
+	filter  func(*loggregator_v2.Envelope) bool
+	onError func(error)
+}
+
+// TeeClient emits every envelope to each of its sinks. This allows mirroring
+// envelopes, e.g. to an analytics pipeline, without changing the code that
+// emits them. Every sink receives its own copy of the envelope, and an error
+// of one sink does not affect the others.
+type TeeClient struct {
+	sinks  []*teeSink
+	tags   map[string]string
+	logger Logger
+}
+
+// NewTeeClient creates a TeeClient. Sinks are added with WithSink.
+func NewTeeClient(opts ...TeeOption) *TeeClient {
+	c := &TeeClient{
+		tags:   make(map[string]string),
+		logger: log.New(ioutil.Discard, "", 0),
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	return c
+}
+
+// EmitLog sends a message to every sink.
+func (c *TeeClient) EmitLog(message string, opts ...EmitLogOption) {
+	c.emit(newLogEnvelope(time.Now(), c.tags, []byte(message), opts))
+}
+
+// EmitGauge sends the configured gauge values to every sink.
+func (c *TeeClient) EmitGauge(opts ...EmitGaugeOption) {
+	c.emit(newGaugeEnvelope(time.Now(), c.tags, opts))
+}
+
+// EmitCounter sends a counter envelope with a delta of 1 to every sink.
+func (c *TeeClient) EmitCounter(name string, opts ...EmitCounterOption) {
+	c.emit(newCounterEnvelope(time.Now(), c.tags, name, opts))
+}
+
+// EmitTimer sends a timer envelope with the given name, start time and stop
+// time to every sink.
+func (c *TeeClient) EmitTimer(name string, start, stop time.Time, opts ...EmitTimerOption) {
+	c.emit(newTimerEnvelope(time.Now(), c.tags, name, start, stop, opts))
+}
+
+func (c *TeeClient) emit(e *loggregator_v2.Envelope) {
+	r := takeRouting(e)
+	for _, s := range c.sinks {
+		if s.filter != nil && !s.filter(e) {
+			continue
+		}
+
+		cp := proto.Clone(e).(*loggregator_v2.Envelope)
+		if _, ok := s.sink.(*IngressClient); ok && r.set {
+			// An IngressClient takes the route chosen with WithBatching
+			// off the envelope before anything else sees it.
+			if cp.Tags == nil {
+				cp.Tags = make(map[string]string, 1)
+			}
+			cp.Tags[batchingTag] = strconv.FormatBool(r.route == Batched)
+		}
+
+		err := s.sink.EmitEnvelope(cp)
+		if err == nil {
+			continue
+		}
+
+		if s.onError != nil {
+			s.onError(err)
+			continue
+		}
+
+		c.logger.Printf("Error while emitting to sink %s: %s", s.name, err)
+	}
+}
@@ -0,0 +1,122 @@
+package loggregator_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TeeClient", func() {
+	var (
+		a, b *spySink
+	)
+
+	BeforeEach(func() {
+		a = &spySink{}
+		b = &spySink{}
+	})
+
+	It("emits every envelope to every sink", func() {
+		c := loggregator.NewTeeClient(
+			loggregator.WithSink("a", a),
+			loggregator.WithSink("b", b),
+			loggregator.WithTeeTag("some-tag", "some-value"),
+		)
+
+		c.EmitLog("message")
+		c.EmitGauge(loggregator.WithGaugeValue("some-gauge", 1, "unit"))
+		c.EmitCounter("some-counter")
+		c.EmitTimer("some-timer", time.Now(), time.Now())
+
+		Expect(a.envelopes).To(HaveLen(4))
+		Expect(b.envelopes).To(HaveLen(4))
+		Expect(a.envelopes[0].GetLog().GetPayload()).To(Equal([]byte("message")))
+		Expect(a.envelopes[0].Tags).To(HaveKeyWithValue("some-tag", "some-value"))
+	})
+
+	It("gives every sink its own copy of the envelope", func() {
+		c := loggregator.NewTeeClient(
+			loggregator.WithSink("a", a),
+			loggregator.WithSink("b", b),
+			loggregator.WithTeeTag("some-tag", "some-value"),
+		)
+
+		c.EmitLog("message")
+		a.envelopes[0].Tags["mutated"] = "true"
+
+		Expect(b.envelopes[0].Tags).NotTo(HaveKey("mutated"))
+	})
+
+	It("only emits envelopes that pass the sink's filter", func() {
+		c := loggregator.NewTeeClient(
+			loggregator.WithSink("a", a, loggregator.WithSinkFilter(loggregator.IsCounter)),
+			loggregator.WithSink("b", b),
+		)
+
+		c.EmitLog("message")
+		c.EmitCounter("some-counter")
+
+		Expect(a.envelopes).To(HaveLen(1))
+		Expect(a.envelopes[0].GetCounter().GetName()).To(Equal("some-counter"))
+		Expect(b.envelopes).To(HaveLen(2))
+	})
+
+	It("handles the errors of each sink separately", func() {
+		a.err = errors.New("some-error")
+		logger := newSpyLogger()
+
+		var handled []error
+		c := loggregator.NewTeeClient(
+			loggregator.WithSink("a", a),
+			loggregator.WithSink("b", b, loggregator.WithSinkErrorHandler(func(err error) {
+				handled = append(handled, err)
+			})),
+			loggregator.WithTeeLogger(logger),
+		)
+
+		c.EmitLog("message")
+
+		Expect(b.envelopes).To(HaveLen(1))
+		Expect(handled).To(BeEmpty())
+		Expect(logger.messages()).To(ConsistOf("Error while emitting to sink a: some-error"))
+
+		b.err = errors.New("other-error")
+		c.EmitLog("message")
+
+		Expect(handled).To(ConsistOf(MatchError("other-error")))
+	})
+
+	It("writes envelopes as JSON lines", func() {
+		buf := &bytes.Buffer{}
+		c := loggregator.NewTeeClient(
+			loggregator.WithSink("file", loggregator.NewWriterSink(buf)),
+		)
+
+		c.EmitCounter("some-counter")
+		c.EmitCounter("other-counter")
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(2))
+		Expect(string(lines[0])).To(ContainSubstring(`"name":"some-counter"`))
+	})
+})
+
+type spySink struct {
+	envelopes []*loggregator_v2.Envelope
+	err       error
+}
+
+func (s *spySink) EmitEnvelope(e *loggregator_v2.Envelope) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.envelopes = append(s.envelopes, e)
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package testhelpers provides helpers for tests that assert on envelopes.
+package testhelpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// DiffOption configures DiffEnvelopes.
+type DiffOption func(*differ)
+
+// IgnoreTimestamps ignores the timestamp of the envelopes and the start and
+// stop times of timers.
+func IgnoreTimestamps() DiffOption {
+	return func(d *differ) {
+		d.ignoreTimestamps = true
+	}
+}
+
+// DiffEnvelopes returns a field by field description of the differences
+// between the envelopes, one difference per line. It returns an empty
+// string if the envelopes are equal. It may be used in assertions such as
+//
+//	Expect(testhelpers.DiffEnvelopes(expected, actual)).To(BeEmpty())
+func DiffEnvelopes(expected, actual *loggregator_v2.Envelope, opts ...DiffOption) string {
+	d := &differ{}
+	for _, o := range opts {
+		o(d)
+	}
+
+	d.envelope(expected, actual)
+
+	return strings.Join(d.lines, "\n")
+}
+
+type differ struct {
+	ignoreTimestamps bool
+	lines            []string
+}
+
+func (d *differ) compare(field string, expected, actual interface{}) {
+	if expected == actual {
+		return
+	}
+
+	d.lines = append(d.lines, fmt.Sprintf("%s: expected %s, got %s", field, format(expected), format(actual)))
+}
+
+// rendered is a value that is printed as is.
+type rendered string
+
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "<missing>"
+	case rendered:
+		return string(v)
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func (d *differ) envelope(expected, actual *loggregator_v2.Envelope) {
+	if expected == nil || actual == nil {
+		if expected != actual {
+			d.compare("envelope", nilOr(expected == nil, "envelope"), nilOr(actual == nil, "envelope"))
+		}
+		return
+	}
+
+	if !d.ignoreTimestamps {
+		d.compare("timestamp", expected.GetTimestamp(), actual.GetTimestamp())
+	}
+	d.compare("source_id", expected.GetSourceId(), actual.GetSourceId())
+	d.compare("instance_id", expected.GetInstanceId(), actual.GetInstanceId())
+	d.tags(expected, actual)
+	d.message(expected, actual)
+}
+
+func nilOr(isNil bool, v string) interface{} {
+	if isNil {
+		return nil
+	}
+
+	return rendered(v)
+}
+
+// tags compares the preferred and the deprecated tags. Deprecated tags are
+// rendered with their type.
+func (d *differ) tags(expected, actual *loggregator_v2.Envelope) {
+	for _, k := range keys(expected.GetTags(), actual.GetTags()) {
+		d.compare("tags["+k+"]", stringOrNil(expected.GetTags(), k), stringOrNil(actual.GetTags(), k))
+	}
+
+	names := make(map[string]string)
+	for k := range expected.GetDeprecatedTags() {
+		names[k] = ""
+	}
+	for k := range actual.GetDeprecatedTags() {
+		names[k] = ""
+	}
+	for _, k := range keys(names, nil) {
+		d.compare("deprecated_tags["+k+"]", value(expected.GetDeprecatedTags()[k]), value(actual.GetDeprecatedTags()[k]))
+	}
+}
+
+func keys(a, b map[string]string) []string {
+	var ks []string
+	for k := range a {
+		ks = append(ks, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			ks = append(ks, k)
+		}
+	}
+	sort.Strings(ks)
+
+	return ks
+}
+
+func stringOrNil(m map[string]string, k string) interface{} {
+	v, ok := m[k]
+	if !ok {
+		return nil
+	}
+
+	return v
+}
+
+func value(v *loggregator_v2.Value) interface{} {
+	switch d := v.GetData().(type) {
+	case *loggregator_v2.Value_Text:
+		return rendered(fmt.Sprintf("text(%q)", d.Text))
+	case *loggregator_v2.Value_Integer:
+		return rendered(fmt.Sprintf("integer(%d)", d.Integer))
+	case *loggregator_v2.Value_Decimal:
+		return rendered(fmt.Sprintf("decimal(%g)", d.Decimal))
+	default:
+		return nil
+	}
+}
+
+func (d *differ) message(expected, actual *loggregator_v2.Envelope) {
+	et, at := envelope.Type(expected), envelope.Type(actual)
+	if et != at {
+		d.compare("type", et.String(), at.String())
+		return
+	}
+
+	switch et {
+	case envelope.Log:
+		e, a := expected.GetLog(), actual.GetLog()
+		d.compare("log.payload", string(e.GetPayload()), string(a.GetPayload()))
+		d.compare("log.type", e.GetType().String(), a.GetType().String())
+	case envelope.Counter:
+		e, a := expected.GetCounter(), actual.GetCounter()
+		d.compare("counter.name", e.GetName(), a.GetName())
+		d.compare("counter.delta", e.GetDelta(), a.GetDelta())
+		d.compare("counter.total", e.GetTotal(), a.GetTotal())
+	case envelope.Gauge:
+		e, a := expected.GetGauge().GetMetrics(), actual.GetGauge().GetMetrics()
+		names := make(map[string]string)
+		for k := range e {
+			names[k] = ""
+		}
+		for k := range a {
+			names[k] = ""
+		}
+		for _, k := range keys(names, nil) {
+			d.compare("gauge["+k+"]", gaugeValue(e[k]), gaugeValue(a[k]))
+		}
+	case envelope.Timer:
+		e, a := expected.GetTimer(), actual.GetTimer()
+		d.compare("timer.name", e.GetName(), a.GetName())
+		if !d.ignoreTimestamps {
+			d.compare("timer.start", e.GetStart(), a.GetStart())
+			d.compare("timer.stop", e.GetStop(), a.GetStop())
+		}
+	case envelope.Event:
+		e, a := expected.GetEvent(), actual.GetEvent()
+		d.compare("event.title", e.GetTitle(), a.GetTitle())
+		d.compare("event.body", e.GetBody(), a.GetBody())
+	}
+}
+
+func gaugeValue(v *loggregator_v2.GaugeValue) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	return rendered(fmt.Sprintf("%g %s", v.GetValue(), v.GetUnit()))
+}
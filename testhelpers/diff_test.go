@@ -0,0 +1,62 @@
+package testhelpers_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/testhelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiffEnvelopes", func() {
+	timer := func(ts int64, tags map[string]string) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			Timestamp: ts,
+			SourceId:  "some-source",
+			Tags:      tags,
+			DeprecatedTags: map[string]*loggregator_v2.Value{
+				"status_code": {Data: &loggregator_v2.Value_Integer{Integer: 200}},
+			},
+			Message: &loggregator_v2.Envelope_Timer{
+				Timer: &loggregator_v2.Timer{Name: "http", Start: ts - 10, Stop: ts},
+			},
+		}
+	}
+
+	It("returns an empty string for equal envelopes", func() {
+		Expect(testhelpers.DiffEnvelopes(timer(1, nil), timer(1, nil))).To(BeEmpty())
+	})
+
+	It("describes every difference", func() {
+		expected := timer(1, map[string]string{"a": "1", "b": "2"})
+		actual := timer(2, map[string]string{"a": "1", "c": "3"})
+		actual.SourceId = "other-source"
+		actual.DeprecatedTags["status_code"] = &loggregator_v2.Value{Data: &loggregator_v2.Value_Text{Text: "200"}}
+
+		Expect(testhelpers.DiffEnvelopes(expected, actual)).To(Equal(`timestamp: expected 1, got 2
+source_id: expected "some-source", got "other-source"
+tags[b]: expected "2", got <missing>
+tags[c]: expected <missing>, got "3"
+deprecated_tags[status_code]: expected integer(200), got text("200")
+timer.start: expected -9, got -8
+timer.stop: expected 1, got 2`))
+	})
+
+	It("ignores timestamps", func() {
+		Expect(testhelpers.DiffEnvelopes(timer(1, nil), timer(2, nil), testhelpers.IgnoreTimestamps())).To(BeEmpty())
+	})
+
+	It("describes different message types and contents", func() {
+		log := &loggregator_v2.Envelope{
+			Message: &loggregator_v2.Envelope_Log{Log: &loggregator_v2.Log{Payload: []byte("a")}},
+		}
+		otherLog := &loggregator_v2.Envelope{
+			Message: &loggregator_v2.Envelope_Log{Log: &loggregator_v2.Log{Payload: []byte("b"), Type: loggregator_v2.Log_ERR}},
+		}
+
+		Expect(testhelpers.DiffEnvelopes(log, timer(0, nil), testhelpers.IgnoreTimestamps())).To(ContainSubstring(`type: expected "log", got "timer"`))
+		Expect(testhelpers.DiffEnvelopes(log, otherLog)).To(Equal(`log.payload: expected "a", got "b"
+log.type: expected "OUT", got "ERR"`))
+		Expect(testhelpers.DiffEnvelopes(log, nil)).To(Equal("envelope: expected envelope, got <missing>"))
+	})
+})
@@ -0,0 +1,80 @@
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"code.cloudfoundry.org/go-loggregator/envelope"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// UpdateGoldenEnv is the environment variable that makes CompareGoldenFile
+// write the golden file instead of comparing with it.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// CanonicalJSON renders the envelopes as an indented JSON array that is
+// identical for equal envelopes: object keys, including tag names, are
+// sorted and the timestamps of the envelopes and the start and stop times
+// of timers are removed. The envelopes are not modified.
+func CanonicalJSON(envelopes []*loggregator_v2.Envelope) ([]byte, error) {
+	m := jsonpb.Marshaler{OrigName: true}
+
+	list := make([]interface{}, 0, len(envelopes))
+	for _, e := range envelopes {
+		e = envelope.DeepCopy(e)
+		e.Timestamp = 0
+		if t := e.GetTimer(); t != nil {
+			t.Start, t.Stop = 0, 0
+		}
+
+		s, err := m.MarshalToString(e)
+		if err != nil {
+			return nil, err
+		}
+
+		// The output of jsonpb is not stable, so it is decoded and encoded
+		// again with encoding/json, which sorts object keys.
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// CompareGoldenFile compares the canonical JSON of the envelopes with the
+// contents of the golden file at path. It returns an error describing the
+// difference if they are not equal. If the UPDATE_GOLDEN environment
+// variable is set, the golden file is written instead.
+func CompareGoldenFile(path string, envelopes []*loggregator_v2.Envelope) error {
+	actual, err := CanonicalJSON(envelopes)
+	if err != nil {
+		return err
+	}
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		return ioutil.WriteFile(path, actual, 0644)
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s (set %s=1 to create it)", err, UpdateGoldenEnv)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		return fmt.Errorf("envelopes do not match golden file %s (set %s=1 to update it):\nexpected:\n%s\nactual:\n%s", path, UpdateGoldenEnv, expected, actual)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package testhelpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/go-loggregator/testhelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Golden files", func() {
+	envelopes := func(ts int64) []*loggregator_v2.Envelope {
+		return []*loggregator_v2.Envelope{
+			{
+				Timestamp: ts,
+				SourceId:  "some-source",
+				Tags:      map[string]string{"b": "2", "a": "1"},
+				Message: &loggregator_v2.Envelope_Timer{
+					Timer: &loggregator_v2.Timer{Name: "http", Start: ts - 10, Stop: ts},
+				},
+			},
+			{
+				Timestamp: ts,
+				Message: &loggregator_v2.Envelope_Counter{
+					Counter: &loggregator_v2.Counter{Name: "requests", Delta: 1},
+				},
+			},
+		}
+	}
+
+	It("renders envelopes as canonical JSON", func() {
+		first, err := testhelpers.CanonicalJSON(envelopes(1))
+		Expect(err).NotTo(HaveOccurred())
+		second, err := testhelpers.CanonicalJSON(envelopes(2))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(first).To(Equal(second))
+		Expect(string(first)).To(Equal(`[
+  {
+    "source_id": "some-source",
+    "tags": {
+      "a": "1",
+      "b": "2"
+    },
+    "timer": {
+      "name": "http"
+    }
+  },
+  {
+    "counter": {
+      "delta": "1",
+      "name": "requests"
+    }
+  }
+]
+`))
+	})
+
+	It("does not modify the envelopes", func() {
+		e := envelopes(1)
+		_, err := testhelpers.CanonicalJSON(e)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(e[0].Timestamp).To(Equal(int64(1)))
+	})
+
+	Describe("CompareGoldenFile", func() {
+		var path string
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "golden")
+			Expect(err).NotTo(HaveOccurred())
+			path = filepath.Join(dir, "envelopes.json")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(filepath.Dir(path))
+			os.Unsetenv(testhelpers.UpdateGoldenEnv)
+		})
+
+		It("writes the golden file when updating and compares with it", func() {
+			Expect(testhelpers.CompareGoldenFile(path, envelopes(1))).To(MatchError(ContainSubstring("UPDATE_GOLDEN=1")))
+
+			os.Setenv(testhelpers.UpdateGoldenEnv, "1")
+			Expect(testhelpers.CompareGoldenFile(path, envelopes(1))).To(Succeed())
+			os.Unsetenv(testhelpers.UpdateGoldenEnv)
+
+			Expect(testhelpers.CompareGoldenFile(path, envelopes(2))).To(Succeed())
+
+			changed := envelopes(1)
+			changed[0].SourceId = "other-source"
+			Expect(testhelpers.CompareGoldenFile(path, changed)).To(MatchError(ContainSubstring("other-source")))
+		})
+	})
+})
@@ -0,0 +1,155 @@
+package loggregator
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// CounterEnvelope is an envelope that is known to hold a counter.
+type CounterEnvelope struct {
+	*loggregator_v2.Envelope
+	Counter *loggregator_v2.Counter
+}
+
+// GaugeEnvelope is an envelope that is known to hold a gauge.
+type GaugeEnvelope struct {
+	*loggregator_v2.Envelope
+	Gauge *loggregator_v2.Gauge
+}
+
+// LogEnvelope is an envelope that is known to hold a log.
+type LogEnvelope struct {
+	*loggregator_v2.Envelope
+	Log *loggregator_v2.Log
+}
+
+// StreamCounters streams the counters of the given source IDs, or of all
+// sources if none are given. The shard ID is used as in an
+// EgressBatchRequest. The returned channel is closed once the context is
+// done or the stream stopped because of a terminal error.
+func (c *EnvelopeStreamConnector) StreamCounters(ctx context.Context, shardID string, sourceIDs ...string) <-chan CounterEnvelope {
+	counters := make(chan CounterEnvelope, 100)
+	go func() {
+		defer close(counters)
+		c.streamTyped(ctx, shardID, sourceIDs, selectCounters, func(e *loggregator_v2.Envelope) bool {
+			if e.GetCounter() == nil {
+				return true
+			}
+
+			select {
+			case counters <- CounterEnvelope{Envelope: e, Counter: e.GetCounter()}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return counters
+}
+
+// StreamGauges streams the gauges of the given source IDs, or of all
+// sources if none are given. The shard ID is used as in an
+// EgressBatchRequest. The returned channel is closed once the context is
+// done or the stream stopped because of a terminal error.
+func (c *EnvelopeStreamConnector) StreamGauges(ctx context.Context, shardID string, sourceIDs ...string) <-chan GaugeEnvelope {
+	gauges := make(chan GaugeEnvelope, 100)
+	go func() {
+		defer close(gauges)
+		c.streamTyped(ctx, shardID, sourceIDs, selectGauges, func(e *loggregator_v2.Envelope) bool {
+			if e.GetGauge() == nil {
+				return true
+			}
+
+			select {
+			case gauges <- GaugeEnvelope{Envelope: e, Gauge: e.GetGauge()}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return gauges
+}
+
+// StreamLogs streams the logs of the given source IDs, or of all sources if
+// none are given. The shard ID is used as in an EgressBatchRequest. The
+// returned channel is closed once the context is done or the stream stopped
+// because of a terminal error.
+func (c *EnvelopeStreamConnector) StreamLogs(ctx context.Context, shardID string, sourceIDs ...string) <-chan LogEnvelope {
+	logs := make(chan LogEnvelope, 100)
+	go func() {
+		defer close(logs)
+		c.streamTyped(ctx, shardID, sourceIDs, selectLogs, func(e *loggregator_v2.Envelope) bool {
+			if e.GetLog() == nil {
+				return true
+			}
+
+			select {
+			case logs <- LogEnvelope{Envelope: e, Log: e.GetLog()}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return logs
+}
+
+// The selectors of the typed streams. Each sets the message type of a
+// selector.
+func selectCounters(s *loggregator_v2.Selector) {
+	s.Message = &loggregator_v2.Selector_Counter{Counter: &loggregator_v2.CounterSelector{}}
+}
+
+func selectGauges(s *loggregator_v2.Selector) {
+	s.Message = &loggregator_v2.Selector_Gauge{Gauge: &loggregator_v2.GaugeSelector{}}
+}
+
+func selectLogs(s *loggregator_v2.Selector) {
+	s.Message = &loggregator_v2.Selector_Log{Log: &loggregator_v2.LogSelector{}}
+}
+
+// streamTyped streams the envelopes of the given source IDs, or of all
+// sources if none are given, with selectors of the message type set by
+// selector. It hands every received envelope to extract, which sends the
+// envelope on if it holds the message type, until extract returns false,
+// the context is done or the stream stopped.
+func (c *EnvelopeStreamConnector) streamTyped(
+	ctx context.Context,
+	shardID string,
+	sourceIDs []string,
+	selector func(*loggregator_v2.Selector),
+	extract func(*loggregator_v2.Envelope) bool,
+) {
+	if len(sourceIDs) == 0 {
+		sourceIDs = []string{""}
+	}
+
+	req := &loggregator_v2.EgressBatchRequest{
+		ShardId:          shardID,
+		UsePreferredTags: true,
+	}
+	for _, id := range sourceIDs {
+		s := &loggregator_v2.Selector{SourceId: id}
+		selector(s)
+		req.Selectors = append(req.Selectors, s)
+	}
+
+	s := c.newStream(ctx, req)
+	for {
+		batch := s.recv()
+		if s.stopped || ctx.Err() != nil {
+			return
+		}
+
+		for _, e := range batch {
+			if !extract(e) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package loggregator_test
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("typed streams", func() {
+	var (
+		producer  *fakeEventProducer
+		connector *loggregator.EnvelopeStreamConnector
+		ctx       context.Context
+		cancel    func()
+	)
+
+	BeforeEach(func() {
+		var err error
+		producer, err = newFakeEventProducer()
+		Expect(err).NotTo(HaveOccurred())
+		producer.batch = []*loggregator_v2.Envelope{
+			{
+				SourceId: "some-source",
+				Message: &loggregator_v2.Envelope_Log{
+					Log: &loggregator_v2.Log{Payload: []byte("message")},
+				},
+			},
+			{
+				SourceId: "some-source",
+				Message: &loggregator_v2.Envelope_Counter{
+					Counter: &loggregator_v2.Counter{Name: "some-counter", Total: 5},
+				},
+			},
+			{
+				SourceId: "some-source",
+				Message: &loggregator_v2.Envelope_Gauge{
+					Gauge: &loggregator_v2.Gauge{
+						Metrics: map[string]*loggregator_v2.GaugeValue{
+							"some-gauge": {Value: 1},
+						},
+					},
+				},
+			},
+		}
+		producer.start()
+
+		tlsConf, err := NewClientMutualTLSConfig(
+			fixture("server.crt"),
+			fixture("server.key"),
+			fixture("CA.crt"),
+			"metron",
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		connector = loggregator.NewEnvelopeStreamConnector(producer.addr, tlsConf)
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+		producer.stop()
+	})
+
+	It("streams counters", func() {
+		counters := connector.StreamCounters(ctx, "some-shard", "some-source", "other-source")
+
+		var c loggregator.CounterEnvelope
+		Eventually(counters).Should(Receive(&c))
+		Expect(c.SourceId).To(Equal("some-source"))
+		Expect(c.Counter.Name).To(Equal("some-counter"))
+		Expect(c.Counter.Total).To(Equal(uint64(5)))
+
+		req := producer.actualReq()
+		Expect(req.ShardId).To(Equal("some-shard"))
+		Expect(req.Selectors).To(HaveLen(2))
+		Expect(req.Selectors[0].SourceId).To(Equal("some-source"))
+		Expect(req.Selectors[0].GetCounter()).NotTo(BeNil())
+		Expect(req.Selectors[1].SourceId).To(Equal("other-source"))
+	})
+
+	It("streams gauges", func() {
+		gauges := connector.StreamGauges(ctx, "some-shard")
+
+		var g loggregator.GaugeEnvelope
+		Eventually(gauges).Should(Receive(&g))
+		Expect(g.Gauge.Metrics).To(HaveKey("some-gauge"))
+
+		req := producer.actualReq()
+		Expect(req.Selectors).To(HaveLen(1))
+		Expect(req.Selectors[0].SourceId).To(BeEmpty())
+		Expect(req.Selectors[0].GetGauge()).NotTo(BeNil())
+	})
+
+	It("streams logs", func() {
+		logs := connector.StreamLogs(ctx, "some-shard")
+
+		var l loggregator.LogEnvelope
+		Eventually(logs).Should(Receive(&l))
+		Expect(l.Log.Payload).To(Equal([]byte("message")))
+		Expect(producer.actualReq().Selectors[0].GetLog()).NotTo(BeNil())
+	})
+
+	It("closes the channel once the context is done", func() {
+		logs := connector.StreamLogs(ctx, "some-shard")
+		Eventually(logs).Should(Receive())
+
+		cancel()
+
+		Eventually(logs).Should(BeClosed())
+	})
+})
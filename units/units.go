@@ -0,0 +1,75 @@
+// Package units enumerates the units of gauge metrics. Using the constants
+// rather than spelling out units keeps them consistent across components,
+// so that downstream dashboards do not have to cope with "Bytes", "B" and
+// "bytes" meaning the same. The constants are strings to be passed to e.g.
+// loggregator.WithGaugeValue.
+package units
+
+import "strings"
+
+// The units of gauge metrics.
+const (
+	Bytes          = "bytes"
+	MiB            = "MiB"
+	BytesPerSecond = "bytes/s"
+	Percentage     = "percentage"
+	Nanos          = "ns"
+	Millis         = "ms"
+	Seconds        = "s"
+	Count          = "count"
+	IOPS           = "iops"
+)
+
+// aliases maps lower case spellings of units to the units.
+var aliases = map[string]string{
+	"bytes":            Bytes,
+	"byte":             Bytes,
+	"b":                Bytes,
+	"by":               Bytes,
+	"mib":              MiB,
+	"mebibytes":        MiB,
+	"bytes/s":          BytesPerSecond,
+	"b/s":              BytesPerSecond,
+	"bytes_per_second": BytesPerSecond,
+	"percentage":       Percentage,
+	"percent":          Percentage,
+	"pct":              Percentage,
+	"%":                Percentage,
+	"ns":               Nanos,
+	"nanos":            Nanos,
+	"nanoseconds":      Nanos,
+	"ms":               Millis,
+	"millis":           Millis,
+	"milliseconds":     Millis,
+	"s":                Seconds,
+	"sec":              Seconds,
+	"seconds":          Seconds,
+	"count":            Count,
+	"iops":             IOPS,
+}
+
+// Known reports whether the unit is one of the units of this package.
+func Known(unit string) bool {
+	switch unit {
+	case Bytes, MiB, BytesPerSecond, Percentage, Nanos, Millis, Seconds, Count, IOPS:
+		return true
+	default:
+		return false
+	}
+}
+
+// Normalize returns the unit of this package that the given unit is a
+// spelling of, e.g. Bytes for "Bytes" or "B", and true. Spellings are
+// matched regardless of case. If the unit is unknown, it is returned
+// unchanged with false.
+func Normalize(unit string) (string, bool) {
+	if Known(unit) {
+		return unit, true
+	}
+
+	if u, ok := aliases[strings.ToLower(unit)]; ok {
+		return u, true
+	}
+
+	return unit, false
+}
@@ -0,0 +1,40 @@
+package units_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/units"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Units", func() {
+	It("knows its units", func() {
+		Expect(units.Known(units.Bytes)).To(BeTrue())
+		Expect(units.Known(units.BytesPerSecond)).To(BeTrue())
+		Expect(units.Known("Bytes")).To(BeFalse())
+		Expect(units.Known("nanofortnights")).To(BeFalse())
+	})
+
+	DescribeTable("normalizes spellings of units",
+		func(unit, expected string) {
+			u, ok := units.Normalize(unit)
+			Expect(ok).To(BeTrue())
+			Expect(u).To(Equal(expected))
+		},
+		Entry("canonical", "bytes", units.Bytes),
+		Entry("capitalized", "Bytes", units.Bytes),
+		Entry("symbol", "By", units.Bytes),
+		Entry("mebibytes", "mib", units.MiB),
+		Entry("percent", "Percent", units.Percentage),
+		Entry("percent sign", "%", units.Percentage),
+		Entry("nanoseconds", "nanoseconds", units.Nanos),
+		Entry("count", "Count", units.Count),
+	)
+
+	It("returns unknown units unchanged", func() {
+		u, ok := units.Normalize("nanofortnights")
+		Expect(ok).To(BeFalse())
+		Expect(u).To(Equal("nanofortnights"))
+	})
+})
@@ -258,6 +258,10 @@ func (e *envelopeWrapper) SetDelta(d uint64) {
 	e.Messages[0].GetCounterEvent().Delta = proto.Uint64(d)
 }
 
+func (e *envelopeWrapper) SetTotal(t uint64) {
+	e.Messages[0].GetCounterEvent().Total = proto.Uint64(t)
+}
+
 func (e *envelopeWrapper) SetTag(name string, value string) {
 	e.Tags[name] = value
 }
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	loggregator "code.cloudfoundry.org/go-loggregator"
+
+	"github.com/cloudfoundry/sonde-go/events"
+)
+
+// MethodTag returns the value of the method tag of a v2 HTTP timer for the
+// given v1 method, e.g. "GET" for events.Method_GET. It returns an empty
+// string for unknown methods.
+func MethodTag(m events.Method) string {
+	return events.Method_name[int32(m)]
+}
+
+// ParseMethod returns the v1 method for the value of the method tag of a v2
+// HTTP timer. It returns false if the value is not a known method.
+func ParseMethod(tag string) (events.Method, bool) {
+	m, ok := events.Method_value[tag]
+	return events.Method(m), ok
+}
+
+// PeerTypeTag returns the value of the peer_type tag of a v2 HTTP timer for
+// the given v1 peer type. It returns an empty string for unknown peer
+// types.
+func PeerTypeTag(p events.PeerType) loggregator.PeerType {
+	return loggregator.PeerType(events.PeerType_name[int32(p)])
+}
+
+// ParsePeerType returns the v1 peer type for the value of the peer_type tag
+// of a v2 HTTP timer. It returns false if the value is not a known peer
+// type.
+func ParsePeerType(tag loggregator.PeerType) (events.PeerType, bool) {
+	p, ok := events.PeerType_value[string(tag)]
+	return events.PeerType(p), ok
+}
@@ -0,0 +1,40 @@
+package v1_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/v1"
+	"github.com/cloudfoundry/sonde-go/events"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTP enums", func() {
+	It("maps methods to tag values and back", func() {
+		for m := range events.Method_name {
+			method := events.Method(m)
+
+			parsed, ok := v1.ParseMethod(v1.MethodTag(method))
+			Expect(ok).To(BeTrue())
+			Expect(parsed).To(Equal(method))
+		}
+
+		Expect(v1.MethodTag(events.Method_GET)).To(Equal("GET"))
+		Expect(v1.MethodTag(events.Method(-1))).To(BeEmpty())
+
+		_, ok := v1.ParseMethod("get")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("maps peer types to tag values and back", func() {
+		Expect(v1.PeerTypeTag(events.PeerType_Client)).To(Equal(loggregator.PeerTypeClient))
+		Expect(v1.PeerTypeTag(events.PeerType_Server)).To(Equal(loggregator.PeerTypeServer))
+
+		p, ok := v1.ParsePeerType(loggregator.PeerTypeServer)
+		Expect(ok).To(BeTrue())
+		Expect(p).To(Equal(events.PeerType_Server))
+
+		_, ok = v1.ParsePeerType("Proxy")
+		Expect(ok).To(BeFalse())
+	})
+})